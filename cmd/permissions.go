@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	cusCtx "goadmin/internal/context"
+	"goadmin/internal/model/permission"
+	"goadmin/internal/wire"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	permissionsDryRun bool
+
+	permissionsCmd = &cobra.Command{
+		Use:   "permissions",
+		Short: "权限管理命令",
+		Long:  `管理权限目录，权限由各路由注册时声明`,
+	}
+
+	permissionsSyncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "同步权限目录",
+		Long:  `将路由声明的权限写入 permissions 表，并列出数据库中未被任何路由声明的孤立权限`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPermissionsSync(permissionsDryRun)
+		},
+	}
+)
+
+func init() {
+	permissionsSyncCmd.Flags().BoolVar(&permissionsDryRun, "dry-run", false, "只显示差异，不写入数据库")
+	permissionsCmd.AddCommand(permissionsSyncCmd)
+}
+
+// runPermissionsSync 同步权限目录并输出差异
+func runPermissionsSync(dryRun bool) error {
+	// 初始化应用时注册路由，权限目录随之登记
+	app, err := wire.InitializeApp()
+	if err != nil {
+		return err
+	}
+	ctx := cusCtx.NewCliContext(context.Background())
+	defer ctx.Close()
+
+	plan, err := app.RoleService.SyncPermissionCatalog(ctx, dryRun)
+	if err != nil {
+		return fmt.Errorf("同步权限目录失败: %w", err)
+	}
+	printPermissions("新增", "+", plan.Create)
+	printPermissions("更新", "~", plan.Update)
+	printPermissions("孤立", "!", plan.Orphans)
+	switch {
+	case !plan.Changed():
+		fmt.Println("权限目录已是最新")
+	case dryRun:
+		fmt.Println("dry-run 模式，未写入数据库")
+	default:
+		fmt.Printf("同步完成: 新增 %d 更新 %d\n", len(plan.Create), len(plan.Update))
+	}
+	if len(plan.Orphans) > 0 {
+		fmt.Printf("孤立权限 %d 个，确认无角色使用后可手动删除\n", len(plan.Orphans))
+	}
+	return nil
+}
+
+// printPermissions 输出一组权限
+func printPermissions(title, mark string, list []permission.Permission) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", title, len(list))
+	for _, p := range list {
+		method := p.Method
+		if method == "" {
+			method = "*"
+		}
+		fmt.Printf("  %s %-24s %-6s %s\n", mark, p.Code, method, p.Path)
+	}
+}
@@ -57,4 +57,7 @@ func init() {
 	// 添加子命令
 	rootCmd.AddCommand(controlCmd)
 	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(permissionsCmd)
+	rootCmd.AddCommand(tenantCmd)
+	rootCmd.AddCommand(auditCmd)
 }
@@ -12,11 +12,12 @@ import (
 )
 
 type CronManager struct {
-	c *cron.Cron
+	c    *cron.Cron
+	jobs []*bizCron.Job
 }
 
-func NewCronManager() *CronManager {
-	return &CronManager{c: cron.New(cron.WithSeconds())}
+func NewCronManager(jobs []*bizCron.Job) *CronManager {
+	return &CronManager{c: cron.New(cron.WithSeconds()), jobs: jobs}
 }
 
 func (cm *CronManager) Name() string { return "CronManager" }
@@ -24,7 +25,7 @@ func (cm *CronManager) Name() string { return "CronManager" }
 func (cm *CronManager) Start(ctx context.Context) error {
 	// 封装任务注册
 	add := func(job *bizCron.Job) {
-		_, _ = cm.c.AddFunc(job.Spec, func() {
+		_, err := cm.c.AddFunc(job.Spec, func() {
 			defer func() {
 				if r := recover(); r != nil {
 					logger.Errorf("[Cron] job %s panic: %v %s", job.Name, r, debug.Stack())
@@ -34,10 +35,12 @@ func (cm *CronManager) Start(ctx context.Context) error {
 				logger.Errorf("[Cron] job %s error: %v", job.Name, err)
 			}
 		})
+		if err != nil {
+			logger.Errorf("[Cron] job %s invalid spec %q: %v", job.Name, job.Spec, err)
+		}
 	}
 
-	tasks := bizCron.Register()
-	for _, task := range tasks {
+	for _, task := range cm.jobs {
 		add(task)
 	}
 
@@ -5,6 +5,7 @@ package server
 import (
 	"context"
 	cusCtx "goadmin/internal/context"
+	roleservice "goadmin/internal/service/role"
 	"runtime"
 
 	"golang.org/x/sync/errgroup"
@@ -15,28 +16,35 @@ type HookFunc func(ctx *cusCtx.CliContext) error
 type HookServer struct {
 	hooks       []HookFunc
 	parallelNum int // 并发数
+	roleService roleservice.RoleService
 }
 
-func NewHookServer() *HookServer {
+func NewHookServer(roleService roleservice.RoleService) *HookServer {
 	num := runtime.NumCPU() - 1
 	if num < 1 {
 		num = 1
 	}
 	return &HookServer{
 		parallelNum: num,
+		roleService: roleService,
 	}
 }
 
 func (s *HookServer) register() error {
 	s.hooks = append(s.hooks,
-		func(ctx *cusCtx.CliContext) error {
-			ctx.Logger.Info("demo")
-			return nil
-		},
+		s.syncPermissionCatalog,
 	)
 	return nil
 }
 
+// syncPermissionCatalog 将路由声明的权限同步到权限表，失败时仅记录日志，不影响服务启动
+func (s *HookServer) syncPermissionCatalog(ctx *cusCtx.CliContext) error {
+	if _, err := s.roleService.SyncPermissionCatalog(ctx, false); err != nil {
+		ctx.Logger.Errorf("[Hook] 同步权限目录失败: %v", err)
+	}
+	return nil
+}
+
 func (s *HookServer) Name() string {
 	return "HookServer"
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	cusCtx "goadmin/internal/context"
+	"goadmin/internal/model/tenant"
+	"goadmin/internal/wire"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tenantCreateReq tenant.ProvisionRequest
+
+	tenantCmd = &cobra.Command{
+		Use:   "tenant",
+		Short: "租户管理命令",
+		Long:  `管理租户的开通`,
+	}
+
+	tenantCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "开通租户",
+		Long:  `创建租户及由模板克隆的租户管理员角色、管理员用户，并输出管理员初始密码`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTenantCreate(&tenantCreateReq)
+		},
+	}
+)
+
+func init() {
+	flags := tenantCreateCmd.Flags()
+	flags.StringVar(&tenantCreateReq.Name, "name", "", "租户名称")
+	flags.StringVar(&tenantCreateReq.Code, "code", "", "租户编码，全局唯一")
+	flags.StringVar(&tenantCreateReq.ContactEmail, "contact-email", "", "联系邮箱")
+	flags.StringVar(&tenantCreateReq.ContactPhone, "contact-phone", "", "联系电话")
+	flags.StringVar(&tenantCreateReq.Config, "config", "", "租户扩展配置 JSON")
+	flags.StringVar(&tenantCreateReq.AdminUsername, "admin-username", "", "管理员用户名，全局唯一")
+	flags.StringVar(&tenantCreateReq.AdminEmail, "admin-email", "", "管理员邮箱")
+	_ = tenantCreateCmd.MarkFlagRequired("name")
+	_ = tenantCreateCmd.MarkFlagRequired("code")
+	_ = tenantCreateCmd.MarkFlagRequired("admin-username")
+	tenantCmd.AddCommand(tenantCreateCmd)
+}
+
+// runTenantCreate 开通租户并输出管理员账号
+func runTenantCreate(req *tenant.ProvisionRequest) error {
+	app, err := wire.InitializeApp()
+	if err != nil {
+		return err
+	}
+	ctx := cusCtx.NewCliContext(context.Background())
+	defer ctx.Close()
+
+	rs, err := app.TenantService.ProvisionTenantCli(ctx, req)
+	if err != nil {
+		return fmt.Errorf("开通租户失败: %w", err)
+	}
+	fmt.Printf("租户已开通: %s (ID %d)\n", rs.Tenant.Code, rs.Tenant.ID)
+	fmt.Printf("管理员角色: %s\n", rs.AdminRole)
+	fmt.Printf("管理员账号: %s\n", rs.AdminUsername)
+	fmt.Printf("初始密码:   %s（仅显示一次，首次登录须修改）\n", rs.AdminPassword)
+	return nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"goadmin/pkg/ldap"
 	"goadmin/pkg/logger"
+	"goadmin/pkg/mail"
+	"goadmin/pkg/oidc"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,6 +23,13 @@ type Config struct {
 	Logger   logger.Config  `yaml:"logger"`
 	JWT      JWTConfig      `yaml:"jwt"`
 	Upload   UploadConfig   `yaml:"upload"`
+	Mail     mail.Config    `yaml:"mail"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Tenant   TenantConfig   `yaml:"tenant"`
+	Audit    AuditConfig    `yaml:"audit"`
+	Export   ExportConfig   `yaml:"export"`
+
+	PasswordReset PasswordResetConfig `yaml:"password_reset"`
 }
 
 // AppConfig 应用基础配置
@@ -98,6 +109,293 @@ type JWTConfig struct {
 	RefreshExpire   time.Duration `yaml:"refresh_expire"`
 	Issuer          string        `yaml:"issuer"`
 	RefreshTokenKey string        `yaml:"refresh_token_key"`
+	RevokedTokenKey string        `yaml:"revoked_token_key"`
+	Audience        []string      `yaml:"audience"`  // 令牌受众，首个为本服务，校验时要求令牌包含该受众
+	Algorithm       string        `yaml:"algorithm"` // 签名算法 HS256(默认)/RS256/ES256/EdDSA
+	Keys            []JWTKey      `yaml:"keys"`      // 非对称签名密钥，算法为 HS256 时使用 secret
+}
+
+// PlaceholderJWTSecret 示例配置中的 JWT 密钥，仅可用于本地开发
+const PlaceholderJWTSecret = "your-256-bit-secret"
+
+// Placeholder 是否仍在使用示例配置中的对称密钥
+func (c JWTConfig) Placeholder() bool {
+	return len(c.Keys) == 0 && c.Secret == PlaceholderJWTSecret
+}
+
+// JWTKey JWT签名密钥配置
+//
+// 同一时刻可存在多把密钥，签发时选用已生效且未退役、生效时间最晚的一把；
+// 退役后的密钥在访问令牌有效期内仍保留用于验签，实现平滑轮换
+type JWTKey struct {
+	Kid        string    `yaml:"kid"`         // 密钥ID，写入令牌头部
+	Algorithm  string    `yaml:"algorithm"`   // 为空时沿用 JWTConfig.Algorithm
+	PrivateKey string    `yaml:"private_key"` // 私钥PEM文件路径，仅用于验签的密钥可不配置
+	PublicKey  string    `yaml:"public_key"`  // 公钥PEM文件路径，为空时由私钥推导
+	ActivateAt time.Time `yaml:"activate_at"` // 开始签发的时间，为空表示立即生效
+	RetireAt   time.Time `yaml:"retire_at"`   // 停止签发的时间，为空表示长期有效
+}
+
+// PasswordResetConfig 找回密码配置，开关及有效期等在系统设置中维护
+type PasswordResetConfig struct {
+	Secret string `yaml:"secret"` // 签名找回密码令牌的密钥，为空时不能开启找回密码
+}
+
+// AuthConfig 登录认证配置，按部署区分
+type AuthConfig struct {
+	DisableLocalLogin bool            `yaml:"disable_local_login"` // 关闭本地账号密码认证
+	Chain             []AuthChainRule `yaml:"chain"`               // 按用户名选择认证器链，为空时依次尝试 ldap(启用时)、local
+	LDAP              ldap.Config     `yaml:"ldap"`                // LDAP / Active Directory 认证
+	OIDC              oidc.Config     `yaml:"oidc"`                // OIDC 单点登录
+	Cache             AuthCacheConfig `yaml:"cache"`               // 鉴权缓存
+	TwoFactorKey      string          `yaml:"two_factor_key"`      // 加密 TOTP 密钥的 AES-256 密钥，base64 编码的32字节，为空时无法启用双因素认证
+}
+
+// TwoFactorKeyBytes 解码 TOTP 密钥的加密密钥
+func (c AuthConfig) TwoFactorKeyBytes() ([]byte, error) {
+	if c.TwoFactorKey == "" {
+		return nil, fmt.Errorf("未配置 auth.two_factor_key")
+	}
+	key, err := base64.StdEncoding.DecodeString(c.TwoFactorKey)
+	if err != nil {
+		return nil, fmt.Errorf("auth.two_factor_key 不是有效的 base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("auth.two_factor_key 长度须为32字节，实际为 %d", len(key))
+	}
+	return key, nil
+}
+
+// AuthCacheConfig 鉴权缓存配置，缓存角色权限及登录用户信息
+//
+// 进程内 LRU 之后以 Redis 为二级缓存，变更时经 Redis 发布订阅通知所有实例失效
+type AuthCacheConfig struct {
+	Enable    bool          `yaml:"enable"`
+	Size      int           `yaml:"size"`       // 进程内最多缓存的条目数，默认 1024
+	LocalTTL  time.Duration `yaml:"local_ttl"`  // 进程内条目有效期，默认 1 分钟
+	RemoteTTL time.Duration `yaml:"remote_ttl"` // Redis 条目有效期，默认 10 分钟
+}
+
+// SizeOrDefault 进程内最多缓存的条目数
+func (c AuthCacheConfig) SizeOrDefault() int {
+	if c.Size <= 0 {
+		return 1024
+	}
+	return c.Size
+}
+
+// LocalTTLOrDefault 进程内条目有效期
+func (c AuthCacheConfig) LocalTTLOrDefault() time.Duration {
+	if c.LocalTTL <= 0 {
+		return time.Minute
+	}
+	return c.LocalTTL
+}
+
+// RemoteTTLOrDefault Redis 条目有效期
+func (c AuthCacheConfig) RemoteTTLOrDefault() time.Duration {
+	if c.RemoteTTL <= 0 {
+		return 10 * time.Minute
+	}
+	return c.RemoteTTL
+}
+
+// TenantConfig 多租户配置
+//
+// 普通用户固定访问所属租户；超级管理员默认可跨租户访问，可通过请求头或子域名指定租户
+type TenantConfig struct {
+	Header     string `yaml:"header"`      // 指定租户编码的请求头，默认 X-Tenant
+	Domain     string `yaml:"domain"`      // 租户子域名的基础域名，如 admin.example.com，为空时不按子域名识别
+	AdminRole  string `yaml:"admin_role"`  // 开通租户时克隆的管理员角色模板编码，默认 tenant_admin
+	ArchiveDir string `yaml:"archive_dir"` // 注销租户前归档数据的目录，默认 data/tenant_archive
+}
+
+// HeaderOrDefault 指定租户编码的请求头
+func (c TenantConfig) HeaderOrDefault() string {
+	if c.Header == "" {
+		return "X-Tenant"
+	}
+	return c.Header
+}
+
+// AdminRoleOrDefault 管理员角色模板编码
+func (c TenantConfig) AdminRoleOrDefault() string {
+	if c.AdminRole == "" {
+		return "tenant_admin"
+	}
+	return c.AdminRole
+}
+
+// ArchiveDirOrDefault 租户数据归档目录
+func (c TenantConfig) ArchiveDirOrDefault() string {
+	if c.ArchiveDir == "" {
+		return filepath.Join("data", "tenant_archive")
+	}
+	return c.ArchiveDir
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	CheckpointKey  string `yaml:"checkpoint_key"`  // 哈希链检查点的签名密钥，为空时不生成检查点
+	CheckpointSpec string `yaml:"checkpoint_spec"` // 生成检查点的 cron 表达式（含秒），默认每小时
+
+	BufferSize    int           `yaml:"buffer_size"`    // 异步写入队列长度，队列满时丢弃，默认 4096
+	BatchSize     int           `yaml:"batch_size"`     // 每批写入的条数，默认 100
+	FlushInterval time.Duration `yaml:"flush_interval"` // 未满一批时的写入间隔，默认 1 秒
+	SpoolFile     string        `yaml:"spool_file"`     // 数据库不可用时暂存日志的本地文件，默认 data/operate_log_spool.jsonl
+
+	RetentionDays int    `yaml:"retention_days"` // 日志保留天数，为 0 时不按天数清理
+	RetentionRows int64  `yaml:"retention_rows"` // 最多保留的日志条数，为 0 时不按条数清理
+	RetentionSpec string `yaml:"retention_spec"` // 清理过期日志的 cron 表达式（含秒），默认每天 03:30
+	ArchiveDir    string `yaml:"archive_dir"`    // 过期日志删除前的归档目录，默认 data/operate_log_archive
+}
+
+// RetentionEnabled 是否配置了日志保留策略
+func (c AuditConfig) RetentionEnabled() bool {
+	return c.RetentionDays > 0 || c.RetentionRows > 0
+}
+
+// CheckpointSpecOrDefault 生成检查点的 cron 表达式
+func (c AuditConfig) CheckpointSpecOrDefault() string {
+	if c.CheckpointSpec == "" {
+		return "0 0 * * * *"
+	}
+	return c.CheckpointSpec
+}
+
+// BufferSizeOrDefault 异步写入队列长度
+func (c AuditConfig) BufferSizeOrDefault() int {
+	if c.BufferSize <= 0 {
+		return 4096
+	}
+	return c.BufferSize
+}
+
+// BatchSizeOrDefault 每批写入的条数
+func (c AuditConfig) BatchSizeOrDefault() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// FlushIntervalOrDefault 未满一批时的写入间隔
+func (c AuditConfig) FlushIntervalOrDefault() time.Duration {
+	if c.FlushInterval <= 0 {
+		return time.Second
+	}
+	return c.FlushInterval
+}
+
+// SpoolFileOrDefault 数据库不可用时暂存日志的本地文件
+func (c AuditConfig) SpoolFileOrDefault() string {
+	if c.SpoolFile == "" {
+		return filepath.Join("data", "operate_log_spool.jsonl")
+	}
+	return c.SpoolFile
+}
+
+// RetentionSpecOrDefault 清理过期日志的 cron 表达式
+func (c AuditConfig) RetentionSpecOrDefault() string {
+	if c.RetentionSpec == "" {
+		return "0 30 3 * * *"
+	}
+	return c.RetentionSpec
+}
+
+// ArchiveDirOrDefault 过期日志的归档目录
+func (c AuditConfig) ArchiveDirOrDefault() string {
+	if c.ArchiveDir == "" {
+		return filepath.Join("data", "operate_log_archive")
+	}
+	return c.ArchiveDir
+}
+
+// ExportConfig 列表导出配置
+type ExportConfig struct {
+	Dir       string        `yaml:"dir"`        // 导出文件目录，默认 data/export；多实例部署时须为各实例共享的存储
+	Instance  string        `yaml:"instance"`   // 本实例的标识，记录在导出任务上，默认主机名
+	Workers   int           `yaml:"workers"`    // 同时执行的导出任务数，默认 2
+	QueueSize int           `yaml:"queue_size"` // 等待执行的导出任务数上限，默认 100
+	ChunkSize int           `yaml:"chunk_size"` // 每次读取的行数，默认 500
+	SyncRows  int64         `yaml:"sync_rows"`  // 不超过此行数时在请求中直接导出，默认 1000
+	MaxRows   int64         `yaml:"max_rows"`   // 单次导出的最大行数，默认 1000000
+	Expire    time.Duration `yaml:"expire"`     // 导出文件保留时长，默认 24 小时
+}
+
+// DirOrDefault 导出文件目录
+func (c ExportConfig) DirOrDefault() string {
+	if c.Dir == "" {
+		return filepath.Join("data", "export")
+	}
+	return c.Dir
+}
+
+// InstanceOrDefault 本实例的标识，未配置时取主机名
+//
+// 重启时只将本实例未结束的任务标记为失败，各实例须不同且重启后保持不变
+func (c ExportConfig) InstanceOrDefault() string {
+	if c.Instance != "" {
+		return c.Instance
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "default"
+}
+
+// WorkersOrDefault 同时执行的导出任务数
+func (c ExportConfig) WorkersOrDefault() int {
+	if c.Workers <= 0 {
+		return 2
+	}
+	return c.Workers
+}
+
+// QueueSizeOrDefault 等待执行的导出任务数上限
+func (c ExportConfig) QueueSizeOrDefault() int {
+	if c.QueueSize <= 0 {
+		return 100
+	}
+	return c.QueueSize
+}
+
+// ChunkSizeOrDefault 每次读取的行数
+func (c ExportConfig) ChunkSizeOrDefault() int {
+	if c.ChunkSize <= 0 {
+		return 500
+	}
+	return c.ChunkSize
+}
+
+// SyncRowsOrDefault 在请求中直接导出的最大行数
+func (c ExportConfig) SyncRowsOrDefault() int64 {
+	if c.SyncRows <= 0 {
+		return 1000
+	}
+	return c.SyncRows
+}
+
+// MaxRowsOrDefault 单次导出的最大行数
+func (c ExportConfig) MaxRowsOrDefault() int64 {
+	if c.MaxRows <= 0 {
+		return 1000000
+	}
+	return c.MaxRows
+}
+
+// ExpireOrDefault 导出文件保留时长
+func (c ExportConfig) ExpireOrDefault() time.Duration {
+	if c.Expire <= 0 {
+		return 24 * time.Hour
+	}
+	return c.Expire
+}
+
+// AuthChainRule 认证器链规则，按顺序匹配第一条
+type AuthChainRule struct {
+	Pattern        string   `yaml:"pattern"`        // 用户名通配符，path.Match 语法
+	Authenticators []string `yaml:"authenticators"` // 依次尝试的认证器 local/ldap
 }
 
 // UploadConfig 文件上传配置
@@ -145,6 +443,11 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("解析时间字段失败: %w", err)
 	}
 
+	// 示例密钥众所周知，任何人都可伪造令牌，仅允许在调试模式下使用
+	if cfg.JWT.Placeholder() && !cfg.App.Debug {
+		return nil, fmt.Errorf("jwt.secret 仍为示例值，请修改后再启动")
+	}
+
 	return &cfg, nil
 }
 
@@ -0,0 +1,91 @@
+package api_key
+
+import (
+	"net/http"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/api_key"
+	"goadmin/internal/model/schema"
+	apikeySrv "goadmin/internal/service/api_key"
+)
+
+type Handler struct {
+	apiKeySrv apikeySrv.APIKeyService
+}
+
+func NewHandler(apiKeySrv apikeySrv.APIKeyService) *Handler {
+	return &Handler{
+		apiKeySrv: apiKeySrv,
+	}
+}
+
+// ListAPIKeys 获取当前用户的 API 密钥
+func (h *Handler) ListAPIKeys(ctx *context.Context) {
+	list, err := h.apiKeySrv.ListAPIKeys(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    list,
+	})
+}
+
+// CreateAPIKey 创建 API 密钥
+func (h *Handler) CreateAPIKey(ctx *context.Context) {
+	var req api_key.CreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	rs, err := h.apiKeySrv.CreateAPIKey(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    rs,
+	})
+}
+
+// RevokeAPIKey 吊销 API 密钥
+func (h *Handler) RevokeAPIKey(ctx *context.Context) {
+	var req schema.IDRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	if err := h.apiKeySrv.RevokeAPIKey(ctx, &req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
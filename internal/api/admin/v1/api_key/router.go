@@ -0,0 +1,27 @@
+package api_key
+
+import (
+	"goadmin/internal/catalog"
+	"goadmin/internal/context"
+	"goadmin/internal/middleware"
+	apikeySrv "goadmin/internal/service/api_key"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册 API 密钥相关的API路由
+func RegisterRoutes(r *gin.RouterGroup, apiKeyService apikeySrv.APIKeyService) {
+	handler := NewHandler(apiKeyService)
+
+	group := r.Group("/api_key")
+	{
+		// 需要认证的接口
+		authGroup := catalog.Wrap(group.Group("/"), "api_key")
+		authGroup.Use(middleware.Auth())
+		{
+			authGroup.GET("/list", catalog.Perm("api_key_list", "API密钥列表").AsGlobal(), context.Build(handler.ListAPIKeys))
+			authGroup.POST("/create", catalog.Perm("api_key_create", "API密钥创建"), context.Build(handler.CreateAPIKey))
+			authGroup.POST("/revoke", catalog.Perm("api_key_revoke", "API密钥吊销").AsGlobal(), context.Build(handler.RevokeAPIKey))
+		}
+	}
+}
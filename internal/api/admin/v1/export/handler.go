@@ -0,0 +1,93 @@
+package export
+
+import (
+	"net/http"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modelexport "goadmin/internal/model/export"
+	"goadmin/internal/model/schema"
+	exportSrv "goadmin/internal/service/export"
+	"goadmin/pkg/export"
+)
+
+type Handler struct {
+	exportSrv exportSrv.ExportService
+}
+
+func NewHandler(exportSrv exportSrv.ExportService) *Handler {
+	return &Handler{
+		exportSrv: exportSrv,
+	}
+}
+
+// CreateJob 导出资源，查询参数同对应的列表接口
+func (h *Handler) CreateJob(resource string) context.HandlerFunc {
+	return func(ctx *context.Context) {
+		var req modelexport.CreateRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, schema.Response{
+				Code:    http.StatusBadRequest,
+				Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+			})
+			return
+		}
+
+		job, err := h.exportSrv.CreateJob(ctx, resource, &req)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, schema.Response{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, schema.Response{
+			Code:    http.StatusOK,
+			Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+			Data:    job,
+		})
+	}
+}
+
+// ListJobs 获取当前用户的导出任务
+func (h *Handler) ListJobs(ctx *context.Context) {
+	list, err := h.exportSrv.ListJobs(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    list,
+	})
+}
+
+// Download 下载导出文件
+func (h *Handler) Download(ctx *context.Context) {
+	var req schema.IDRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	job, path, err := h.exportSrv.GetFile(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", export.ContentType(job.Format))
+	ctx.FileAttachment(path, job.Filename())
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"goadmin/internal/catalog"
+	"goadmin/internal/context"
+	"goadmin/internal/middleware"
+	modelexport "goadmin/internal/model/export"
+	exportSrv "goadmin/internal/service/export"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册列表导出相关的API路由
+//
+// 各资源的导出登记到所属模块的权限目录，与列表权限分开授权
+func RegisterRoutes(r *gin.RouterGroup, exportService exportSrv.ExportService) {
+	handler := NewHandler(exportService)
+
+	group := r.Group("/export")
+	{
+		// 需要认证的接口
+		group.Use(middleware.Auth())
+		catalog.Wrap(group, "user").POST("/user", catalog.Perm("user_export", "用户导出"),
+			context.Build(handler.CreateJob(modelexport.ResourceUser)))
+		catalog.Wrap(group, "role").POST("/role", catalog.Perm("role_export", "角色导出"),
+			context.Build(handler.CreateJob(modelexport.ResourceRole)))
+		catalog.Wrap(group, "tenant").POST("/tenant", catalog.Perm("tenant_export", "租户导出"),
+			context.Build(handler.CreateJob(modelexport.ResourceTenant)))
+		catalog.Wrap(group, "position").POST("/position", catalog.Perm("position_export", "位置导出"),
+			context.Build(handler.CreateJob(modelexport.ResourcePosition)))
+		catalog.Wrap(group, "operate_log").POST("/operate_log", catalog.Perm("operate_log_export", "操作日志导出"),
+			context.Build(handler.CreateJob(modelexport.ResourceOperateLog)))
+
+		jobGroup := catalog.Wrap(group, "export")
+		jobGroup.GET("/jobs", catalog.Perm("export_jobs", "我的导出").AsGlobal(), context.Build(handler.ListJobs))
+		jobGroup.GET("/download", catalog.Perm("export_download", "下载导出文件").AsGlobal(), context.Build(handler.Download))
+	}
+}
@@ -48,3 +48,12 @@ func (h *Handler) ListOperateLogs(ctx *context.Context) {
 		},
 	})
 }
+
+// WriterStats 获取操作日志异步写入的累计统计，含丢弃及重新写入的条数
+func (h *Handler) WriterStats(ctx *context.Context) {
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    h.logSrv.WriterStats(),
+	})
+}
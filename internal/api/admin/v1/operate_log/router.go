@@ -1,6 +1,7 @@
 package operate_log
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	operatelog "goadmin/internal/service/operate_log"
@@ -15,11 +16,12 @@ func RegisterRoutes(r *gin.RouterGroup, logService operatelog.OperateLogService)
 	group := r.Group("/operate_log")
 	{
 		// 需要认证的接口
-		authGroup := group.Group("/")
+		authGroup := catalog.Wrap(group.Group("/"), "operate_log")
 
 		authGroup.Use(middleware.Auth())
 		{
-			authGroup.GET("/list", context.Build(handler.ListOperateLogs))
+			authGroup.GET("/list", catalog.Perm("operate_log", "操作日志"), context.Build(handler.ListOperateLogs))
+			authGroup.GET("/writer_stats", catalog.Perm("operate_log_writer_stats", "操作日志写入统计"), context.Build(handler.WriterStats))
 		}
 	}
 }
\ No newline at end of file
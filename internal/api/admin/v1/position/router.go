@@ -1,6 +1,7 @@
 package position
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	positionSrv "goadmin/internal/service/position"
@@ -15,14 +16,14 @@ func RegisterRoutes(r *gin.RouterGroup, positionService positionSrv.PositionServ
 	group := r.Group("/position")
 	{
 		// 需要认证的接口
-		authGroup := group.Group("/")
+		authGroup := catalog.Wrap(group.Group("/"), "position")
 		authGroup.Use(middleware.Auth())
 		{
-			authGroup.GET("/list", context.Build(handler.ListPositions))
-			authGroup.GET("/get", context.Build(handler.GetPosition))
-			authGroup.POST("/create", context.Build(handler.CreatePosition))
-			authGroup.POST("/update", context.Build(handler.UpdatePosition))
-			authGroup.POST("/delete", context.Build(handler.DeletePosition))
+			authGroup.GET("/list", catalog.Perm("position_list", "位置列表"), context.Build(handler.ListPositions))
+			authGroup.GET("/get", catalog.Perm("position_info", "位置详情"), context.Build(handler.GetPosition))
+			authGroup.POST("/create", catalog.Perm("position_create", "位置创建"), context.Build(handler.CreatePosition))
+			authGroup.POST("/update", catalog.Perm("position_update", "位置更新"), context.Build(handler.UpdatePosition))
+			authGroup.POST("/delete", catalog.Perm("position_delete", "位置删除"), context.Build(handler.DeletePosition))
 		}
 	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package role
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	rolesrv "goadmin/internal/service/role"
@@ -15,21 +16,22 @@ func RegisterRoutes(r *gin.RouterGroup, roleService rolesrv.RoleService) {
 	group := r.Group("/role")
 	{
 		// 需要认证的接口
-		group.Use(middleware.Auth())
+		authGroup := catalog.Wrap(group, "role")
+		authGroup.Use(middleware.Auth())
 		{
 			// 角色管理
-			group.GET("/list", context.Build(handler.ListRoles))
-			group.GET("/all", context.Build(handler.ListAllRoles))
-			group.GET("/active", context.Build(handler.ListActiveRoles))
-			group.POST("/get", context.Build(handler.GetRole))
-			group.POST("/create", context.Build(handler.CreateRole))
-			group.POST("/update", context.Build(handler.UpdateRole))
-			group.POST("/delete", context.Build(handler.DeleteRole))
+			authGroup.GET("/list", catalog.Perm("role_list", "角色列表"), context.Build(handler.ListRoles))
+			authGroup.GET("/all", catalog.Perm("role_all", "全部角色"), context.Build(handler.ListAllRoles))
+			authGroup.GET("/active", catalog.Perm("role_active", "活跃角色"), context.Build(handler.ListActiveRoles))
+			authGroup.POST("/get", catalog.Perm("role_info", "角色详情"), context.Build(handler.GetRole))
+			authGroup.POST("/create", catalog.Perm("role_create", "角色创建"), context.Build(handler.CreateRole))
+			authGroup.POST("/update", catalog.Perm("role_update", "角色更新"), context.Build(handler.UpdateRole))
+			authGroup.POST("/delete", catalog.Perm("role_delete", "角色删除"), context.Build(handler.DeleteRole))
 
 			// 角色权限管理
-			group.GET("/permissions/get", context.Build(handler.GetRolePermissions))
-			group.POST("/permissions/assign", context.Build(handler.AssignPermissions))
-			group.GET("/permissions/all", context.Build(handler.ListAllPermissions))
+			authGroup.GET("/permissions/get", catalog.Perm("role_perm_info", "角色权限查看"), context.Build(handler.GetRolePermissions))
+			authGroup.POST("/permissions/assign", catalog.Perm("role_perm_set", "角色权限设置"), context.Build(handler.AssignPermissions))
+			authGroup.GET("/permissions/all", catalog.Perm("role_perm_all", "全部权限"), context.Build(handler.ListAllPermissions))
 		}
 	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	"goadmin/internal/service/setting"
@@ -18,18 +19,18 @@ func RegisterRoutes(r *gin.RouterGroup, settingService setting.ServerSettingServ
 		group.GET("/get_settings", context.Build(handler.GetSettings))
 
 		// 需要认证的接口
-		authGroup := group.Use(middleware.Auth())
+		authGroup := catalog.Wrap(group, "server").Use(middleware.Auth())
 		{
 			// 系统设置操作
-			authGroup.POST("/set_settings", context.Build(handler.UpdateSettings))
+			authGroup.POST("/set_settings", catalog.Perm("server_settings_set", "系统设置批量更新"), context.Build(handler.UpdateSettings))
 
 			// 基础配置操作
-			authGroup.GET("/get", context.Build(handler.GetByNames))
-			authGroup.POST("/set", context.Build(handler.SetByName))
+			authGroup.GET("/get", catalog.Perm("server_get", "系统设置查询"), context.Build(handler.GetByNames))
+			authGroup.POST("/set", catalog.Perm("server_set", "系统设置"), context.Build(handler.SetByName))
 
 			// 加密配置操作
-			authGroup.POST("/encrypted", context.Build(handler.SetEncryptedValue))
-			authGroup.GET("/decrypted", context.Build(handler.GetDecryptedValue))
+			authGroup.POST("/encrypted", catalog.Perm("server_encrypted", "系统设置(加密)"), context.Build(handler.SetEncryptedValue))
+			authGroup.GET("/decrypted", catalog.Perm("server_decrypted", "系统设置(解密)查询"), context.Build(handler.GetDecryptedValue))
 		}
 
 	}
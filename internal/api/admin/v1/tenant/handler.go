@@ -20,9 +20,9 @@ func NewHandler(tenantSrv tenantSrv.TenantService) *Handler {
 	}
 }
 
-// CreateTenant 创建租户
+// CreateTenant 开通租户，返回管理员初始密码
 func (h *Handler) CreateTenant(ctx *context.Context) {
-	var req tenant.CreateRequest
+	var req tenant.ProvisionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, schema.Response{
 			Code:    http.StatusBadRequest,
@@ -31,7 +31,7 @@ func (h *Handler) CreateTenant(ctx *context.Context) {
 		return
 	}
 
-	err := h.tenantSrv.CreateTenant(ctx, &req)
+	rs, err := h.tenantSrv.ProvisionTenant(ctx, &req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, schema.Response{
 			Code:    http.StatusInternalServerError,
@@ -43,6 +43,7 @@ func (h *Handler) CreateTenant(ctx *context.Context) {
 	ctx.JSON(http.StatusOK, schema.Response{
 		Code:    http.StatusOK,
 		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    rs,
 	})
 }
 
@@ -72,7 +73,7 @@ func (h *Handler) UpdateTenant(ctx *context.Context) {
 	})
 }
 
-// DeleteTenant 删除租户
+// DeleteTenant 注销租户，归档租户数据后删除
 func (h *Handler) DeleteTenant(ctx *context.Context) {
 	var req schema.IDRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -83,7 +84,7 @@ func (h *Handler) DeleteTenant(ctx *context.Context) {
 		return
 	}
 
-	err := h.tenantSrv.DeleteTenant(ctx, &req)
+	rs, err := h.tenantSrv.DeprovisionTenant(ctx, &req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, schema.Response{
 			Code:    http.StatusInternalServerError,
@@ -95,6 +96,7 @@ func (h *Handler) DeleteTenant(ctx *context.Context) {
 	ctx.JSON(http.StatusOK, schema.Response{
 		Code:    http.StatusOK,
 		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    rs,
 	})
 }
 
@@ -1,6 +1,7 @@
 package tenant
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	tenantSrv "goadmin/internal/service/tenant"
@@ -15,14 +16,14 @@ func RegisterRoutes(r *gin.RouterGroup, tenantService tenantSrv.TenantService) {
 	group := r.Group("/tenant")
 	{
 		// 需要认证的接口
-		authGroup := group.Group("/")
+		authGroup := catalog.Wrap(group.Group("/"), "tenant")
 		authGroup.Use(middleware.Auth())
 		{
-			authGroup.GET("/list", context.Build(handler.ListTenants))
-			authGroup.GET("/get", context.Build(handler.GetTenant))
-			authGroup.POST("/create", context.Build(handler.CreateTenant))
-			authGroup.POST("/update", context.Build(handler.UpdateTenant))
-			authGroup.POST("/delete", context.Build(handler.DeleteTenant))
+			authGroup.GET("/list", catalog.Perm("tenant_list", "租户列表"), context.Build(handler.ListTenants))
+			authGroup.GET("/get", catalog.Perm("tenant_info", "租户详情"), context.Build(handler.GetTenant))
+			authGroup.POST("/create", catalog.Perm("tenant_create", "租户开通"), context.Build(handler.CreateTenant))
+			authGroup.POST("/update", catalog.Perm("tenant_update", "租户更新"), context.Build(handler.UpdateTenant))
+			authGroup.POST("/delete", catalog.Perm("tenant_delete", "租户注销"), context.Build(handler.DeleteTenant))
 		}
 	}
 }
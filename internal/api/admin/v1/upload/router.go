@@ -1,6 +1,7 @@
 package upload
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 
@@ -15,11 +16,11 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	group := r.Group("/upload")
 	{
 		// 需要认证的接口
-		authGroup := group.Group("/")
+		authGroup := catalog.Wrap(group.Group("/"), "upload")
 		authGroup.Use(middleware.Auth())
 		{
 			// 上传单个文件
-			authGroup.POST("/file", context.Build(handler.UploadFile))
+			authGroup.POST("/file", catalog.Perm("upload_file", "上传文件").AsGlobal(), context.Build(handler.UploadFile))
 		}
 	}
 }
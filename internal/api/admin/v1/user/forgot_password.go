@@ -0,0 +1,59 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	modeluser "goadmin/internal/model/user"
+	"net/http"
+)
+
+// ForgotPassword 申请通过邮件找回密码
+func (h *Handler) ForgotPassword(ctx *context.Context) {
+	var req modeluser.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	if err := h.userSrv.ForgotPassword(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "user.PasswordResetMailSent", nil),
+	})
+}
+
+// ConfirmPasswordReset 使用邮件中的令牌设置新密码
+func (h *Handler) ConfirmPasswordReset(ctx *context.Context) {
+	var req modeluser.PasswordResetConfirmRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	if err := h.userSrv.ConfirmPasswordReset(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"goadmin/config"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
 	"goadmin/internal/model/schema"
@@ -15,13 +16,16 @@ type Handler struct {
 	userSrv  userSrv.UserService
 	userRepo user.UserRepository
 	tokenSrv *token.TokenService
+	// 单点登录完成后跳转的前端页面
+	oidcSuccessURL string
 }
 
 func NewHandler(userSrv userSrv.UserService, userRepo user.UserRepository, tokenSrv *token.TokenService) *Handler {
 	return &Handler{
-		userSrv:  userSrv,
-		userRepo: userRepo,
-		tokenSrv: tokenSrv,
+		userSrv:        userSrv,
+		userRepo:       userRepo,
+		tokenSrv:       tokenSrv,
+		oidcSuccessURL: config.Get().Auth.OIDC.SuccessURL,
 	}
 }
 
@@ -52,10 +56,10 @@ func (h *Handler) Login(ctx *context.Context) {
 	})
 }
 
-// Logout 用户退出
-func (h *Handler) Logout(ctx *context.Context) {
-	token := ctx.GetHeader("Authorization")
-	if token == "" {
+// RefreshToken 刷新令牌
+func (h *Handler) RefreshToken(ctx *context.Context) {
+	var req modeluser.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, schema.Response{
 			Code:    http.StatusBadRequest,
 			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
@@ -63,23 +67,35 @@ func (h *Handler) Logout(ctx *context.Context) {
 		return
 	}
 
-	// 从Bearer token中提取token值
-	if len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
+	resp, err := h.userSrv.RefreshToken(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, schema.Response{
+			Code:    http.StatusUnauthorized,
+			Message: err.Error(),
+		})
+		return
 	}
 
-	if err := h.tokenSrv.DeleteToken(ctx, token); err != nil {
-		ctx.Logger.Errorf("删除token失败: %v", err)
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "user.RefreshSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// Logout 用户退出
+func (h *Handler) Logout(ctx *context.Context) {
+	if err := h.userSrv.Logout(ctx); err != nil {
 		ctx.JSON(http.StatusInternalServerError, schema.Response{
 			Code:    http.StatusInternalServerError,
-			Message: i18n.T(ctx.Context, "common.SystemError", nil),
+			Message: err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, schema.Response{
 		Code:    http.StatusOK,
-		Message: i18n.T(ctx.Context, "user.LoginSuccess", nil),
+		Message: i18n.T(ctx.Context, "user.LogoutSuccess", nil),
 	})
 }
 
@@ -228,7 +244,7 @@ func (h *Handler) ResetPassword(ctx *context.Context) {
 		return
 	}
 
-	err := h.userSrv.ResetPassword(ctx, &req)
+	resp, err := h.userSrv.ResetPassword(ctx, &req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, schema.Response{
 			Code:    http.StatusInternalServerError,
@@ -240,12 +256,13 @@ func (h *Handler) ResetPassword(ctx *context.Context) {
 	ctx.JSON(http.StatusOK, schema.Response{
 		Code:    http.StatusOK,
 		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
 	})
 }
 
 // GetCurrentUser 获取当前用户信息
 func (h *Handler) GetCurrentUser(ctx *context.Context) {
-	user, err := h.userSrv.GetUserByIDWithPerm(ctx, ctx.Session().GetID())
+	user, err := h.userSrv.GetCurrentUser(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, schema.Response{
 			Code:    http.StatusInternalServerError,
@@ -260,3 +277,116 @@ func (h *Handler) GetCurrentUser(ctx *context.Context) {
 		Data:    user,
 	})
 }
+
+// ListSessions 获取当前用户的登录会话
+func (h *Handler) ListSessions(ctx *context.Context) {
+	sessions, err := h.userSrv.ListSessions(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    sessions,
+	})
+}
+
+// RevokeSession 注销当前用户的指定会话
+func (h *Handler) RevokeSession(ctx *context.Context) {
+	var req modeluser.RevokeSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	err := h.userSrv.RevokeSession(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
+
+// RevokeAllSessions 注销当前用户的全部会话
+func (h *Handler) RevokeAllSessions(ctx *context.Context) {
+	err := h.userSrv.RevokeAllSessions(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
+
+// ForceLogout 强制指定用户下线
+func (h *Handler) ForceLogout(ctx *context.Context) {
+	var req schema.IDRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	err := h.userSrv.ForceLogout(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
+
+// UnlockUser 解锁因登录失败过多被锁定的用户
+func (h *Handler) UnlockUser(ctx *context.Context) {
+	var req schema.IDRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	err := h.userSrv.UnlockUser(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	modeluser "goadmin/internal/model/user"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// LoginOptions 获取登录页可用的登录方式
+func (h *Handler) LoginOptions(ctx *context.Context) {
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    h.userSrv.LoginOptions(ctx),
+	})
+}
+
+// OIDCLogin 跳转到身份提供方登录
+func (h *Handler) OIDCLogin(ctx *context.Context) {
+	authURL, err := h.userSrv.OIDCLoginURL(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供方登录完成后的回调
+//
+// 配置了前端页面时跳转到该页面，令牌或错误信息放在 URL fragment 中，不会发送到服务端或写入访问日志；
+// 未配置时直接返回 JSON
+func (h *Handler) OIDCCallback(ctx *context.Context) {
+	var req modeluser.OIDCCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		h.oidcResult(ctx, nil, i18n.E(ctx.Context, "common.BadParameter", nil))
+		return
+	}
+	resp, err := h.userSrv.OIDCCallback(ctx, &req)
+	h.oidcResult(ctx, resp, err)
+}
+
+func (h *Handler) oidcResult(ctx *context.Context, resp *modeluser.LoginResponse, err error) {
+	if h.oidcSuccessURL == "" {
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, schema.Response{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusOK, schema.Response{
+			Code:    http.StatusOK,
+			Message: i18n.T(ctx.Context, "user.LoginSuccess", nil),
+			Data:    resp,
+		})
+		return
+	}
+
+	values := url.Values{}
+	switch {
+	case err != nil:
+		values.Set("error", err.Error())
+	case resp.PreAuthToken != "":
+		values.Set("pre_auth_token", resp.PreAuthToken)
+		values.Set("two_factor_required", strconv.FormatBool(resp.TwoFactorRequired))
+		values.Set("two_factor_setup", strconv.FormatBool(resp.TwoFactorSetup))
+	default:
+		values.Set("token", resp.Token)
+		values.Set("refresh_token", resp.RefreshToken)
+		values.Set("expires_at", strconv.FormatInt(resp.ExpiresAt, 10))
+		values.Set("password_change_required", strconv.FormatBool(resp.PasswordChangeRequired))
+	}
+	target, _, _ := strings.Cut(h.oidcSuccessURL, "#")
+	ctx.Redirect(http.StatusFound, target+"#"+values.Encode())
+}
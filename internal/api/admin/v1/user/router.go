@@ -1,6 +1,7 @@
 package user
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/middleware"
 	"goadmin/internal/repository/user"
@@ -23,20 +24,47 @@ func RegisterRoutes(
 	{
 		// 登录接口 - 不需要认证
 		group.POST("/login", context.Build(handler.Login))
+		group.GET("/login/options", context.Build(handler.LoginOptions))
+		// 单点登录 - 跳转到身份提供方，回调凭 state 校验
+		group.GET("/oidc/login", context.Build(handler.OIDCLogin))
+		group.GET("/oidc/callback", context.Build(handler.OIDCCallback))
+		// 刷新令牌 - 凭刷新令牌鉴权，不经过访问令牌认证
+		group.POST("/refresh_token", context.Build(handler.RefreshToken))
+		// 双因素认证登录 - 凭预认证令牌鉴权
+		group.POST("/login/2fa", context.Build(handler.LoginTwoFactor))
+		group.POST("/login/2fa/setup", context.Build(handler.LoginTwoFactorSetup))
+		// 邮件找回密码 - 凭邮件中的令牌鉴权
+		group.POST("/forgot_pwd", context.Build(handler.ForgotPassword))
+		group.POST("/forgot_pwd/reset", context.Build(handler.ConfirmPasswordReset))
 
 		// 需要认证的接口
-		authGroup := group.Group("/")
+		authGroup := catalog.Wrap(group.Group("/"), "user")
 
 		authGroup.Use(middleware.Auth())
 		{
-			authGroup.GET("/logout", context.Build(handler.Logout))
-			authGroup.GET("/info", context.Build(handler.GetCurrentUser))
-			authGroup.POST("/change_pwd", context.Build(handler.ChangePassword))
-			authGroup.POST("/reset_pwd", context.Build(handler.ResetPassword))
-			authGroup.GET("/list", context.Build(handler.ListUsers))
-			authGroup.POST("/create", context.Build(handler.CreateUser))
-			authGroup.POST("/update", context.Build(handler.UpdateUser))
-			authGroup.POST("/delete", context.Build(handler.DeleteUser))
+			authGroup.GET("/logout", catalog.Perm("user_logout", "退出").AsGlobal(), context.Build(handler.Logout))
+			authGroup.GET("/info", catalog.Perm("user_info", "当前用户详情").AsGlobal(), context.Build(handler.GetCurrentUser))
+			authGroup.POST("/change_pwd", catalog.Perm("user_changePwd", "修改密码").AsGlobal(), context.Build(handler.ChangePassword))
+			authGroup.POST("/reset_pwd", catalog.Perm("user_resetPwd", "重置密码"), context.Build(handler.ResetPassword))
+			authGroup.GET("/list", catalog.Perm("user_list", "管理员列表"), context.Build(handler.ListUsers))
+			authGroup.POST("/create", catalog.Perm("user_create", "管理员创建"), context.Build(handler.CreateUser))
+			authGroup.POST("/update", catalog.Perm("user_update", "管理员编辑"), context.Build(handler.UpdateUser))
+			authGroup.POST("/delete", catalog.Perm("user_delete", "管理员删除"), context.Build(handler.DeleteUser))
+			authGroup.POST("/force_logout", catalog.Perm("user_force_logout", "强制下线"), context.Build(handler.ForceLogout))
+			authGroup.POST("/unlock", catalog.Perm("user_unlock", "解锁用户"), context.Build(handler.UnlockUser))
+
+			// 会话管理
+			authGroup.GET("/sessions", catalog.Perm("user_sessions", "我的会话").AsGlobal(), context.Build(handler.ListSessions))
+			authGroup.POST("/sessions/revoke", catalog.Perm("user_session_revoke", "注销会话").AsGlobal(), context.Build(handler.RevokeSession))
+			authGroup.POST("/sessions/revoke_all", catalog.Perm("user_session_all", "退出所有设备").AsGlobal(), context.Build(handler.RevokeAllSessions))
+
+			// 双因素认证
+			authGroup.GET("/2fa/status", catalog.Perm("user_2fa_status", "双因素认证状态").AsGlobal(), context.Build(handler.TwoFactorStatus))
+			authGroup.POST("/2fa/enroll", catalog.Perm("user_2fa_enroll", "绑定双因素认证").AsGlobal(), context.Build(handler.EnrollTwoFactor))
+			authGroup.POST("/2fa/verify", catalog.Perm("user_2fa_verify", "启用双因素认证").AsGlobal(), context.Build(handler.VerifyTwoFactor))
+			authGroup.POST("/2fa/disable", catalog.Perm("user_2fa_disable", "关闭双因素认证").AsGlobal(), context.Build(handler.DisableTwoFactor))
+			authGroup.POST("/2fa/recovery_codes", catalog.Perm("user_2fa_recovery", "重新生成恢复码").AsGlobal(), context.Build(handler.RegenerateRecoveryCodes))
+			authGroup.POST("/2fa/reset", catalog.Perm("user_2fa_reset", "重置双因素认证"), context.Build(handler.ResetTwoFactor))
 		}
 	}
-}
\ No newline at end of file
+}
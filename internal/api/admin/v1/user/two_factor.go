@@ -0,0 +1,205 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	modeluser "goadmin/internal/model/user"
+	"net/http"
+)
+
+// LoginTwoFactor 登录第二步，校验动态码或恢复码
+func (h *Handler) LoginTwoFactor(ctx *context.Context) {
+	var req modeluser.TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	resp, err := h.userSrv.LoginTwoFactor(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "user.LoginSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// LoginTwoFactorSetup 登录时绑定认证器（角色强制启用双因素认证）
+func (h *Handler) LoginTwoFactorSetup(ctx *context.Context) {
+	var req modeluser.TwoFactorSetupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	resp, err := h.userSrv.LoginTwoFactorSetup(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// TwoFactorStatus 获取当前用户的双因素认证状态
+func (h *Handler) TwoFactorStatus(ctx *context.Context) {
+	resp, err := h.userSrv.TwoFactorStatus(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// EnrollTwoFactor 生成TOTP密钥及二维码配置链接
+func (h *Handler) EnrollTwoFactor(ctx *context.Context) {
+	resp, err := h.userSrv.EnrollTwoFactor(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// VerifyTwoFactor 校验动态码并启用双因素认证
+func (h *Handler) VerifyTwoFactor(ctx *context.Context) {
+	var req modeluser.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	resp, err := h.userSrv.VerifyTwoFactor(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// DisableTwoFactor 关闭双因素认证
+func (h *Handler) DisableTwoFactor(ctx *context.Context) {
+	var req modeluser.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	err := h.userSrv.DisableTwoFactor(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *Handler) RegenerateRecoveryCodes(ctx *context.Context) {
+	var req modeluser.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	resp, err := h.userSrv.RegenerateRecoveryCodes(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+		Data:    resp,
+	})
+}
+
+// ResetTwoFactor 管理员重置指定用户的双因素认证
+func (h *Handler) ResetTwoFactor(ctx *context.Context) {
+	var req schema.IDRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, schema.Response{
+			Code:    http.StatusBadRequest,
+			Message: i18n.T(ctx.Context, "common.BadParameter", nil),
+		})
+		return
+	}
+
+	err := h.userSrv.ResetTwoFactor(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, schema.Response{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema.Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(ctx.Context, "common.ActionSuccess", nil),
+	})
+}
@@ -3,7 +3,9 @@ package api
 import (
 	"os"
 
+	"goadmin/internal/api/admin/v1/api_key"
 	"goadmin/internal/api/admin/v1/captcha"
+	"goadmin/internal/api/admin/v1/export"
 	"goadmin/internal/api/admin/v1/operate_log"
 	"goadmin/internal/api/admin/v1/position"
 	"goadmin/internal/api/admin/v1/role"
@@ -11,10 +13,13 @@ import (
 	"goadmin/internal/api/admin/v1/tenant"
 	"goadmin/internal/api/admin/v1/upload"
 	userapi "goadmin/internal/api/admin/v1/user"
+	"goadmin/internal/api/wellknown"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
 	"goadmin/internal/middleware"
 	"goadmin/internal/repository/user"
+	apikeyservice "goadmin/internal/service/api_key"
+	exportservice "goadmin/internal/service/export"
 	operatelogsService "goadmin/internal/service/operate_log"
 	positionservice "goadmin/internal/service/position"
 	roleservice "goadmin/internal/service/role"
@@ -29,12 +34,15 @@ import (
 // Services holds all services for dependency injection into routers
 type Services struct {
 	TokenService      *token.TokenService
+	JwtTokenService   *token.JwtTokenService
 	UserService       userservice.UserService
 	RoleService       roleservice.RoleService
 	PositionService   positionservice.PositionService
 	OperateLogService operatelogsService.OperateLogService
 	SettingService    settingsservice.ServerSettingService
 	TenantService     tenantservice.TenantService
+	APIKeyService     apikeyservice.APIKeyService
+	ExportService     exportservice.ExportService
 	UserRepository    user.UserRepository
 }
 
@@ -44,6 +52,7 @@ func RegisterRouter(r *gin.Engine, services Services) {
 		i18n.Middleware(),
 		middleware.Logger(),
 		middleware.Header(),
+		middleware.TenantHint(),
 		middleware.Recovery(),
 	)
 	// 健康检查
@@ -53,6 +62,9 @@ func RegisterRouter(r *gin.Engine, services Services) {
 		})
 	})
 
+	// JWT验签公钥
+	wellknown.RegisterRoutes(r, services.JwtTokenService)
+
 	// API路由组
 	adminHandler(r, services)
 }
@@ -87,6 +99,12 @@ func adminHandler(r *gin.Engine, services Services) {
 
 		// 租户相关路由
 		tenant.RegisterRoutes(adminGroup, services.TenantService)
+
+		// API密钥相关路由
+		api_key.RegisterRoutes(adminGroup, services.APIKeyService)
+
+		// 列表导出相关路由
+		export.RegisterRoutes(adminGroup, services.ExportService)
 	}
 
 	// 静态文件服务 - 提供上传文件的访问
@@ -0,0 +1,35 @@
+package wellknown
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/service/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册 /.well-known 下的公开路由
+func RegisterRoutes(r *gin.Engine, jwtToken *token.JwtTokenService) {
+	r.GET("/.well-known/jwks.json", context.Build(JWKSHandler(jwtToken)))
+}
+
+// JWKSHandler 公开JWT验签公钥，供其他服务校验本服务签发的令牌
+//
+// 响应遵循 RFC 7517，不使用统一的 schema.Response 包装
+func JWKSHandler(jwtToken *token.JwtTokenService) func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		jwks, err := jwtToken.JWKS()
+		if err != nil {
+			ctx.Logger.Errorf("jwks 加载密钥失败: %v", err)
+			ctx.JSON(http.StatusInternalServerError, schema.Response{
+				Code:    http.StatusInternalServerError,
+				Message: i18n.T(ctx.Context, "common.InternalError", nil),
+			})
+			return
+		}
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, jwks)
+	}
+}
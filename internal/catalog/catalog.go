@@ -0,0 +1,167 @@
+// Package catalog 权限目录：注册路由时声明接口所需的权限，启动时同步到 permissions 表
+package catalog
+
+import (
+	"fmt"
+	"goadmin/internal/model/permission"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	mu      sync.Mutex
+	entries = make(map[string]permission.Permission)
+)
+
+// Entry 路由声明的权限
+type Entry struct {
+	Code   string
+	Name   string
+	Global bool
+}
+
+// Perm 声明需授权才能访问的权限
+func Perm(code, name string) Entry {
+	return Entry{Code: code, Name: name}
+}
+
+// AsGlobal 标记为公共权限，登录即可访问
+func (e Entry) AsGlobal() Entry {
+	e.Global = true
+	return e
+}
+
+// Group 注册路由时登记权限的路由组
+type Group struct {
+	group  *gin.RouterGroup
+	module string
+}
+
+// Wrap 包装路由组，经其注册的路由登记到所属模块的权限目录
+func Wrap(group *gin.RouterGroup, module string) *Group {
+	return &Group{group: group, module: module}
+}
+
+// Use 为路由组添加中间件
+func (g *Group) Use(middleware ...gin.HandlerFunc) *Group {
+	g.group.Use(middleware...)
+	return g
+}
+
+// GET 注册 GET 路由并登记权限
+func (g *Group) GET(relativePath string, e Entry, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, relativePath, e, handlers...)
+}
+
+// POST 注册 POST 路由并登记权限
+func (g *Group) POST(relativePath string, e Entry, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, relativePath, e, handlers...)
+}
+
+// PUT 注册 PUT 路由并登记权限
+func (g *Group) PUT(relativePath string, e Entry, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, relativePath, e, handlers...)
+}
+
+// DELETE 注册 DELETE 路由并登记权限
+func (g *Group) DELETE(relativePath string, e Entry, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, relativePath, e, handlers...)
+}
+
+// Handle 注册路由并登记权限
+func (g *Group) Handle(method, relativePath string, e Entry, handlers ...gin.HandlerFunc) {
+	g.group.Handle(method, relativePath, handlers...)
+	flag := permission.GlobalFlagNo
+	if e.Global {
+		flag = permission.GlobalFlagYes
+	}
+	Register(permission.Permission{
+		Code:       e.Code,
+		Name:       e.Name,
+		Method:     method,
+		Path:       strings.TrimPrefix(path.Join(g.group.BasePath(), relativePath), "/"),
+		GlobalFlag: flag,
+		Module:     g.module,
+	})
+}
+
+// Register 登记权限，同一权限可重复登记，但代码相同而定义不同时 panic
+func Register(p permission.Permission) {
+	mu.Lock()
+	defer mu.Unlock()
+	if old, ok := entries[p.Code]; ok {
+		if !same(old, p) {
+			panic(fmt.Sprintf("catalog: 权限 %s 重复声明: %s %s 与 %s %s", p.Code, old.Method, old.Path, p.Method, p.Path))
+		}
+		return
+	}
+	entries[p.Code] = p
+}
+
+// Entries 返回已登记的全部权限，按代码排序
+func Entries() []permission.Permission {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]permission.Permission, 0, len(entries))
+	for _, p := range entries {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// Plan 权限目录与数据库的差异
+type Plan struct {
+	Create  []permission.Permission `json:"create"`  // 数据库中不存在的权限
+	Update  []permission.Permission `json:"update"`  // 名称、路由、模块或公共标识有变化的权限
+	Orphans []permission.Permission `json:"orphans"` // 数据库中存在但未被任何路由声明的权限
+}
+
+// Changed 是否需要写入数据库
+func (p *Plan) Changed() bool {
+	return len(p.Create) > 0 || len(p.Update) > 0
+}
+
+// Diff 比较权限目录与数据库中的权限
+//
+// 待更新的权限保留数据库中的ID及描述；孤立权限可能仍被角色引用，只报告不删除
+func Diff(declared, existing []permission.Permission) *Plan {
+	plan := &Plan{}
+	byCode := make(map[string]permission.Permission, len(existing))
+	for _, p := range existing {
+		byCode[p.Code] = p
+	}
+	for _, p := range declared {
+		old, ok := byCode[p.Code]
+		if !ok {
+			plan.Create = append(plan.Create, p)
+			continue
+		}
+		delete(byCode, p.Code)
+		if same(old, p) {
+			continue
+		}
+		p.BaseModel, p.Description = old.BaseModel, old.Description
+		plan.Update = append(plan.Update, p)
+	}
+	for _, p := range existing {
+		if _, ok := byCode[p.Code]; ok {
+			plan.Orphans = append(plan.Orphans, p)
+		}
+	}
+	return plan
+}
+
+// same 比较路由声明的字段，历史数据中非公共权限的标识可能为 0，按是否公共比较
+func same(a, b permission.Permission) bool {
+	return a.Name == b.Name &&
+		strings.EqualFold(a.Method, b.Method) &&
+		a.Path == b.Path &&
+		a.Module == b.Module &&
+		a.IsGlobal() == b.IsGlobal()
+}
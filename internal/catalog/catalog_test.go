@@ -0,0 +1,78 @@
+package catalog
+
+import (
+	"goadmin/internal/model/permission"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWrapRegistersRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	g := Wrap(r.Group("/admin/v1").Group("/catalog_test"), "catalog_test")
+	noop := func(*gin.Context) {}
+	g.GET("/list", Perm("catalog_test_list", "目录测试列表"), noop)
+	g.POST("/:id/update", Perm("catalog_test_update", "目录测试更新").AsGlobal(), noop)
+	// 同一定义重复登记不报错
+	Register(permission.Permission{
+		Code: "catalog_test_list", Name: "目录测试列表", Method: http.MethodGet,
+		Path: "admin/v1/catalog_test/list", Module: "catalog_test", GlobalFlag: permission.GlobalFlagNo,
+	})
+
+	got := map[string]permission.Permission{}
+	for _, p := range Entries() {
+		got[p.Code] = p
+	}
+	if p := got["catalog_test_list"]; p.Method != http.MethodGet || p.Path != "admin/v1/catalog_test/list" || p.IsGlobal() {
+		t.Errorf("list = %+v", p)
+	}
+	if p := got["catalog_test_update"]; p.Method != http.MethodPost || p.Path != "admin/v1/catalog_test/:id/update" ||
+		!p.IsGlobal() || p.Module != "catalog_test" {
+		t.Errorf("update = %+v", p)
+	}
+	if len(r.Routes()) != 2 {
+		t.Errorf("routes = %d", len(r.Routes()))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("conflicting declaration should panic")
+		}
+	}()
+	g.POST("/other", Perm("catalog_test_list", "目录测试列表"), noop)
+}
+
+func TestDiff(t *testing.T) {
+	declared := []permission.Permission{
+		{Code: "a", Name: "A", Method: http.MethodGet, Path: "admin/v1/a", Module: "m", GlobalFlag: permission.GlobalFlagNo},
+		{Code: "b", Name: "B", Method: http.MethodPost, Path: "admin/v1/b", Module: "m", GlobalFlag: permission.GlobalFlagNo},
+		{Code: "c", Name: "C", Method: http.MethodPost, Path: "admin/v1/c", Module: "m", GlobalFlag: permission.GlobalFlagYes},
+	}
+	existing := []permission.Permission{
+		// 历史数据以 0 表示非公共权限，视为未变化
+		{Code: "a", Name: "A", Method: "GET", Path: "admin/v1/a", Module: "m", GlobalFlag: 0},
+		{Code: "b", Name: "B", Description: "keep", Path: "admin/v1/b", Module: "m", GlobalFlag: 0},
+		{Code: "old", Name: "Old", Path: "admin/v1/old", Module: "m"},
+	}
+	existing[1].ID = 2
+
+	plan := Diff(declared, existing)
+	if len(plan.Create) != 1 || plan.Create[0].Code != "c" {
+		t.Errorf("Create = %+v", plan.Create)
+	}
+	if len(plan.Update) != 1 || plan.Update[0].Code != "b" || plan.Update[0].ID != 2 ||
+		plan.Update[0].Description != "keep" || plan.Update[0].Method != http.MethodPost {
+		t.Errorf("Update = %+v", plan.Update)
+	}
+	if len(plan.Orphans) != 1 || plan.Orphans[0].Code != "old" {
+		t.Errorf("Orphans = %+v", plan.Orphans)
+	}
+	if !plan.Changed() {
+		t.Error("plan should be changed")
+	}
+	if Diff(declared[:1], existing[:1]).Changed() {
+		t.Error("identical catalog should not change")
+	}
+}
@@ -20,6 +20,18 @@ func (c *Context) Session() Session {
 	return data.(Session)
 }
 
+// APIKeyCtxKey 上下文中保存请求所用 API 密钥的键
+const APIKeyCtxKey = "goadmin/api_key"
+
+// APIKey 返回请求所用的 API 密钥，使用访问令牌认证时返回 nil
+func (c *Context) APIKey() APIKey {
+	data, exists := c.Get(APIKeyCtxKey)
+	if !exists {
+		return nil
+	}
+	return data.(APIKey)
+}
+
 func (c *Context) ToCli() *CliContext {
 	return &CliContext{
 		Context: c,
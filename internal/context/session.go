@@ -12,9 +12,18 @@ type Session interface {
 	// GetStatus 返回用户状态（例如 0=禁用，1=启用）
 	GetStatus() int
 
-	// GetRole 返回角色标识（如 "admin", "user"）
-	GetRole() *role.Role
+	// GetRoles 返回用户拥有的角色集合，权限取各角色的并集
+	GetRoles() role.Set
 
 	// IsActive 用户状态是否正常
 	IsActive() bool
 }
+
+// APIKey 请求使用的 API 密钥
+type APIKey interface {
+	// GetID 返回密钥ID
+	GetID() uint64
+
+	// GetPrefix 返回密钥前缀，可用于展示及审计
+	GetPrefix() string
+}
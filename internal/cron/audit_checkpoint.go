@@ -0,0 +1,21 @@
+package cron
+
+import (
+	"context"
+
+	cusCtx "goadmin/internal/context"
+	operatelogservice "goadmin/internal/service/operate_log"
+)
+
+// AuditCheckpointJob 定时为操作日志哈希链创建签名检查点
+func AuditCheckpointJob(logService operatelogservice.OperateLogService, spec string) *Job {
+	return &Job{
+		Name: "审计日志检查点",
+		Spec: spec,
+		Fn: func() error {
+			ctx := cusCtx.NewCliContext(context.Background())
+			defer ctx.Close()
+			return logService.CreateCheckpoint(ctx)
+		},
+	}
+}
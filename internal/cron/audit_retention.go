@@ -0,0 +1,35 @@
+package cron
+
+import (
+	"context"
+
+	cusCtx "goadmin/internal/context"
+	operatelogservice "goadmin/internal/service/operate_log"
+)
+
+// AuditRetentionJob 定时归档并删除过期的操作日志
+func AuditRetentionJob(logService operatelogservice.OperateLogService, spec string) *Job {
+	return &Job{
+		Name: "审计日志保留",
+		Spec: spec,
+		Fn: func() error {
+			ctx := cusCtx.NewCliContext(context.Background())
+			defer ctx.Close()
+			_, err := logService.ApplyRetention(ctx)
+			return err
+		},
+	}
+}
+
+// AuditPartitionJob 每天为按月分区的操作日志表提前创建分区
+func AuditPartitionJob(logService operatelogservice.OperateLogService) *Job {
+	return &Job{
+		Name: "审计日志分区",
+		Spec: "0 10 2 * * *", // 每天 02:10
+		Fn: func() error {
+			ctx := cusCtx.NewCliContext(context.Background())
+			defer ctx.Close()
+			return logService.MaintainPartitions(ctx)
+		},
+	}
+}
@@ -2,7 +2,13 @@ package cron
 
 import (
 	"log"
+	"strings"
 	"time"
+
+	"goadmin/config"
+	exportservice "goadmin/internal/service/export"
+	operatelogservice "goadmin/internal/service/operate_log"
+	userservice "goadmin/internal/service/user"
 )
 
 type Job struct {
@@ -11,8 +17,16 @@ type Job struct {
 	Fn   func() error
 }
 
-func Register() []*Job {
-	return []*Job{
+// Deps 定时任务依赖的配置及服务
+type Deps struct {
+	Config        *config.Config
+	UserService   userservice.UserService
+	LogService    operatelogservice.OperateLogService
+	ExportService exportservice.ExportService
+}
+
+func Register(deps *Deps) []*Job {
+	jobs := []*Job{
 		{
 			Name: "示例任务",
 			Spec: "*/30 * * * * *", // 每30秒执行一次  秒 分 时 日 月 周
@@ -22,4 +36,20 @@ func Register() []*Job {
 			},
 		},
 	}
+
+	if ldapCfg := deps.Config.Auth.LDAP; ldapCfg.Enable && ldapCfg.Sync.Enable {
+		jobs = append(jobs, LDAPSyncJob(deps.UserService, ldapCfg.Sync.SyncSpec()))
+	}
+	if auditCfg := deps.Config.Audit; auditCfg.CheckpointKey != "" {
+		jobs = append(jobs, AuditCheckpointJob(deps.LogService, auditCfg.CheckpointSpecOrDefault()))
+	}
+	if auditCfg := deps.Config.Audit; auditCfg.RetentionEnabled() {
+		jobs = append(jobs, AuditRetentionJob(deps.LogService, auditCfg.RetentionSpecOrDefault()))
+	}
+	// 只有 PostgreSQL 支持按月分区，未分区时任务不做处理
+	if driver := strings.ToLower(deps.Config.Database.Master.Driver); driver == "postgres" || driver == "postgresql" {
+		jobs = append(jobs, AuditPartitionJob(deps.LogService))
+	}
+	jobs = append(jobs, ExportCleanJob(deps.ExportService))
+	return jobs
 }
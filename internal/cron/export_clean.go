@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"context"
+
+	cusCtx "goadmin/internal/context"
+	exportservice "goadmin/internal/service/export"
+)
+
+// ExportCleanJob 每小时删除超过保留时长的导出文件
+func ExportCleanJob(exportService exportservice.ExportService) *Job {
+	return &Job{
+		Name: "导出文件清理",
+		Spec: "0 20 * * * *", // 每小时第 20 分钟
+		Fn: func() error {
+			ctx := cusCtx.NewCliContext(context.Background())
+			defer ctx.Close()
+			_, err := exportService.CleanExpired(ctx)
+			return err
+		},
+	}
+}
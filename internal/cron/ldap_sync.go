@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"context"
+
+	cusCtx "goadmin/internal/context"
+	userservice "goadmin/internal/service/user"
+)
+
+// LDAPSyncJob 定时同步 LDAP 目录中的用户
+func LDAPSyncJob(userService userservice.UserService, spec string) *Job {
+	return &Job{
+		Name: "LDAP用户同步",
+		Spec: spec,
+		Fn: func() error {
+			ctx := cusCtx.NewCliContext(context.Background())
+			defer ctx.Close()
+			_, err := userService.SyncLDAPUsers(ctx)
+			return err
+		},
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"goadmin/config"
+	"goadmin/pkg/db"
 	"goadmin/pkg/logger"
 	"goadmin/pkg/trace"
 	"net/http"
@@ -9,8 +10,11 @@ import (
 
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
+	modelapikey "goadmin/internal/model/api_key"
 	modeluser "goadmin/internal/model/user"
+	apikeyService "goadmin/internal/service/api_key"
 	roleService "goadmin/internal/service/role"
+	tenantService "goadmin/internal/service/tenant"
 	tokenService "goadmin/internal/service/token"
 	userService "goadmin/internal/service/user"
 
@@ -22,14 +26,20 @@ var (
 	whiteList = []string{}
 
 	tokenHeadName = "Authorization"
+	// API 密钥也可通过 Authorization: Bearer 传递
+	apiKeyHeadName = "X-API-Key"
 
-	userSrv userService.UserService
-	roleSrv roleService.RoleService
+	userSrv   userService.UserService
+	roleSrv   roleService.RoleService
+	apiKeySrv apikeyService.APIKeyService
+	tenantSrv tenantService.TenantService
 )
 
 func Auth() gin.HandlerFunc {
 	userSrv = userService.NewUserService_legacy()
 	roleSrv = roleService.NewRoleService_legacy()
+	apiKeySrv = apikeyService.NewAPIKeyService_legacy()
+	tenantSrv = tenantService.NewTenantService_legacy()
 	return func(c *gin.Context) {
 		// 检查是否为忽略认证的路径
 		path := c.Request.URL.Path
@@ -40,6 +50,11 @@ func Auth() gin.HandlerFunc {
 			}
 		}
 
+		if apiKey := c.GetHeader(apiKeyHeadName); apiKey != "" {
+			authAPIKey(c, apiKey)
+			return
+		}
+
 		// 从请求头获取Token
 		authHeader := c.GetHeader(tokenHeadName)
 		if authHeader == "" {
@@ -59,6 +74,10 @@ func Auth() gin.HandlerFunc {
 				i18n.E(c, "common.NotFound", map[string]any{"item": i18n.T(c, "common.item.token", nil)}))
 			return
 		}
+		if strings.HasPrefix(parts[1], modelapikey.KeyPrefix) {
+			authAPIKey(c, parts[1])
+			return
+		}
 
 		tokenSrv := tokenService.NewJwtTokenService(&config.Get().JWT)
 		claims, err := tokenSrv.ValidateJWTToken(parts[1])
@@ -70,32 +89,94 @@ func Auth() gin.HandlerFunc {
 				i18n.E(c, "common.InvalidParameter", map[string]any{"item": i18n.T(c, "common.item.token", nil)}))
 			return
 		}
-		sessionData, err := userSrv.GetUserByID(context.New(c), claims.UserID)
+		ctx := context.New(c)
+		revoked, err := tokenSrv.IsRevoked(ctx, claims)
+		if err != nil || revoked {
+			ctx.Logger.Warnf("token revoked %s %d %v", claims.ID, claims.UserID, err)
+			abortWithError(c, http.StatusUnauthorized, i18n.E(c, "user.TokenRevoked", nil))
+			return
+		}
+		sessionData, err := userSrv.GetSessionUser(ctx, claims.UserID)
 		if err != nil {
 			abortWithError(c, http.StatusUnauthorized, err)
 			return
 		}
+		if err = checkClaimsTenant(c, sessionData, claims.TenantID); err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+		// 租户停用后已签发的令牌同样不可使用
+		if err = userSrv.CheckTenant(ctx, sessionData); err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
 		err = hasPermission(c, sessionData)
 		if err != nil {
 			abortWithError(c, http.StatusUnauthorized, err)
 			return
 		}
+		if err = setTenant(c, sessionData); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
 
-		// 将用户存入上下文
+		// 将用户及令牌信息存入上下文
 		c.Set(gin.AuthUserKey, sessionData)
+		c.Set(tokenService.ClaimsKey, claims)
+		c.Set(db.ScopeCtxKey, sessionData.DataScope())
 
 		// 继续处理请求
 		c.Next()
 	}
 }
 
+// authAPIKey 使用 API 密钥认证，密钥所属用户须有权限且接口在密钥的授权范围内
+func authAPIKey(c *gin.Context, key string) {
+	ctx := context.New(c)
+	k, err := apiKeySrv.Authenticate(ctx, key)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	sessionData, err := userSrv.GetSessionUser(ctx, k.UserID)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	// API 密钥不经过登录，须在此拦截已停用租户的用户
+	if err = userSrv.CheckTenant(ctx, sessionData); err != nil {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	if err = hasPermission(c, sessionData); err != nil {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	if err = apiKeySrv.CheckScope(ctx, k, c.Request.Method, strings.TrimLeft(c.Request.URL.Path, "/")); err != nil {
+		abortWithError(c, http.StatusUnauthorized, err)
+		return
+	}
+	if err = setTenant(c, sessionData); err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return
+	}
+	apiKeySrv.Touch(ctx, k)
+
+	// 将用户及密钥信息存入上下文，操作日志据此记录所用密钥
+	c.Set(gin.AuthUserKey, sessionData)
+	c.Set(context.APIKeyCtxKey, k)
+	c.Set(db.ScopeCtxKey, sessionData.DataScope())
+
+	c.Next()
+}
+
 // 是否有权限
 func hasPermission(ctx *gin.Context, u *modeluser.User) error {
 	if u.IsSuperAdmin() {
 		return nil
 	}
 	path := strings.TrimLeft(ctx.Request.URL.Path, "/")
-	return roleSrv.HasAccessURL(context.New(ctx), u.RoleCode, path)
+	return roleSrv.HasAccessURL(context.New(ctx), u.RoleCodes(), ctx.Request.Method, path)
 }
 
 // abortWithError 中止请求并返回错误
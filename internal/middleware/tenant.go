@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"goadmin/config"
+	"net"
+	"strings"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modeltenant "goadmin/internal/model/tenant"
+	modeluser "goadmin/internal/model/user"
+	tenantService "goadmin/internal/service/tenant"
+	"goadmin/pkg/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setTenant 确定当前请求的租户并写入上下文，仓储据此按租户隔离
+//
+// 普通用户固定为所属租户；超级管理员未指定租户时可访问全部租户
+func setTenant(c *gin.Context, u *modeluser.User) error {
+	if !u.IsSuperAdmin() {
+		c.Set(db.TenantCtxKey, u.TenantID)
+		return nil
+	}
+	code := requestTenantCode(c, config.Get().Tenant)
+	if code == "" {
+		return nil
+	}
+	t, err := tenantSrv.GetTenantByCode(context.New(c), code)
+	if err != nil {
+		return err
+	}
+	c.Set(db.TenantCtxKey, t.ID)
+	return nil
+}
+
+// TenantHint 按请求头或子域名识别请求的租户，供未登录的页面（如登录页）读取租户配置
+//
+// 识别结果不参与数据隔离，登录后以 Auth 确定的租户为准；租户不存在或已停用时忽略
+func TenantHint() gin.HandlerFunc {
+	srv := tenantService.NewTenantService_legacy()
+	return func(c *gin.Context) {
+		if code := requestTenantCode(c, config.Get().Tenant); code != "" {
+			if t, err := srv.GetTenantByCode(context.New(c), code); err == nil && t.IsActive() {
+				c.Set(modeltenant.HintCtxKey, t.ID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// requestTenantCode 请求指定的租户编码，请求头优先于子域名
+func requestTenantCode(c *gin.Context, cfg config.TenantConfig) string {
+	if code := strings.TrimSpace(c.GetHeader(cfg.HeaderOrDefault())); code != "" {
+		return code
+	}
+	if cfg.Domain == "" {
+		return ""
+	}
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	code, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(cfg.Domain))
+	if !ok || strings.Contains(code, ".") {
+		return ""
+	}
+	return code
+}
+
+// checkClaimsTenant 令牌中的租户须与用户当前所属租户一致，用户调整租户后须重新登录
+func checkClaimsTenant(c *gin.Context, u *modeluser.User, tenantID uint64) error {
+	if u.TenantID != tenantID {
+		return i18n.E(c, "user.TokenRevoked", nil)
+	}
+	return nil
+}
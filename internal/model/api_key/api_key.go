@@ -0,0 +1,130 @@
+package api_key
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/util"
+	"slices"
+	"time"
+)
+
+const (
+	// KeyPrefix 密钥的固定前缀，便于识别及在代码仓库中扫描泄露的密钥
+	KeyPrefix = "gak_"
+	// PrefixLength 密钥前缀长度（含 KeyPrefix），用于查找密钥及展示
+	PrefixLength = len(KeyPrefix) + 8
+	// SecretLength 前缀之后随机部分的长度
+	SecretLength = 40
+)
+
+// APIKey API 密钥表，仅保存前缀及完整密钥的哈希
+type APIKey struct {
+	schema.BaseModel
+	UserID     uint64         `gorm:"not null;index:idx_api_key_user" json:"user_id"`
+	Name       string         `gorm:"size:64;not null;default:''" json:"name"`
+	Prefix     string         `gorm:"size:16;not null;uniqueIndex" json:"prefix"`
+	KeyHash    string         `gorm:"size:64;not null;default:''" json:"-"` // 完整密钥的 SHA-256
+	Scopes     Scopes         `gorm:"type:text" json:"scopes"`              // 可访问的权限编码
+	ExpiresAt  *util.DateTime `gorm:"column:expires_at" json:"expires_at"`  // 为空表示永不过期
+	LastUsedAt *util.DateTime `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP string         `gorm:"column:last_used_ip;size:45;not null;default:''" json:"last_used_ip"`
+	RevokedAt  *util.DateTime `gorm:"column:revoked_at" json:"revoked_at"`
+
+	State State `gorm:"-" json:"state"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// AuditResource 变更时记录审计事件
+func (APIKey) AuditResource() string {
+	return "api_key"
+}
+
+// GetID 实现 context.APIKey 接口
+func (k *APIKey) GetID() uint64 {
+	return k.ID
+}
+
+// GetPrefix 实现 context.APIKey 接口
+func (k *APIKey) GetPrefix() string {
+	return k.Prefix
+}
+
+// IsRevoked 是否已吊销
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsExpired 在 now 时是否已过期
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(time.Time(*k.ExpiresAt))
+}
+
+// StateAt 计算密钥在 now 时的状态
+func (k *APIKey) StateAt(now time.Time) State {
+	switch {
+	case k.IsRevoked():
+		return StateRevoked
+	case k.IsExpired(now):
+		return StateExpired
+	default:
+		return StateActive
+	}
+}
+
+// HasScope 是否包含任一权限编码
+func (k *APIKey) HasScope(codes ...string) bool {
+	for _, code := range codes {
+		if slices.Contains(k.Scopes, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// State 密钥状态
+type State string
+
+const (
+	StateActive  State = "active"  // 可用
+	StateExpired State = "expired" // 已过期
+	StateRevoked State = "revoked" // 已吊销
+)
+
+// Scopes 权限编码列表，以 JSON 数组存储
+type Scopes []string
+
+// Value 实现 driver.Valuer 接口
+func (s Scopes) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(s))
+	return string(b), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (s *Scopes) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = Scopes{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+	if len(data) == 0 {
+		*s = Scopes{}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(s))
+}
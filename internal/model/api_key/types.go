@@ -0,0 +1,16 @@
+package api_key
+
+import "goadmin/pkg/util"
+
+// CreateRequest 创建 API 密钥请求参数
+type CreateRequest struct {
+	Name      string         `json:"name" binding:"required,max=64"`                // 名称，用于区分用途
+	Scopes    []string       `json:"scopes" binding:"required,min=1,dive,required"` // 可访问的权限编码
+	ExpiresAt *util.DateTime `json:"expires_at"`                                    // 过期时间，为空表示永不过期
+}
+
+// CreateResponse 创建 API 密钥响应，完整密钥仅在创建时返回一次
+type CreateResponse struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/util"
+)
+
+// Job 导出任务表
+type Job struct {
+	schema.BaseModel
+	TenantID   uint64         `gorm:"column:tenant_id;not null;default:0;index:idx_export_job_tenant;comment:所属租户ID" json:"tenant_id"`
+	UserID     uint64         `gorm:"column:user_id;not null;default:0;index:idx_export_job_user;comment:发起用户ID" json:"user_id"`
+	Resource   string         `gorm:"size:32;not null;default:'';comment:导出的资源" json:"resource"`
+	Format     string         `gorm:"size:8;not null;default:'';comment:文件格式 csv/xlsx" json:"format"`
+	Params     string         `gorm:"type:text;comment:筛选条件" json:"-"` // 列表请求参数，JSON
+	Lang       string         `gorm:"size:16;not null;default:'';comment:表头语言" json:"-"`
+	Status     Status         `gorm:"size:16;not null;default:'';comment:状态" json:"status"`
+	Total      int64          `gorm:"not null;default:0;comment:开始导出时符合条件的行数" json:"total"`
+	Rows       int64          `gorm:"column:exported_rows;not null;default:0;comment:已导出行数" json:"rows"`
+	File       string         `gorm:"size:255;not null;default:'';comment:导出文件名" json:"-"` // 相对导出目录
+	Size       int64          `gorm:"not null;default:0;comment:文件大小" json:"size"`
+	Error      string         `gorm:"size:512;not null;default:'';comment:失败原因" json:"error"`
+	FinishedAt *util.DateTime `gorm:"column:finished_at;index:idx_export_job_finished" json:"finished_at"`
+	Instance   string         `gorm:"size:64;not null;default:'';index:idx_export_job_instance;comment:执行任务的实例" json:"-"`
+}
+
+// TableName 指定表名
+func (Job) TableName() string {
+	return "export_jobs"
+}
+
+// TenantShared 导出任务按租户隔离，不共享
+func (Job) TenantShared() bool {
+	return false
+}
+
+// Finished 是否已结束
+func (j *Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusExpired
+}
+
+// Filename 下载时的文件名，如 user_20240102150405.xlsx
+func (j *Job) Filename() string {
+	return fmt.Sprintf("%s_%s.%s", j.Resource, time.Time(j.CTime).Format("20060102150405"), j.Format)
+}
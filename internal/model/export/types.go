@@ -0,0 +1,26 @@
+package export
+
+// Status 导出任务状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // 等待执行
+	StatusRunning   Status = "running"   // 执行中
+	StatusSucceeded Status = "succeeded" // 已完成，可下载
+	StatusFailed    Status = "failed"    // 失败
+	StatusExpired   Status = "expired"   // 文件已过期删除
+)
+
+// CreateRequest 导出请求，其余查询参数为对应列表接口的筛选条件
+type CreateRequest struct {
+	Format string `form:"format,default=xlsx" binding:"oneof=csv xlsx"` // 文件格式
+}
+
+// 可导出的资源
+const (
+	ResourceUser       = "user"
+	ResourceRole       = "role"
+	ResourceTenant     = "tenant"
+	ResourcePosition   = "position"
+	ResourceOperateLog = "operate_log"
+)
@@ -0,0 +1,142 @@
+package operate_log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goadmin/pkg/util"
+)
+
+// ChainHeadID 链头表中唯一一行的ID
+const ChainHeadID = 1
+
+// ChainHead 操作日志哈希链的链头，写入日志时加锁以保证链按ID顺序串行追加
+//
+// 锚点为链首之前的一条日志：启用哈希链时为之前的最后一条日志，哈希为空；归档删除后为最后一条被删除的日志。
+// ID大于锚点的日志均须有哈希，其中首条须接在锚点哈希之后，锚点仅在删除已归档日志时前移
+type ChainHead struct {
+	ID         uint64        `gorm:"primaryKey" json:"id"`
+	LastID     uint64        `gorm:"column:last_id;not null;default:0" json:"last_id"`
+	Hash       string        `gorm:"size:64;not null;default:''" json:"hash"`
+	AnchorID   uint64        `gorm:"column:anchor_id;not null;default:0" json:"anchor_id"`
+	AnchorHash string        `gorm:"size:64;not null;default:''" json:"anchor_hash"`
+	MTime      util.DateTime `gorm:"column:mtime" json:"mtime"`
+}
+
+// TableName 指定表名
+func (ChainHead) TableName() string {
+	return "operate_log_chain"
+}
+
+// Checkpoint 链的签名检查点，记录某一时刻链尾的哈希
+//
+// 签名密钥不存放在数据库中，可发现连同链头一起被改写或截断的日志
+type Checkpoint struct {
+	ID        uint64        `gorm:"primaryKey" json:"id"`
+	LastID    uint64        `gorm:"column:last_id;not null" json:"last_id"`
+	Hash      string        `gorm:"size:64;not null;default:''" json:"hash"`
+	Signature string        `gorm:"size:64;not null;default:''" json:"signature"`
+	CTime     util.DateTime `gorm:"column:ctime" json:"ctime"`
+}
+
+// TableName 指定表名
+func (Checkpoint) TableName() string {
+	return "operate_log_checkpoint"
+}
+
+// Sign 使用密钥签名检查点
+func (c *Checkpoint) Sign(key string) {
+	c.Signature = c.signature(key)
+}
+
+// VerifySignature 校验检查点签名
+func (c *Checkpoint) VerifySignature(key string) bool {
+	return hmac.Equal([]byte(c.Signature), []byte(c.signature(key)))
+}
+
+func (c *Checkpoint) signature(key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%d:%s", c.LastID, c.Hash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// chainPayload 参与哈希计算的字段，字段顺序固定
+type chainPayload struct {
+	PrevHash   string `json:"prev_hash"`
+	TenantID   uint64 `json:"tenant_id"`
+	Content    string `json:"content"`
+	Action     string `json:"action"`
+	Resource   string `json:"resource"`
+	ResourceID string `json:"resource_id"`
+	Diff       string `json:"diff"`
+	Result     Result `json:"result"`
+	UserID     uint64 `json:"user_id"`
+	Username   string `json:"username"`
+	IP         string `json:"ip"`
+	TraceID    string `json:"trace_id"`
+	UserAgent  string `json:"user_agent"`
+	APIKeyID   uint64 `json:"api_key_id"`
+	APIKey     string `json:"api_key"`
+	CTime      string `json:"ctime"`
+}
+
+// ComputeHash 按前一条日志的哈希及本条日志内容计算哈希
+//
+// 自增ID在写入后才确定，不参与计算；日志顺序由 PrevHash 保证。时间按墙上时间参与计算，
+// 与数据库驱动读回时所用的时区无关
+func (l *OperateLog) ComputeHash() string {
+	b, _ := json.Marshal(chainPayload{
+		PrevHash:   l.PrevHash,
+		TenantID:   l.TenantID,
+		Content:    l.Content,
+		Action:     l.Action,
+		Resource:   l.Resource,
+		ResourceID: l.ResourceID,
+		Diff:       l.Diff,
+		Result:     l.Result,
+		UserID:     l.UserID,
+		Username:   l.Username,
+		IP:         l.IP,
+		TraceID:    l.TraceID,
+		UserAgent:  l.UserAgent,
+		APIKeyID:   l.APIKeyID,
+		APIKey:     l.APIKey,
+		CTime:      l.CTime.String(),
+	})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain 接在前一条日志之后：时间截断到秒以与数据库精度一致，未设置的结果取列默认值，并计算哈希
+func (l *OperateLog) Chain(prevHash string) {
+	if l.Result == 0 {
+		l.Result = ResultSuccess
+	}
+	if l.CTime.IsZero() {
+		l.CTime = util.Now()
+	}
+	l.CTime = util.DateTime(time.Time(l.CTime).Truncate(time.Second))
+	l.MTime = l.CTime
+	l.PrevHash = prevHash
+	l.Hash = l.ComputeHash()
+}
+
+// Broken 链断裂的位置
+type Broken struct {
+	ID     uint64 `json:"id"`     // 首条校验失败的日志ID，链尾被截断时为链头记录的最后ID
+	Reason string `json:"reason"` // 原因
+}
+
+// VerifyResult 哈希链校验结果
+type VerifyResult struct {
+	Legacy      int64   `json:"legacy"`      // 启用哈希链之前没有哈希的日志条数，不参与校验
+	Checked     int64   `json:"checked"`     // 已校验的日志条数
+	FirstID     uint64  `json:"first_id"`    // 首条参与校验的日志ID
+	LastID      uint64  `json:"last_id"`     // 最后一条参与校验的日志ID
+	Checkpoints int     `json:"checkpoints"` // 已校验的检查点数
+	Broken      *Broken `json:"broken"`      // 首个断裂处，链完整时为空
+}
@@ -2,14 +2,40 @@ package operate_log
 
 import (
 	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+)
+
+// Result 操作结果
+type Result int8
+
+const (
+	ResultSuccess Result = iota + 1 // 成功
+	ResultFailure                   // 失败
 )
 
 // OperateLog 操作日志表
+//
+// 由服务手动记录的日志只有 Content；模型变更自动记录的审计事件有操作编码、资源及变更前后的字段
 type OperateLog struct {
 	schema.BaseModel
-	Content  string `gorm:"size:512;comment:详情内容" json:"content"`
-	Username string `gorm:"size:64;not null;default:'';comment:操作用户" json:"username"`
-	IP       string `gorm:"size:40;not null;default:'';comment:操作人ip" json:"ip"`
+	TenantID   uint64 `gorm:"column:tenant_id;not null;default:0;index:idx_operate_log_tenant;comment:所属租户ID" json:"tenant_id"`
+	Content    string `gorm:"size:512;comment:详情内容" json:"content"`
+	Action     string `gorm:"size:64;not null;default:'';index:idx_operate_log_action;comment:操作编码" json:"action"`
+	Resource   string `gorm:"size:64;not null;default:'';index:idx_operate_log_resource;comment:资源类型" json:"resource"`
+	ResourceID string `gorm:"column:resource_id;size:64;not null;default:'';index:idx_operate_log_resource;comment:资源ID" json:"resource_id"`
+	Diff       string `gorm:"type:text;comment:变更前后的字段" json:"diff"` // JSON 对象，键为字段名，值为 {before, after}
+	Result     Result `gorm:"type:tinyint;not null;default:1;comment:结果 1:成功,2:失败" json:"result"`
+	UserID     uint64 `gorm:"column:user_id;not null;default:0;index:idx_operate_log_user;comment:操作用户ID" json:"user_id"`
+	Username   string `gorm:"size:64;not null;default:'';index:idx_operate_log_username;comment:操作用户" json:"username"`
+	IP         string `gorm:"size:40;not null;default:'';comment:操作人ip" json:"ip"`
+	TraceID    string `gorm:"column:trace_id;size:64;not null;default:'';index:idx_operate_log_trace;comment:请求跟踪ID" json:"trace_id"`
+	UserAgent  string `gorm:"column:user_agent;size:255;not null;default:'';comment:客户端UA" json:"user_agent"`
+	// 使用 API 密钥调用时记录所用密钥
+	APIKeyID uint64 `gorm:"column:api_key_id;not null;default:0;comment:API密钥ID" json:"api_key_id"`
+	APIKey   string `gorm:"column:api_key;size:16;not null;default:'';comment:API密钥前缀" json:"api_key"`
+	// 哈希链，写入后不可修改，见 ComputeHash
+	PrevHash string `gorm:"column:prev_hash;size:64;not null;default:'';comment:前一条日志的哈希" json:"prev_hash"`
+	Hash     string `gorm:"size:64;not null;default:'';comment:本条日志的哈希" json:"hash"`
 }
 
 // TableName 指定表名
@@ -17,12 +43,30 @@ func (OperateLog) TableName() string {
 	return "operate_log"
 }
 
+// ScopeColumns 数据范围按操作用户及所属租户过滤
+func (OperateLog) ScopeColumns() db.Columns {
+	return db.Columns{Owner: "user_id", Tenant: "tenant_id"}
+}
+
+// TenantShared 操作日志按租户隔离，不共享
+func (OperateLog) TenantShared() bool {
+	return false
+}
+
 // ListRequest 操作日志列表请求
 type ListRequest struct {
 	schema.PageRequest
-	Username  string `form:"username" json:"username"`     // 用户名
-	Content   string `form:"content" json:"content"`       // 内容
-	IP        string `form:"ip" json:"ip"`                 // IP地址
-	StartTime string `form:"start_time" json:"start_time"` // 开始时间
-	EndTime   string `form:"end_time" json:"end_time"`     // 结束时间
+	UserID     uint64 `form:"user_id" json:"user_id"`         // 操作用户ID
+	Username   string `form:"username" json:"username"`       // 用户名
+	Content    string `form:"content" json:"content"`         // 内容
+	Action     string `form:"action" json:"action"`           // 操作编码
+	Resource   string `form:"resource" json:"resource"`       // 资源类型
+	ResourceID string `form:"resource_id" json:"resource_id"` // 资源ID
+	Result     Result `form:"result" json:"result"`           // 操作结果
+	TraceID    string `form:"trace_id" json:"trace_id"`       // 请求跟踪ID
+	UserAgent  string `form:"user_agent" json:"user_agent"`   // 客户端UA
+	IP         string `form:"ip" json:"ip"`                   // IP地址
+	APIKey     string `form:"api_key" json:"api_key"`         // API密钥前缀
+	StartTime  string `form:"start_time" json:"start_time"`   // 开始时间
+	EndTime    string `form:"end_time" json:"end_time"`       // 结束时间
 }
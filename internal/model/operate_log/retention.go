@@ -0,0 +1,16 @@
+package operate_log
+
+// RetentionResult 清理过期日志的结果
+type RetentionResult struct {
+	Archive string `json:"archive"`  // 归档文件路径，没有过期日志时为空
+	FirstID uint64 `json:"first_id"` // 归档的首条日志ID
+	LastID  uint64 `json:"last_id"`  // 归档的最后一条日志ID
+	Deleted int64  `json:"deleted"`  // 已删除的日志条数
+}
+
+// RestoreResult 导入归档文件的结果
+type RestoreResult struct {
+	Total    int64 `json:"total"`    // 归档文件中的日志条数
+	Restored int64 `json:"restored"` // 已导入的日志条数
+	Skipped  int64 `json:"skipped"`  // 已存在而跳过的日志条数
+}
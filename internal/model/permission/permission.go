@@ -12,7 +12,8 @@ type Permission struct {
 	Code        string     `gorm:"size:32;not null;unique;default:''" json:"code"` // 权限唯一标识，如 user:create
 	Name        string     `gorm:"size:50;not null;unique;default:''" json:"name"`
 	Description string     `gorm:"size:200;default:''" json:"description"`
-	Path        string     `gorm:"size:200;not null;default:''" json:"path"`           // API路径
+	Method      string     `gorm:"size:10;not null;default:''" json:"method"`          // HTTP方法，为空表示任意方法
+	Path        string     `gorm:"size:200;not null;default:''" json:"path"`           // API路径，支持 :name 参数及 *name 通配
 	GlobalFlag  GlobalFlag `gorm:"type:tinyint;default:2;not null" json:"global_flag"` //
 	Module      string     `gorm:"size:50;not null;default:''" json:"module"`          // 所属模块
 }
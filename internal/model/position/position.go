@@ -2,10 +2,12 @@ package position
 
 import (
 	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
 )
 
 type Position struct {
 	schema.BaseModel
+	TenantID   uint64  `gorm:"column:tenant_id;not null;default:0;index:idx_position_tenant;comment:所属租户ID" json:"tenant_id"`
 	City       string  `gorm:"column:city;type:varchar(64);not null;default:'';index:idx_city;comment:城市名称" json:"city"`
 	Location   string  `gorm:"column:location;type:varchar(128);not null;default:'';index:idx_location;comment:详细位置（如街道/建筑）" json:"location"`
 	Longitude  float64 `gorm:"column:longitude;type:numeric(10,6);not null;comment:经度" json:"longitude"`
@@ -19,3 +21,18 @@ type Position struct {
 func (Position) TableName() string {
 	return "position"
 }
+
+// AuditResource 变更时记录审计事件
+func (Position) AuditResource() string {
+	return "position"
+}
+
+// ScopeColumns 数据范围按创建人及所属租户过滤
+func (Position) ScopeColumns() db.Columns {
+	return db.Columns{Owner: "creator_id", Tenant: "tenant_id"}
+}
+
+// TenantShared 位置按租户隔离，不共享
+func (Position) TenantShared() bool {
+	return false
+}
@@ -3,18 +3,29 @@ package role
 import (
 	"goadmin/internal/model/permission"
 	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
 )
 
 // Role 角色表
 type Role struct {
 	schema.BaseModel
+	// 所属租户，为 0 表示平台内置，各租户只读共享
+	TenantID    uint64     `gorm:"column:tenant_id;not null;default:0;index:idx_role_tenant" json:"tenant_id"`
 	Code        string     `gorm:"column:code;size:32;not null;unique;default:''" json:"code"`
+	ParentCode  string     `gorm:"column:parent_code;size:32;not null;index:idx_role_parent;default:''" json:"parent_code"` // 父角色代码，子角色继承其权限
 	Name        string     `gorm:"column:name;size:50;not null;unique;default:''" json:"name"`
 	Description string     `gorm:"column:description;size:200;default:''" json:"description"`
 	Status      RoleStatus `gorm:"column:status;default:1;comment:1:active,2:inactive" json:"status"`
 	SystemFlag  SystemFlag `gorm:"column:system_flag;default:2;comment:2:非系统,1:系统" json:"system_flag"` //
+	// 数据范围，自定义范围的条件为 JSON 数组，见 db.Condition
+	DataScope  db.ScopeKind `gorm:"column:data_scope;type:tinyint;not null;default:1;comment:1:全部,2:本租户,3:本部门,4:本人,5:自定义" json:"data_scope"`
+	DataFilter string       `gorm:"column:data_filter;type:text" json:"data_filter"`
 
 	Permissions []permission.Permission `gorm:"-" json:"permissions"`
+
+	// 以下仅在查看角色详情时填充
+	Ancestors            []string                `gorm:"-" json:"ancestors,omitempty"`             // 祖先角色代码，由近及远
+	EffectivePermissions []permission.Permission `gorm:"-" json:"effective_permissions,omitempty"` // 含继承的有效权限
 }
 
 // TableName 指定表名
@@ -22,13 +33,42 @@ func (Role) TableName() string {
 	return "roles"
 }
 
+// AuditResource 变更时记录审计事件
+func (Role) AuditResource() string {
+	return "role"
+}
+
+// TenantShared 平台内置角色各租户共享
+func (Role) TenantShared() bool {
+	return true
+}
+
 // SystemFlag 是否系统权限
 func (r Role) IsSystem() bool {
 	return r.SystemFlag == SystemFlagYes
 }
 
+// IsActive 是否启用
+func (r Role) IsActive() bool {
+	return r.Status == RoleStatusActive
+}
+
+// DataScopeRule 角色的数据范围规则，配置无效时不可见任何数据
+func (r Role) DataScopeRule() db.Rule {
+	if !r.DataScope.IsValid() {
+		return db.Rule{Kind: db.ScopeCustom}
+	}
+	rule := db.Rule{Kind: r.DataScope}
+	if r.DataScope == db.ScopeCustom {
+		rule.Conditions, _ = db.ParseConditions(r.DataFilter)
+	}
+	return rule
+}
+
 const (
 	CodeSuperAdmin = "sup_admin" // 超级管理员角色code
+
+	MaxDepth = 8 // 角色层级上限（含自身）
 )
 
 type SystemFlag int8
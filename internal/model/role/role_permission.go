@@ -21,6 +21,11 @@ func (RolePermission) TableName() string {
 	return TableNameRolePermission
 }
 
+// AuditResource 变更时记录审计事件
+func (RolePermission) AuditResource() string {
+	return "role_permission"
+}
+
 type RoleFullPermission struct {
 	RoleCode string `gorm:"column:role_code" json:"role_code"`
 	permission.Permission
@@ -0,0 +1,20 @@
+package role
+
+import "slices"
+
+// Set 用户拥有的角色集合
+type Set []Role
+
+// Codes 返回角色代码
+func (s Set) Codes() []string {
+	codes := make([]string, 0, len(s))
+	for _, r := range s {
+		codes = append(codes, r.Code)
+	}
+	return codes
+}
+
+// Has 是否包含指定角色
+func (s Set) Has(code string) bool {
+	return slices.ContainsFunc(s, func(r Role) bool { return r.Code == code })
+}
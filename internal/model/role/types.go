@@ -1,6 +1,9 @@
 package role
 
-import "goadmin/internal/model/schema"
+import (
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+)
 
 // LoginRequest 登录请求参数
 type CreateRequest struct {
@@ -8,6 +11,10 @@ type CreateRequest struct {
 	Name        string     `json:"name" form:"name" binding:"required,max=50"`
 	Description string     `json:"description" form:"description" binding:"max=200"`
 	Status      RoleStatus `json:"status" form:"status" binding:"oneof=1 2"`
+	ParentCode  string     `json:"parent_code" form:"parent_code" binding:"max=32"` // 父角色代码，为空表示顶级角色
+	// 数据范围，为空时不限制；自定义范围须提供条件
+	DataScope  db.ScopeKind `json:"data_scope" form:"data_scope" binding:"omitempty,oneof=1 2 3 4 5"`
+	DataFilter string       `json:"data_filter" form:"data_filter" binding:"max=2000"`
 }
 
 type UpdateRequest struct {
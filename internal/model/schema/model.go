@@ -8,7 +8,7 @@ import (
 
 // BaseModel 基础模型，包含共有字段
 type BaseModel struct {
-	ID    uint64        `gorm:"primary_key;auto_increment;default:0" json:"id"`
+	ID    uint64        `gorm:"primaryKey;autoIncrement" json:"id"`
 	CTime util.DateTime `gorm:"column:ctime" json:"ctime"`
 	MTime util.DateTime `gorm:"column:mtime" json:"mtime"`
 }
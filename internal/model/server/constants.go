@@ -7,4 +7,12 @@ const (
 
 	// 是否开启验证码开关
 	SettingCaptchaSwitch = "captcha_switch"
+	// 双因素认证配置
+	SettingTwoFactor = "two_factor"
+	// 登录安全配置（防暴力破解）
+	SettingLoginSecurity = "login_security"
+	// 密码策略
+	SettingPasswordPolicy = "password_policy"
+	// 自助找回密码配置
+	SettingPasswordReset = "password_reset"
 )
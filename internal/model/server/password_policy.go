@@ -0,0 +1,100 @@
+package server
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicyConfig 密码策略配置
+type PasswordPolicyConfig struct {
+	MinLength     int      `json:"password_min_length" binding:"gte=0"`    // 最小长度
+	MaxLength     int      `json:"password_max_length" binding:"gte=0"`    // 最大长度，0 表示不限制
+	RequireUpper  bool     `json:"password_require_upper"`                 // 必须包含大写字母
+	RequireLower  bool     `json:"password_require_lower"`                 // 必须包含小写字母
+	RequireDigit  bool     `json:"password_require_digit"`                 // 必须包含数字
+	RequireSymbol bool     `json:"password_require_symbol"`                // 必须包含特殊字符
+	BannedList    []string `json:"password_banned_list"`                   // 禁用的弱密码，不区分大小写
+	HistoryCount  int      `json:"password_history_count" binding:"gte=0"` // 不允许与最近 N 次密码相同，0 表示不限制
+	MaxAge        int      `json:"password_max_age" binding:"gte=0"`       // 密码有效期（天），0 表示永不过期
+}
+
+// PasswordPolicyError 密码不符合策略，Key 为 i18n 消息键
+type PasswordPolicyError struct {
+	Key    string
+	Params map[string]any
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Key
+}
+
+// Check 校验明文密码是否符合策略，符合时返回 nil
+func (c *PasswordPolicyConfig) Check(password, username string) *PasswordPolicyError {
+	n := utf8.RuneCountInString(password)
+	if n < c.MinLength {
+		return &PasswordPolicyError{Key: "user.PasswordTooShort", Params: map[string]any{"min": c.MinLength}}
+	}
+	if c.MaxLength > 0 && n > c.MaxLength {
+		return &PasswordPolicyError{Key: "user.PasswordTooLong", Params: map[string]any{"max": c.MaxLength}}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	switch {
+	case c.RequireUpper && !upper:
+		return &PasswordPolicyError{Key: "user.PasswordRequireUpper"}
+	case c.RequireLower && !lower:
+		return &PasswordPolicyError{Key: "user.PasswordRequireLower"}
+	case c.RequireDigit && !digit:
+		return &PasswordPolicyError{Key: "user.PasswordRequireDigit"}
+	case c.RequireSymbol && !symbol:
+		return &PasswordPolicyError{Key: "user.PasswordRequireSymbol"}
+	}
+
+	lowered := strings.ToLower(password)
+	for _, banned := range c.BannedList {
+		if banned != "" && lowered == strings.ToLower(banned) {
+			return &PasswordPolicyError{Key: "user.PasswordBanned"}
+		}
+	}
+	if username != "" && strings.Contains(lowered, strings.ToLower(username)) {
+		return &PasswordPolicyError{Key: "user.PasswordContainsUsername"}
+	}
+	return nil
+}
+
+// ExpiresAt 根据密码修改时间计算过期时间，永不过期时返回零值
+func (c *PasswordPolicyConfig) ExpiresAt(changedAt time.Time) time.Time {
+	if c.MaxAge <= 0 || changedAt.IsZero() {
+		return time.Time{}
+	}
+	return changedAt.AddDate(0, 0, c.MaxAge)
+}
+
+// Expired 密码是否已过期
+func (c *PasswordPolicyConfig) Expired(changedAt, now time.Time) bool {
+	expiresAt := c.ExpiresAt(changedAt)
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+// GenerateLength 生成一次性密码的长度，满足最小长度且不少于 12 位
+func (c *PasswordPolicyConfig) GenerateLength() int {
+	n := max(c.MinLength, 12)
+	if c.MaxLength > 0 && n > c.MaxLength {
+		n = c.MaxLength
+	}
+	return n
+}
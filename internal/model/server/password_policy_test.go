@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	cfg := &PasswordPolicyConfig{
+		MinLength:    8,
+		MaxLength:    16,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		BannedList:   []string{"Passw0rd1"},
+	}
+	cases := []struct {
+		password string
+		key      string
+	}{
+		{"Ab1", "user.PasswordTooShort"},
+		{"Abcdefgh12345678x", "user.PasswordTooLong"},
+		{"abcdefg1", "user.PasswordRequireUpper"},
+		{"ABCDEFG1", "user.PasswordRequireLower"},
+		{"Abcdefgh", "user.PasswordRequireDigit"},
+		{"passw0rD1", "user.PasswordBanned"},
+		{"Alice2024x", "user.PasswordContainsUsername"},
+		{"Tr0ub4dor", ""},
+	}
+	for _, c := range cases {
+		e := cfg.Check(c.password, "alice")
+		got := ""
+		if e != nil {
+			got = e.Key
+		}
+		if got != c.key {
+			t.Errorf("%s 期望 %q, 实际为 %q", c.password, c.key, got)
+		}
+	}
+
+	cfg.RequireSymbol = true
+	if e := cfg.Check("Tr0ub4dor", ""); e == nil || e.Key != "user.PasswordRequireSymbol" {
+		t.Errorf("缺少特殊字符应校验失败: %v", e)
+	}
+	if e := cfg.Check("Tr0ub4dor&3", ""); e != nil {
+		t.Errorf("符合策略的密码应校验通过: %s", e.Key)
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	changedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &PasswordPolicyConfig{MaxAge: 90}
+	if cfg.Expired(changedAt, changedAt.AddDate(0, 0, 89)) {
+		t.Error("有效期内的密码不应过期")
+	}
+	if !cfg.Expired(changedAt, changedAt.AddDate(0, 0, 90)) {
+		t.Error("超过有效期的密码应过期")
+	}
+
+	cfg.MaxAge = 0
+	if !cfg.ExpiresAt(changedAt).IsZero() || cfg.Expired(changedAt, changedAt.AddDate(10, 0, 0)) {
+		t.Error("未配置有效期时密码不应过期")
+	}
+}
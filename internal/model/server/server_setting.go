@@ -5,11 +5,23 @@ import "goadmin/internal/model/schema"
 // ServerSetting 服务端配置表
 type ServerSetting struct {
 	schema.BaseModel
-	Name  string `gorm:"size:64;not null;unique;default:''" json:"name"`
-	Value string `gorm:"type:text" json:"value"`
+	// 所属租户，为 0 表示全局配置，各租户只读共享
+	TenantID uint64 `gorm:"column:tenant_id;not null;default:0;uniqueIndex:uk_setting_tenant_name" json:"tenant_id"`
+	Name     string `gorm:"size:64;not null;uniqueIndex:uk_setting_tenant_name;default:''" json:"name"`
+	Value    string `gorm:"type:text" json:"value"`
 }
 
 // TableName 指定表名
 func (ServerSetting) TableName() string {
 	return "server_setting"
 }
+
+// AuditResource 变更时记录审计事件
+func (ServerSetting) AuditResource() string {
+	return "server_setting"
+}
+
+// TenantShared 全局配置各租户共享
+func (ServerSetting) TenantShared() bool {
+	return true
+}
@@ -1,5 +1,10 @@
 package server
 
+import (
+	"slices"
+	"time"
+)
+
 type Switch int8
 
 const (
@@ -25,6 +30,71 @@ func (c *CaptchaSwitchConfig) IsWebOn() bool {
 	return c.Web == SwitchOn
 }
 
+// TwoFactorConfig 双因素认证配置
+type TwoFactorConfig struct {
+	RequiredRoles []string `json:"required_roles"` // 强制启用双因素认证的角色编码
+}
+
+// IsRequired 拥有任一指定角色时必须启用双因素认证
+func (c *TwoFactorConfig) IsRequired(roleCodes []string) bool {
+	return slices.ContainsFunc(roleCodes, func(code string) bool { return slices.Contains(c.RequiredRoles, code) })
+}
+
+// LoginSecurityConfig 登录安全配置，用于防暴力破解
+type LoginSecurityConfig struct {
+	MaxFailures   int `json:"max_failures" binding:"gte=0"`    // 窗口期内同一账号允许的失败次数，达到后锁定账号，0 表示不锁定
+	IPMaxFailures int `json:"ip_max_failures" binding:"gte=0"` // 窗口期内同一IP允许的失败次数，超过后拒绝该IP登录，0 表示不限制
+	FailureWindow int `json:"failure_window" binding:"gte=0"`  // 失败计数窗口（秒）
+	LockDuration  int `json:"lock_duration" binding:"gte=0"`   // 锁定时长（秒），到期自动解锁，0 表示需管理员解锁
+	DelayBase     int `json:"delay_base" binding:"gte=0"`      // 登录失败后的初始等待时间（秒），连续失败逐次翻倍
+	DelayMax      int `json:"delay_max" binding:"gte=0"`       // 单次等待时间上限（秒）
+}
+
+// Window 失败计数窗口，未配置时默认15分钟
+func (c *LoginSecurityConfig) Window() time.Duration {
+	if c.FailureWindow <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(c.FailureWindow) * time.Second
+}
+
+// Delay 第 failures 次失败后需等待的时间
+func (c *LoginSecurityConfig) Delay(failures int64) time.Duration {
+	if c.DelayBase <= 0 || failures <= 0 {
+		return 0
+	}
+	delay := c.DelayBase
+	for i := int64(1); i < failures && (c.DelayMax <= 0 || delay < c.DelayMax); i++ {
+		delay *= 2
+	}
+	if c.DelayMax > 0 && delay > c.DelayMax {
+		delay = c.DelayMax
+	}
+	return time.Duration(delay) * time.Second
+}
+
+// PasswordResetConfig 自助找回密码配置
+type PasswordResetConfig struct {
+	Enable     Switch `json:"password_reset_enable" binding:"gte=0,lte=1"` // 是否开启邮件找回密码
+	ResetURL   string `json:"password_reset_url"`                          // 前端重置密码页面地址，令牌以 token 参数附加
+	TokenTTL   int    `json:"password_reset_token_ttl" binding:"gte=0"`    // 令牌有效期（秒），默认30分钟
+	EmailLimit int    `json:"password_reset_email_limit" binding:"gte=0"`  // 每小时同一账号最多发送邮件次数，0 表示不限制
+	IPLimit    int    `json:"password_reset_ip_limit" binding:"gte=0"`     // 每小时同一IP最多申请次数，0 表示不限制
+}
+
+// IsOn 是否开启邮件找回密码
+func (c *PasswordResetConfig) IsOn() bool {
+	return c.Enable == SwitchOn
+}
+
+// TTL 令牌有效期，未配置时默认30分钟
+func (c *PasswordResetConfig) TTL() time.Duration {
+	if c.TokenTTL <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.TokenTTL) * time.Second
+}
+
 type SystemConfig struct {
 	SystemName string `json:"system_name" binding:"required"`
 	Logo       string `json:"logo"`
@@ -39,4 +109,8 @@ type SystemSettingsRequest SystemSettingsResponse
 type SystemSettingsResponse struct {
 	SystemConfig
 	CaptchaSwitchConfig
+	TwoFactorConfig
+	LoginSecurityConfig
+	PasswordPolicyConfig
+	PasswordResetConfig
 }
@@ -0,0 +1,43 @@
+package tenant
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+
+	"goadmin/pkg/jsonschema"
+)
+
+// HintCtxKey 上下文中保存未登录请求按请求头或子域名识别出的租户ID，值为 uint64
+//
+// 仅用于读取租户配置（如登录页的系统名称、验证码开关），不参与数据隔离
+const HintCtxKey = "goadmin/tenant_hint"
+
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+// configSchema 租户扩展配置的 JSON Schema
+var configSchema = jsonschema.MustCompile(configSchemaJSON)
+
+// Config 租户扩展配置，键为服务端配置名，值覆盖同名全局配置
+type Config map[string]json.RawMessage
+
+// ParseConfig 按 JSON Schema 校验并解析租户扩展配置，空字符串表示未配置
+func ParseConfig(raw string) (Config, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	if err := configSchema.ValidateJSON([]byte(raw)); err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Override 获取指定配置的租户覆盖值，未配置时返回 nil
+func (c Config) Override(name string) json.RawMessage {
+	return c[name]
+}
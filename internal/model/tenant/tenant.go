@@ -2,6 +2,7 @@ package tenant
 
 import (
 	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
 )
 
 // Tenant 租户表
@@ -20,6 +21,16 @@ func (Tenant) TableName() string {
 	return "tenants"
 }
 
+// AuditResource 变更时记录审计事件
+func (Tenant) AuditResource() string {
+	return "tenant"
+}
+
+// ScopeColumns 数据范围按租户过滤，租户数据即租户本身
+func (Tenant) ScopeColumns() db.Columns {
+	return db.Columns{Tenant: "id"}
+}
+
 // IsActive 判断租户是否启用
 func (t *Tenant) IsActive() bool {
 	return t.Status == TenantStatusEnabled
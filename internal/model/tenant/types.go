@@ -23,8 +23,8 @@ type CreateRequest struct {
 	Code         string `json:"code" binding:"required,max=64"`           // 租户编码
 	ContactEmail string `json:"contact_email" binding:"omitempty,email"`  // 联系邮箱
 	ContactPhone string `json:"contact_phone" binding:"omitempty,max=32"` // 联系电话
-	Status       int    `json:"status" binding:"omitempty,oneof=0 1"`     // 状态
-	Config       string `json:"config"`                                   // 扩展配置JSON
+	Status       int    `json:"status" binding:"omitempty,oneof=0 1 2"`   // 状态：1-启用，2-停用
+	Config       string `json:"config"`                                   // 扩展配置JSON，须符合 config.schema.json
 }
 
 // UpdateRequest 更新租户请求参数
@@ -32,3 +32,24 @@ type UpdateRequest struct {
 	schema.IDRequest
 	CreateRequest
 }
+
+// ProvisionRequest 开通租户请求参数，同时创建租户管理员
+type ProvisionRequest struct {
+	CreateRequest
+	AdminUsername string `json:"admin_username" binding:"required,max=50"`      // 管理员用户名
+	AdminEmail    string `json:"admin_email" binding:"omitempty,email,max=100"` // 管理员邮箱
+}
+
+// ProvisionResponse 开通租户结果，管理员初始密码仅展示一次
+type ProvisionResponse struct {
+	Tenant        *Tenant `json:"tenant"`
+	AdminRole     string  `json:"admin_role"`     // 由模板克隆的管理员角色编码
+	AdminUsername string  `json:"admin_username"` // 管理员用户名
+	AdminPassword string  `json:"admin_password"` // 管理员初始密码，首次登录须修改
+}
+
+// DeprovisionResponse 注销租户结果
+type DeprovisionResponse struct {
+	Archive string           `json:"archive"` // 租户数据归档文件
+	Rows    map[string]int64 `json:"rows"`    // 各表归档并删除的记录数
+}
@@ -0,0 +1,23 @@
+package user
+
+import "goadmin/internal/model/schema"
+
+// 外部身份来源
+const (
+	IdentityProviderOIDC = "oidc"
+	IdentityProviderLDAP = "ldap"
+)
+
+// Identity 用户外部身份绑定表，记录单点登录账号与本地用户的对应关系
+type Identity struct {
+	schema.BaseModel
+	UserID   uint64 `gorm:"not null;index:idx_user_id" json:"user_id"`
+	Provider string `gorm:"size:32;not null;default:'';uniqueIndex:uk_identity" json:"provider"` // 身份来源
+	Issuer   string `gorm:"size:255;not null;default:'';uniqueIndex:uk_identity" json:"issuer"`  // 身份提供方
+	Subject  string `gorm:"size:255;not null;default:'';uniqueIndex:uk_identity" json:"subject"` // 身份提供方中的用户标识
+}
+
+// TableName 指定表名
+func (Identity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,15 @@
+package user
+
+import "goadmin/internal/model/schema"
+
+// PasswordHistory 用户历史密码表，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	schema.BaseModel
+	UserID   uint64 `gorm:"not null;index:idx_user_id" json:"user_id"`
+	Password string `gorm:"size:100;not null;default:''" json:"-"` // 密码哈希
+}
+
+// TableName 指定表名
+func (PasswordHistory) TableName() string {
+	return "user_password_history"
+}
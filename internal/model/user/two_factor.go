@@ -0,0 +1,54 @@
+package user
+
+import (
+	"encoding/json"
+	"goadmin/internal/model/schema"
+)
+
+// TwoFactorStatus 双因素认证状态
+type TwoFactorStatus int
+
+const (
+	// TwoFactorPending 已生成密钥，待验证启用
+	TwoFactorPending TwoFactorStatus = iota
+	// TwoFactorEnabled 已启用
+	TwoFactorEnabled
+)
+
+// TwoFactor 用户双因素认证（TOTP）配置表
+type TwoFactor struct {
+	schema.BaseModel
+	UserID        uint64          `gorm:"not null;uniqueIndex" json:"user_id"`
+	Secret        string          `gorm:"size:255;not null;default:''" json:"-"` // AES-GCM 加密后的 TOTP 密钥
+	Status        TwoFactorStatus `gorm:"type:int;default:0;comment:0:pending,1:enabled" json:"status"`
+	RecoveryCodes string          `gorm:"type:text" json:"-"` // 未使用恢复码的哈希，JSON 数组
+}
+
+// TableName 指定表名
+func (TwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// IsEnabled 是否已启用
+func (t *TwoFactor) IsEnabled() bool {
+	return t.Status == TwoFactorEnabled
+}
+
+// RecoveryHashes 未使用恢复码的哈希列表
+func (t *TwoFactor) RecoveryHashes() []string {
+	var hashes []string
+	if t.RecoveryCodes != "" {
+		_ = json.Unmarshal([]byte(t.RecoveryCodes), &hashes)
+	}
+	return hashes
+}
+
+// SetRecoveryHashes 设置恢复码哈希列表
+func (t *TwoFactor) SetRecoveryHashes(hashes []string) {
+	if len(hashes) == 0 {
+		t.RecoveryCodes = ""
+		return
+	}
+	b, _ := json.Marshal(hashes)
+	t.RecoveryCodes = string(b)
+}
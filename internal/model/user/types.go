@@ -7,8 +7,8 @@ import (
 // LoginRequest 登录请求参数
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Token    string `json:"token"` // 验证码token
+	Password string `json:"password" binding:"required,max=128"` // 密码明文，由认证器链校验
+	Token    string `json:"token"`                               // 验证码token
 }
 
 // LoginResponse 登录响应
@@ -16,13 +16,103 @@ type LoginResponse struct {
 	Token        string `json:"token"`         // JWT token
 	RefreshToken string `json:"refresh_token"` // 刷新token
 	ExpiresAt    int64  `json:"expires_at"`
+
+	// 开启双因素认证时，密码校验通过后仅返回预认证令牌，需再提交动态码完成登录
+	TwoFactorRequired bool     `json:"two_factor_required,omitempty"` // 需要输入动态码
+	TwoFactorSetup    bool     `json:"two_factor_setup,omitempty"`    // 所属角色强制启用双因素认证，需先绑定认证器
+	PreAuthToken      string   `json:"pre_auth_token,omitempty"`      // 预认证令牌
+	RecoveryCodes     []string `json:"recovery_codes,omitempty"`      // 登录时完成绑定返回的恢复码，仅展示一次
+
+	// 管理员重置密码或密码已过期时为 true，前端应引导用户修改密码
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
+}
+
+// LoginOptionsResponse 登录页可用的登录方式
+type LoginOptionsResponse struct {
+	LocalLogin bool   `json:"local_login"` // 允许用户名密码登录
+	OIDC       bool   `json:"oidc"`        // 允许单点登录
+	OIDCName   string `json:"oidc_name"`   // 单点登录按钮显示的名称
+}
+
+// LDAPSyncResult LDAP 用户同步结果
+type LDAPSyncResult struct {
+	Total    int `json:"total"`    // 目录中的用户数
+	Created  int `json:"created"`  // 新建的用户数
+	Updated  int `json:"updated"`  // 同步了角色的用户数
+	Disabled int `json:"disabled"` // 因目录中已不存在而禁用的用户数
+	Skipped  int `json:"skipped"`  // 未开通或无法创建的用户数
+}
+
+// OIDCCallbackRequest 身份提供方回调参数
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// TwoFactorLoginRequest 双因素认证登录请求参数
+type TwoFactorLoginRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+	Code         string `json:"code" binding:"required"` // 动态码或恢复码
+}
+
+// TwoFactorSetupRequest 登录时绑定认证器请求参数
+type TwoFactorSetupRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+}
+
+// TwoFactorCodeRequest 双因素认证校验请求参数
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 动态码或恢复码
+}
+
+// TwoFactorEnrollResponse 绑定认证器响应
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"` // base32 密钥，供无法扫码时手动输入
+	URI    string `json:"uri"`    // otpauth 链接，由前端渲染为二维码
+}
+
+// TwoFactorStatusResponse 双因素认证状态
+type TwoFactorStatusResponse struct {
+	Enabled       bool `json:"enabled"`
+	Required      bool `json:"required"`       // 所属角色是否强制启用
+	RecoveryCodes int  `json:"recovery_codes"` // 剩余可用恢复码数量
+}
+
+// TwoFactorRecoveryResponse 恢复码响应，恢复码仅展示一次
+type TwoFactorRecoveryResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshTokenRequest 刷新令牌请求参数
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // ChangePasswordRequest 修改密码请求参数
 type ChangePasswordRequest struct {
-	OldPassword     string `json:"old_password" binding:"required"`
-	NewPassword     string `json:"new_password" binding:"required"`
-	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"`
+	OldPassword     string `json:"old_password" binding:"required,max=128"`                 // 旧密码明文
+	NewPassword     string `json:"new_password" binding:"required,max=128"`                 // 新密码明文，需符合密码策略
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"` // 确认新密码
+}
+
+// ForgotPasswordRequest 找回密码请求参数
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Token string `json:"token"` // 验证码token
+}
+
+// PasswordResetConfirmRequest 通过邮件令牌重置密码请求参数
+type PasswordResetConfirmRequest struct {
+	Token           string `json:"token" binding:"required"`                                // 邮件中的找回密码令牌
+	NewPassword     string `json:"new_password" binding:"required,max=128"`                 // 新密码明文，需符合密码策略
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"` // 确认新密码
+}
+
+// ResetPasswordResponse 重置密码响应，一次性密码仅展示一次
+type ResetPasswordResponse struct {
+	Password string `json:"password"`
 }
 
 // ListRequest 用户列表请求参数
@@ -33,18 +123,25 @@ type ListRequest struct {
 
 // CreateUserRequest 创建用户请求参数
 type CreateUserRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"` // 用户名
-	Password string `json:"password" binding:"required,min=6,max=32"` // 密码
-	Email    string `json:"email" binding:"omitempty,email"`          // 邮箱
-	RoleCode string `json:"role_code" binding:"required"`             // 角色代码
-	Status   int    `json:"status" binding:"omitempty,min=0,max=1"`   // 状态：0-禁用，1-启用
+	Username  string   `json:"username" binding:"required,min=3,max=50"`                 // 用户名
+	Password  string   `json:"password" binding:"required,max=128"`                      // 密码明文，长度等规则由密码策略校验
+	Email     string   `json:"email" binding:"omitempty,email"`                          // 邮箱
+	RoleCodes []string `json:"role_codes" binding:"required,min=1,dive,required,max=32"` // 角色代码
+	DeptID    uint64   `json:"dept_id"`                                                  // 所属部门ID
+	Status    int      `json:"status" binding:"omitempty,min=0,max=1"`                   // 状态：0-禁用，1-启用
 }
 
 // UpdateUserRequest 更新用户请求参数
 type UpdateUserRequest struct {
-	ID       uint64 `json:"id" binding:"required"`                     // 用户ID
-	Username string `json:"username" binding:"omitempty,min=3,max=50"` // 用户名
-	Email    string `json:"email" binding:"omitempty,email"`           // 邮箱
-	RoleCode string `json:"role_code" binding:"omitempty"`             // 角色代码
-	Status   int    `json:"status" binding:"omitempty,min=0,max=1"`    // 状态：0-禁用，1-启用
+	ID        uint64   `json:"id" binding:"required"`                               // 用户ID
+	Username  string   `json:"username" binding:"omitempty,min=3,max=50"`           // 用户名
+	Email     string   `json:"email" binding:"omitempty,email"`                     // 邮箱
+	RoleCodes []string `json:"role_codes" binding:"omitempty,dive,required,max=32"` // 角色代码，为空表示不修改
+	DeptID    *uint64  `json:"dept_id"`                                             // 所属部门ID，为空表示不修改
+	Status    int      `json:"status" binding:"omitempty,min=0,max=2"`              // 状态：0-禁用，1-启用，2-锁定
+}
+
+// RevokeSessionRequest 注销会话请求参数
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id" binding:"required"` // 会话ID
 }
@@ -3,6 +3,9 @@ package user
 import (
 	"goadmin/internal/model/role"
 	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+	"goadmin/pkg/util"
+	"time"
 )
 
 // User 用户表
@@ -12,11 +15,21 @@ type User struct {
 	Password string     `gorm:"size:100;not null;default:''" json:"-"`
 	Email    string     `gorm:"size:100;unique;default:''" json:"email"`
 	Status   UserStatus `gorm:"type:int;default:1;comment:0:inactive,1:active,2:locked,3:deleted" json:"status"`
-	RoleCode string     `gorm:"size:32;not null;index:idx_user_role;default:''" json:"role_code"`
-	// 用户角色关联表
-	Role role.Role `gorm:"foreignKey:RoleCode;references:Code" json:"role"`
+	TenantID uint64     `gorm:"column:tenant_id;not null;default:0;index:idx_user_tenant;comment:所属租户ID" json:"tenant_id"`
+	DeptID   uint64     `gorm:"column:dept_id;not null;default:0;index:idx_user_dept;comment:所属部门ID" json:"dept_id"`
+	// 自动锁定的解锁时间，为空表示未锁定或需管理员解锁
+	LockedUntil *util.DateTime `gorm:"column:locked_until" json:"locked_until"`
+	// 密码最近修改时间，用于计算密码有效期
+	PasswordChangedAt *util.DateTime `gorm:"column:password_changed_at" json:"password_changed_at"`
+	// 下次登录须修改密码（管理员重置密码后）
+	MustChangePassword bool `gorm:"column:must_change_password;default:false" json:"must_change_password"`
+	// 用户拥有的角色，由仓储按 user_roles 关联表加载
+	Roles role.Set `gorm:"-" json:"roles"`
 
 	PermissionCodes []string `gorm:"-" json:"permission_codes"` // 权限
+
+	PasswordExpiresAt *util.DateTime `gorm:"-" json:"password_expires_at,omitempty"` // 密码过期时间，永不过期时为空
+	PasswordExpired   bool           `gorm:"-" json:"password_expired"`              // 密码已过期，须修改后继续使用
 }
 
 // TableName 指定表名
@@ -24,12 +37,55 @@ func (User) TableName() string {
 	return "users"
 }
 
+// AuditResource 变更时记录审计事件
+func (User) AuditResource() string {
+	return "user"
+}
+
+// ScopeColumns 数据范围按用户自身、所属租户及部门过滤
+func (User) ScopeColumns() db.Columns {
+	return db.Columns{Owner: "id", Tenant: "tenant_id", Dept: "dept_id"}
+}
+
+// TenantShared 用户按租户隔离，不共享
+func (User) TenantShared() bool {
+	return false
+}
+
+// DataScope 用户的数据范围，拥有多个角色时取并集，超级管理员不受限制
+func (u *User) DataScope() *db.Scope {
+	s := &db.Scope{UserID: u.ID, TenantID: u.TenantID, DeptID: u.DeptID}
+	if u.IsSuperAdmin() {
+		s.Rules = []db.Rule{{Kind: db.ScopeAll}}
+		return s
+	}
+	for _, r := range u.Roles {
+		s.Rules = append(s.Rules, r.DataScopeRule())
+	}
+	return s
+}
+
 func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
 }
 
+// IsLocked 账户是否处于锁定状态
+func (u *User) IsLocked() bool {
+	return u.Status == UserStatusLocked
+}
+
+// LockExpired 自动锁定是否已到期，管理员手动锁定的账户不会自动解锁
+func (u *User) LockExpired(now time.Time) bool {
+	return u.IsLocked() && u.LockedUntil != nil && !now.Before(time.Time(*u.LockedUntil))
+}
+
 func (u *User) IsSuperAdmin() bool {
-	return u.RoleCode == role.CodeSuperAdmin
+	return u.Roles.Has(role.CodeSuperAdmin)
+}
+
+// RoleCodes 用户拥有的角色代码
+func (u *User) RoleCodes() []string {
+	return u.Roles.Codes()
 }
 
 // GetID 实现 Session 接口
@@ -47,7 +103,7 @@ func (u *User) GetStatus() int {
 	return int(u.Status)
 }
 
-// GetRole 实现 Session 接口
-func (u *User) GetRole() *role.Role {
-	return &u.Role
+// GetRoles 实现 Session 接口
+func (u *User) GetRoles() role.Set {
+	return u.Roles
 }
@@ -0,0 +1,20 @@
+package user
+
+import "goadmin/internal/model/schema"
+
+// UserRole 用户角色关联表，一个用户可拥有多个角色
+type UserRole struct {
+	schema.BaseModel
+	UserID   uint64 `gorm:"not null;uniqueIndex:uk_user_role" json:"user_id"`
+	RoleCode string `gorm:"size:32;not null;default:'';uniqueIndex:uk_user_role;index:idx_user_role_code" json:"role_code"`
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// AuditResource 变更时记录审计事件
+func (UserRole) AuditResource() string {
+	return "user_role"
+}
@@ -0,0 +1,25 @@
+package api_key
+
+import (
+	"context"
+	"goadmin/internal/model/api_key"
+	"goadmin/pkg/db"
+	"time"
+)
+
+// Repository 定义 API 密钥仓储接口
+type Repository interface {
+	db.Repository[api_key.APIKey]
+
+	// GetByPrefix 根据密钥前缀获取密钥
+	GetByPrefix(ctx context.Context, prefix string) (*api_key.APIKey, error)
+
+	// ListByUserID 获取用户的全部密钥，按创建时间倒序
+	ListByUserID(ctx context.Context, userID uint64) ([]*api_key.APIKey, error)
+
+	// Revoke 吊销用户的密钥，返回值表示密钥是否存在且此前未吊销
+	Revoke(ctx context.Context, id, userID uint64) (bool, error)
+
+	// UpdateLastUsed 记录密钥最近使用时间及 IP
+	UpdateLastUsed(ctx context.Context, id uint64, ip string, at time.Time) error
+}
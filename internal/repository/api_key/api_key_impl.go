@@ -0,0 +1,74 @@
+package api_key
+
+import (
+	"context"
+	"errors"
+	"goadmin/internal/model/api_key"
+	"goadmin/pkg/db"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 确保 APIKeyRepositoryImpl 实现了 Repository 接口
+var _ Repository = (*APIKeyRepositoryImpl)(nil)
+
+// APIKeyRepositoryImpl 实现 Repository 接口
+type APIKeyRepositoryImpl struct {
+	*db.BaseRepository[api_key.APIKey]
+}
+
+// NewAPIKeyRepository 创建 API 密钥仓储实例（Wire 注入）
+func NewAPIKeyRepository(database *gorm.DB) Repository {
+	return &APIKeyRepositoryImpl{
+		db.NewBaseRepository[api_key.APIKey](database),
+	}
+}
+
+// Deprecated: 使用 NewAPIKeyRepository 替代
+// NewAPIKeyRepository_legacy 创建 API 密钥仓储实例（兼容旧代码，使用全局db）
+func NewAPIKeyRepository_legacy() Repository {
+	return NewAPIKeyRepository(db.GetDB())
+}
+
+// GetByPrefix 根据密钥前缀获取密钥
+func (r *APIKeyRepositoryImpl) GetByPrefix(ctx context.Context, prefix string) (*api_key.APIKey, error) {
+	var k api_key.APIKey
+	err := r.DB().WithContext(ctx).Where("prefix = ?", prefix).First(&k).Error
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+// ListByUserID 获取用户的全部密钥，按创建时间倒序
+func (r *APIKeyRepositoryImpl) ListByUserID(ctx context.Context, userID uint64) ([]*api_key.APIKey, error) {
+	var list []*api_key.APIKey
+	err := r.DB().WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+// Revoke 吊销用户的密钥，返回值表示密钥是否存在且此前未吊销
+func (r *APIKeyRepositoryImpl) Revoke(ctx context.Context, id, userID uint64) (bool, error) {
+	now := time.Now()
+	result := r.DB().WithContext(ctx).Model(&api_key.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{
+			"revoked_at": now,
+			"mtime":      now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateLastUsed 记录密钥最近使用时间及 IP，不产生审计事件
+func (r *APIKeyRepositoryImpl) UpdateLastUsed(ctx context.Context, id uint64, ip string, at time.Time) error {
+	return r.DB().WithContext(db.SkipAudit(ctx)).Model(&api_key.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,25 @@
+package export
+
+import (
+	"context"
+	"goadmin/internal/model/export"
+	"goadmin/pkg/db"
+	"time"
+)
+
+// Repository 定义导出任务仓储接口
+type Repository interface {
+	db.Repository[export.Job]
+
+	// ListByUserID 获取用户最近的导出任务，按创建时间倒序
+	ListByUserID(ctx context.Context, userID uint64, limit int) ([]*export.Job, error)
+
+	// UpdateFields 更新导出任务的指定字段
+	UpdateFields(ctx context.Context, id uint64, fields map[string]any) error
+
+	// ListUnfinished 获取实例 instance 未结束的任务，用于进程重启后清理中断的任务
+	ListUnfinished(ctx context.Context, instance string) ([]*export.Job, error)
+
+	// ListExpired 获取在 before 之前完成且文件尚未删除的任务
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*export.Job, error)
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"goadmin/internal/model/export"
+	"goadmin/pkg/db"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 确保 ExportRepositoryImpl 实现了 Repository 接口
+var _ Repository = (*ExportRepositoryImpl)(nil)
+
+// ExportRepositoryImpl 实现 Repository 接口
+type ExportRepositoryImpl struct {
+	*db.BaseRepository[export.Job]
+}
+
+// NewExportRepository 创建导出任务仓储实例（Wire 注入）
+func NewExportRepository(database *gorm.DB) Repository {
+	return &ExportRepositoryImpl{
+		db.NewBaseRepository[export.Job](database),
+	}
+}
+
+// Deprecated: 使用 NewExportRepository 替代
+// NewExportRepository_legacy 创建导出任务仓储实例（兼容旧代码，使用全局db）
+func NewExportRepository_legacy() Repository {
+	return NewExportRepository(db.GetDB())
+}
+
+// GetByID 根据ID获取导出任务，不存在时返回 nil
+func (r *ExportRepositoryImpl) GetByID(ctx context.Context, id uint64) (*export.Job, error) {
+	var j export.Job
+	err := r.DB().WithContext(ctx).Where("id = ?", id).First(&j).Error
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &j, nil
+}
+
+// ListByUserID 获取用户最近的导出任务，按创建时间倒序
+func (r *ExportRepositoryImpl) ListByUserID(ctx context.Context, userID uint64, limit int) ([]*export.Job, error) {
+	var list []*export.Job
+	err := r.DB().WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// UpdateFields 更新导出任务的指定字段
+func (r *ExportRepositoryImpl) UpdateFields(ctx context.Context, id uint64, fields map[string]any) error {
+	fields["mtime"] = time.Now()
+	return r.DB().WithContext(ctx).Model(&export.Job{}).
+		Where("id = ?", id).
+		UpdateColumns(fields).Error
+}
+
+// ListUnfinished 获取实例 instance 未结束的任务，用于进程重启后清理中断的任务
+func (r *ExportRepositoryImpl) ListUnfinished(ctx context.Context, instance string) ([]*export.Job, error) {
+	var list []*export.Job
+	err := r.DB().WithContext(ctx).
+		Where("instance = ? AND status IN ?", instance, []export.Status{export.StatusPending, export.StatusRunning}).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+// ListExpired 获取在 before 之前完成且文件尚未删除的任务
+func (r *ExportRepositoryImpl) ListExpired(ctx context.Context, before time.Time, limit int) ([]*export.Job, error) {
+	var list []*export.Job
+	err := r.DB().WithContext(ctx).
+		Where("status = ? AND finished_at < ?", export.StatusSucceeded, before).
+		Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
@@ -2,17 +2,56 @@ package operate_log
 
 import (
 	"context"
+	"errors"
 	"goadmin/internal/model/operate_log"
-	"goadmin/pkg/db"
+	"time"
 )
 
+// ErrChainTail 删除范围包含哈希链末尾的日志
+var ErrChainTail = errors.New("cannot delete the tail of the operate log chain")
+
+// ErrChainGap 删除范围与锚点之间还有日志，删除后链中间出现缺口
+var ErrChainGap = errors.New("cannot delete logs in the middle of the operate log chain")
+
 // OperateLogRepository 定义操作日志仓储接口
+//
+// 操作日志只追加不修改：不提供更新方法，每条日志写入时接在哈希链末尾；
+// 只能按保留策略删除链首已归档的一段，剩余的链仍可从首条日志开始校验
 type OperateLogRepository interface {
-	db.Repository[operate_log.OperateLog]
-
 	// PageList 获取操作日志列表（支持多条件查询）
 	PageList(ctx context.Context, req *operate_log.ListRequest) ([]*operate_log.OperateLog, int64, error)
 
-	// CreateLog 创建操作日志
-	CreateLog(ctx context.Context, log *operate_log.OperateLog) error
+	// BatchCreate 按顺序将日志追加到哈希链末尾，同一批日志在同一事务中写入
+	BatchCreate(ctx context.Context, logs []*operate_log.OperateLog) error
+
+	// Walk 按ID顺序分批遍历ID大于 afterID 的全部日志，不受租户及数据范围限制
+	Walk(ctx context.Context, afterID uint64, batch int, fn func([]*operate_log.OperateLog) error) error
+
+	// GetChainHead 获取哈希链链头
+	GetChainHead(ctx context.Context) (*operate_log.ChainHead, error)
+
+	// CreateCheckpoint 创建检查点
+	CreateCheckpoint(ctx context.Context, cp *operate_log.Checkpoint) error
+
+	// ListCheckpoints 按ID顺序获取全部检查点
+	ListCheckpoints(ctx context.Context) ([]*operate_log.Checkpoint, error)
+
+	// GetLastCheckpoint 获取最近的检查点，没有时返回 nil
+	GetLastCheckpoint(ctx context.Context) (*operate_log.Checkpoint, error)
+
+	// LastIDBefore 获取创建时间早于 t 的最后一条日志ID，没有时返回 0
+	LastIDBefore(ctx context.Context, t time.Time) (uint64, error)
+
+	// LastIDBeyond 获取最新 n 条之前的最后一条日志ID，即保留最新 n 条时可删除的最大ID，没有时返回 0
+	LastIDBeyond(ctx context.Context, n int64) (uint64, error)
+
+	// DeleteRange 删除ID在 (afterID, throughID] 内的已归档日志并前移锚点，范围包含链头指向的日志时返回 ErrChainTail，
+	// 范围之前还有锚点之后的日志时返回 ErrChainGap
+	DeleteRange(ctx context.Context, afterID, throughID uint64) (int64, error)
+
+	// Restore 按原ID及哈希导入归档的日志，不改变链头，已存在的跳过，返回导入的条数
+	Restore(ctx context.Context, logs []*operate_log.OperateLog) (int64, error)
+
+	// EnsurePartitions 表按月分区时（PostgreSQL）创建 from 所在月起 months 个月的分区，未分区时不处理
+	EnsurePartitions(ctx context.Context, from time.Time, months int) error
 }
@@ -2,24 +2,33 @@ package operate_log
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"goadmin/internal/model/operate_log"
 	"goadmin/pkg/db"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // 确保OperateLogRepositoryImpl实现了OperateLogRepository接口
 var _ OperateLogRepository = (*OperateLogRepositoryImpl)(nil)
 
 // OperateLogRepositoryImpl 实现OperateLogRepository接口
+//
+// 通用仓储不以嵌入方式提供，以免暴露更新、删除方法
 type OperateLogRepositoryImpl struct {
-	*db.BaseRepository[operate_log.OperateLog]
+	base *db.BaseRepository[operate_log.OperateLog]
+	db   *gorm.DB
 }
 
 // NewOperateLogRepositoryImpl 创建操作日志仓储实例（Wire 注入）
 func NewOperateLogRepositoryImpl(database *gorm.DB) *OperateLogRepositoryImpl {
 	return &OperateLogRepositoryImpl{
-		db.NewBaseRepository[operate_log.OperateLog](database),
+		base: db.NewBaseRepository[operate_log.OperateLog](database),
+		db:   database,
 	}
 }
 
@@ -40,6 +49,11 @@ func (r *OperateLogRepositoryImpl) PageList(ctx context.Context, req *operate_lo
 		db.Order[operate_log.OperateLog](req.OrderBy),
 	}
 
+	// 如果有操作用户ID，添加查询条件
+	if req.UserID > 0 {
+		opts = append(opts, db.Where[operate_log.OperateLog]("user_id = ?", req.UserID))
+	}
+
 	// 如果有用户名，添加查询条件
 	if req.Username != "" {
 		opts = append(opts, db.Where[operate_log.OperateLog]("username LIKE ?", "%"+req.Username+"%"))
@@ -50,11 +64,46 @@ func (r *OperateLogRepositoryImpl) PageList(ctx context.Context, req *operate_lo
 		opts = append(opts, db.Where[operate_log.OperateLog]("content LIKE ?", "%"+req.Content+"%"))
 	}
 
+	// 如果有操作编码，添加查询条件
+	if req.Action != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("action = ?", req.Action))
+	}
+
+	// 如果有资源类型，添加查询条件
+	if req.Resource != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("resource = ?", req.Resource))
+	}
+
+	// 如果有资源ID，添加查询条件
+	if req.ResourceID != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("resource_id = ?", req.ResourceID))
+	}
+
+	// 如果有操作结果，添加查询条件
+	if req.Result > 0 {
+		opts = append(opts, db.Where[operate_log.OperateLog]("result = ?", req.Result))
+	}
+
+	// 如果有跟踪ID，添加查询条件
+	if req.TraceID != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("trace_id = ?", req.TraceID))
+	}
+
+	// 如果有客户端UA，添加查询条件
+	if req.UserAgent != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("user_agent LIKE ?", "%"+req.UserAgent+"%"))
+	}
+
 	// 如果有IP地址，添加查询条件
 	if req.IP != "" {
 		opts = append(opts, db.Where[operate_log.OperateLog]("ip LIKE ?", "%"+req.IP+"%"))
 	}
 
+	// 如果有API密钥前缀，添加查询条件
+	if req.APIKey != "" {
+		opts = append(opts, db.Where[operate_log.OperateLog]("api_key = ?", req.APIKey))
+	}
+
 	// 如果有开始时间，添加查询条件
 	if req.StartTime != "" {
 		opts = append(opts, db.Where[operate_log.OperateLog]("ctime >= ?", req.StartTime))
@@ -65,10 +114,236 @@ func (r *OperateLogRepositoryImpl) PageList(ctx context.Context, req *operate_lo
 		opts = append(opts, db.Where[operate_log.OperateLog]("ctime <= ?", req.EndTime))
 	}
 
-	return r.List(ctx, req.Page, req.PageSize, opts...)
+	return r.base.List(ctx, req.Page, req.PageSize, opts...)
+}
+
+// BatchCreate 按顺序将日志追加到哈希链末尾
+//
+// 锁定链头行使各进程的写入串行进行，日志的租户在计算哈希前确定，与租户隔离插件写入的一致
+func (r *OperateLogRepositoryImpl) BatchCreate(ctx context.Context, logs []*operate_log.OperateLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	tenantID, hasTenant := db.TenantFrom(ctx)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var head operate_log.ChainHead
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("id = ?", operate_log.ChainHeadID).First(&head).Error
+		if err != nil {
+			return err
+		}
+		prev := head.Hash
+		for _, log := range logs {
+			if hasTenant {
+				log.TenantID = tenantID
+			}
+			log.Chain(prev)
+			if err = tx.Create(log).Error; err != nil {
+				return err
+			}
+			prev = log.Hash
+		}
+		last := logs[len(logs)-1]
+		return tx.Model(&head).Updates(map[string]any{"last_id": last.ID, "hash": last.Hash, "mtime": last.CTime}).Error
+	})
+}
+
+// Walk 按ID顺序分批遍历ID大于 afterID 的全部日志
+func (r *OperateLogRepositoryImpl) Walk(
+	ctx context.Context, afterID uint64, batch int, fn func([]*operate_log.OperateLog) error) error {
+	for {
+		var logs []*operate_log.OperateLog
+		err := r.unscoped(ctx).Where("id > ?", afterID).Order("id").Limit(batch).Find(&logs).Error
+		if err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err = fn(logs); err != nil {
+			return err
+		}
+		if len(logs) < batch {
+			return nil
+		}
+		afterID = logs[len(logs)-1].ID
+	}
+}
+
+// GetChainHead 获取哈希链链头
+func (r *OperateLogRepositoryImpl) GetChainHead(ctx context.Context) (*operate_log.ChainHead, error) {
+	var head operate_log.ChainHead
+	err := r.db.WithContext(ctx).Where("id = ?", operate_log.ChainHeadID).First(&head).Error
+	if err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// CreateCheckpoint 创建检查点
+func (r *OperateLogRepositoryImpl) CreateCheckpoint(ctx context.Context, cp *operate_log.Checkpoint) error {
+	return r.db.WithContext(ctx).Create(cp).Error
+}
+
+// ListCheckpoints 按ID顺序获取全部检查点
+func (r *OperateLogRepositoryImpl) ListCheckpoints(ctx context.Context) ([]*operate_log.Checkpoint, error) {
+	var list []*operate_log.Checkpoint
+	err := r.db.WithContext(ctx).Order("id").Find(&list).Error
+	return list, err
+}
+
+// GetLastCheckpoint 获取最近的检查点，没有时返回 nil
+func (r *OperateLogRepositoryImpl) GetLastCheckpoint(ctx context.Context) (*operate_log.Checkpoint, error) {
+	var cp operate_log.Checkpoint
+	err := r.db.WithContext(ctx).Order("id DESC").First(&cp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// unscoped 不受租户及数据范围限制，保留策略及归档处理全部租户的日志
+func (r *OperateLogRepositoryImpl) unscoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(db.SkipScope(db.SkipTenant(ctx)))
+}
+
+// LastIDBefore 获取创建时间早于 t 的最后一条日志ID，没有时返回 0
+func (r *OperateLogRepositoryImpl) LastIDBefore(ctx context.Context, t time.Time) (uint64, error) {
+	var id sql.NullInt64
+	err := r.unscoped(ctx).Model(&operate_log.OperateLog{}).
+		Select("MAX(id)").Where("ctime < ?", t).Row().Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id.Int64), nil
+}
+
+// LastIDBeyond 获取最新 n 条之前的最后一条日志ID，没有时返回 0
+func (r *OperateLogRepositoryImpl) LastIDBeyond(ctx context.Context, n int64) (uint64, error) {
+	var ids []uint64
+	err := r.unscoped(ctx).Model(&operate_log.OperateLog{}).
+		Order("id DESC").Offset(int(n)).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// DeleteRange 删除ID在 (afterID, throughID] 内的已归档日志
+//
+// 锁定链头后检查范围，链头指向的日志须保留，否则无法发现链尾被截断；只能删除紧接锚点的一段，
+// 删除后锚点前移到最后一条被删除的日志，早于锚点的检查点随日志一并删除
+func (r *OperateLogRepositoryImpl) DeleteRange(ctx context.Context, afterID, throughID uint64) (int64, error) {
+	if throughID <= afterID {
+		return 0, nil
+	}
+	var deleted int64
+	err := r.unscoped(ctx).Transaction(func(tx *gorm.DB) error {
+		var head operate_log.ChainHead
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("id = ?", operate_log.ChainHeadID).First(&head).Error
+		if err != nil {
+			return err
+		}
+		if head.LastID > 0 && throughID >= head.LastID {
+			return ErrChainTail
+		}
+		var last operate_log.OperateLog
+		err = tx.Select("id", "hash").Where("id > ? AND id <= ?", max(afterID, head.AnchorID), throughID).
+			Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+		if last.ID > 0 {
+			if afterID > head.AnchorID {
+				var gap int64
+				err = tx.Model(&operate_log.OperateLog{}).
+					Where("id > ? AND id <= ?", head.AnchorID, afterID).Count(&gap).Error
+				if err != nil {
+					return err
+				}
+				if gap > 0 {
+					return ErrChainGap
+				}
+			}
+			err = tx.Model(&head).Updates(map[string]any{"anchor_id": last.ID, "anchor_hash": last.Hash}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("last_id < ?", last.ID).Delete(&operate_log.Checkpoint{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		rs := tx.Where("id > ? AND id <= ?", afterID, throughID).Delete(&operate_log.OperateLog{})
+		deleted = rs.RowsAffected
+		return rs.Error
+	})
+	return deleted, err
+}
+
+// Restore 按原ID及哈希导入归档的日志，已存在的跳过
+//
+// 带 RETURNING 的插入语句中影响行数不可靠，先排除已存在的ID；并发导入时由冲突子句兜底
+func (r *OperateLogRepositoryImpl) Restore(ctx context.Context, logs []*operate_log.OperateLog) (int64, error) {
+	if len(logs) == 0 {
+		return 0, nil
+	}
+	ids := make([]uint64, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.ID)
+	}
+	var existing []uint64
+	err := r.unscoped(ctx).Model(&operate_log.OperateLog{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	if err != nil {
+		return 0, err
+	}
+	skip := make(map[uint64]bool, len(existing))
+	for _, id := range existing {
+		skip[id] = true
+	}
+	missing := make([]*operate_log.OperateLog, 0, len(logs))
+	for _, log := range logs {
+		if !skip[log.ID] {
+			missing = append(missing, log)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	err = r.unscoped(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(missing).Error
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(missing)), nil
 }
 
-// CreateLog 创建操作日志
-func (r *OperateLogRepositoryImpl) CreateLog(ctx context.Context, log *operate_log.OperateLog) error {
-	return r.Create(ctx, log)
+// EnsurePartitions 表按月分区时创建 from 所在月起 months 个月的分区
+//
+// 分区表由迁移 00019_operate_log_partition 按需创建，分区命名为 operate_log_pYYYYMM
+func (r *OperateLogRepositoryImpl) EnsurePartitions(ctx context.Context, from time.Time, months int) error {
+	if r.db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	var partitioned int64
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT COUNT(*) FROM pg_class WHERE relname = ? AND relkind = 'p' AND pg_table_is_visible(oid)",
+		operate_log.OperateLog{}.TableName()).Scan(&partitioned).Error
+	if err != nil || partitioned == 0 {
+		return err
+	}
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	for i := 0; i < months; i++ {
+		lo := start.AddDate(0, i, 0)
+		hi := lo.AddDate(0, 1, 0)
+		stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS operate_log_p%s PARTITION OF operate_log FOR VALUES FROM ('%s') TO ('%s')",
+			lo.Format("200601"), lo.Format(time.DateOnly), hi.Format(time.DateOnly))
+		if err = r.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,199 @@
+package operate_log
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"goadmin/internal/model/operate_log"
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+	"goadmin/pkg/db/dbtest"
+	"goadmin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// newLogDB 带租户隔离插件的内存数据库，含链头行
+func newLogDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb := dbtest.Open(t, &operate_log.OperateLog{}, &operate_log.ChainHead{}, &operate_log.Checkpoint{})
+	if err := gdb.Use(db.TenantPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Exec("INSERT INTO operate_log_chain (id, last_id, hash) VALUES (1, 0, '')").Error; err != nil {
+		t.Fatal(err)
+	}
+	return gdb
+}
+
+func TestBatchCreateChain(t *testing.T) {
+	repo := NewOperateLogRepositoryImpl(newLogDB(t))
+	ctx := context.Background()
+
+	if err := repo.BatchCreate(db.WithTenant(ctx, 2), []*operate_log.OperateLog{{Content: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.BatchCreate(ctx, []*operate_log.OperateLog{{Content: "b"}, {Content: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs []*operate_log.OperateLog
+	err := repo.Walk(ctx, 0, 2, func(batch []*operate_log.OperateLog) error {
+		logs = append(logs, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("logs = %d, want 3", len(logs))
+	}
+	if logs[0].TenantID != 2 {
+		t.Errorf("tenant = %d, want 2", logs[0].TenantID)
+	}
+	// 读回的日志重新计算哈希须与写入时一致
+	prev := ""
+	for _, l := range logs {
+		if l.PrevHash != prev || l.ComputeHash() != l.Hash {
+			t.Errorf("log %d: prev %q hash %q, recomputed %q", l.ID, l.PrevHash, l.Hash, l.ComputeHash())
+		}
+		prev = l.Hash
+	}
+
+	head, err := repo.GetChainHead(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.LastID != logs[2].ID || head.Hash != logs[2].Hash {
+		t.Errorf("head = %+v, want last %d", head, logs[2].ID)
+	}
+
+	if cp, err := repo.GetLastCheckpoint(ctx); err != nil || cp != nil {
+		t.Errorf("last checkpoint = %+v, %v", cp, err)
+	}
+}
+
+func TestPageListScope(t *testing.T) {
+	repo := NewOperateLogRepositoryImpl(newLogDB(t))
+	ctx := context.Background()
+	for _, l := range []*operate_log.OperateLog{
+		{TenantID: 1, UserID: 1, Content: "a"},
+		{TenantID: 1, UserID: 2, Content: "b"},
+		{TenantID: 2, UserID: 3, Content: "c"},
+	} {
+		if err := repo.BatchCreate(db.WithTenant(ctx, l.TenantID), []*operate_log.OperateLog{l}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page := schema.PageRequest{Page: 1, PageSize: 10, OrderBy: "id"}
+	cases := []struct {
+		name string
+		kind db.ScopeKind
+		want []string
+	}{
+		{"self", db.ScopeSelf, []string{"a"}},
+		{"tenant", db.ScopeTenant, []string{"a", "b"}},
+		{"all", db.ScopeAll, []string{"a", "b", "c"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scoped := db.WithScope(ctx, &db.Scope{UserID: 1, TenantID: 1, Rules: []db.Rule{{Kind: c.kind}}})
+			list, total, err := repo.PageList(scoped, &operate_log.ListRequest{PageRequest: page})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(list))
+			for _, l := range list {
+				got = append(got, l.Content)
+			}
+			if total != int64(len(c.want)) || !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("PageList = %v (total %d), want %v", got, total, c.want)
+			}
+		})
+	}
+
+	// 哈希链校验须读取全部日志，不受数据范围限制
+	scoped := db.WithScope(ctx, &db.Scope{UserID: 1, TenantID: 1, Rules: []db.Rule{{Kind: db.ScopeSelf}}})
+	n := 0
+	err := repo.Walk(scoped, 0, 10, func(batch []*operate_log.OperateLog) error {
+		n += len(batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("Walk read %d logs, want 3", n)
+	}
+}
+
+func TestRetentionRange(t *testing.T) {
+	repo := NewOperateLogRepositoryImpl(newLogDB(t))
+	ctx := context.Background()
+
+	old := util.DateTime(time.Now().AddDate(0, 0, -10))
+	logs := []*operate_log.OperateLog{
+		{BaseModel: schema.BaseModel{CTime: old}, Content: "a"},
+		{BaseModel: schema.BaseModel{CTime: old}, Content: "b"},
+		{Content: "c"},
+		{Content: "d"},
+	}
+	if err := repo.BatchCreate(ctx, logs); err != nil {
+		t.Fatal(err)
+	}
+
+	if id, err := repo.LastIDBefore(ctx, time.Now().AddDate(0, 0, -1)); err != nil || id != logs[1].ID {
+		t.Errorf("last id before = %d, %v, want %d", id, err, logs[1].ID)
+	}
+	if id, err := repo.LastIDBeyond(ctx, 1); err != nil || id != logs[2].ID {
+		t.Errorf("last id beyond = %d, %v, want %d", id, err, logs[2].ID)
+	}
+	if id, err := repo.LastIDBeyond(ctx, 10); err != nil || id != 0 {
+		t.Errorf("last id beyond all = %d, %v", id, err)
+	}
+
+	// 链头指向的日志不可删除
+	if _, err := repo.DeleteRange(ctx, 0, logs[3].ID); !errors.Is(err, ErrChainTail) {
+		t.Errorf("delete tail err = %v, want ErrChainTail", err)
+	}
+	// 锚点与删除范围之间还有日志
+	if _, err := repo.DeleteRange(ctx, logs[0].ID, logs[1].ID); !errors.Is(err, ErrChainGap) {
+		t.Errorf("delete gap err = %v, want ErrChainGap", err)
+	}
+	deleted, err := repo.DeleteRange(ctx, 0, logs[1].ID)
+	if err != nil || deleted != 2 {
+		t.Fatalf("deleted = %d, %v", deleted, err)
+	}
+	if head, _ := repo.GetChainHead(ctx); head.AnchorID != logs[1].ID || head.AnchorHash != logs[1].Hash {
+		t.Errorf("anchor = %d %s, want %d %s", head.AnchorID, head.AnchorHash, logs[1].ID, logs[1].Hash)
+	}
+
+	// 按原ID及哈希导入，已存在的跳过
+	restored, err := repo.Restore(ctx, []*operate_log.OperateLog{logs[0], logs[1], logs[2]})
+	if err != nil || restored != 2 {
+		t.Fatalf("restored = %d, %v", restored, err)
+	}
+	var got []*operate_log.OperateLog
+	err = repo.Walk(ctx, 0, 10, func(batch []*operate_log.OperateLog) error {
+		got = append(got, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[0].ID != logs[0].ID || got[1].Hash != logs[1].Hash || got[2].PrevHash != logs[1].Hash {
+		t.Errorf("after restore = %+v", got)
+	}
+	if head, _ := repo.GetChainHead(ctx); head.LastID != logs[3].ID {
+		t.Errorf("head = %+v, want last %d", head, logs[3].ID)
+	}
+
+	// 未分区的表不处理
+	if err = repo.EnsurePartitions(ctx, time.Now(), 3); err != nil {
+		t.Error(err)
+	}
+}
@@ -38,7 +38,7 @@ func NewPositionRepository_legacy() PositionRepository {
 // GetByID 根据ID获取位置
 func (r *PositionRepositoryImpl) GetByID(ctx context.Context, id uint64) (*position.Position, error) {
 	var p position.Position
-	err := r.DB().WithContext(ctx).Where("id = ?", id).First(&p).Error
+	err := r.Scoped(ctx).Where("id = ?", id).First(&p).Error
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, nil
@@ -51,7 +51,7 @@ func (r *PositionRepositoryImpl) GetByID(ctx context.Context, id uint64) (*posit
 // GetByCity 根据城市获取位置列表
 func (r *PositionRepositoryImpl) GetByCity(ctx context.Context, city string) ([]*position.Position, error) {
 	var positions []*position.Position
-	err := r.DB().WithContext(ctx).Where("city = ?", city).Find(&positions).Error
+	err := r.Scoped(ctx).Where("city = ?", city).Find(&positions).Error
 	return positions, err
 }
 
@@ -0,0 +1,77 @@
+package position
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"goadmin/internal/model/position"
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+	"goadmin/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+// newPositionDB 位置 1、2 属于租户 1，分别由用户 1、2 创建，位置 3 属于租户 2
+func newPositionDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb := dbtest.Open(t, &position.Position{})
+	positions := []*position.Position{
+		{BaseModel: schema.BaseModel{ID: 1}, TenantID: 1, City: "北京", Location: "a", CreatorID: 1},
+		{BaseModel: schema.BaseModel{ID: 2}, TenantID: 1, City: "北京", Location: "b", CreatorID: 2},
+		{BaseModel: schema.BaseModel{ID: 3}, TenantID: 2, City: "北京", Location: "c", CreatorID: 3},
+	}
+	if err := gdb.Create(&positions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return gdb
+}
+
+func positionIDs(list []*position.Position) []uint64 {
+	ids := make([]uint64, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestPositionRepositoryScope(t *testing.T) {
+	repo := NewPositionRepositoryImpl(newPositionDB(t))
+	page := schema.PageRequest{Page: 1, PageSize: 10, OrderBy: "id"}
+	cases := []struct {
+		name  string
+		rules []db.Rule
+		want  []uint64
+	}{
+		{"self", []db.Rule{{Kind: db.ScopeSelf}}, []uint64{1}},
+		{"tenant", []db.Rule{{Kind: db.ScopeTenant}}, []uint64{1, 2}},
+		{"all", []db.Rule{{Kind: db.ScopeAll}}, []uint64{1, 2, 3}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := db.WithScope(context.Background(), &db.Scope{UserID: 1, TenantID: 1, Rules: c.rules})
+			list, total, err := repo.PageList(ctx, &position.ListRequest{PageRequest: page})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := positionIDs(list); total != int64(len(c.want)) || !slices.Equal(got, c.want) {
+				t.Fatalf("PageList = %v (total %d), want %v", got, total, c.want)
+			}
+			byCity, err := repo.GetByCity(ctx, "北京")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := positionIDs(byCity); !slices.Equal(got, c.want) {
+				t.Fatalf("GetByCity = %v, want %v", got, c.want)
+			}
+			p, err := repo.GetByID(ctx, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (p != nil) != (c.name == "all") {
+				t.Fatalf("GetByID(3) visible = %v", p != nil)
+			}
+		})
+	}
+}
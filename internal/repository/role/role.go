@@ -22,6 +22,14 @@ type RoleRepository interface {
 	// GetByCodes 根据角色代码列表获取角色
 	GetByCodes(ctx context.Context, codes []string) ([]*role.Role, error)
 
+	// GetChain 获取角色及其祖先的代码，由近及远
+	//
+	// 遇到不存在或停用的祖先、出现环或超过层级上限时停止向上查找
+	GetChain(ctx context.Context, code string) ([]string, error)
+
+	// ListChildren 获取直接子角色
+	ListChildren(ctx context.Context, parentCode string) ([]*role.Role, error)
+
 	// GetWithPermissions 根据ID获取角色及其权限
 	GetWithPermissions(ctx context.Context, id uint64) (*role.Role, error)
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"goadmin/internal/model/role"
 	"goadmin/pkg/db"
+	"slices"
 
 	"gorm.io/gorm"
 )
@@ -45,7 +46,8 @@ func (r *RoleRepositoryImpl) GetByCode(ctx context.Context, code string) (*role.
 // GetByName 根据角色名称获取角色
 func (r *RoleRepositoryImpl) GetByName(ctx context.Context, name string) (*role.Role, error) {
 	var result role.Role
-	err := r.DB().WithContext(ctx).Where("name = ?", name).First(&result).Error
+	// 角色名称全局唯一，不受租户隔离限制
+	err := r.DB().WithContext(db.SkipTenant(ctx)).Where("name = ?", name).First(&result).Error
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, nil
@@ -72,6 +74,35 @@ func (r *RoleRepositoryImpl) GetByCodes(ctx context.Context, codes []string) ([]
 	return roles, err
 }
 
+// GetChain 获取角色及其祖先的代码，由近及远
+//
+// 遇到不存在或停用的祖先、出现环或超过层级上限时停止向上查找
+func (r *RoleRepositoryImpl) GetChain(ctx context.Context, code string) ([]string, error) {
+	chain := []string{code}
+	current, err := r.GetByCode(ctx, code)
+	for err == nil && current != nil && current.ParentCode != "" && len(chain) < role.MaxDepth {
+		if slices.Contains(chain, current.ParentCode) {
+			break
+		}
+		current, err = r.GetByCode(ctx, current.ParentCode)
+		if err != nil || current == nil || !current.IsActive() {
+			break
+		}
+		chain = append(chain, current.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// ListChildren 获取直接子角色
+func (r *RoleRepositoryImpl) ListChildren(ctx context.Context, parentCode string) ([]*role.Role, error) {
+	var roles []*role.Role
+	err := r.DB().WithContext(ctx).Where("parent_code = ?", parentCode).Find(&roles).Error
+	return roles, err
+}
+
 // GetWithPermissions 根据ID获取角色及其权限
 func (r *RoleRepositoryImpl) GetWithPermissions(ctx context.Context, id uint64) (*role.Role, error) {
 	var result role.Role
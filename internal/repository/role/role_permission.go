@@ -36,6 +36,14 @@ type RolePermissionRepository interface {
 	// Param: containPublic 是否包含公共权限 默认包含
 	GetAllPermissions(ctx context.Context, containPublic ...bool) ([]permission.Permission, error)
 
-	// HasAccessURL 检查角色是否有访问指定URL的权限
-	HasAccessURL(ctx context.Context, roleCode string, accessURL string) (bool, error)
+	// SavePermissions 保存权限，ID为空时新增，否则按代码更新路由声明的字段
+	SavePermissions(ctx context.Context, permissions []permission.Permission) error
+
+	// GetPermissionCodesByRoleCodes 获取多个角色的权限代码并集
+	//
+	// Param: containPublic 是否包含公共权限 默认包含
+	GetPermissionCodesByRoleCodes(ctx context.Context, roleCodes []string, containPublic ...bool) ([]string, error)
+
+	// HasAnyPermission 检查任一角色是否拥有任一权限
+	HasAnyPermission(ctx context.Context, roleCodes []string, permissionCodes []string) (bool, error)
 }
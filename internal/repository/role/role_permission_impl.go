@@ -5,6 +5,7 @@ import (
 	"goadmin/internal/model/permission"
 	"goadmin/internal/model/role"
 	"goadmin/pkg/db"
+	"goadmin/pkg/util"
 
 	"gorm.io/gorm"
 )
@@ -130,29 +131,63 @@ func (r *RolePermissionRepositoryImpl) GetAllPermissions(
 	return permissions, err
 }
 
-// HasAccessURL 检查角色是否有访问指定URL的权限
-func (r *RolePermissionRepositoryImpl) HasAccessURL(
-	ctx context.Context, roleCode, accessURL string) (bool, error) {
-	db := r.DB().WithContext(ctx)
-	permTable := (permission.Permission{}).TableName()
-	rolePermTable := (role.RolePermission{}).TableName()
-
-	var cnt int64
-	// 检查角色权限
-	err := db.Table(rolePermTable+" AS rp").
-		Joins("JOIN "+permTable+" AS p ON rp.permission_code = p.code").
-		Where("rp.role_code = ? AND p.path = ?", roleCode, accessURL).
-		Count(&cnt).Error
-	if err != nil {
-		return false, err
-	}
-	if cnt > 0 {
-		return true, nil
+// GetPermissionCodesByRoleCodes 获取多个角色的权限代码并集
+func (r *RolePermissionRepositoryImpl) GetPermissionCodesByRoleCodes(
+	ctx context.Context, roleCodes []string, containPublic ...bool) ([]string, error) {
+	var (
+		permissions []string
+		db          *gorm.DB
+	)
+	db = r.DB().WithContext(ctx)
+	if len(containPublic) == 0 || containPublic[0] {
+		db = db.Raw("? UNION ?",
+			r.DB().Model(&role.RolePermission{}).Select("permission_code").Where("role_code IN ?", roleCodes),
+			r.DB().Model(&permission.Permission{}).Select("code").Where("global_flag = ?", permission.GlobalFlagYes),
+		)
+	} else {
+		db = db.Model(&role.RolePermission{}).Distinct("permission_code").Where("role_code IN ?", roleCodes)
 	}
+	err := db.Pluck("permission_code", &permissions).Error
+	return permissions, err
+}
 
-	// 检查全局权限
-	err = db.Table(permTable).
-		Where("path = ? AND global_flag = ?", accessURL, permission.GlobalFlagYes).
+// HasAnyPermission 检查任一角色是否拥有任一权限
+func (r *RolePermissionRepositoryImpl) HasAnyPermission(
+	ctx context.Context, roleCodes []string, permissionCodes []string) (bool, error) {
+	if len(roleCodes) == 0 || len(permissionCodes) == 0 {
+		return false, nil
+	}
+	var cnt int64
+	err := r.DB().WithContext(ctx).Model(&role.RolePermission{}).
+		Where("role_code IN ? AND permission_code IN ?", roleCodes, permissionCodes).
 		Count(&cnt).Error
 	return cnt > 0, err
 }
+
+// SavePermissions 保存权限，ID为空时新增，否则按代码更新路由声明的字段
+func (r *RolePermissionRepositoryImpl) SavePermissions(
+	ctx context.Context, permissions []permission.Permission) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range permissions {
+			p := &permissions[i]
+			if p.ID == 0 {
+				if err := tx.Create(p).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			err := tx.Model(&permission.Permission{}).Where("code = ?", p.Code).Updates(map[string]any{
+				"name":        p.Name,
+				"method":      p.Method,
+				"path":        p.Path,
+				"module":      p.Module,
+				"global_flag": p.GlobalFlag,
+				"mtime":       util.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -29,10 +29,10 @@ func NewServerSettingRepository_legacy() ServerSettingRepository {
 	return NewServerSettingRepository(db.GetDB())
 }
 
-// GetByName 根据名称获取服务端配置
+// GetByName 根据名称获取服务端配置，租户自有的配置优先于全局配置
 func (r *ServerSettingRepositoryImpl) GetByName(ctx context.Context, name string) (*server.ServerSetting, error) {
 	var setting server.ServerSetting
-	err := r.DB().WithContext(ctx).Where("name = ?", name).First(&setting).Error
+	err := r.DB().WithContext(ctx).Where("name = ?", name).Order("tenant_id DESC").First(&setting).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -58,6 +58,19 @@ func (r *ServerSettingRepositoryImpl) BatchGet(ctx context.Context, names []stri
 		return settings, nil
 	}
 
-	err := r.DB().WithContext(ctx).Where("name IN ?", names).Find(&settings).Error
-	return settings, err
+	err := r.DB().WithContext(ctx).Where("name IN ?", names).Order("tenant_id DESC").Find(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 同名配置只保留租户自有的一条
+	seen := make(map[string]bool, len(settings))
+	list := settings[:0]
+	for _, s := range settings {
+		if !seen[s.Name] {
+			seen[s.Name] = true
+			list = append(list, s)
+		}
+	}
+	return list, nil
 }
@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"goadmin/internal/model/tenant"
+	"goadmin/internal/model/user"
+
+	"gorm.io/gorm"
+)
+
+// ErrRoleTemplateNotFound 管理员角色模板不存在
+var ErrRoleTemplateNotFound = errors.New("tenant admin role template not found")
+
+// Hook 开通、注销租户的扩展步骤，与租户数据在同一事务中执行，返回错误时整体回滚
+//
+// ctx 及 tx 已限定为该租户，通过 tx 创建的租户隔离模型自动归属该租户
+type Hook struct {
+	Name        string
+	Provision   func(ctx context.Context, tx *gorm.DB, t *tenant.Tenant) error
+	Deprovision func(ctx context.Context, tx *gorm.DB, t *tenant.Tenant) error
+}
+
+// Provision 开通租户所需的数据
+type Provision struct {
+	Tenant       *tenant.Tenant
+	RoleTemplate string     // 管理员角色模板编码，须为平台内置角色
+	Admin        *user.User // 管理员用户，密码须已加密
+
+	AdminRole string // 开通后填充，克隆出的管理员角色编码
+}
+
+// Exporter 接收导出的租户数据
+type Exporter interface {
+	// Write 写入一条记录
+	Write(table string, row map[string]any) error
+	// Close 全部记录写入后调用，须确保数据已持久化，返回错误时不删除任何数据
+	Close() error
+}
+
+// ProvisionRepository 租户开通、注销仓储
+type ProvisionRepository interface {
+	// Provision 在同一事务中创建租户、由模板克隆的管理员角色及管理员用户，并依次执行扩展步骤
+	Provision(ctx context.Context, p *Provision, hooks []Hook) error
+
+	// Deprovision 在同一事务中导出并删除租户的全部数据，返回各表删除的记录数
+	//
+	// 导出或任一扩展步骤失败时不删除任何数据
+	Deprovision(ctx context.Context, t *tenant.Tenant, exporter Exporter, hooks []Hook) (map[string]int64, error)
+
+	// UserIDs 获取租户全部用户的ID，不受租户隔离限制
+	UserIDs(ctx context.Context, tenantID uint64) ([]uint64, error)
+}
@@ -0,0 +1,219 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/tenant"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// 确保 ProvisionRepositoryImpl 实现了 ProvisionRepository 接口
+var _ ProvisionRepository = (*ProvisionRepositoryImpl)(nil)
+
+// userOfTenant 按用户关联租户的表的筛选条件
+const userOfTenant = "user_id IN (SELECT id FROM users WHERE tenant_id = ?)"
+
+// tenantTables 租户数据所在的表及筛选条件，按删除顺序排列
+//
+// 操作日志只追加不删除，以免破坏哈希链，注销后仍保留
+var tenantTables = []struct {
+	name     string
+	cond     string
+	resource string // 审计事件的资源类型，与模型的 AuditResource 一致
+}{
+	{"user_roles", userOfTenant, "user_role"},
+	{"user_identities", userOfTenant, "user_identity"},
+	{"user_two_factor", userOfTenant, "user_two_factor"},
+	{"user_password_history", userOfTenant, "user_password_history"},
+	{"api_keys", userOfTenant, "api_key"},
+	{role.TableNameRolePermission, "role_code IN (SELECT code FROM roles WHERE tenant_id = ?)", "role_permission"},
+	{"position", "tenant_id = ?", "position"},
+	{"server_setting", "tenant_id = ?", "server_setting"},
+	{"export_jobs", "tenant_id = ?", "export_job"},
+	{"users", "tenant_id = ?", "user"},
+	{"roles", "tenant_id = ?", "role"},
+	{"tenants", "id = ?", "tenant"},
+}
+
+// ProvisionRepositoryImpl 实现 ProvisionRepository 接口
+type ProvisionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewProvisionRepository 创建租户开通仓储实例（Wire 注入）
+func NewProvisionRepository(database *gorm.DB) ProvisionRepository {
+	return &ProvisionRepositoryImpl{db: database}
+}
+
+// Deprecated: 使用 NewProvisionRepository 替代
+// NewProvisionRepository_legacy 创建租户开通仓储实例（兼容旧代码，使用全局db）
+func NewProvisionRepository_legacy() ProvisionRepository {
+	return NewProvisionRepository(db.GetDB())
+}
+
+// Provision 在同一事务中创建租户、由模板克隆的管理员角色及管理员用户，并依次执行扩展步骤
+func (r *ProvisionRepositoryImpl) Provision(ctx context.Context, p *Provision, hooks []Hook) error {
+	// 操作人可能已限定租户，开通过程按新租户显式隔离
+	ctx = db.SkipTenant(ctx)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(p.Tenant).Error; err != nil {
+			return err
+		}
+		tctx := db.WithTenant(ctx, p.Tenant.ID)
+		ttx := tx.WithContext(tctx)
+
+		adminRole, err := cloneRole(tx, ttx, p.RoleTemplate, p.Tenant.ID)
+		if err != nil {
+			return err
+		}
+		p.AdminRole = adminRole
+
+		if err = ttx.Create(p.Admin).Error; err != nil {
+			return err
+		}
+		if err = tx.Create(&user.UserRole{UserID: p.Admin.ID, RoleCode: adminRole}).Error; err != nil {
+			return err
+		}
+
+		for _, h := range hooks {
+			if h.Provision == nil {
+				continue
+			}
+			if err = h.Provision(tctx, ttx, p.Tenant); err != nil {
+				return fmt.Errorf("provision hook %s: %w", h.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// cloneRole 克隆平台内置的角色模板及其权限为租户角色，返回新角色编码
+func cloneRole(tx, ttx *gorm.DB, code string, tenantID uint64) (string, error) {
+	var tpl role.Role
+	err := tx.Where("code = ? AND tenant_id = 0", code).First(&tpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrRoleTemplateNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 角色编码、名称全局唯一，以租户ID区分
+	suffix := "_" + strconv.FormatUint(tenantID, 10)
+	newCode := tpl.Code
+	if len(newCode)+len(suffix) > 32 {
+		newCode = newCode[:32-len(suffix)]
+	}
+	clone := &role.Role{
+		Code:        newCode + suffix,
+		ParentCode:  tpl.ParentCode,
+		Name:        fmt.Sprintf("%s-%d", tpl.Name, tenantID),
+		Description: tpl.Description,
+		Status:      role.RoleStatusActive,
+		SystemFlag:  role.SystemFlagNo,
+		DataScope:   tpl.DataScope,
+		DataFilter:  tpl.DataFilter,
+	}
+	if err = ttx.Create(clone).Error; err != nil {
+		return "", err
+	}
+
+	// 逐条创建权限关联，由审计插件记录
+	var codes []string
+	err = tx.Model(&role.RolePermission{}).Where("role_code = ?", tpl.Code).Pluck("permission_code", &codes).Error
+	if err != nil || len(codes) == 0 {
+		return clone.Code, err
+	}
+	perms := make([]*role.RolePermission, 0, len(codes))
+	for _, c := range codes {
+		perms = append(perms, &role.RolePermission{RoleCode: clone.Code, PermissionCode: c})
+	}
+	err = ttx.Create(&perms).Error
+	return clone.Code, err
+}
+
+// Deprovision 在同一事务中导出并删除租户的全部数据，返回各表删除的记录数
+func (r *ProvisionRepositoryImpl) Deprovision(
+	ctx context.Context, t *tenant.Tenant, exporter Exporter, hooks []Hook) (map[string]int64, error) {
+	ctx = db.SkipTenant(ctx)
+	counts := make(map[string]int64, len(tenantTables))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make(map[string][]string, len(tenantTables))
+		for _, table := range tenantTables {
+			exported, err := exportTable(tx, table.name, table.cond, t.ID, exporter)
+			if err != nil {
+				return fmt.Errorf("export %s: %w", table.name, err)
+			}
+			ids[table.name] = exported
+		}
+		if err := exporter.Close(); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+
+		tctx := db.WithTenant(ctx, t.ID)
+		for _, h := range hooks {
+			if h.Deprovision == nil {
+				continue
+			}
+			if err := h.Deprovision(tctx, tx.WithContext(tctx), t); err != nil {
+				return fmt.Errorf("deprovision hook %s: %w", h.Name, err)
+			}
+		}
+
+		for _, table := range tenantTables {
+			res := tx.Exec("DELETE FROM "+table.name+" WHERE "+table.cond, t.ID)
+			if res.Error != nil {
+				return fmt.Errorf("delete %s: %w", table.name, res.Error)
+			}
+			counts[table.name] = res.RowsAffected
+			// 原生 SQL 不经过审计插件，按导出的记录逐条记录删除，随事务提交发送
+			for _, id := range ids[table.name] {
+				db.EmitAudit(tx, &db.AuditEvent{
+					Action: db.AuditDelete, Resource: table.resource, ResourceID: id, TenantID: t.ID,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// UserIDs 获取租户全部用户的ID
+func (r *ProvisionRepositoryImpl) UserIDs(ctx context.Context, tenantID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(db.SkipTenant(ctx)).Model(&user.User{}).
+		Where("tenant_id = ?", tenantID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// exportTable 逐行导出表中属于租户的记录，返回导出记录的ID
+func exportTable(tx *gorm.DB, table, cond string, tenantID uint64, exporter Exporter) ([]string, error) {
+	rows, err := tx.Table(table).Where(cond, tenantID).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		row := make(map[string]any)
+		if err = tx.ScanRows(rows, &row); err != nil {
+			return nil, err
+		}
+		if err = exporter.Write(table, row); err != nil {
+			return nil, err
+		}
+		ids = append(ids, fmt.Sprint(row["id"]))
+	}
+	return ids, rows.Err()
+}
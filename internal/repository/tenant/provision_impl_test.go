@@ -0,0 +1,259 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"goadmin/internal/model/operate_log"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/tenant"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+	"goadmin/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+// memExporter 在内存中收集导出的记录
+type memExporter struct {
+	rows     map[string]int
+	closeErr error
+}
+
+func (e *memExporter) Write(table string, _ map[string]any) error {
+	e.rows[table]++
+	return nil
+}
+
+func (e *memExporter) Close() error { return e.closeErr }
+
+// newProvisionDB 带租户隔离插件的内存数据库，含平台内置的管理员角色模板
+func newProvisionDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb := dbtest.Open(t,
+		&tenant.Tenant{}, &role.Role{}, &role.RolePermission{}, &user.User{}, &user.UserRole{}, &operate_log.OperateLog{})
+	if err := gdb.Use(db.TenantPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	// 其余租户数据表只需存在即可
+	for _, ddl := range []string{
+		"CREATE TABLE user_identities (id integer primary key, user_id integer)",
+		"CREATE TABLE user_two_factor (id integer primary key, user_id integer)",
+		"CREATE TABLE user_password_history (id integer primary key, user_id integer)",
+		"CREATE TABLE api_keys (id integer primary key, user_id integer)",
+		"CREATE TABLE position (id integer primary key, tenant_id integer)",
+		"CREATE TABLE server_setting (id integer primary key, tenant_id integer, name text, value text)",
+		"CREATE TABLE export_jobs (id integer primary key, tenant_id integer, user_id integer)",
+	} {
+		if err := gdb.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	tpl := &role.Role{Code: "tenant_admin", Name: "模板", Status: role.RoleStatusInactive, DataScope: db.ScopeTenant}
+	if err := gdb.Create(tpl).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"user_list", "role_list"} {
+		if err := gdb.Create(&role.RolePermission{RoleCode: "tenant_admin", PermissionCode: code}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return gdb
+}
+
+func newProvision(code string) *Provision {
+	return &Provision{
+		Tenant:       &tenant.Tenant{Name: code, Code: code, Status: tenant.TenantStatusEnabled, Config: "{}"},
+		RoleTemplate: "tenant_admin",
+		Admin:        &user.User{Username: code + "_admin", Email: code + "@example.com", Status: user.UserStatusActive},
+	}
+}
+
+func count(t *testing.T, gdb *gorm.DB, table, cond string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	if err := gdb.Table(table).Where(cond, args...).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestProvision(t *testing.T) {
+	gdb := newProvisionDB(t)
+	repo := NewProvisionRepository(gdb)
+
+	var hookTenant uint64
+	hooks := []Hook{{Name: "seed", Provision: func(ctx context.Context, tx *gorm.DB, tn *tenant.Tenant) error {
+		hookTenant, _ = db.TenantFrom(ctx)
+		return tx.Create(&operate_log.OperateLog{Username: "seed"}).Error
+	}}}
+	p := newProvision("acme")
+	if err := repo.Provision(context.Background(), p, hooks); err != nil {
+		t.Fatal(err)
+	}
+
+	id := p.Tenant.ID
+	if p.AdminRole != "tenant_admin_1" || p.Admin.TenantID != id || hookTenant != id {
+		t.Errorf("role = %s admin tenant = %d hook tenant = %d, want tenant_admin_1 %d", p.AdminRole, p.Admin.TenantID, hookTenant, id)
+	}
+	var r role.Role
+	gdb.Where("code = ?", p.AdminRole).First(&r)
+	if r.TenantID != id || !r.IsActive() || r.DataScope != db.ScopeTenant {
+		t.Errorf("cloned role = %+v", r)
+	}
+	if n := count(t, gdb, "role_permissions", "role_code = ?", p.AdminRole); n != 2 {
+		t.Errorf("cloned permissions = %d, want 2", n)
+	}
+	if n := count(t, gdb, "user_roles", "user_id = ? AND role_code = ?", p.Admin.ID, p.AdminRole); n != 1 {
+		t.Errorf("admin roles = %d, want 1", n)
+	}
+	if n := count(t, gdb, "operate_log", "tenant_id = ?", id); n != 1 {
+		t.Errorf("hook logs = %d, want 1", n)
+	}
+}
+
+func TestProvisionRollback(t *testing.T) {
+	gdb := newProvisionDB(t)
+	repo := NewProvisionRepository(gdb)
+	errHook := errors.New("hook failed")
+
+	hooks := []Hook{{Name: "fail", Provision: func(context.Context, *gorm.DB, *tenant.Tenant) error { return errHook }}}
+	if err := repo.Provision(context.Background(), newProvision("acme"), hooks); !errors.Is(err, errHook) {
+		t.Fatalf("err = %v, want hook error", err)
+	}
+	p := newProvision("beta")
+	p.RoleTemplate = "missing"
+	if err := repo.Provision(context.Background(), p, nil); !errors.Is(err, ErrRoleTemplateNotFound) {
+		t.Fatalf("err = %v, want ErrRoleTemplateNotFound", err)
+	}
+	for _, table := range []string{"tenants", "users", "user_roles"} {
+		if n := count(t, gdb, table, "1 = 1"); n != 0 {
+			t.Errorf("%s = %d after rollback, want 0", table, n)
+		}
+	}
+	if n := count(t, gdb, "roles", "1 = 1"); n != 1 {
+		t.Errorf("roles = %d after rollback, want template only", n)
+	}
+}
+
+func TestDeprovision(t *testing.T) {
+	gdb := newProvisionDB(t)
+	repo := NewProvisionRepository(gdb)
+	ctx := context.Background()
+	acme, beta := newProvision("acme"), newProvision("beta")
+	for _, p := range []*Provision{acme, beta} {
+		if err := repo.Provision(ctx, p, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, p := range []*Provision{acme, beta} {
+		err := gdb.Exec("INSERT INTO export_jobs (tenant_id, user_id) VALUES (?, ?)", p.Tenant.ID, p.Admin.ID).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := repo.UserIDs(ctx, acme.Tenant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != acme.Admin.ID {
+		t.Errorf("user ids = %v, want acme admin %d", ids, acme.Admin.ID)
+	}
+
+	// 导出失败时不删除
+	failing := &memExporter{rows: map[string]int{}, closeErr: errors.New("disk full")}
+	if _, err := repo.Deprovision(ctx, acme.Tenant, failing, nil); err == nil {
+		t.Fatal("deprovision should fail when export fails")
+	}
+	if n := count(t, gdb, "users", "tenant_id = ?", acme.Tenant.ID); n != 1 {
+		t.Errorf("users = %d after failed deprovision, want 1", n)
+	}
+
+	exporter := &memExporter{rows: map[string]int{}}
+	counts, err := repo.Deprovision(ctx, acme.Tenant, exporter, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"tenants", "users", "roles", "user_roles", "export_jobs"} {
+		if counts[table] != 1 || exporter.rows[table] != 1 {
+			t.Errorf("%s deleted %d exported %d, want 1", table, counts[table], exporter.rows[table])
+		}
+	}
+	if counts["role_permissions"] != 2 {
+		t.Errorf("role_permissions deleted = %d, want 2", counts["role_permissions"])
+	}
+	// 其他租户及平台数据保留
+	if n := count(t, gdb, "users", "1 = 1"); n != 1 {
+		t.Errorf("users = %d, want beta admin only", n)
+	}
+	if n := count(t, gdb, "roles", "1 = 1"); n != 2 {
+		t.Errorf("roles = %d, want template and beta admin", n)
+	}
+	if n := count(t, gdb, "export_jobs", "1 = 1"); n != 1 {
+		t.Errorf("export_jobs = %d, want beta's only", n)
+	}
+}
+
+func TestDeprovisionAudit(t *testing.T) {
+	gdb := newProvisionDB(t)
+	if err := gdb.Use(db.AuditPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	var events []*db.AuditEvent
+	db.SetAuditSink(func(_ context.Context, e *db.AuditEvent) { events = append(events, e) })
+	t.Cleanup(func() { db.SetAuditSink(nil) })
+
+	repo := NewProvisionRepository(gdb)
+	ctx := context.Background()
+	acme := newProvision("acme")
+	if err := repo.Provision(ctx, acme, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 克隆的角色权限同样记录
+	perms := 0
+	for _, e := range events {
+		if e.Resource == "role_permission" && e.Action == db.AuditCreate && e.TenantID == acme.Tenant.ID {
+			perms++
+		}
+	}
+	if perms != 2 {
+		t.Errorf("role_permission create events = %d, want 2", perms)
+	}
+
+	// 注销失败回滚时不记录
+	events = nil
+	failing := &memExporter{rows: map[string]int{}, closeErr: errors.New("disk full")}
+	if _, err := repo.Deprovision(ctx, acme.Tenant, failing, nil); err == nil {
+		t.Fatal("deprovision should fail when export fails")
+	}
+	if len(events) != 0 {
+		t.Fatalf("events after failed deprovision = %d, want 0", len(events))
+	}
+
+	counts, err := repo.Deprovision(ctx, acme.Tenant, &memExporter{rows: map[string]int{}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	if int64(len(events)) != total {
+		t.Fatalf("delete events = %d, want %d", len(events), total)
+	}
+	found := false
+	for _, e := range events {
+		if e.Action != db.AuditDelete || e.TenantID != acme.Tenant.ID {
+			t.Errorf("event = %+v", e)
+		}
+		if e.Resource == "user" && e.ResourceID == strconv.FormatUint(acme.Admin.ID, 10) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("missing delete event for the tenant admin")
+	}
+}
@@ -39,7 +39,7 @@ func NewTenantRepository_legacy() Repository {
 // GetByCode 根据编码获取租户
 func (r *TenantRepositoryImpl) GetByCode(ctx context.Context, code string) (*tenant.Tenant, error) {
 	var t tenant.Tenant
-	err := r.DB().WithContext(ctx).Where("code = ?", code).First(&t).Error
+	err := r.Scoped(ctx).Where("code = ?", code).First(&t).Error
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, nil
@@ -69,7 +69,7 @@ func (r *TenantRepositoryImpl) PageList(ctx context.Context, req *tenant.ListReq
 	return r.List(ctx, req.Page, req.PageSize, opts...)
 }
 
-// ExistsByCode 检查编码是否存在
+// ExistsByCode 检查编码是否存在，编码全局唯一，不受数据范围限制
 func (r *TenantRepositoryImpl) ExistsByCode(ctx context.Context, code string, excludeID ...uint64) (bool, error) {
 	opts := []db.QueryOption[tenant.Tenant]{
 		db.Where[tenant.Tenant]("code = ?", code),
@@ -79,5 +79,5 @@ func (r *TenantRepositoryImpl) ExistsByCode(ctx context.Context, code string, ex
 	if len(excludeID) > 0 && excludeID[0] > 0 {
 		opts = append(opts, db.Where[tenant.Tenant]("id != ?", excludeID[0]))
 	}
-	return r.Exists(ctx, opts...)
+	return r.Exists(db.SkipScope(ctx), opts...)
 }
@@ -0,0 +1,38 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/tenant"
+	"goadmin/pkg/db"
+)
+
+func TestTenantRepositoryScope(t *testing.T) {
+	gdb := newProvisionDB(t)
+	for _, code := range []string{"t1", "t2"} {
+		if err := gdb.Create(&tenant.Tenant{Name: code, Code: code, Config: "{}"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := NewTenantRepositoryImpl(gdb)
+	ctx := db.WithScope(context.Background(), &db.Scope{
+		UserID: 1, TenantID: 1, Rules: []db.Rule{{Kind: db.ScopeTenant}},
+	})
+
+	list, total, err := repo.PageList(ctx, &tenant.ListRequest{PageRequest: schema.PageRequest{Page: 1, PageSize: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(list) != 1 || list[0].Code != "t1" {
+		t.Fatalf("PageList = %d rows (total %d), want only t1", len(list), total)
+	}
+	if got, err := repo.GetByCode(ctx, "t2"); err != nil || got != nil {
+		t.Fatalf("GetByCode(t2) = %v, %v, want nil", got, err)
+	}
+	// 编码唯一性校验须查询全表
+	if exists, err := repo.ExistsByCode(ctx, "t2"); err != nil || !exists {
+		t.Fatalf("ExistsByCode(t2) = %v, %v, want true", exists, err)
+	}
+}
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+)
+
+// IdentityRepository 定义外部身份绑定仓储接口
+type IdentityRepository interface {
+	db.Repository[user.Identity]
+
+	// GetBySubject 根据身份来源及用户标识获取绑定关系
+	GetBySubject(ctx context.Context, provider, issuer, subject string) (*user.Identity, error)
+
+	// ListByIssuer 获取指定身份提供方的全部绑定关系
+	ListByIssuer(ctx context.Context, provider, issuer string) ([]*user.Identity, error)
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// 确保IdentityRepositoryImpl实现了IdentityRepository接口
+var _ IdentityRepository = (*IdentityRepositoryImpl)(nil)
+
+// IdentityRepositoryImpl 实现IdentityRepository接口
+type IdentityRepositoryImpl struct {
+	*db.BaseRepository[user.Identity]
+}
+
+// NewIdentityRepository 创建外部身份绑定仓储实例（Wire 注入）
+func NewIdentityRepository(database *gorm.DB) IdentityRepository {
+	return &IdentityRepositoryImpl{
+		db.NewBaseRepository[user.Identity](database),
+	}
+}
+
+// Deprecated: 使用 NewIdentityRepository 替代
+// NewIdentityRepository_legacy 创建外部身份绑定仓储实例（兼容旧代码，使用全局db）
+func NewIdentityRepository_legacy() IdentityRepository {
+	return NewIdentityRepository(db.GetDB())
+}
+
+// GetBySubject 根据身份来源及用户标识获取绑定关系
+func (r *IdentityRepositoryImpl) GetBySubject(
+	ctx context.Context, provider, issuer, subject string) (*user.Identity, error) {
+	var identity user.Identity
+	err := r.DB().WithContext(ctx).
+		Where("provider = ? AND issuer = ? AND subject = ?", provider, issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByIssuer 获取指定身份提供方的全部绑定关系
+func (r *IdentityRepositoryImpl) ListByIssuer(ctx context.Context, provider, issuer string) ([]*user.Identity, error) {
+	var list []*user.Identity
+	err := r.DB().WithContext(ctx).
+		Where("provider = ? AND issuer = ?", provider, issuer).
+		Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+)
+
+// PasswordHistoryRepository 定义历史密码仓储接口
+type PasswordHistoryRepository interface {
+	db.Repository[user.PasswordHistory]
+
+	// ListRecent 获取用户最近的 limit 条历史密码，按时间倒序
+	ListRecent(ctx context.Context, userID uint64, limit int) ([]*user.PasswordHistory, error)
+
+	// Prune 仅保留用户最近的 keep 条历史密码
+	Prune(ctx context.Context, userID uint64, keep int) error
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// 确保PasswordHistoryRepositoryImpl实现了PasswordHistoryRepository接口
+var _ PasswordHistoryRepository = (*PasswordHistoryRepositoryImpl)(nil)
+
+// PasswordHistoryRepositoryImpl 实现PasswordHistoryRepository接口
+type PasswordHistoryRepositoryImpl struct {
+	*db.BaseRepository[user.PasswordHistory]
+}
+
+// NewPasswordHistoryRepository 创建历史密码仓储实例（Wire 注入）
+func NewPasswordHistoryRepository(database *gorm.DB) PasswordHistoryRepository {
+	return &PasswordHistoryRepositoryImpl{
+		db.NewBaseRepository[user.PasswordHistory](database),
+	}
+}
+
+// Deprecated: 使用 NewPasswordHistoryRepository 替代
+// NewPasswordHistoryRepository_legacy 创建历史密码仓储实例（兼容旧代码，使用全局db）
+func NewPasswordHistoryRepository_legacy() PasswordHistoryRepository {
+	return NewPasswordHistoryRepository(db.GetDB())
+}
+
+// ListRecent 获取用户最近的 limit 条历史密码，按时间倒序
+func (r *PasswordHistoryRepositoryImpl) ListRecent(
+	ctx context.Context, userID uint64, limit int) ([]*user.PasswordHistory, error) {
+	var list []*user.PasswordHistory
+	err := r.DB().WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id DESC").Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// Prune 仅保留用户最近的 keep 条历史密码
+func (r *PasswordHistoryRepositoryImpl) Prune(ctx context.Context, userID uint64, keep int) error {
+	var ids []uint64
+	err := r.DB().WithContext(ctx).Model(&user.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").Offset(keep).Limit(1000).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	return r.DB().WithContext(ctx).Where("id IN ?", ids).Delete(&user.PasswordHistory{}).Error
+}
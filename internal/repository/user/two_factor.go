@@ -0,0 +1,24 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+)
+
+// TwoFactorRepository 定义双因素认证仓储接口
+type TwoFactorRepository interface {
+	db.Repository[user.TwoFactor]
+
+	// GetByUserID 根据用户ID获取双因素认证配置
+	GetByUserID(ctx context.Context, userID uint64) (*user.TwoFactor, error)
+
+	// UpdateRecoveryCodes 仅在恢复码未被并发修改时更新，保证每个恢复码只能使用一次
+	UpdateRecoveryCodes(ctx context.Context, id uint64, old, new string) (bool, error)
+
+	// UpdateSecret 仅在密钥未被并发修改时更新加密后的TOTP密钥
+	UpdateSecret(ctx context.Context, id uint64, old, new string) (bool, error)
+
+	// DeleteByUserID 删除用户的双因素认证配置
+	DeleteByUserID(ctx context.Context, userID uint64) error
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 确保TwoFactorRepositoryImpl实现了TwoFactorRepository接口
+var _ TwoFactorRepository = (*TwoFactorRepositoryImpl)(nil)
+
+// TwoFactorRepositoryImpl 实现TwoFactorRepository接口
+type TwoFactorRepositoryImpl struct {
+	*db.BaseRepository[user.TwoFactor]
+}
+
+// NewTwoFactorRepository 创建双因素认证仓储实例（Wire 注入）
+func NewTwoFactorRepository(database *gorm.DB) TwoFactorRepository {
+	return &TwoFactorRepositoryImpl{
+		db.NewBaseRepository[user.TwoFactor](database),
+	}
+}
+
+// Deprecated: 使用 NewTwoFactorRepository 替代
+// NewTwoFactorRepository_legacy 创建双因素认证仓储实例（兼容旧代码，使用全局db）
+func NewTwoFactorRepository_legacy() TwoFactorRepository {
+	return NewTwoFactorRepository(db.GetDB())
+}
+
+// GetByUserID 根据用户ID获取双因素认证配置
+func (r *TwoFactorRepositoryImpl) GetByUserID(ctx context.Context, userID uint64) (*user.TwoFactor, error) {
+	var tf user.TwoFactor
+	err := r.DB().WithContext(ctx).Where("user_id = ?", userID).First(&tf).Error
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// UpdateRecoveryCodes 仅在恢复码未被并发修改时更新，保证每个恢复码只能使用一次
+func (r *TwoFactorRepositoryImpl) UpdateRecoveryCodes(ctx context.Context, id uint64, old, new string) (bool, error) {
+	result := r.DB().WithContext(ctx).Model(&user.TwoFactor{}).
+		Where("id = ? AND recovery_codes = ?", id, old).
+		Updates(map[string]interface{}{
+			"recovery_codes": new,
+			"mtime":          time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateSecret 仅在密钥未被并发修改时更新加密后的TOTP密钥
+func (r *TwoFactorRepositoryImpl) UpdateSecret(ctx context.Context, id uint64, old, new string) (bool, error) {
+	result := r.DB().WithContext(ctx).Model(&user.TwoFactor{}).
+		Where("id = ? AND secret = ?", id, old).
+		Updates(map[string]interface{}{
+			"secret": new,
+			"mtime":  time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByUserID 删除用户的双因素认证配置
+func (r *TwoFactorRepositoryImpl) DeleteByUserID(ctx context.Context, userID uint64) error {
+	return r.DB().WithContext(ctx).Where("user_id = ?", userID).Delete(&user.TwoFactor{}).Error
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"goadmin/internal/model/user"
 	"goadmin/pkg/db"
+	"time"
 )
 
 // UserRepository 定义用户仓储接口
 type UserRepository interface {
 	db.Repository[user.User]
 
+	// CreateWithIdentity 在同一事务中创建用户及其外部身份绑定
+	CreateWithIdentity(ctx context.Context, u *user.User, identity *user.Identity) error
+
 	// GetByUsername 根据用户名获取用户
 	GetByUsername(ctx context.Context, username string) (*user.User, error)
 
@@ -19,8 +23,17 @@ type UserRepository interface {
 	// UpdateStatus 更新用户状态
 	UpdateStatus(ctx context.Context, id uint64, status user.UserStatus) error
 
+	// Lock 锁定用户，until 为空表示需管理员解锁
+	Lock(ctx context.Context, id uint64, until *time.Time) error
+
+	// Unlock 解锁用户
+	Unlock(ctx context.Context, id uint64) error
+
 	// UpdatePassword 更新用户密码
-	UpdatePassword(ctx context.Context, id uint64, password string) error
+	UpdatePassword(ctx context.Context, id uint64, password string, mustChange bool) error
+
+	// UpdateRoles 替换用户的全部角色
+	UpdateRoles(ctx context.Context, id uint64, roleCodes []string) error
 
 	// IsUsernameExists 检查用户名是否存在
 	IsUsernameExists(ctx context.Context, username string, excludeID ...uint64) (bool, error)
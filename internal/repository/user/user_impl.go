@@ -14,14 +14,18 @@ import (
 var _ UserRepository = (*UserRepositoryImpl)(nil)
 
 // UserRepositoryImpl 实现UserRepository接口
+//
+// 返回的用户均已加载 user_roles 中的角色
 type UserRepositoryImpl struct {
 	*db.BaseRepository[user.User]
+	userRoles *UserRoleRepositoryImpl
 }
 
 // NewUserRepositoryImpl 创建用户仓储实例（Wire 注入）
 func NewUserRepositoryImpl(database *gorm.DB) *UserRepositoryImpl {
 	return &UserRepositoryImpl{
-		db.NewBaseRepository[user.User](database),
+		BaseRepository: db.NewBaseRepository[user.User](database),
+		userRoles:      newUserRoleRepositoryImpl(database),
 	}
 }
 
@@ -33,23 +37,48 @@ func NewUserRepository(database *gorm.DB) UserRepository {
 // Deprecated: 使用 NewUserRepository 替代
 // NewUserRepository_legacy 创建用户仓储实例（兼容旧代码，使用全局db）
 func NewUserRepository_legacy() UserRepository {
-	return &UserRepositoryImpl{
-		db.NewBaseRepository[user.User](db.GetDB()),
-	}
+	return NewUserRepositoryImpl(db.GetDB())
+}
+
+// Create 创建用户并写入其角色
+func (r *UserRepositoryImpl) Create(ctx context.Context, u *user.User) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(u).Error; err != nil {
+			return err
+		}
+		return setRoles(tx, u.ID, u.RoleCodes())
+	})
+}
+
+// CreateWithIdentity 在同一事务中创建用户及其外部身份绑定，绑定失败时不留下用户
+func (r *UserRepositoryImpl) CreateWithIdentity(ctx context.Context, u *user.User, identity *user.Identity) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(u).Error; err != nil {
+			return err
+		}
+		if err := setRoles(tx, u.ID, u.RoleCodes()); err != nil {
+			return err
+		}
+		identity.UserID = u.ID
+		return tx.Create(identity).Error
+	})
 }
 
 // GetByID 根据ID获取用户
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uint64) (*user.User, error) {
 	var u user.User
-	err := r.DB().WithContext(ctx).
+	err := r.Scoped(ctx).
 		Where("id = ? AND status != ?", id, user.UserStatusDeleted).
-		Preload("Role").First(&u).Error
+		First(&u).Error
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if err = r.loadRoles(ctx, &u); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
@@ -58,13 +87,16 @@ func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string)
 	var u user.User
 	err := r.DB().WithContext(ctx).
 		Where("username = ? AND status != ?", username, user.UserStatusDeleted).
-		Preload("Role").First(&u).Error
+		First(&u).Error
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if err = r.loadRoles(ctx, &u); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
@@ -78,12 +110,15 @@ func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*use
 		}
 		return nil, err
 	}
+	if err = r.loadRoles(ctx, &u); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
 // UpdateStatus 更新用户状态
 func (r *UserRepositoryImpl) UpdateStatus(ctx context.Context, id uint64, status user.UserStatus) error {
-	return r.DB().WithContext(ctx).Model(&user.User{}).
+	return r.Scoped(ctx).Model(&user.User{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"status": status,
@@ -91,16 +126,59 @@ func (r *UserRepositoryImpl) UpdateStatus(ctx context.Context, id uint64, status
 		}).Error
 }
 
-// UpdatePassword 更新用户密码
-func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, id uint64, password string) error {
-	return r.DB().WithContext(ctx).Model(&user.User{}).
+// Lock 锁定用户，until 为空表示需管理员解锁
+func (r *UserRepositoryImpl) Lock(ctx context.Context, id uint64, until *time.Time) error {
+	return r.Scoped(ctx).Model(&user.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       user.UserStatusLocked,
+			"locked_until": until,
+			"mtime":        time.Now(),
+		}).Error
+}
+
+// Unlock 解锁用户
+func (r *UserRepositoryImpl) Unlock(ctx context.Context, id uint64) error {
+	return r.Scoped(ctx).Model(&user.User{}).
+		Where("id = ? AND status = ?", id, user.UserStatusLocked).
+		Updates(map[string]interface{}{
+			"status":       user.UserStatusActive,
+			"locked_until": nil,
+			"mtime":        time.Now(),
+		}).Error
+}
+
+// UpdatePassword 更新用户密码，mustChange 表示用户下次登录须修改密码
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, id uint64, password string, mustChange bool) error {
+	now := time.Now()
+	return r.Scoped(ctx).Model(&user.User{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"password": password,
-			"mtime":    time.Now(),
+			"password":             password,
+			"password_changed_at":  now,
+			"must_change_password": mustChange,
+			"mtime":                now,
 		}).Error
 }
 
+// UpdateRoles 替换用户的全部角色，数据范围外的用户返回 gorm.ErrRecordNotFound
+func (r *UserRepositoryImpl) UpdateRoles(ctx context.Context, id uint64, roleCodes []string) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&user.User{}).Scopes(db.DataScope[user.User](ctx)).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := setRoles(tx, id, roleCodes); err != nil {
+			return err
+		}
+		return tx.Model(&user.User{}).Where("id = ?", id).Update("mtime", time.Now()).Error
+	})
+}
+
 // Delete 删除用户（逻辑删除）
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id uint64) error {
 	return r.UpdateStatus(ctx, id, user.UserStatusDeleted)
@@ -109,7 +187,8 @@ func (r *UserRepositoryImpl) Delete(ctx context.Context, id uint64) error {
 // IsUsernameExists 检查用户名是否存在
 func (r *UserRepositoryImpl) IsUsernameExists(ctx context.Context, username string, excludeID ...uint64) (bool, error) {
 	var count int64
-	query := r.DB().WithContext(ctx).Model(&user.User{}).
+	// 用户名全局唯一，不受租户隔离限制
+	query := r.DB().WithContext(db.SkipTenant(ctx)).Model(&user.User{}).
 		Where("username = ?", username)
 
 	// 排除指定ID
@@ -124,7 +203,8 @@ func (r *UserRepositoryImpl) IsUsernameExists(ctx context.Context, username stri
 // IsEmailExists 检查邮箱是否存在
 func (r *UserRepositoryImpl) IsEmailExists(ctx context.Context, email string, excludeID ...uint64) (bool, error) {
 	var count int64
-	query := r.DB().WithContext(ctx).Model(&user.User{}).
+	// 邮箱全局唯一，不受租户隔离限制
+	query := r.DB().WithContext(db.SkipTenant(ctx)).Model(&user.User{}).
 		Where("email = ?", email)
 
 	// 排除指定ID
@@ -139,17 +219,24 @@ func (r *UserRepositoryImpl) IsEmailExists(ctx context.Context, email string, ex
 // GetUsersByRoleCode 获取指定角色的所有用户
 func (r *UserRepositoryImpl) GetUsersByRoleCode(ctx context.Context, roleCode string) ([]*user.User, error) {
 	var users []*user.User
-	err := r.DB().WithContext(ctx).
-		Where("role_code = ? AND status != ?", roleCode, user.UserStatusDeleted).
+	err := r.Scoped(ctx).
+		Where("id IN (?) AND status != ?",
+			r.DB().Model(&user.UserRole{}).Select("user_id").Where("role_code = ?", roleCode),
+			user.UserStatusDeleted).
 		Find(&users).Error
-	return users, err
+	if err != nil {
+		return nil, err
+	}
+	if err = r.loadRoles(ctx, users...); err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
 // List 获取用户列表（重写以排除已删除用户）
 func (r *UserRepositoryImpl) PageList(ctx context.Context, req *user.ListRequest) ([]*user.User, int64, error) {
 	opts := []db.QueryOption[user.User]{
 		db.Order[user.User](req.OrderBy),
-		db.Preload[user.User]("Role"),
 		db.Where[user.User]("status != ?", user.UserStatusDeleted), // 添加排除已删除用户的条件
 	}
 
@@ -157,6 +244,28 @@ func (r *UserRepositoryImpl) PageList(ctx context.Context, req *user.ListRequest
 	if req.Keyword != "" {
 		opts = append(opts, db.Where[user.User]("username LIKE ? OR email LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%"))
 	}
-	return r.List(ctx, req.Page, req.PageSize, opts...)
+	list, total, err := r.List(ctx, req.Page, req.PageSize, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err = r.loadRoles(ctx, list...); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
 
+// loadRoles 批量加载用户的角色
+func (r *UserRepositoryImpl) loadRoles(ctx context.Context, users ...*user.User) error {
+	ids := make([]uint64, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	roles, err := r.userRoles.GetRolesByUserIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		u.Roles = roles[u.ID]
+	}
+	return nil
 }
@@ -0,0 +1,107 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+	"goadmin/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+// newUserDB 用户 1、2 属于租户 1 部门 10、20，用户 3 属于租户 2 部门 30，用户 2、3 拥有 editor 角色
+func newUserDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb := dbtest.Open(t, &user.User{}, &user.UserRole{}, &role.Role{})
+	users := []*user.User{
+		{BaseModel: schema.BaseModel{ID: 1}, Username: "alice", Email: "alice@example.com", TenantID: 1, DeptID: 10},
+		{BaseModel: schema.BaseModel{ID: 2}, Username: "bob", Email: "bob@example.com", TenantID: 1, DeptID: 20},
+		{BaseModel: schema.BaseModel{ID: 3}, Username: "carol", Email: "carol@example.com", TenantID: 2, DeptID: 30},
+	}
+	for _, u := range users {
+		u.Status = user.UserStatusActive
+	}
+	if err := gdb.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	userRoles := []*user.UserRole{
+		{BaseModel: schema.BaseModel{ID: 1}, UserID: 2, RoleCode: "editor"},
+		{BaseModel: schema.BaseModel{ID: 2}, UserID: 3, RoleCode: "editor"},
+	}
+	if err := gdb.Create(&userRoles).Error; err != nil {
+		t.Fatal(err)
+	}
+	return gdb
+}
+
+// deptScope 用户 1 仅可访问本部门数据
+func deptScope() context.Context {
+	return db.WithScope(context.Background(), &db.Scope{
+		UserID: 1, TenantID: 1, DeptID: 10, Rules: []db.Rule{{Kind: db.ScopeDept}},
+	})
+}
+
+func TestUserRepositoryScope(t *testing.T) {
+	gdb := newUserDB(t)
+	repo := NewUserRepositoryImpl(gdb)
+	ctx := deptScope()
+
+	if u, err := repo.GetByID(ctx, 2); err != nil || u != nil {
+		t.Fatalf("GetByID out of scope = %v, %v, want nil", u, err)
+	}
+	list, total, err := repo.PageList(ctx, &user.ListRequest{PageRequest: schema.PageRequest{Page: 1, PageSize: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(list) != 1 || list[0].ID != 1 {
+		t.Fatalf("PageList = %d rows, total %d, want only user 1", len(list), total)
+	}
+	users, err := repo.GetUsersByRoleCode(ctx, "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("GetUsersByRoleCode returned %d users outside the scope", len(users))
+	}
+
+	until := time.Now().Add(time.Hour)
+	if err = repo.Lock(ctx, 2, &until); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.UpdateRoles(ctx, 2, []string{"admin"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("UpdateRoles out of scope err = %v, want ErrRecordNotFound", err)
+	}
+
+	var bob user.User
+	if err = gdb.First(&bob, 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if bob.Status != user.UserStatusActive {
+		t.Fatalf("user 2 status = %d, Lock must not touch users outside the scope", bob.Status)
+	}
+	var codes []string
+	if err = gdb.Model(&user.UserRole{}).Where("user_id = ?", 2).Pluck("role_code", &codes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 1 || codes[0] != "editor" {
+		t.Fatalf("user 2 roles = %v, UpdateRoles must not touch users outside the scope", codes)
+	}
+
+	// 无数据范围（命令行、登录流程）时不受限制
+	if err = repo.UpdateRoles(context.Background(), 2, []string{"admin"}); err != nil {
+		t.Fatal(err)
+	}
+	users, err = repo.GetUsersByRoleCode(context.Background(), "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != 3 {
+		t.Fatalf("GetUsersByRoleCode without scope = %d users, want user 3", len(users))
+	}
+}
@@ -0,0 +1,22 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+)
+
+// UserRoleRepository 定义用户角色关联仓储接口
+type UserRoleRepository interface {
+	db.Repository[user.UserRole]
+
+	// GetRolesByUserIDs 批量获取用户的角色，已删除的角色不返回
+	GetRolesByUserIDs(ctx context.Context, userIDs []uint64) (map[uint64]role.Set, error)
+
+	// GetUserIDsByRoleCode 获取拥有指定角色的用户ID
+	GetUserIDsByRoleCode(ctx context.Context, roleCode string) ([]uint64, error)
+
+	// SetRoles 替换用户的全部角色
+	SetRoles(ctx context.Context, userID uint64, roleCodes []string) error
+}
@@ -0,0 +1,93 @@
+package user
+
+import (
+	"context"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/user"
+	"goadmin/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// 确保UserRoleRepositoryImpl实现了UserRoleRepository接口
+var _ UserRoleRepository = (*UserRoleRepositoryImpl)(nil)
+
+// UserRoleRepositoryImpl 实现UserRoleRepository接口
+type UserRoleRepositoryImpl struct {
+	*db.BaseRepository[user.UserRole]
+}
+
+// NewUserRoleRepository 创建用户角色关联仓储实例（Wire 注入）
+func NewUserRoleRepository(database *gorm.DB) UserRoleRepository {
+	return newUserRoleRepositoryImpl(database)
+}
+
+// Deprecated: 使用 NewUserRoleRepository 替代
+// NewUserRoleRepository_legacy 创建用户角色关联仓储实例（兼容旧代码，使用全局db）
+func NewUserRoleRepository_legacy() UserRoleRepository {
+	return NewUserRoleRepository(db.GetDB())
+}
+
+func newUserRoleRepositoryImpl(database *gorm.DB) *UserRoleRepositoryImpl {
+	return &UserRoleRepositoryImpl{
+		db.NewBaseRepository[user.UserRole](database),
+	}
+}
+
+// GetRolesByUserIDs 批量获取用户的角色，已删除的角色不返回
+func (r *UserRoleRepositoryImpl) GetRolesByUserIDs(
+	ctx context.Context, userIDs []uint64) (map[uint64]role.Set, error) {
+	result := make(map[uint64]role.Set, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		UserID uint64 `gorm:"column:user_id"`
+		role.Role
+	}
+	err := r.DB().WithContext(ctx).
+		Table((user.UserRole{}).TableName()+" ur").
+		Select("ur.user_id, r.*").
+		Joins("JOIN "+(role.Role{}).TableName()+" r ON r.code = ur.role_code").
+		Where("ur.user_id IN ?", userIDs).
+		Order("ur.user_id, r.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.UserID] = append(result[row.UserID], row.Role)
+	}
+	return result, nil
+}
+
+// GetUserIDsByRoleCode 获取拥有指定角色的用户ID
+func (r *UserRoleRepositoryImpl) GetUserIDsByRoleCode(ctx context.Context, roleCode string) ([]uint64, error) {
+	var ids []uint64
+	err := r.DB().WithContext(ctx).Model(&user.UserRole{}).
+		Where("role_code = ?", roleCode).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// SetRoles 替换用户的全部角色
+func (r *UserRoleRepositoryImpl) SetRoles(ctx context.Context, userID uint64, roleCodes []string) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return setRoles(tx, userID, roleCodes)
+	})
+}
+
+// setRoles 在事务中替换用户角色
+func setRoles(tx *gorm.DB, userID uint64, roleCodes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&user.UserRole{}).Error; err != nil {
+		return err
+	}
+	if len(roleCodes) == 0 {
+		return nil
+	}
+	rows := make([]*user.UserRole, 0, len(roleCodes))
+	for _, code := range roleCodes {
+		rows = append(rows, &user.UserRole{UserID: userID, RoleCode: code})
+	}
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,288 @@
+package api_key
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/api_key"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/schema"
+	apikeyrepo "goadmin/internal/repository/api_key"
+	rolerepo "goadmin/internal/repository/role"
+	"goadmin/internal/service/operate_log"
+	roleservice "goadmin/internal/service/role"
+	"goadmin/pkg/db"
+	"goadmin/pkg/util"
+	"strings"
+	"time"
+)
+
+// 同一 IP 连续使用密钥时，最近使用时间的最小更新间隔
+const touchInterval = time.Minute
+
+// APIKeyService API 密钥服务接口
+type APIKeyService interface {
+	// ListAPIKeys 获取当前用户的 API 密钥
+	ListAPIKeys(ctx *context.Context) ([]*api_key.APIKey, error)
+
+	// CreateAPIKey 为当前用户创建 API 密钥，完整密钥仅返回一次
+	CreateAPIKey(ctx *context.Context, req *api_key.CreateRequest) (*api_key.CreateResponse, error)
+
+	// RevokeAPIKey 吊销当前用户的 API 密钥
+	RevokeAPIKey(ctx *context.Context, req *schema.IDRequest) error
+
+	// Authenticate 校验密钥，返回未吊销且未过期的密钥
+	Authenticate(ctx *context.Context, key string) (*api_key.APIKey, error)
+
+	// CheckScope 检查密钥是否可访问指定请求方法及URL
+	CheckScope(ctx *context.Context, k *api_key.APIKey, method string, accessURL string) error
+
+	// Touch 记录密钥最近使用时间及 IP，失败仅记录日志
+	Touch(ctx *context.Context, k *api_key.APIKey)
+}
+
+// apiKeyService API 密钥服务实现
+type apiKeyService struct {
+	apiKeyRepo         apikeyrepo.Repository
+	rolePermissionRepo rolerepo.RolePermissionRepository
+	roleSrv            roleservice.RoleService
+	logService         operate_log.OperateLogService
+}
+
+// NewAPIKeyService 创建 API 密钥服务实例（Wire 注入）
+func NewAPIKeyService(
+	apiKeyRepo apikeyrepo.Repository,
+	rolePermissionRepo rolerepo.RolePermissionRepository,
+	roleSrv roleservice.RoleService,
+	logService operate_log.OperateLogService,
+) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:         apiKeyRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		roleSrv:            roleSrv,
+		logService:         logService,
+	}
+}
+
+// Deprecated: 使用 NewAPIKeyService(apiKeyRepo, rolePermissionRepo, roleSrv, logService) 替代
+// NewAPIKeyService_legacy 创建 API 密钥服务实例（兼容旧代码，使用全局db）
+func NewAPIKeyService_legacy() APIKeyService {
+	return NewAPIKeyService(
+		apikeyrepo.NewAPIKeyRepository(db.GetDB()),
+		rolerepo.NewRolePermissionRepositoryWithDB(),
+		roleservice.NewRoleService_legacy(),
+		operate_log.NewOperateLogService_legacy(),
+	)
+}
+
+func (*apiKeyService) logPrefix() string {
+	return "api-key-service"
+}
+
+// ListAPIKeys 获取当前用户的 API 密钥
+func (s *apiKeyService) ListAPIKeys(ctx *context.Context) ([]*api_key.APIKey, error) {
+	userID := ctx.Session().GetID()
+	list, err := s.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取API密钥列表失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	now := time.Now()
+	for _, k := range list {
+		k.State = k.StateAt(now)
+	}
+	if list == nil {
+		list = []*api_key.APIKey{}
+	}
+	return list, nil
+}
+
+// CreateAPIKey 为当前用户创建 API 密钥，完整密钥仅返回一次
+func (s *apiKeyService) CreateAPIKey(
+	ctx *context.Context, req *api_key.CreateRequest) (*api_key.CreateResponse, error) {
+	if err := s.checkSessionLogin(ctx); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !time.Now().Before(time.Time(*req.ExpiresAt)) {
+		return nil, i18n.E(ctx.Context, "user.APIKeyExpiresInvalid", nil)
+	}
+	scopes := util.Unique(req.Scopes)
+	if err := s.checkScopes(ctx, scopes); err != nil {
+		return nil, err
+	}
+
+	key, prefix, err := generateKey()
+	if err != nil {
+		ctx.Logger.Errorf("%s 生成API密钥失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	k := &api_key.APIKey{
+		UserID:    ctx.Session().GetID(),
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hashKey(key),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err = s.apiKeyRepo.Create(ctx, k); err != nil {
+		ctx.Logger.Errorf("%s 创建API密钥失败: %s %v", s.logPrefix(), req.Name, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	k.State = api_key.StateActive
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.APIKey.Create",
+		map[string]any{"name": k.Name, "prefix": k.Prefix}))
+	ctx.Logger.Infof("%s 创建API密钥成功: %s %s", s.logPrefix(), ctx.Session().GetUsername(), k.Prefix)
+	return &api_key.CreateResponse{APIKey: k, Key: key}, nil
+}
+
+// RevokeAPIKey 吊销当前用户的 API 密钥
+func (s *apiKeyService) RevokeAPIKey(ctx *context.Context, req *schema.IDRequest) error {
+	if err := s.checkSessionLogin(ctx); err != nil {
+		return err
+	}
+	userID := ctx.Session().GetID()
+	k, err := s.apiKeyRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取API密钥失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if k == nil || k.UserID != userID {
+		return i18n.E(
+			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.api_key", nil)})
+	}
+
+	revoked, err := s.apiKeyRepo.Revoke(ctx, k.ID, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 吊销API密钥失败: %d %v", s.logPrefix(), k.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if !revoked {
+		return nil
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.APIKey.Revoke",
+		map[string]any{"name": k.Name, "prefix": k.Prefix}))
+	ctx.Logger.Infof("%s 吊销API密钥成功: %s %s", s.logPrefix(), ctx.Session().GetUsername(), k.Prefix)
+	return nil
+}
+
+// Authenticate 校验密钥，返回未吊销且未过期的密钥
+func (s *apiKeyService) Authenticate(ctx *context.Context, key string) (*api_key.APIKey, error) {
+	prefix, ok := parseKey(key)
+	if !ok {
+		return nil, i18n.E(ctx.Context, "user.APIKeyInvalid", nil)
+	}
+	k, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取API密钥失败: %s %v", s.logPrefix(), prefix, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if k == nil || subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(k.KeyHash)) != 1 {
+		ctx.Logger.Warnf("%s API密钥无效: %s", s.logPrefix(), prefix)
+		return nil, i18n.E(ctx.Context, "user.APIKeyInvalid", nil)
+	}
+	if k.IsRevoked() {
+		ctx.Logger.Warnf("%s API密钥已吊销: %s", s.logPrefix(), prefix)
+		return nil, i18n.E(ctx.Context, "user.APIKeyInvalid", nil)
+	}
+	if k.IsExpired(time.Now()) {
+		ctx.Logger.Warnf("%s API密钥已过期: %s", s.logPrefix(), prefix)
+		return nil, i18n.E(ctx.Context, "user.APIKeyExpired", nil)
+	}
+	return k, nil
+}
+
+// CheckScope 检查密钥是否可访问指定请求方法及URL
+//
+// 密钥只能访问授权范围内的接口，公共权限也须显式授权
+func (s *apiKeyService) CheckScope(ctx *context.Context, k *api_key.APIKey, method string, accessURL string) error {
+	perms, err := s.roleSrv.MatchPermissions(ctx, method, accessURL)
+	if err != nil {
+		return err
+	}
+	codes := make([]string, 0, len(perms))
+	for _, p := range perms {
+		codes = append(codes, p.Code)
+	}
+	if !k.HasScope(codes...) {
+		ctx.Logger.Warnf("%s API密钥无权访问: %s %s %s", s.logPrefix(), k.Prefix, method, accessURL)
+		return i18n.E(ctx.Context, "common.PermissionDeny", nil)
+	}
+	return nil
+}
+
+// Touch 记录密钥最近使用时间及 IP，失败仅记录日志
+func (s *apiKeyService) Touch(ctx *context.Context, k *api_key.APIKey) {
+	now, ip := time.Now(), ctx.ClientIP()
+	if k.LastUsedAt != nil && k.LastUsedIP == ip && now.Sub(time.Time(*k.LastUsedAt)) < touchInterval {
+		return
+	}
+	if err := s.apiKeyRepo.UpdateLastUsed(ctx, k.ID, ip, now); err != nil {
+		ctx.Logger.Errorf("%s 记录API密钥使用失败: %s %v", s.logPrefix(), k.Prefix, err)
+	}
+}
+
+// checkSessionLogin 密钥管理须使用访问令牌，避免泄露的密钥派生出新密钥
+func (s *apiKeyService) checkSessionLogin(ctx *context.Context) error {
+	if ctx.APIKey() != nil {
+		return i18n.E(ctx.Context, "user.APIKeyNotAllowed", nil)
+	}
+	return nil
+}
+
+// checkScopes 授权范围须为当前用户拥有的权限（含角色继承的权限）
+func (s *apiKeyService) checkScopes(ctx *context.Context, scopes []string) error {
+	var (
+		granted []string
+		err     error
+		roles   = ctx.Session().GetRoles()
+	)
+	if roles.Has(role.CodeSuperAdmin) {
+		perms, err := s.rolePermissionRepo.GetAllPermissions(ctx)
+		if err != nil {
+			ctx.Logger.Errorf("%s 获取全部权限失败: %v", s.logPrefix(), err)
+			return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		for _, p := range perms {
+			granted = append(granted, p.Code)
+		}
+	} else if granted, err = s.roleSrv.GetEffectivePermissions(ctx, roles.Codes()); err != nil {
+		return err
+	}
+	if denied := util.Difference(scopes, granted); len(denied) > 0 {
+		ctx.Logger.Warnf("%s API密钥授权超出用户权限: %s %v", s.logPrefix(), ctx.Session().GetUsername(), denied)
+		return i18n.E(ctx.Context, "user.APIKeyScopeInvalid", map[string]any{"scopes": strings.Join(denied, ", ")})
+	}
+	return nil
+}
+
+// generateKey 生成完整密钥及其前缀，格式为 gak_<8位前缀>_<40位随机串>
+func generateKey() (key, prefix string, err error) {
+	id, err := util.GenerateRandomString(api_key.PrefixLength - len(api_key.KeyPrefix))
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := util.GenerateRandomString(api_key.SecretLength)
+	if err != nil {
+		return "", "", err
+	}
+	prefix = api_key.KeyPrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+// parseKey 校验密钥格式并返回前缀
+func parseKey(key string) (string, bool) {
+	if len(key) != api_key.PrefixLength+1+api_key.SecretLength ||
+		!strings.HasPrefix(key, api_key.KeyPrefix) || key[api_key.PrefixLength] != '_' {
+		return "", false
+	}
+	return key[:api_key.PrefixLength], true
+}
+
+// hashKey 密钥为高熵随机串，SHA-256 即可防止数据库泄露后被还原
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package api_key
+
+import (
+	"goadmin/internal/model/api_key"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAndParseKey(t *testing.T) {
+	key, prefix, err := generateKey()
+	if err != nil {
+		t.Fatalf("generateKey: %v", err)
+	}
+	if !strings.HasPrefix(key, prefix+"_") || len(prefix) != api_key.PrefixLength {
+		t.Fatalf("unexpected key %q prefix %q", key, prefix)
+	}
+	got, ok := parseKey(key)
+	if !ok || got != prefix {
+		t.Fatalf("parseKey(%q) = %q, %v", key, got, ok)
+	}
+	other := []byte(key)
+	other[len(other)-1] ^= 1
+	if hashKey(key) == hashKey(string(other)) {
+		t.Fatal("hash should differ for different keys")
+	}
+}
+
+func TestParseKeyRejectsMalformed(t *testing.T) {
+	key, _, _ := generateKey()
+	cases := []string{
+		"",
+		key[:len(key)-1],
+		key + "a",
+		"xxx_" + key[4:],
+		key[:api_key.PrefixLength] + "-" + key[api_key.PrefixLength+1:],
+		"eyJhbGciOiJIUzI1NiJ9.e30.sig",
+	}
+	for _, c := range cases {
+		if _, ok := parseKey(c); ok {
+			t.Errorf("parseKey(%q) should fail", c)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package export
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"goadmin/config"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modelexport "goadmin/internal/model/export"
+	exportrepo "goadmin/internal/repository/export"
+	"goadmin/internal/service/operate_log"
+	positionservice "goadmin/internal/service/position"
+	roleservice "goadmin/internal/service/role"
+	tenantservice "goadmin/internal/service/tenant"
+	userservice "goadmin/internal/service/user"
+	"goadmin/pkg/db"
+	"goadmin/pkg/export"
+	"goadmin/pkg/util"
+)
+
+const (
+	// listLimit 导出任务列表返回的最近任务数
+	listLimit = 50
+	// cleanBatch 每批清理的过期任务数
+	cleanBatch = 100
+	// maxErrorLen 失败原因的最大长度
+	maxErrorLen = 512
+	// maxLangLen 保存的 Accept-Language 最大长度
+	maxLangLen = 64
+)
+
+// ExportService 列表导出服务接口
+type ExportService interface {
+	// CreateJob 按列表接口的筛选条件导出资源，行数不多时在请求中直接完成，否则在后台执行
+	CreateJob(ctx *context.Context, resource string, req *modelexport.CreateRequest) (*modelexport.Job, error)
+
+	// ListJobs 获取当前用户最近的导出任务
+	ListJobs(ctx *context.Context) ([]*modelexport.Job, error)
+
+	// GetFile 获取当前用户已完成的导出任务及其文件路径
+	GetFile(ctx *context.Context, id uint64) (*modelexport.Job, string, error)
+
+	// CleanExpired 删除超过保留时长的导出文件，返回清理的任务数
+	CleanExpired(ctx *context.CliContext) (int, error)
+}
+
+// exportService 列表导出服务实现
+type exportService struct {
+	cfg        *config.Config
+	exportRepo exportrepo.Repository
+	runner     *Runner
+	logService operate_log.OperateLogService
+	sources    map[string]Source
+}
+
+// NewExportService 创建列表导出服务实例（Wire 注入）
+func NewExportService(
+	cfg *config.Config,
+	exportRepo exportrepo.Repository,
+	runner *Runner,
+	userSrv userservice.UserService,
+	roleSrv roleservice.RoleService,
+	tenantSrv tenantservice.TenantService,
+	positionSrv positionservice.PositionService,
+	logService operate_log.OperateLogService,
+) ExportService {
+	return &exportService{
+		cfg:        cfg,
+		exportRepo: exportRepo,
+		runner:     runner,
+		logService: logService,
+		sources:    newSources(userSrv, roleSrv, tenantSrv, positionSrv, logService),
+	}
+}
+
+// Deprecated: 使用 NewExportService 替代
+// NewExportService_legacy 创建列表导出服务实例（兼容旧代码，使用全局db）
+//
+// 执行器未运行，超过直接导出行数的任务会被拒绝
+func NewExportService_legacy() ExportService {
+	cfg := config.Get()
+	repo := exportrepo.NewExportRepository(db.GetDB())
+	return NewExportService(
+		cfg,
+		repo,
+		NewRunner(cfg, repo),
+		userservice.NewUserService_legacy(),
+		roleservice.NewRoleService_legacy(),
+		tenantservice.NewTenantService_legacy(),
+		positionservice.NewPositionService_legacy(),
+		operate_log.NewOperateLogService_legacy(),
+	)
+}
+
+func (*exportService) logPrefix() string {
+	return "export-service"
+}
+
+// CreateJob 按列表接口的筛选条件导出资源，行数不多时在请求中直接完成，否则在后台执行
+//
+// 后台任务使用请求上下文的副本，保留当前用户、租户、数据范围及语言，请求结束后仍可使用
+func (s *exportService) CreateJob(
+	ctx *context.Context, resource string, req *modelexport.CreateRequest) (*modelexport.Job, error) {
+	src, ok := s.sources[resource]
+	if !ok || !export.Valid(req.Format) {
+		return nil, i18n.E(ctx.Context, "common.BadParameter", nil)
+	}
+	params, err := src.Bind(ctx)
+	if err != nil {
+		ctx.Logger.Warnf("%s 导出筛选条件无效: %s %v", s.logPrefix(), resource, err)
+		return nil, i18n.E(ctx.Context, "common.BadParameter", nil)
+	}
+	total, err := src.Count(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if maxRows := s.cfg.Export.MaxRowsOrDefault(); total > maxRows {
+		return nil, i18n.E(ctx.Context, "export.TooManyRows", map[string]any{"total": total, "max": maxRows})
+	}
+
+	job := &modelexport.Job{
+		UserID:   ctx.Session().GetID(),
+		Resource: resource,
+		Format:   req.Format,
+		Params:   params,
+		Lang:     truncate(ctx.GetHeader("Accept-Language"), maxLangLen),
+		Status:   modelexport.StatusPending,
+		Total:    total,
+		Instance: s.runner.Instance(),
+	}
+	if err = s.exportRepo.Create(ctx, job); err != nil {
+		ctx.Logger.Errorf("%s 创建导出任务失败: %s %v", s.logPrefix(), resource, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Export.Create", map[string]any{
+		"resource": i18n.T(ctx.Context, "common.item."+resource, nil),
+		"format":   req.Format,
+		"total":    total,
+	}))
+
+	if total <= s.cfg.Export.SyncRowsOrDefault() {
+		if err = s.run(ctx, src, job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	jobCtx := context.New(ctx.Context.Copy())
+	jobCtx.Logger = ctx.Logger
+	bg := *job
+	if !s.runner.Submit(func() { _ = s.run(jobCtx, src, &bg) }) {
+		ctx.Logger.Warnf("%s 导出任务队列已满: %d", s.logPrefix(), job.ID)
+		busy := i18n.E(ctx.Context, "export.Busy", nil)
+		s.finish(ctx, job, busy)
+		return nil, busy
+	}
+	ctx.Logger.Infof("%s 已提交导出任务: %d %s %d 行", s.logPrefix(), job.ID, resource, total)
+	return job, nil
+}
+
+// run 执行导出任务，结果写回 job
+func (s *exportService) run(ctx *context.Context, src Source, job *modelexport.Job) error {
+	job.Status = modelexport.StatusRunning
+	if err := s.exportRepo.UpdateFields(ctx, job.ID, map[string]any{"status": job.Status}); err != nil {
+		ctx.Logger.Errorf("%s 更新导出任务状态失败: %d %v", s.logPrefix(), job.ID, err)
+	}
+	start := time.Now()
+	err := s.write(ctx, src, job)
+	s.finish(ctx, job, err)
+	if err != nil {
+		ctx.Logger.Errorf("%s 导出失败: %d %s %v", s.logPrefix(), job.ID, job.Resource, err)
+		return err
+	}
+	ctx.Logger.Infof("%s 导出完成: %d %s %d 行 %d 字节 耗时 %s",
+		s.logPrefix(), job.ID, job.Resource, job.Rows, job.Size, time.Since(start))
+	return nil
+}
+
+// write 分批读取数据写入文件，先写入临时文件，完整写入后改名
+func (s *exportService) write(ctx *context.Context, src Source, job *modelexport.Job) error {
+	writeErr := i18n.E(ctx.Context, "export.WriteFailed", nil)
+	dir := s.cfg.Export.DirOrDefault()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		ctx.Logger.Errorf("%s 创建导出目录失败: %s %v", s.logPrefix(), dir, err)
+		return writeErr
+	}
+	f, err := os.CreateTemp(dir, "export_*.tmp")
+	if err != nil {
+		ctx.Logger.Errorf("%s 创建导出文件失败: %v", s.logPrefix(), err)
+		return writeErr
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	bw := bufio.NewWriter(f)
+	w, err := export.NewWriter(job.Format, bw)
+	if err == nil {
+		err = w.Write(src.Header(ctx))
+	}
+	if err != nil {
+		_ = f.Close()
+		ctx.Logger.Errorf("%s 写入导出文件失败: %d %v", s.logPrefix(), job.ID, err)
+		return writeErr
+	}
+
+	err = src.Each(ctx, job.Params, s.cfg.Export.ChunkSizeOrDefault(), s.cfg.Export.MaxRowsOrDefault(),
+		func(rows [][]string) error {
+			for _, row := range rows {
+				if err := w.Write(row); err != nil {
+					ctx.Logger.Errorf("%s 写入导出文件失败: %d %v", s.logPrefix(), job.ID, err)
+					return writeErr
+				}
+			}
+			job.Rows += int64(len(rows))
+			if err := s.exportRepo.UpdateFields(ctx, job.ID, map[string]any{"exported_rows": job.Rows}); err != nil {
+				ctx.Logger.Warnf("%s 更新导出进度失败: %d %v", s.logPrefix(), job.ID, err)
+			}
+			return nil
+		})
+	if err != nil {
+		_ = w.Close()
+		_ = f.Close()
+		return err
+	}
+	if err = w.Close(); err == nil {
+		if err = bw.Flush(); err == nil {
+			err = f.Sync()
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		ctx.Logger.Errorf("%s 写入导出文件失败: %d %v", s.logPrefix(), job.ID, err)
+		return writeErr
+	}
+
+	name := fmt.Sprintf("%d_%s.%s", job.ID, job.Resource, job.Format)
+	path := filepath.Join(dir, name)
+	if err = os.Rename(tmp, path); err != nil {
+		ctx.Logger.Errorf("%s 保存导出文件失败: %s %v", s.logPrefix(), path, err)
+		return writeErr
+	}
+	job.File = name
+	if fi, err := os.Stat(path); err == nil {
+		job.Size = fi.Size()
+	}
+	return nil
+}
+
+// finish 记录任务结果，err 不为空时标记为失败
+func (s *exportService) finish(ctx *context.Context, job *modelexport.Job, err error) {
+	now := time.Now()
+	job.FinishedAt = (*util.DateTime)(&now)
+	fields := map[string]any{"exported_rows": job.Rows, "finished_at": now}
+	if err != nil {
+		job.Status, job.Error = modelexport.StatusFailed, truncate(err.Error(), maxErrorLen)
+		fields["status"], fields["error"] = job.Status, job.Error
+	} else {
+		job.Status = modelexport.StatusSucceeded
+		fields["status"], fields["file"], fields["size"] = job.Status, job.File, job.Size
+	}
+	if err = s.exportRepo.UpdateFields(ctx, job.ID, fields); err != nil {
+		ctx.Logger.Errorf("%s 更新导出任务结果失败: %d %v", s.logPrefix(), job.ID, err)
+	}
+}
+
+// ListJobs 获取当前用户最近的导出任务
+func (s *exportService) ListJobs(ctx *context.Context) ([]*modelexport.Job, error) {
+	userID := ctx.Session().GetID()
+	list, err := s.exportRepo.ListByUserID(ctx, userID, listLimit)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取导出任务列表失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if list == nil {
+		list = []*modelexport.Job{}
+	}
+	return list, nil
+}
+
+// GetFile 获取当前用户已完成的导出任务及其文件路径
+func (s *exportService) GetFile(ctx *context.Context, id uint64) (*modelexport.Job, string, error) {
+	job, err := s.exportRepo.GetByID(ctx, id)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取导出任务失败: %d %v", s.logPrefix(), id, err)
+		return nil, "", i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	// 只能下载本人发起的导出
+	if job == nil || job.UserID != ctx.Session().GetID() {
+		return nil, "", i18n.E(ctx.Context, "common.NotFound",
+			map[string]any{"item": i18n.T(ctx.Context, "common.item.export", nil)})
+	}
+	if job.Status != modelexport.StatusSucceeded {
+		return nil, "", i18n.E(ctx.Context, "export.NotReady", nil)
+	}
+	path := filepath.Join(s.cfg.Export.DirOrDefault(), job.File)
+	if _, err = os.Stat(path); err != nil {
+		// 文件写入在执行任务的实例上，导出目录未在各实例间共享时其他实例上不存在
+		ctx.Logger.Errorf("%s 导出文件不存在: %d %s 执行实例 %s 本实例 %s %v",
+			s.logPrefix(), id, path, job.Instance, s.runner.Instance(), err)
+		return nil, "", i18n.E(ctx.Context, "export.NotReady", nil)
+	}
+	return job, path, nil
+}
+
+// CleanExpired 删除超过保留时长的导出文件，返回清理的任务数
+func (s *exportService) CleanExpired(ctx *context.CliContext) (int, error) {
+	dir := s.cfg.Export.DirOrDefault()
+	before := time.Now().Add(-s.cfg.Export.ExpireOrDefault())
+	cleaned := 0
+	for {
+		jobs, err := s.exportRepo.ListExpired(ctx, before, cleanBatch)
+		if err != nil {
+			ctx.Logger.Errorf("%s 获取过期的导出任务失败: %v", s.logPrefix(), err)
+			return cleaned, err
+		}
+		for _, job := range jobs {
+			path := filepath.Join(dir, job.File)
+			if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				ctx.Logger.Errorf("%s 删除导出文件失败: %s %v", s.logPrefix(), path, err)
+				return cleaned, err
+			}
+			err = s.exportRepo.UpdateFields(ctx, job.ID, map[string]any{
+				"status": modelexport.StatusExpired,
+				"file":   "",
+			})
+			if err != nil {
+				ctx.Logger.Errorf("%s 更新过期的导出任务失败: %d %v", s.logPrefix(), job.ID, err)
+				return cleaned, err
+			}
+			cleaned++
+		}
+		if len(jobs) < cleanBatch {
+			break
+		}
+	}
+	if cleaned > 0 {
+		ctx.Logger.Infof("%s 已删除过期的导出文件: %d 个", s.logPrefix(), cleaned)
+	}
+	return cleaned, nil
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package export
+
+import (
+	stdctx "context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goadmin/config"
+	"goadmin/internal/i18n"
+	modelexport "goadmin/internal/model/export"
+	exportrepo "goadmin/internal/repository/export"
+	"goadmin/pkg/logger"
+)
+
+// Runner 导出任务执行器
+//
+// 作为 task.Service 运行，固定数量的协程依次执行队列中的任务，队列已满时拒绝提交。
+// 停止时等待执行中的任务完成，队列中尚未执行的任务在下次启动时标记为失败。
+// 任务记录创建时所在的实例，多实例部署时各实例只处理自己中断的任务
+type Runner struct {
+	repo     exportrepo.Repository
+	queue    chan func()
+	workers  int
+	instance string
+
+	mu      sync.RWMutex // 保护 running
+	running bool
+	started atomic.Bool
+	done    chan struct{}
+}
+
+// NewRunner 创建导出任务执行器
+func NewRunner(cfg *config.Config, repo exportrepo.Repository) *Runner {
+	return &Runner{
+		repo:     repo,
+		queue:    make(chan func(), cfg.Export.QueueSizeOrDefault()),
+		workers:  cfg.Export.WorkersOrDefault(),
+		instance: cfg.Export.InstanceOrDefault(),
+		done:     make(chan struct{}),
+	}
+}
+
+// Instance 本实例的标识
+func (r *Runner) Instance() string {
+	return r.instance
+}
+
+// Name 实现 task.Service 接口
+func (*Runner) Name() string {
+	return "ExportRunner"
+}
+
+func (*Runner) logPrefix() string {
+	return "export-runner"
+}
+
+// Submit 提交任务，未运行或队列已满时返回 false
+func (r *Runner) Submit(fn func()) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.running {
+		return false
+	}
+	select {
+	case r.queue <- fn:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start 实现 task.Service 接口，执行队列中的任务直到 ctx 结束
+func (r *Runner) Start(ctx stdctx.Context) error {
+	r.started.Store(true)
+	defer close(r.done)
+
+	r.failInterrupted()
+	r.mu.Lock()
+	r.running = true
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case fn := <-r.queue:
+					fn()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	r.mu.Lock()
+	r.running = false
+	r.mu.Unlock()
+	wg.Wait()
+	logger.Global().Infof("%s 已停止，未执行的任务 %d 个", r.logPrefix(), len(r.queue))
+	return nil
+}
+
+// Stop 实现 task.Service 接口，等待执行中的任务完成
+func (r *Runner) Stop(ctx stdctx.Context) error {
+	if !r.started.Load() {
+		return nil
+	}
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// failInterrupted 本实例上次运行时未结束的任务已随进程中断，标记为失败，其他实例的任务可能仍在执行
+func (r *Runner) failInterrupted() {
+	ctx := stdctx.Background()
+	jobs, err := r.repo.ListUnfinished(ctx, r.instance)
+	if err != nil {
+		logger.Global().Errorf("%s 获取未结束的导出任务失败: %v", r.logPrefix(), err)
+		return
+	}
+	for _, j := range jobs {
+		err = r.repo.UpdateFields(ctx, j.ID, map[string]any{
+			"status":      modelexport.StatusFailed,
+			"error":       i18n.Translate(j.Lang, "export.Interrupted", nil),
+			"finished_at": time.Now(),
+		})
+		if err != nil {
+			logger.Global().Errorf("%s 标记中断的导出任务失败: %d %v", r.logPrefix(), j.ID, err)
+		}
+	}
+	if len(jobs) > 0 {
+		logger.Global().Warnf("%s 已将中断的导出任务标记为失败: %d 个", r.logPrefix(), len(jobs))
+	}
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	stdctx "context"
+	"testing"
+
+	"goadmin/config"
+	"goadmin/internal/i18n"
+	modelexport "goadmin/internal/model/export"
+	exportrepo "goadmin/internal/repository/export"
+	"goadmin/pkg/db/dbtest"
+)
+
+func TestFailInterruptedOwnInstance(t *testing.T) {
+	i18n.Init()
+	gdb := dbtest.Open(t, &modelexport.Job{})
+	jobs := []*modelexport.Job{
+		{Status: modelexport.StatusRunning, Instance: "a"},
+		{Status: modelexport.StatusPending, Instance: "a"},
+		{Status: modelexport.StatusRunning, Instance: "b"},
+		{Status: modelexport.StatusSucceeded, Instance: "a"},
+	}
+	if err := gdb.Create(&jobs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := exportrepo.NewExportRepository(gdb)
+	r := NewRunner(&config.Config{Export: config.ExportConfig{Instance: "a"}}, repo)
+	r.failInterrupted()
+
+	// 其他实例的任务可能仍在执行，不受影响
+	want := map[uint64]modelexport.Status{
+		1: modelexport.StatusFailed,
+		2: modelexport.StatusFailed,
+		3: modelexport.StatusRunning,
+		4: modelexport.StatusSucceeded,
+	}
+	for id, status := range want {
+		j, err := repo.GetByID(stdctx.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.Status != status {
+			t.Errorf("job %d status = %s, want %s", id, j.Status, status)
+		}
+	}
+}
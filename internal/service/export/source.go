@@ -0,0 +1,127 @@
+package export
+
+import (
+	"encoding/json"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+)
+
+// Source 可导出的列表数据
+type Source interface {
+	// Bind 绑定列表接口的筛选条件，返回 JSON 以保存到导出任务
+	Bind(ctx *context.Context) (string, error)
+
+	// Count 符合筛选条件的行数
+	Count(ctx *context.Context, params string) (int64, error)
+
+	// Header 本地化的表头
+	Header(ctx *context.Context) []string
+
+	// Each 按页读取符合筛选条件的数据，每页转换为行后回调，最多读取 limit 行
+	Each(ctx *context.Context, params string, size int, limit int64, fn func(rows [][]string) error) error
+}
+
+// column 导出列，header 为表头的 i18n 消息ID
+type column[T any] struct {
+	header string
+	value  func(ctx *context.Context, item *T) string
+}
+
+// listSource 基于服务列表方法的数据源
+//
+// 复用列表接口的请求类型 R 及服务方法，导出与列表页面的筛选条件、租户隔离及数据范围一致。
+// 按页码分页读取，导出期间有数据增删时可能出现重复或遗漏的行
+type listSource[R any, T any] struct {
+	list    func(ctx *context.Context, req *R) ([]*T, int64, error)
+	page    func(req *R) *schema.PageRequest
+	columns []column[T]
+}
+
+// newSource 创建基于服务列表方法的数据源
+func newSource[R any, T any](
+	list func(ctx *context.Context, req *R) ([]*T, int64, error),
+	page func(req *R) *schema.PageRequest,
+	columns ...column[T],
+) Source {
+	return &listSource[R, T]{list: list, page: page, columns: columns}
+}
+
+// Bind 实现 Source 接口
+func (s *listSource[R, T]) Bind(ctx *context.Context) (string, error) {
+	req := new(R)
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(req)
+	return string(b), err
+}
+
+// decode 解析保存的筛选条件
+func (s *listSource[R, T]) decode(params string) (*R, error) {
+	req := new(R)
+	if err := json.Unmarshal([]byte(params), req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Count 实现 Source 接口
+func (s *listSource[R, T]) Count(ctx *context.Context, params string) (int64, error) {
+	req, err := s.decode(params)
+	if err != nil {
+		return 0, err
+	}
+	p := s.page(req)
+	p.Page, p.PageSize = 1, 1
+	_, total, err := s.list(ctx, req)
+	return total, err
+}
+
+// Header 实现 Source 接口
+func (s *listSource[R, T]) Header(ctx *context.Context) []string {
+	header := make([]string, len(s.columns))
+	for i, c := range s.columns {
+		header[i] = i18n.T(ctx.Context, c.header, nil)
+	}
+	return header
+}
+
+// Each 实现 Source 接口
+func (s *listSource[R, T]) Each(ctx *context.Context, params string, size int, limit int64, fn func(rows [][]string) error) error {
+	req, err := s.decode(params)
+	if err != nil {
+		return err
+	}
+	p := s.page(req)
+	p.PageSize = size
+	var read int64
+	for p.Page = 1; read < limit; p.Page++ {
+		list, _, err := s.list(ctx, req)
+		if err != nil {
+			return err
+		}
+		if int64(len(list)) > limit-read {
+			list = list[:limit-read]
+		}
+		rows := make([][]string, len(list))
+		for i, item := range list {
+			row := make([]string, len(s.columns))
+			for j, c := range s.columns {
+				row[j] = c.value(ctx, item)
+			}
+			rows[i] = row
+		}
+		if len(rows) > 0 {
+			if err = fn(rows); err != nil {
+				return err
+			}
+		}
+		read += int64(len(list))
+		if len(list) < size {
+			return nil
+		}
+	}
+	return nil
+}
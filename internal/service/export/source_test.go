@@ -0,0 +1,92 @@
+package export
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goadmin/internal/context"
+	"goadmin/internal/model/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+type itemRequest struct {
+	schema.PageRequest
+	Keyword string `form:"keyword"`
+}
+
+type item struct {
+	Name string
+}
+
+// newItemSource 在内存中按关键字筛选并分页的数据源，pages 记录每次读取的页码及每页数量
+func newItemSource(names []string, pages *[][2]int) Source {
+	list := func(_ *context.Context, req *itemRequest) ([]*item, int64, error) {
+		var matched []*item
+		for _, n := range names {
+			if strings.Contains(n, req.Keyword) {
+				matched = append(matched, &item{Name: n})
+			}
+		}
+		*pages = append(*pages, [2]int{req.Page, req.PageSize})
+		start := min((req.Page-1)*req.PageSize, len(matched))
+		end := min(start+req.PageSize, len(matched))
+		return matched[start:end], int64(len(matched)), nil
+	}
+	return newSource(list,
+		func(req *itemRequest) *schema.PageRequest { return &req.PageRequest },
+		column[item]{"export.column.name", func(_ *context.Context, it *item) string { return it.Name }},
+		column[item]{"export.column.length", func(_ *context.Context, it *item) string { return strings.Repeat("*", len(it.Name)) }},
+	)
+}
+
+func TestListSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/export/item?keyword=a&page=3&page_size=2", nil)
+	ctx := context.New(c)
+
+	var pages [][2]int
+	src := newItemSource([]string{"a", "ab", "b", "ca", "da", "ea", "fa"}, &pages)
+	params, err := src.Bind(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 导出全部符合条件的数据，不受列表页面分页的影响
+	total, err := src.Count(ctx, params)
+	if err != nil || total != 6 {
+		t.Fatalf("count = %d, %v", total, err)
+	}
+	// 未加载翻译时输出消息ID
+	if header := src.Header(ctx); !reflect.DeepEqual(header, []string{"export.column.name", "export.column.length"}) {
+		t.Errorf("header = %v", header)
+	}
+
+	pages = nil
+	var rows [][]string
+	err = src.Each(ctx, params, 4, 100, func(batch [][]string) error {
+		rows = append(rows, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 6 || rows[0][0] != "a" || rows[5][0] != "fa" || rows[1][1] != "**" {
+		t.Errorf("rows = %v", rows)
+	}
+	if !reflect.DeepEqual(pages, [][2]int{{1, 4}, {2, 4}}) {
+		t.Errorf("pages = %v", pages)
+	}
+
+	// 超过上限的行不读取
+	pages, rows = nil, nil
+	err = src.Each(ctx, params, 2, 3, func(batch [][]string) error {
+		rows = append(rows, batch...)
+		return nil
+	})
+	if err != nil || len(rows) != 3 || len(pages) != 2 {
+		t.Errorf("limited rows = %v pages = %v err = %v", rows, pages, err)
+	}
+}
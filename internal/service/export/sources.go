@@ -0,0 +1,130 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modelexport "goadmin/internal/model/export"
+	modeloperatelog "goadmin/internal/model/operate_log"
+	"goadmin/internal/model/position"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/tenant"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/internal/service/operate_log"
+	positionservice "goadmin/internal/service/position"
+	roleservice "goadmin/internal/service/role"
+	tenantservice "goadmin/internal/service/tenant"
+	userservice "goadmin/internal/service/user"
+)
+
+// newSources 可导出的资源及其列
+func newSources(
+	userSrv userservice.UserService,
+	roleSrv roleservice.RoleService,
+	tenantSrv tenantservice.TenantService,
+	positionSrv positionservice.PositionService,
+	logSrv operate_log.OperateLogService,
+) map[string]Source {
+	return map[string]Source{
+		modelexport.ResourceUser: newSource(userSrv.ListUsers,
+			func(req *modeluser.ListRequest) *schema.PageRequest { return &req.PageRequest },
+			column[modeluser.User]{"export.column.id", func(_ *context.Context, u *modeluser.User) string { return formatID(u.ID) }},
+			column[modeluser.User]{"export.column.username", func(_ *context.Context, u *modeluser.User) string { return u.Username }},
+			column[modeluser.User]{"export.column.email", func(_ *context.Context, u *modeluser.User) string { return u.Email }},
+			column[modeluser.User]{"export.column.roles", func(_ *context.Context, u *modeluser.User) string { return roleNames(u.Roles) }},
+			column[modeluser.User]{"export.column.status", func(ctx *context.Context, u *modeluser.User) string {
+				return enumText(ctx, "user_status", int(u.Status))
+			}},
+			column[modeluser.User]{"export.column.tenant_id", func(_ *context.Context, u *modeluser.User) string { return formatID(u.TenantID) }},
+			column[modeluser.User]{"export.column.ctime", func(_ *context.Context, u *modeluser.User) string { return u.CTime.String() }},
+		),
+		modelexport.ResourceRole: newSource(roleSrv.ListRoles,
+			func(req *schema.PageRequest) *schema.PageRequest { return req },
+			column[role.Role]{"export.column.id", func(_ *context.Context, r *role.Role) string { return formatID(r.ID) }},
+			column[role.Role]{"export.column.code", func(_ *context.Context, r *role.Role) string { return r.Code }},
+			column[role.Role]{"export.column.name", func(_ *context.Context, r *role.Role) string { return r.Name }},
+			column[role.Role]{"export.column.parent_code", func(_ *context.Context, r *role.Role) string { return r.ParentCode }},
+			column[role.Role]{"export.column.description", func(_ *context.Context, r *role.Role) string { return r.Description }},
+			column[role.Role]{"export.column.status", func(ctx *context.Context, r *role.Role) string {
+				return enumText(ctx, "role_status", int(r.Status))
+			}},
+			column[role.Role]{"export.column.permissions", func(_ *context.Context, r *role.Role) string {
+				names := make([]string, len(r.Permissions))
+				for i, p := range r.Permissions {
+					names[i] = p.Name
+				}
+				return strings.Join(names, ",")
+			}},
+			column[role.Role]{"export.column.ctime", func(_ *context.Context, r *role.Role) string { return r.CTime.String() }},
+		),
+		modelexport.ResourceTenant: newSource(tenantSrv.ListTenants,
+			func(req *tenant.ListRequest) *schema.PageRequest { return &req.PageRequest },
+			column[tenant.Tenant]{"export.column.id", func(_ *context.Context, t *tenant.Tenant) string { return formatID(t.ID) }},
+			column[tenant.Tenant]{"export.column.code", func(_ *context.Context, t *tenant.Tenant) string { return t.Code }},
+			column[tenant.Tenant]{"export.column.name", func(_ *context.Context, t *tenant.Tenant) string { return t.Name }},
+			column[tenant.Tenant]{"export.column.contact_email", func(_ *context.Context, t *tenant.Tenant) string { return t.ContactEmail }},
+			column[tenant.Tenant]{"export.column.contact_phone", func(_ *context.Context, t *tenant.Tenant) string { return t.ContactPhone }},
+			column[tenant.Tenant]{"export.column.status", func(ctx *context.Context, t *tenant.Tenant) string {
+				return enumText(ctx, "tenant_status", int(t.Status))
+			}},
+			column[tenant.Tenant]{"export.column.ctime", func(_ *context.Context, t *tenant.Tenant) string { return t.CTime.String() }},
+		),
+		modelexport.ResourcePosition: newSource(positionSrv.ListPositions,
+			func(req *position.ListRequest) *schema.PageRequest { return &req.PageRequest },
+			column[position.Position]{"export.column.id", func(_ *context.Context, p *position.Position) string { return formatID(p.ID) }},
+			column[position.Position]{"export.column.city", func(_ *context.Context, p *position.Position) string { return p.City }},
+			column[position.Position]{"export.column.location", func(_ *context.Context, p *position.Position) string { return p.Location }},
+			column[position.Position]{"export.column.custom_name", func(_ *context.Context, p *position.Position) string { return p.CustomName }},
+			column[position.Position]{"export.column.longitude", func(_ *context.Context, p *position.Position) string {
+				return strconv.FormatFloat(p.Longitude, 'f', -1, 64)
+			}},
+			column[position.Position]{"export.column.latitude", func(_ *context.Context, p *position.Position) string {
+				return strconv.FormatFloat(p.Latitude, 'f', -1, 64)
+			}},
+			column[position.Position]{"export.column.creator", func(_ *context.Context, p *position.Position) string { return p.Creator }},
+			column[position.Position]{"export.column.ctime", func(_ *context.Context, p *position.Position) string { return p.CTime.String() }},
+		),
+		modelexport.ResourceOperateLog: newSource(logSrv.ListOperateLogs,
+			func(req *modeloperatelog.ListRequest) *schema.PageRequest { return &req.PageRequest },
+			column[modeloperatelog.OperateLog]{"export.column.id", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return formatID(l.ID) }},
+			column[modeloperatelog.OperateLog]{"export.column.ctime", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.CTime.String() }},
+			column[modeloperatelog.OperateLog]{"export.column.username", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.Username }},
+			column[modeloperatelog.OperateLog]{"export.column.action", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.Action }},
+			column[modeloperatelog.OperateLog]{"export.column.resource", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.Resource }},
+			column[modeloperatelog.OperateLog]{"export.column.resource_id", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.ResourceID }},
+			column[modeloperatelog.OperateLog]{"export.column.content", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.Content }},
+			column[modeloperatelog.OperateLog]{"export.column.result", func(ctx *context.Context, l *modeloperatelog.OperateLog) string {
+				return enumText(ctx, "operate_result", int(l.Result))
+			}},
+			column[modeloperatelog.OperateLog]{"export.column.ip", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.IP }},
+			column[modeloperatelog.OperateLog]{"export.column.api_key", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.APIKey }},
+			column[modeloperatelog.OperateLog]{"export.column.trace_id", func(_ *context.Context, l *modeloperatelog.OperateLog) string { return l.TraceID }},
+		),
+	}
+}
+
+func formatID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+// roleNames 以逗号分隔的角色名称
+func roleNames(roles role.Set) string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// enumText 本地化的枚举值，消息ID为 export.value.<kind>.<值>，未定义时输出原值
+func enumText(ctx *context.Context, kind string, v int) string {
+	id := fmt.Sprintf("export.value.%s.%d", kind, v)
+	if text := i18n.T(ctx.Context, id, nil); text != id {
+		return text
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,53 @@
+package operate_log
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	modeloperatelog "goadmin/internal/model/operate_log"
+	"goadmin/pkg/util"
+)
+
+// logEntry 转存文件及归档文件中的一条日志，JSON Lines 格式
+//
+// 时间单独以带时区的格式保存：日志的时间只精确到秒且不带时区，读回后按本地时区写入数据库，
+// 与计算哈希时的墙上时间一致
+type logEntry struct {
+	At  time.Time                   `json:"at"`
+	Log *modeloperatelog.OperateLog `json:"log"`
+}
+
+// encodeLogs 逐行写入日志
+func encodeLogs(w io.Writer, logs []*modeloperatelog.OperateLog) error {
+	enc := json.NewEncoder(w)
+	for _, log := range logs {
+		if err := enc.Encode(logEntry{At: time.Time(log.CTime), Log: log}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeLogs 逐行读取日志，fn 返回错误时停止
+func decodeLogs(r io.Reader, fn func(*modeloperatelog.OperateLog) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var e logEntry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if e.Log == nil {
+			continue
+		}
+		e.Log.CTime = util.DateTime(e.At.Local())
+		e.Log.MTime = e.Log.CTime
+		if err := fn(e.Log); err != nil {
+			return err
+		}
+	}
+}
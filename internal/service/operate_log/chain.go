@@ -0,0 +1,202 @@
+package operate_log
+
+import (
+	"errors"
+	"fmt"
+
+	"goadmin/internal/context"
+	modeloperatelog "goadmin/internal/model/operate_log"
+	"goadmin/pkg/util"
+)
+
+// verifyBatch 校验时每批读取的日志条数
+const verifyBatch = 1000
+
+// chainVerifier 按ID顺序逐条校验哈希链
+type chainVerifier struct {
+	result   modeloperatelog.VerifyResult
+	prev     string
+	started  bool
+	anchored bool                       // 已越过锚点
+	anchor   *modeloperatelog.ChainHead // 链头记录的锚点，校验归档文件时为空
+	hashes   map[uint64]string          // 检查点处日志的哈希
+}
+
+// newChainVerifier 创建校验器，anchor 为链头，checkpoints 为需比对哈希的检查点
+func newChainVerifier(anchor *modeloperatelog.ChainHead, checkpoints []*modeloperatelog.Checkpoint) *chainVerifier {
+	v := &chainVerifier{anchor: anchor, hashes: make(map[uint64]string, len(checkpoints))}
+	for _, cp := range checkpoints {
+		v.hashes[cp.LastID] = ""
+	}
+	return v
+}
+
+// broken 记录首个断裂处
+func (v *chainVerifier) broken(id uint64, format string, args ...any) {
+	if v.result.Broken == nil {
+		v.result.Broken = &modeloperatelog.Broken{ID: id, Reason: fmt.Sprintf(format, args...)}
+	}
+}
+
+// feed 校验一批日志，发现断裂时返回 false
+//
+// 锚点之前的日志（启用哈希链之前的日志及导入的归档）跳过开头没有哈希的，首条有哈希的日志作为起点；
+// 锚点之后的日志均须有哈希，首条须接在锚点哈希之后，链首日志被删除时可以发现
+func (v *chainVerifier) feed(logs []*modeloperatelog.OperateLog) bool {
+	for _, log := range logs {
+		if a := v.anchor; a != nil && !v.anchored && log.ID > a.AnchorID {
+			v.anchored = true
+			if log.PrevHash != a.AnchorHash {
+				v.broken(log.ID, "与锚点 %d 不符，链首日志被删除", a.AnchorID)
+				return false
+			}
+			if !v.started {
+				v.started = true
+				v.result.FirstID = log.ID
+			}
+			v.prev = a.AnchorHash
+		}
+		if !v.started {
+			if log.Hash == "" {
+				v.result.Legacy++
+				continue
+			}
+			v.started = true
+			v.result.FirstID = log.ID
+			v.prev = log.PrevHash
+		}
+		switch {
+		case log.Hash == "":
+			v.broken(log.ID, "缺少哈希")
+		case log.PrevHash != v.prev:
+			v.broken(log.ID, "前一条哈希不符，前一条日志被修改或删除")
+		case log.ComputeHash() != log.Hash:
+			v.broken(log.ID, "哈希不符，日志内容被修改")
+		}
+		if a := v.anchor; a != nil && log.ID == a.AnchorID && log.Hash != a.AnchorHash {
+			v.broken(log.ID, "与锚点哈希不符，日志被改写")
+		}
+		if v.result.Broken != nil {
+			return false
+		}
+		if _, ok := v.hashes[log.ID]; ok {
+			v.hashes[log.ID] = log.Hash
+		}
+		v.prev = log.Hash
+		v.result.Checked++
+		v.result.LastID = log.ID
+	}
+	return true
+}
+
+// finish 全部日志校验后比对链头及检查点，key 为空时不校验检查点签名
+//
+// 检查点须由剩余的日志或锚点证实，早于锚点且日志未导入的检查点同样视为断裂
+func (v *chainVerifier) finish(
+	head *modeloperatelog.ChainHead, checkpoints []*modeloperatelog.Checkpoint, key string) {
+	if v.result.Broken != nil {
+		return
+	}
+	// 链尾之后的日志被删除时，链头仍指向被删除的日志
+	if head.LastID != v.result.LastID || head.Hash != v.prev {
+		v.broken(head.LastID, "链头与最后一条日志不符，链尾日志被删除")
+		return
+	}
+	for _, cp := range checkpoints {
+		if key != "" && !cp.VerifySignature(key) {
+			v.broken(cp.LastID, "检查点 %d 签名无效", cp.ID)
+			return
+		}
+		hash := v.hashes[cp.LastID]
+		if hash == "" && cp.LastID == head.AnchorID {
+			hash = head.AnchorHash
+		}
+		if hash == "" || hash != cp.Hash {
+			v.broken(cp.LastID, "与检查点 %d 不符，日志被改写或删除", cp.ID)
+			return
+		}
+		v.result.Checkpoints++
+	}
+}
+
+// VerifyChain 按ID顺序校验哈希链及检查点
+func (s *operateLogService) VerifyChain(ctx *context.CliContext) (*modeloperatelog.VerifyResult, error) {
+	checkpoints, err := s.logRepo.ListCheckpoints(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取检查点失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	// 先读取链头：校验期间写入的日志在链头之后，不影响结果
+	head, err := s.logRepo.GetChainHead(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取链头失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+
+	v := newChainVerifier(head, checkpoints)
+	err = s.logRepo.Walk(ctx, 0, verifyBatch, func(logs []*modeloperatelog.OperateLog) error {
+		if n := len(logs); logs[n-1].ID > head.LastID {
+			logs = trimAfter(logs, head.LastID)
+		}
+		if len(logs) == 0 || !v.feed(logs) {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		ctx.Logger.Errorf("%s 读取操作日志失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	v.finish(head, checkpoints, s.cfg.Audit.CheckpointKey)
+
+	if v.result.Broken != nil {
+		ctx.Logger.Warnf("%s 哈希链断裂: %d %s", s.logPrefix(), v.result.Broken.ID, v.result.Broken.Reason)
+	}
+	return &v.result, nil
+}
+
+// errStopWalk 提前结束遍历
+var errStopWalk = errors.New("stop walk")
+
+// trimAfter 去掉ID大于 lastID 的日志
+func trimAfter(logs []*modeloperatelog.OperateLog, lastID uint64) []*modeloperatelog.OperateLog {
+	for i, log := range logs {
+		if log.ID > lastID {
+			return logs[:i]
+		}
+	}
+	return logs
+}
+
+// CreateCheckpoint 为当前链尾创建签名检查点
+func (s *operateLogService) CreateCheckpoint(ctx *context.CliContext) error {
+	key := s.cfg.Audit.CheckpointKey
+	if key == "" {
+		return nil
+	}
+	head, err := s.logRepo.GetChainHead(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取链头失败: %v", s.logPrefix(), err)
+		return err
+	}
+	if head.LastID == 0 {
+		return nil
+	}
+	last, err := s.logRepo.GetLastCheckpoint(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取检查点失败: %v", s.logPrefix(), err)
+		return err
+	}
+	if last != nil && last.LastID == head.LastID {
+		return nil
+	}
+
+	cp := &modeloperatelog.Checkpoint{LastID: head.LastID, Hash: head.Hash, CTime: util.Now()}
+	cp.Sign(key)
+	if err = s.logRepo.CreateCheckpoint(ctx, cp); err != nil {
+		ctx.Logger.Errorf("%s 创建检查点失败: %v", s.logPrefix(), err)
+		return err
+	}
+	ctx.Logger.Infof("%s 创建检查点: %d %s", s.logPrefix(), cp.LastID, cp.Hash)
+	return nil
+}
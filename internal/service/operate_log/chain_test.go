@@ -0,0 +1,141 @@
+package operate_log
+
+import (
+	"testing"
+
+	modeloperatelog "goadmin/internal/model/operate_log"
+)
+
+// newChain 生成 n 条已链接的日志，ID 从 first 开始
+func newChain(first uint64, n int) ([]*modeloperatelog.OperateLog, *modeloperatelog.ChainHead) {
+	logs := make([]*modeloperatelog.OperateLog, 0, n)
+	prev := ""
+	for i := 0; i < n; i++ {
+		l := &modeloperatelog.OperateLog{Content: "op", UserID: uint64(i), Result: modeloperatelog.ResultSuccess}
+		l.ID = first + uint64(i)
+		l.Chain(prev)
+		prev = l.Hash
+		logs = append(logs, l)
+	}
+	last := logs[n-1]
+	return logs, &modeloperatelog.ChainHead{ID: modeloperatelog.ChainHeadID, LastID: last.ID, Hash: last.Hash}
+}
+
+func verify(logs []*modeloperatelog.OperateLog, head *modeloperatelog.ChainHead,
+	checkpoints []*modeloperatelog.Checkpoint, key string) modeloperatelog.VerifyResult {
+	v := newChainVerifier(head, checkpoints)
+	v.feed(logs)
+	v.finish(head, checkpoints, key)
+	return v.result
+}
+
+func TestVerifyChain(t *testing.T) {
+	legacy := &modeloperatelog.OperateLog{Content: "old"}
+	legacy.ID = 1
+	logs, head := newChain(2, 5)
+	head.AnchorID = 1
+	cp := &modeloperatelog.Checkpoint{ID: 1, LastID: 4, Hash: logs[2].Hash}
+	cp.Sign("secret")
+	all := append([]*modeloperatelog.OperateLog{legacy}, logs...)
+
+	rs := verify(all, head, []*modeloperatelog.Checkpoint{cp}, "secret")
+	if rs.Broken != nil || rs.Legacy != 1 || rs.Checked != 5 || rs.FirstID != 2 || rs.LastID != 6 || rs.Checkpoints != 1 {
+		t.Fatalf("intact chain = %+v %+v", rs, rs.Broken)
+	}
+
+	// 链首之前的日志已归档时从锚点开始校验，锚点处的检查点由锚点证实
+	head.AnchorID, head.AnchorHash = logs[1].ID, logs[1].Hash
+	anchored := &modeloperatelog.Checkpoint{ID: 2, LastID: logs[1].ID, Hash: logs[1].Hash}
+	if rs = verify(logs[2:], head, []*modeloperatelog.Checkpoint{anchored, cp}, ""); rs.Broken != nil ||
+		rs.Checked != 3 || rs.Checkpoints != 2 {
+		t.Errorf("archived prefix = %+v %+v", rs, rs.Broken)
+	}
+}
+
+func TestVerifyChainAnchor(t *testing.T) {
+	logs, head := newChain(1, 5)
+	head.AnchorID, head.AnchorHash = logs[0].ID, logs[0].Hash
+
+	// 锚点之后的链首日志被删除
+	if rs := verify(logs[2:], head, nil, ""); rs.Broken == nil || rs.Broken.ID != 3 {
+		t.Errorf("leading delete = %+v", rs.Broken)
+	}
+
+	// 锚点之后没有哈希的日志不能冒充启用哈希链之前的日志
+	unhashed := *logs[1]
+	unhashed.Hash, unhashed.PrevHash = "", ""
+	if rs := verify([]*modeloperatelog.OperateLog{&unhashed}, head, nil, ""); rs.Broken == nil || rs.Broken.ID != 2 {
+		t.Errorf("unhashed after cutoff = %+v", rs.Broken)
+	}
+
+	// 早于锚点且日志未导入的检查点无从证实
+	cp := &modeloperatelog.Checkpoint{ID: 1, LastID: 0, Hash: ""}
+	if rs := verify(logs[1:], head, []*modeloperatelog.Checkpoint{cp}, ""); rs.Broken == nil {
+		t.Errorf("checkpoint before anchor = %+v", rs)
+	}
+	cp = &modeloperatelog.Checkpoint{ID: 2, LastID: logs[0].ID, Hash: logs[1].Hash}
+	if rs := verify(logs[1:], head, []*modeloperatelog.Checkpoint{cp}, ""); rs.Broken == nil || rs.Broken.ID != 1 {
+		t.Errorf("checkpoint at anchor = %+v", rs.Broken)
+	}
+}
+
+func TestVerifyChainBroken(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(logs []*modeloperatelog.OperateLog, head *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog
+		want   uint64
+	}{
+		{"modified", func(logs []*modeloperatelog.OperateLog, _ *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog {
+			logs[2].Content = "forged"
+			return logs
+		}, 3},
+		{"rehashed", func(logs []*modeloperatelog.OperateLog, _ *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog {
+			logs[2].Content = "forged"
+			logs[2].Hash = logs[2].ComputeHash()
+			return logs
+		}, 4},
+		{"deleted", func(logs []*modeloperatelog.OperateLog, _ *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog {
+			return append(logs[:1], logs[2:]...)
+		}, 3},
+		{"truncated", func(logs []*modeloperatelog.OperateLog, _ *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog {
+			return logs[:4]
+		}, 5},
+		{"unhashed", func(logs []*modeloperatelog.OperateLog, _ *modeloperatelog.ChainHead) []*modeloperatelog.OperateLog {
+			logs[3].Hash = ""
+			return logs
+		}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, head := newChain(1, 5)
+			rs := verify(tt.tamper(logs, head), head, nil, "")
+			if rs.Broken == nil || rs.Broken.ID != tt.want {
+				t.Errorf("broken = %+v, want id %d", rs.Broken, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	logs, head := newChain(1, 5)
+	cp := &modeloperatelog.Checkpoint{ID: 1, LastID: 3, Hash: logs[2].Hash}
+	cp.Sign("secret")
+
+	// 签名密钥不符
+	if rs := verify(logs, head, []*modeloperatelog.Checkpoint{cp}, "other"); rs.Broken == nil || rs.Broken.ID != 3 {
+		t.Errorf("bad signature = %+v", rs.Broken)
+	}
+
+	// 链头及之后的日志被整体改写，与检查点不符
+	forged, forgedHead := newChain(1, 5)
+	forged[0].Content = "forged"
+	prev := ""
+	for _, l := range forged {
+		l.Chain(prev)
+		prev = l.Hash
+	}
+	forgedHead.Hash = prev
+	if rs := verify(forged, forgedHead, []*modeloperatelog.Checkpoint{cp}, "secret"); rs.Broken == nil || rs.Broken.ID != 3 {
+		t.Errorf("rewritten chain = %+v", rs.Broken)
+	}
+}
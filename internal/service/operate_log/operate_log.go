@@ -1,11 +1,27 @@
 package operate_log
 
 import (
+	stdctx "context"
+	"encoding/json"
+
+	"goadmin/config"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
 	modeloperatelog "goadmin/internal/model/operate_log"
+	"goadmin/internal/model/schema"
 	operatelogrepo "goadmin/internal/repository/operate_log"
 	"goadmin/pkg/db"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/trace"
+	"goadmin/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 字段长度上限，与表结构一致
+const (
+	maxContentLen   = 512
+	maxUserAgentLen = 255
 )
 
 // OperateLogService 操作日志服务接口
@@ -13,28 +29,56 @@ type OperateLogService interface {
 	// ListOperateLogs 获取操作日志列表
 	ListOperateLogs(ctx *context.Context, req *modeloperatelog.ListRequest) ([]*modeloperatelog.OperateLog, int64, error)
 
-	// CreateOperateLog 创建操作日志
+	// CreateOperateLog 创建操作日志，由写入器异步写入
 	//
 	// @param operator  操作人
 	CreateOperateLog(ctx *context.Context, content string, operator ...string) error
+
+	// Audit 记录模型变更的审计事件，作为 db.AuditSink 使用，由写入器异步写入
+	Audit(ctx stdctx.Context, e *db.AuditEvent)
+
+	// WriterStats 获取异步写入的累计统计
+	WriterStats() WriterStats
+
+	// VerifyChain 按ID顺序校验哈希链及检查点，返回首个断裂处
+	VerifyChain(ctx *context.CliContext) (*modeloperatelog.VerifyResult, error)
+
+	// CreateCheckpoint 为当前链尾创建签名检查点，未配置签名密钥或链尾未变化时跳过
+	CreateCheckpoint(ctx *context.CliContext) error
+
+	// ApplyRetention 按保留策略归档并删除过期日志，未配置保留策略时不处理
+	ApplyRetention(ctx *context.CliContext) (*modeloperatelog.RetentionResult, error)
+
+	// RestoreArchive 校验并按原ID导入归档文件，已存在的日志跳过
+	RestoreArchive(ctx *context.CliContext, path string) (*modeloperatelog.RestoreResult, error)
+
+	// MaintainPartitions 表按月分区时（PostgreSQL）提前创建分区
+	MaintainPartitions(ctx *context.CliContext) error
 }
 
 // operateLogService 操作日志服务实现
 type operateLogService struct {
+	cfg     *config.Config
 	logRepo operatelogrepo.OperateLogRepository
+	writer  *Writer
 }
 
 // NewOperateLogService 创建操作日志服务实例（Wire 注入）
-func NewOperateLogService(logRepo operatelogrepo.OperateLogRepository) OperateLogService {
+func NewOperateLogService(
+	cfg *config.Config, logRepo operatelogrepo.OperateLogRepository, writer *Writer) OperateLogService {
 	return &operateLogService{
+		cfg:     cfg,
 		logRepo: logRepo,
+		writer:  writer,
 	}
 }
 
-// Deprecated: 使用 NewOperateLogService(logRepo) 替代
-// NewOperateLogService_legacy 创建操作日志服务实例（兼容旧代码，使用全局db）
+// Deprecated: 使用 NewOperateLogService(cfg, logRepo, writer) 替代
+// NewOperateLogService_legacy 创建操作日志服务实例（兼容旧代码，使用全局db，同步写入）
 func NewOperateLogService_legacy() OperateLogService {
-	return NewOperateLogService(operatelogrepo.NewOperateLogRepository(db.GetDB()))
+	cfg := config.Get()
+	logRepo := operatelogrepo.NewOperateLogRepository(db.GetDB())
+	return NewOperateLogService(cfg, logRepo, NewWriter(cfg, logRepo))
 }
 
 func (*operateLogService) logPrefix() string {
@@ -55,34 +99,86 @@ func (s *operateLogService) ListOperateLogs(ctx *context.Context, req *modeloper
 }
 
 // CreateOperateLog 创建操作日志
+//
+// 日志归属于当前租户，写入失败由写入器转存重试，不影响请求
 func (s *operateLogService) CreateOperateLog(ctx *context.Context, content string, operator ...string) error {
-	// 获取客户端IP
-	clientIP := ctx.ClientIP()
-
-	// 获取用户名
-	username := ""
+	log := newLog(ctx.Context)
+	log.TenantID, _ = db.TenantFrom(ctx)
+	log.Content = truncate(content, maxContentLen)
 	if len(operator) > 0 {
-		username = operator[0]
-	} else {
-		session := ctx.Session()
-		if session != nil {
-			username = session.GetUsername()
-		}
+		log.Username = operator[0]
+		log.UserID = 0
 	}
 
-	// 创建操作日志
+	s.writer.Write(log)
+	ctx.Logger.Infof("%s 提交操作日志: %s", s.logPrefix(), content)
+	return nil
+}
+
+// Audit 记录模型变更的审计事件
+//
+// 操作人、IP、UA 及跟踪ID取自请求上下文，命令行及定时任务中为空；日志归属于变更记录所在的租户
+func (s *operateLogService) Audit(ctx stdctx.Context, e *db.AuditEvent) {
 	log := &modeloperatelog.OperateLog{
-		Content:  content,
-		Username: username,
-		IP:       clientIP,
+		BaseModel: schema.BaseModel{CTime: util.Now()},
+		Result:    modeloperatelog.ResultSuccess,
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+		log = newLog(c)
+	}
+	log.TenantID = e.TenantID
+	log.Action = e.Action
+	log.Resource = e.Resource
+	log.ResourceID = e.ResourceID
+	if len(e.Diff) > 0 {
+		diff, err := json.Marshal(e.Diff)
+		if err != nil {
+			logger.Global().Errorf("%s 序列化变更失败: %s %s %v", s.logPrefix(), e.Resource, e.ResourceID, err)
+		}
+		log.Diff = string(diff)
+	}
+	if e.Err != nil {
+		log.Result = modeloperatelog.ResultFailure
+		log.Content = truncate(e.Err.Error(), maxContentLen)
 	}
 
-	err := s.logRepo.CreateLog(ctx, log)
-	if err != nil {
-		ctx.Logger.Errorf("%s 创建操作日志失败: %v", s.logPrefix(), err)
-		return err
+	s.writer.Write(log)
+}
+
+// WriterStats 获取异步写入的累计统计
+func (s *operateLogService) WriterStats() WriterStats {
+	return s.writer.Stats()
+}
+
+// newLog 按请求填充操作人、IP、UA 及跟踪ID
+//
+// 日志异步写入，写入时请求已结束，所需信息须在此时取出
+func newLog(c *gin.Context) *modeloperatelog.OperateLog {
+	log := &modeloperatelog.OperateLog{
+		BaseModel: schema.BaseModel{CTime: util.Now()},
+		Result:    modeloperatelog.ResultSuccess,
+		IP:        c.ClientIP(),
+		TraceID:   trace.GetTraceValue(c),
+	}
+	if c.Request != nil {
+		log.UserAgent = truncate(c.Request.UserAgent(), maxUserAgentLen)
+	}
+	ctx := &context.Context{Context: c}
+	if session := ctx.Session(); session != nil {
+		log.UserID = session.GetID()
+		log.Username = session.GetUsername()
 	}
+	if key := ctx.APIKey(); key != nil {
+		log.APIKeyID = key.GetID()
+		log.APIKey = key.GetPrefix()
+	}
+	return log
+}
 
-	ctx.Logger.Infof("%s 创建操作日志成功: %s", s.logPrefix(), content)
-	return nil
+// truncate 按字符截断
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
 }
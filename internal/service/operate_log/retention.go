@@ -0,0 +1,220 @@
+package operate_log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"goadmin/internal/context"
+	modeloperatelog "goadmin/internal/model/operate_log"
+)
+
+// archiveBatch 归档、删除及导入时每批处理的日志条数
+const archiveBatch = 1000
+
+// partitionMonths 提前创建的分区月数，含当月
+const partitionMonths = 3
+
+// ApplyRetention 按保留策略归档并删除过期日志
+//
+// 过期日志按ID顺序先写入 gzip 压缩的 JSON Lines 归档文件，归档文件完整写入后再分批删除。
+// 只删除链首的一段，链头指向的日志始终保留，剩余的链仍可校验
+func (s *operateLogService) ApplyRetention(ctx *context.CliContext) (*modeloperatelog.RetentionResult, error) {
+	rs := &modeloperatelog.RetentionResult{}
+	cutoff, err := s.retentionCutoff(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 计算过期日志失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	if cutoff == 0 {
+		return rs, nil
+	}
+
+	if err = s.archive(ctx, cutoff, rs); err != nil {
+		ctx.Logger.Errorf("%s 归档过期日志失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	if rs.Archive == "" {
+		return rs, nil
+	}
+	for lo := rs.FirstID - 1; lo < rs.LastID; {
+		hi := min(lo+archiveBatch, rs.LastID)
+		n, err := s.logRepo.DeleteRange(ctx, lo, hi)
+		if err != nil {
+			ctx.Logger.Errorf("%s 删除过期日志失败: %d - %d %v", s.logPrefix(), lo+1, hi, err)
+			return rs, err
+		}
+		rs.Deleted += n
+		lo = hi
+	}
+	ctx.Logger.Infof("%s 已归档并删除过期日志: %d 条 (ID %d - %d) %s",
+		s.logPrefix(), rs.Deleted, rs.FirstID, rs.LastID, rs.Archive)
+	return rs, nil
+}
+
+// retentionCutoff 可删除的最大日志ID，天数及条数均配置时取删除较多者
+func (s *operateLogService) retentionCutoff(ctx *context.CliContext) (uint64, error) {
+	audit := s.cfg.Audit
+	var cutoff uint64
+	if audit.RetentionDays > 0 {
+		id, err := s.logRepo.LastIDBefore(ctx, time.Now().AddDate(0, 0, -audit.RetentionDays))
+		if err != nil {
+			return 0, err
+		}
+		cutoff = max(cutoff, id)
+	}
+	if audit.RetentionRows > 0 {
+		id, err := s.logRepo.LastIDBeyond(ctx, audit.RetentionRows)
+		if err != nil {
+			return 0, err
+		}
+		cutoff = max(cutoff, id)
+	}
+	if cutoff == 0 {
+		return 0, nil
+	}
+
+	head, err := s.logRepo.GetChainHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if head.LastID > 0 && cutoff >= head.LastID {
+		cutoff = head.LastID - 1
+	}
+	return cutoff, nil
+}
+
+// archive 将ID不大于 cutoff 的日志写入归档文件，先写入临时文件，完整写入后改名
+func (s *operateLogService) archive(ctx *context.CliContext, cutoff uint64, rs *modeloperatelog.RetentionResult) error {
+	dir := s.cfg.Audit.ArchiveDirOrDefault()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "operate_log_*.jsonl.gz.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	gz := gzip.NewWriter(f)
+	err = s.logRepo.Walk(ctx, 0, archiveBatch, func(logs []*modeloperatelog.OperateLog) error {
+		if logs = trimAfter(logs, cutoff); len(logs) == 0 {
+			return errStopWalk
+		}
+		if rs.FirstID == 0 {
+			rs.FirstID = logs[0].ID
+		}
+		rs.LastID = logs[len(logs)-1].ID
+		if err := encodeLogs(gz, logs); err != nil {
+			return err
+		}
+		if rs.LastID >= cutoff {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		_ = f.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if rs.FirstID == 0 {
+		return nil
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("operate_log_%d_%d_%s.jsonl.gz",
+		rs.FirstID, rs.LastID, time.Now().Format("20060102150405")))
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	rs.Archive = path
+	return nil
+}
+
+// RestoreArchive 校验并导入归档文件
+//
+// 先完整校验文件中各日志的哈希及链接，全部通过后按原ID导入，导入的日志不改变链头；
+// 紧接在现有首条日志之前的归档导入后与现有日志连成一条链，可一并校验
+func (s *operateLogService) RestoreArchive(ctx *context.CliContext, path string) (*modeloperatelog.RestoreResult, error) {
+	rs := &modeloperatelog.RestoreResult{}
+	v := newChainVerifier(nil, nil)
+	err := readArchive(path, func(logs []*modeloperatelog.OperateLog) error {
+		rs.Total += int64(len(logs))
+		if !v.feed(logs) {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		ctx.Logger.Errorf("%s 读取归档文件失败: %s %v", s.logPrefix(), path, err)
+		return nil, err
+	}
+	if b := v.result.Broken; b != nil {
+		ctx.Logger.Warnf("%s 归档文件校验失败: %s %d %s", s.logPrefix(), path, b.ID, b.Reason)
+		return nil, fmt.Errorf("归档文件中的日志 %d 校验失败: %s", b.ID, b.Reason)
+	}
+
+	err = readArchive(path, func(logs []*modeloperatelog.OperateLog) error {
+		n, err := s.logRepo.Restore(ctx, logs)
+		rs.Restored += n
+		return err
+	})
+	rs.Skipped = rs.Total - rs.Restored
+	if err != nil {
+		ctx.Logger.Errorf("%s 导入归档文件失败: %s %v", s.logPrefix(), path, err)
+		return rs, err
+	}
+	ctx.Logger.Infof("%s 已导入归档文件: %s 导入 %d 条，跳过 %d 条", s.logPrefix(), path, rs.Restored, rs.Skipped)
+	return rs, nil
+}
+
+// readArchive 分批读取归档文件中的日志
+func readArchive(path string, fn func([]*modeloperatelog.OperateLog) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	logs := make([]*modeloperatelog.OperateLog, 0, archiveBatch)
+	err = decodeLogs(gz, func(log *modeloperatelog.OperateLog) error {
+		if logs = append(logs, log); len(logs) < archiveBatch {
+			return nil
+		}
+		err := fn(logs)
+		logs = make([]*modeloperatelog.OperateLog, 0, archiveBatch)
+		return err
+	})
+	if err != nil || len(logs) == 0 {
+		return err
+	}
+	return fn(logs)
+}
+
+// MaintainPartitions 表按月分区时提前创建当月及之后的分区
+func (s *operateLogService) MaintainPartitions(ctx *context.CliContext) error {
+	if err := s.logRepo.EnsurePartitions(ctx, time.Now(), partitionMonths); err != nil {
+		ctx.Logger.Errorf("%s 创建分区失败: %v", s.logPrefix(), err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package operate_log
+
+import (
+	"context"
+	"testing"
+
+	"goadmin/config"
+	cusCtx "goadmin/internal/context"
+	modeloperatelog "goadmin/internal/model/operate_log"
+	operatelogrepo "goadmin/internal/repository/operate_log"
+	"goadmin/pkg/db/dbtest"
+)
+
+// newRetentionService 使用内存数据库的操作日志服务，保留最新 rows 条
+func newRetentionService(t *testing.T, rows int64) (OperateLogService, operatelogrepo.OperateLogRepository) {
+	t.Helper()
+	gdb := dbtest.Open(t, &modeloperatelog.OperateLog{}, &modeloperatelog.ChainHead{}, &modeloperatelog.Checkpoint{})
+	if err := gdb.Exec("INSERT INTO operate_log_chain (id, last_id, hash) VALUES (1, 0, '')").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Audit: config.AuditConfig{
+		CheckpointKey: "secret",
+		RetentionRows: rows,
+		ArchiveDir:    t.TempDir(),
+	}}
+	repo := operatelogrepo.NewOperateLogRepository(gdb)
+	return NewOperateLogService(cfg, repo, NewWriter(cfg, repo)), repo
+}
+
+func TestRetentionAndRestore(t *testing.T) {
+	svc, repo := newRetentionService(t, 2)
+	// 不放回池中：sqlite 驱动在语句结束后仍可能异步访问上下文
+	ctx := cusCtx.NewCliContext(context.Background())
+	t.Cleanup(ctx.CancelFunc)
+
+	for _, c := range []string{"a", "b", "c", "d", "e"} {
+		if err := repo.BatchCreate(ctx, []*modeloperatelog.OperateLog{{Content: c}}); err != nil {
+			t.Fatal(err)
+		}
+		// 早于锚点的检查点随归档的日志删除，锚点处的检查点由锚点证实
+		if err := svc.CreateCheckpoint(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs, err := svc.ApplyRetention(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Archive == "" || rs.FirstID != 1 || rs.LastID != 3 || rs.Deleted != 3 {
+		t.Fatalf("retention = %+v", rs)
+	}
+	archive := rs.Archive
+	vr, err := svc.VerifyChain(ctx)
+	if err != nil || vr.Broken != nil || vr.FirstID != 4 || vr.Checked != 2 {
+		t.Fatalf("verify after retention = %+v %v", vr, err)
+	}
+
+	// 没有新的过期日志
+	if rs, err = svc.ApplyRetention(ctx); err != nil || rs.Archive != "" || rs.Deleted != 0 {
+		t.Errorf("second retention = %+v %v", rs, err)
+	}
+
+	restored, err := svc.RestoreArchive(ctx, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Total != 3 || restored.Restored != 3 || restored.Skipped != 0 {
+		t.Errorf("restore = %+v", restored)
+	}
+	vr, err = svc.VerifyChain(ctx)
+	if err != nil || vr.Broken != nil || vr.FirstID != 1 || vr.Checked != 5 || vr.Checkpoints != 3 {
+		t.Errorf("verify after restore = %+v %+v %v", vr, vr.Broken, err)
+	}
+}
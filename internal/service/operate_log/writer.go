@@ -0,0 +1,391 @@
+package operate_log
+
+import (
+	"bytes"
+	stdctx "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goadmin/config"
+	modeloperatelog "goadmin/internal/model/operate_log"
+	operatelogrepo "goadmin/internal/repository/operate_log"
+	"goadmin/pkg/logger"
+)
+
+// WriterStats 异步写入的累计统计
+type WriterStats struct {
+	Pending  int   `json:"pending"`  // 队列中待写入的条数
+	Written  int64 `json:"written"`  // 已写入数据库的条数，含重新写入的
+	Dropped  int64 `json:"dropped"`  // 队列已满而丢弃的条数
+	Spooled  int64 `json:"spooled"`  // 写入失败转存到本地文件的条数
+	Retried  int64 `json:"retried"`  // 从本地文件重新写入数据库的条数
+	Lost     int64 `json:"lost"`     // 转存本地文件也失败而丢失的条数
+	Rejected int64 `json:"rejected"` // 逐条重新写入仍失败而移到隔离文件的条数
+}
+
+// rejectedSuffix 隔离文件在本地文件名后追加的后缀，存放无法写入数据库的日志供人工处理
+const rejectedSuffix = ".rejected"
+
+// Writer 操作日志异步写入器
+//
+// 日志先进入有界队列，由后台按批写入：满一批或到达写入间隔时写入一次，队列已满时丢弃。
+// 写入失败时转存到本地文件，之后每个间隔尝试重新写入。作为 task.Service 运行，停止时写完队列中的日志；
+// 未运行时（如命令行）同步写入
+type Writer struct {
+	repo     operatelogrepo.OperateLogRepository
+	queue    chan *modeloperatelog.OperateLog
+	batch    int
+	interval time.Duration
+	spool    string
+
+	mu      sync.RWMutex // 保护 running，停止时与入队互斥，保证停止后队列中不再有日志
+	running bool
+	started atomic.Bool
+	done    chan struct{}
+	spoolMu sync.Mutex // 串行读写本地文件
+
+	written, dropped, spooled, retried, lost, rejected atomic.Int64
+}
+
+// NewWriter 创建操作日志异步写入器
+func NewWriter(cfg *config.Config, repo operatelogrepo.OperateLogRepository) *Writer {
+	return &Writer{
+		repo:     repo,
+		queue:    make(chan *modeloperatelog.OperateLog, cfg.Audit.BufferSizeOrDefault()),
+		batch:    cfg.Audit.BatchSizeOrDefault(),
+		interval: cfg.Audit.FlushIntervalOrDefault(),
+		spool:    cfg.Audit.SpoolFileOrDefault(),
+		done:     make(chan struct{}),
+	}
+}
+
+// Name 实现 task.Service 接口
+func (*Writer) Name() string {
+	return "OperateLogWriter"
+}
+
+func (*Writer) logPrefix() string {
+	return "operate-log-writer"
+}
+
+// Write 提交日志，不等待写入完成
+func (w *Writer) Write(log *modeloperatelog.OperateLog) {
+	w.mu.RLock()
+	if !w.running {
+		w.mu.RUnlock()
+		w.flush([]*modeloperatelog.OperateLog{log})
+		return
+	}
+	defer w.mu.RUnlock()
+	select {
+	case w.queue <- log:
+	default:
+		w.dropped.Add(1)
+		logger.Global().Warnf("%s 队列已满，丢弃操作日志: %s %s %s %s",
+			w.logPrefix(), log.Action, log.Resource, log.ResourceID, log.Content)
+	}
+}
+
+// Start 实现 task.Service 接口，按批写入队列中的日志直到 ctx 结束
+func (w *Writer) Start(ctx stdctx.Context) error {
+	w.started.Store(true)
+	defer close(w.done)
+
+	// 先写入上次未能写入的日志
+	w.replay()
+	w.mu.Lock()
+	w.running = true
+	w.mu.Unlock()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	logs := make([]*modeloperatelog.OperateLog, 0, w.batch)
+	for {
+		select {
+		case log := <-w.queue:
+			if logs = append(logs, log); len(logs) >= w.batch {
+				w.flush(logs)
+				logs = make([]*modeloperatelog.OperateLog, 0, w.batch)
+			}
+		case <-ticker.C:
+			if len(logs) > 0 {
+				w.flush(logs)
+				logs = make([]*modeloperatelog.OperateLog, 0, w.batch)
+			}
+			w.replay()
+		case <-ctx.Done():
+			w.drain(logs)
+			return nil
+		}
+	}
+}
+
+// drain 停止入队并写完队列中的日志，之后提交的日志同步写入
+func (w *Writer) drain(logs []*modeloperatelog.OperateLog) {
+	w.mu.Lock()
+	w.running = false
+	w.mu.Unlock()
+
+	for {
+		select {
+		case log := <-w.queue:
+			if logs = append(logs, log); len(logs) >= w.batch {
+				w.flush(logs)
+				logs = make([]*modeloperatelog.OperateLog, 0, w.batch)
+			}
+		default:
+			w.flush(logs)
+			stats := w.Stats()
+			logger.Global().Infof("%s 已停止: 写入 %d 丢弃 %d 转存 %d 重新写入 %d 丢失 %d 隔离 %d",
+				w.logPrefix(), stats.Written, stats.Dropped, stats.Spooled, stats.Retried, stats.Lost, stats.Rejected)
+			return
+		}
+	}
+}
+
+// Stop 实现 task.Service 接口，等待队列中的日志写完
+func (w *Writer) Stop(ctx stdctx.Context) error {
+	if !w.started.Load() {
+		return nil
+	}
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 获取累计统计
+func (w *Writer) Stats() WriterStats {
+	return WriterStats{
+		Pending:  len(w.queue),
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Spooled:  w.spooled.Load(),
+		Retried:  w.retried.Load(),
+		Lost:     w.lost.Load(),
+		Rejected: w.rejected.Load(),
+	}
+}
+
+// flush 写入一批日志，失败时转存到本地文件
+func (w *Writer) flush(logs []*modeloperatelog.OperateLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := w.repo.BatchCreate(stdctx.Background(), logs); err != nil {
+		logger.Global().Errorf("%s 写入操作日志失败，转存到本地文件: %d 条 %v", w.logPrefix(), len(logs), err)
+		w.spoolLogs(logs)
+		return
+	}
+	w.written.Add(int64(len(logs)))
+}
+
+// spoolLogs 将日志追加到本地文件
+func (w *Writer) spoolLogs(logs []*modeloperatelog.OperateLog) {
+	w.spoolMu.Lock()
+	defer w.spoolMu.Unlock()
+	if err := writeSpool(w.spool, logs, os.O_APPEND); err != nil {
+		w.lost.Add(int64(len(logs)))
+		logger.Global().Errorf("%s 转存操作日志失败，%d 条日志丢失: %v", w.logPrefix(), len(logs), err)
+		return
+	}
+	w.spooled.Add(int64(len(logs)))
+}
+
+// replay 重新写入本地文件中的日志，写入失败的留待下次
+//
+// 一批写入失败时逐条重试：整批均失败视为数据库仍不可用，其余的留待下次；部分成功时仍失败的日志
+// 无法写入（如内容超出列长度），移到隔离文件，不阻塞之后转存的日志。
+// 无法解析的行（如进程在写入中途退出留下的半行）先原样移到隔离文件，移动失败时本次不重新写入，文件保持不变
+func (w *Writer) replay() {
+	w.spoolMu.Lock()
+	defer w.spoolMu.Unlock()
+	logs, bad, err := readSpool(w.spool)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		logger.Global().Errorf("%s 读取本地文件失败: %v", w.logPrefix(), err)
+		return
+	}
+	if len(bad) > 0 {
+		path := w.spool + rejectedSuffix
+		if err = appendLines(path, bad); err != nil {
+			logger.Global().Errorf("%s 本地文件中 %d 行无法解析，移到隔离文件失败，稍后重试: %v", w.logPrefix(), len(bad), err)
+			return
+		}
+		w.rejected.Add(int64(len(bad)))
+		logger.Global().Errorf("%s 本地文件中 %d 行无法解析，已移到隔离文件: %s", w.logPrefix(), len(bad), path)
+	}
+
+	n := 0
+	var rejected []*modeloperatelog.OperateLog
+	for n < len(logs) {
+		end := min(n+w.batch, len(logs))
+		if err = w.repo.BatchCreate(stdctx.Background(), logs[n:end]); err == nil {
+			w.retried.Add(int64(end - n))
+			w.written.Add(int64(end - n))
+			n = end
+			continue
+		}
+		failed, ok := w.replayEach(logs[n:end])
+		if !ok {
+			logger.Global().Warnf("%s 重新写入操作日志失败，稍后重试: 剩余 %d 条 %v", w.logPrefix(), len(logs)-n, err)
+			break
+		}
+		rejected = append(rejected, failed...)
+		n = end
+	}
+	if len(rejected) > 0 {
+		w.reject(rejected)
+	}
+	if n == len(logs) {
+		if err = os.Remove(w.spool); err != nil {
+			logger.Global().Errorf("%s 删除本地文件失败: %v", w.logPrefix(), err)
+		}
+		return
+	}
+	// 无法解析的行已移走，即使没有写入任何日志也要重写文件，避免下次重复隔离
+	if n > 0 || len(bad) > 0 {
+		if err = writeSpool(w.spool, logs[n:], os.O_TRUNC); err != nil {
+			logger.Global().Errorf("%s 更新本地文件失败: %v", w.logPrefix(), err)
+		}
+	}
+}
+
+// replayEach 逐条重新写入一批日志，返回仍失败的日志；全部失败时返回 false
+func (w *Writer) replayEach(logs []*modeloperatelog.OperateLog) ([]*modeloperatelog.OperateLog, bool) {
+	var failed []*modeloperatelog.OperateLog
+	for _, log := range logs {
+		// 失败的事务中日志可能已被分配ID并计算哈希
+		unchain(log)
+		if err := w.repo.BatchCreate(stdctx.Background(), []*modeloperatelog.OperateLog{log}); err != nil {
+			unchain(log)
+			failed = append(failed, log)
+			continue
+		}
+		w.retried.Add(1)
+		w.written.Add(1)
+	}
+	return failed, len(failed) < len(logs)
+}
+
+// reject 将无法写入的日志追加到隔离文件
+func (w *Writer) reject(logs []*modeloperatelog.OperateLog) {
+	path := w.spool + rejectedSuffix
+	if err := writeSpool(path, logs, os.O_APPEND); err != nil {
+		w.lost.Add(int64(len(logs)))
+		logger.Global().Errorf("%s 隔离无法写入的操作日志失败，%d 条日志丢失: %v", w.logPrefix(), len(logs), err)
+		return
+	}
+	w.rejected.Add(int64(len(logs)))
+	logger.Global().Errorf("%s 操作日志逐条重新写入仍失败，已移到隔离文件: %d 条 %s", w.logPrefix(), len(logs), path)
+}
+
+// writeSpool 写入本地文件，flag 为 os.O_APPEND 或 os.O_TRUNC
+func writeSpool(path string, logs []*modeloperatelog.OperateLog, flag int) error {
+	f, err := openSpool(path, flag)
+	if err != nil {
+		return err
+	}
+	if err = encodeLogs(f, logs); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// appendLines 将无法解析的行原样追加到文件
+func appendLines(path string, lines [][]byte) error {
+	f, err := openSpool(path, os.O_APPEND)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			line = append(line[:len(line):len(line)], '\n')
+		}
+		if _, err = f.Write(line); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// openSpool 打开本地文件，追加时若文件末尾是不完整的行先补上换行，之后写入的日志从新行开始
+func openSpool(path string, flag int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|flag, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if flag != os.O_APPEND {
+		return f, nil
+	}
+	fi, err := f.Stat()
+	if err == nil && fi.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err = f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			_, err = f.Write([]byte("\n"))
+		}
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// readSpool 逐行读取本地文件中的日志，返回解析出的日志及无法解析的行
+//
+// 进程在写入中途退出时留下的半行只影响该行，之后追加的日志从新行开始，仍可读取。
+// 写入失败的事务中日志可能已被分配ID并计算哈希，读回后清除，重新写入时接在链尾
+func readSpool(path string) ([]*modeloperatelog.OperateLog, [][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		logs []*modeloperatelog.OperateLog
+		bad  [][]byte
+	)
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i+1], data[i+1:]
+		} else {
+			data = nil
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var parsed []*modeloperatelog.OperateLog
+		err = decodeLogs(bytes.NewReader(line), func(log *modeloperatelog.OperateLog) error {
+			unchain(log)
+			parsed = append(parsed, log)
+			return nil
+		})
+		if err != nil || len(parsed) != 1 {
+			bad = append(bad, line)
+			continue
+		}
+		logs = append(logs, parsed...)
+	}
+	return logs, bad, nil
+}
+
+// unchain 清除写入时分配的ID及哈希
+func unchain(log *modeloperatelog.OperateLog) {
+	log.ID = 0
+	log.PrevHash, log.Hash = "", ""
+}
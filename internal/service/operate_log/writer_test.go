@@ -0,0 +1,243 @@
+package operate_log
+
+import (
+	stdctx "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/config"
+	modeloperatelog "goadmin/internal/model/operate_log"
+	"goadmin/internal/model/schema"
+	operatelogrepo "goadmin/internal/repository/operate_log"
+	"goadmin/pkg/util"
+)
+
+// fakeLogRepo 在内存中记录写入的批次，down 为 true 时写入失败，含 bad 内容的批次同样失败
+type fakeLogRepo struct {
+	operatelogrepo.OperateLogRepository
+	mu      sync.Mutex
+	down    bool
+	bad     string
+	batches [][]string
+}
+
+func (r *fakeLogRepo) BatchCreate(_ stdctx.Context, logs []*modeloperatelog.OperateLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return errors.New("database is down")
+	}
+	batch := make([]string, 0, len(logs))
+	for _, log := range logs {
+		if log.ID != 0 {
+			return errors.New("id already assigned")
+		}
+		if r.bad != "" && log.Content == r.bad {
+			return errors.New("data too long")
+		}
+		batch = append(batch, log.Content)
+	}
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *fakeLogRepo) setDown(down bool) {
+	r.mu.Lock()
+	r.down = down
+	r.mu.Unlock()
+}
+
+func (r *fakeLogRepo) contents() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []string
+	for _, b := range r.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func newTestWriter(t *testing.T, repo *fakeLogRepo, buffer, batch int) *Writer {
+	t.Helper()
+	cfg := &config.Config{Audit: config.AuditConfig{
+		BufferSize:    buffer,
+		BatchSize:     batch,
+		FlushInterval: time.Hour,
+		SpoolFile:     filepath.Join(t.TempDir(), "spool", "operate_log.jsonl"),
+	}}
+	return NewWriter(cfg, repo)
+}
+
+func logOf(content string) *modeloperatelog.OperateLog {
+	return &modeloperatelog.OperateLog{BaseModel: schema.BaseModel{CTime: util.Now()}, Content: content}
+}
+
+func TestWriterBatchAndDrain(t *testing.T) {
+	repo := &fakeLogRepo{}
+	w := newTestWriter(t, repo, 10, 2)
+	ctx, cancel := stdctx.WithCancel(stdctx.Background())
+	go func() { _ = w.Start(ctx) }()
+	waitRunning(t, w)
+
+	for _, c := range []string{"a", "b", "c"} {
+		w.Write(logOf(c))
+	}
+	// 满一批立即写入，余下的在停止时写完
+	cancel()
+	if err := w.Stop(stdctx.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.contents(); len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Errorf("written = %v", got)
+	}
+	if len(repo.batches[0]) != 2 {
+		t.Errorf("first batch = %v, want 2 logs", repo.batches[0])
+	}
+
+	// 停止后同步写入
+	w.Write(logOf("d"))
+	if stats := w.Stats(); stats.Written != 4 || stats.Pending != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWriterSpoolAndReplay(t *testing.T) {
+	repo := &fakeLogRepo{down: true}
+	w := newTestWriter(t, repo, 10, 2)
+
+	// 未运行时同步写入，失败时转存
+	w.Write(logOf("a"))
+	w.Write(logOf("b"))
+	w.Write(logOf("c"))
+	if stats := w.Stats(); stats.Spooled != 3 || stats.Written != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// 仍不可用时保留本地文件
+	w.replay()
+	if _, err := os.Stat(w.spool); err != nil {
+		t.Fatalf("spool file: %v", err)
+	}
+
+	repo.setDown(false)
+	w.replay()
+	if got := repo.contents(); len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Errorf("replayed = %v", got)
+	}
+	if stats := w.Stats(); stats.Retried != 3 || stats.Written != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if _, err := os.Stat(w.spool); !os.IsNotExist(err) {
+		t.Errorf("spool file should be removed: %v", err)
+	}
+}
+
+func TestWriterReplayRejectsBadLog(t *testing.T) {
+	repo := &fakeLogRepo{down: true, bad: "b"}
+	w := newTestWriter(t, repo, 10, 10)
+	for _, c := range []string{"a", "b", "c"} {
+		w.Write(logOf(c))
+	}
+
+	// 逐条重试仍全部失败时视为数据库不可用，保留全部日志
+	w.replay()
+	if stats := w.Stats(); stats.Rejected != 0 || stats.Retried != 0 {
+		t.Fatalf("stats while down = %+v", stats)
+	}
+
+	// 无法写入的日志移到隔离文件，之后的日志照常写入
+	repo.setDown(false)
+	w.replay()
+	if got := repo.contents(); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("replayed = %v", got)
+	}
+	if stats := w.Stats(); stats.Retried != 2 || stats.Rejected != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if _, err := os.Stat(w.spool); !os.IsNotExist(err) {
+		t.Errorf("spool file should be removed: %v", err)
+	}
+	rejected, _, err := readSpool(w.spool + rejectedSuffix)
+	if err != nil || len(rejected) != 1 || rejected[0].Content != "b" {
+		t.Errorf("rejected = %v, %v", rejected, err)
+	}
+}
+
+func TestWriterReplayTornLine(t *testing.T) {
+	repo := &fakeLogRepo{down: true}
+	w := newTestWriter(t, repo, 10, 10)
+	appendRaw := func(s string) {
+		f, err := os.OpenFile(w.spool, os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err = f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 文件中间有无法解析的行，末尾有进程在写入中途退出留下的半行，之后仍有追加的日志
+	w.Write(logOf("a"))
+	appendRaw("not json\n")
+	w.Write(logOf("b"))
+	appendRaw(`{"at":"2024-01-02T15:04:05Z","log":{"con`)
+	w.Write(logOf("c"))
+
+	// 数据库不可用时无法解析的行同样移走，其余日志保留
+	w.replay()
+	if stats := w.Stats(); stats.Rejected != 2 || stats.Retried != 0 {
+		t.Fatalf("stats while down = %+v", stats)
+	}
+	logs, bad, err := readSpool(w.spool)
+	if err != nil || len(logs) != 3 || len(bad) != 0 {
+		t.Fatalf("spool = %d logs, %d bad, %v", len(logs), len(bad), err)
+	}
+
+	repo.setDown(false)
+	w.replay()
+	if got := repo.contents(); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("replayed = %v", got)
+	}
+	if stats := w.Stats(); stats.Retried != 3 || stats.Rejected != 2 || stats.Lost != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if _, err = os.Stat(w.spool); !os.IsNotExist(err) {
+		t.Errorf("spool file should be removed: %v", err)
+	}
+	_, bad, err = readSpool(w.spool + rejectedSuffix)
+	if err != nil || len(bad) != 2 {
+		t.Errorf("rejected lines = %d, %v", len(bad), err)
+	}
+}
+
+func TestWriterDropWhenFull(t *testing.T) {
+	repo := &fakeLogRepo{}
+	w := newTestWriter(t, repo, 1, 10)
+	// 标记为运行中但不消费队列
+	w.running = true
+	w.Write(logOf("a"))
+	w.Write(logOf("b"))
+	if stats := w.Stats(); stats.Dropped != 1 || stats.Pending != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// waitRunning 等待写入器开始接收日志
+func waitRunning(t *testing.T, w *Writer) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		w.mu.RLock()
+		running := w.running
+		w.mu.RUnlock()
+		if running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("writer not running")
+}
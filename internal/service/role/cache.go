@@ -0,0 +1,108 @@
+package role
+
+import (
+	stdctx "context"
+	"goadmin/config"
+	"goadmin/internal/context"
+	userservice "goadmin/internal/service/user"
+	"goadmin/pkg/cache"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/redisx"
+	"slices"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	permCacheOnce sync.Once
+	permCache     *cache.Tiered[[]string]
+)
+
+// sharedPermCache 进程内共享的角色有效权限缓存，键为角色代码，未开启鉴权缓存时返回 nil
+//
+// 认证中间件与接口使用不同的服务实例，缓存须在进程内共享，变更后才能同时失效
+func sharedPermCache(cfg *config.Config) *cache.Tiered[[]string] {
+	if cfg == nil || !cfg.Auth.Cache.Enable {
+		return nil
+	}
+	permCacheOnce.Do(func() {
+		permCache = cache.NewTiered[[]string](cache.Options{
+			Name:      "rbac:role_perms",
+			Size:      cfg.Auth.Cache.SizeOrDefault(),
+			LocalTTL:  cfg.Auth.Cache.LocalTTLOrDefault(),
+			RemoteTTL: cfg.Auth.Cache.RemoteTTLOrDefault(),
+			Client:    func() *redis.Client { return redisx.GetClient() },
+		})
+	})
+	return permCache
+}
+
+// hasAnyPermission 检查角色集合（含继承）是否拥有任一权限
+//
+// 开启鉴权缓存时按单个角色缓存有效权限，逐个角色检查，命中即返回
+func (s *roleService) hasAnyPermission(ctx *context.Context, roleCodes []string, codes []string) (bool, error) {
+	if len(codes) == 0 || len(roleCodes) == 0 {
+		return false, nil
+	}
+	if s.permCache == nil {
+		chains, err := s.roleChains(ctx, roleCodes)
+		if err != nil {
+			return false, err
+		}
+		return s.rolePermissionRepo.HasAnyPermission(ctx, chains, codes)
+	}
+	for _, roleCode := range roleCodes {
+		granted, err := s.permCache.Get(ctx, roleCode, func(stdctx.Context) ([]string, error) {
+			return s.effectivePermissionCodes(ctx, []string{roleCode}, false)
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, g := range granted {
+			if slices.Contains(codes, g) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// invalidatePermCache 角色权限、层级或权限表变更后使各实例的缓存及权限路由失效，失败时依赖缓存有效期兜底
+//
+// 权限沿层级继承，任一角色变更都可能影响其全部后代，因此直接清空
+func (s *roleService) invalidatePermCache(ctx stdctx.Context, log logger.Logger) {
+	routeGen.Add(1)
+	if s.permCache == nil {
+		return
+	}
+	if err := s.permCache.Purge(ctx); err != nil {
+		log.Errorf("%s 角色权限缓存失效通知失败: %v", s.logPrefix(), err)
+	}
+}
+
+// routeGeneration 权限路由的失效代数，开启鉴权缓存时包含其他实例发出的失效通知
+func (s *roleService) routeGeneration() uint64 {
+	gen := routeGen.Load()
+	if s.permCache != nil {
+		gen += s.permCache.Generation()
+	}
+	return gen
+}
+
+// invalidateRoleSessions 角色变更后使持有该角色用户的登录用户缓存失效
+//
+// 登录用户缓存中保存了角色的数据范围，仅清除权限缓存时旧范围会沿用至缓存过期
+func (s *roleService) invalidateRoleSessions(ctx *context.Context, roleCode string) {
+	if s.cfg == nil || !s.cfg.Auth.Cache.Enable {
+		return
+	}
+	userIDs, err := s.userRoleRepo.GetUserIDsByRoleCode(ctx, roleCode)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取角色用户失败: %s %v", s.logPrefix(), roleCode, err)
+		return
+	}
+	if err = userservice.InvalidateSessionUsers(ctx, s.cfg, userIDs...); err != nil {
+		ctx.Logger.Errorf("%s 用户缓存失效通知失败: %s %v", s.logPrefix(), roleCode, err)
+	}
+}
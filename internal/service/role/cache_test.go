@@ -0,0 +1,319 @@
+package role
+
+import (
+	stdctx "context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goadmin/internal/context"
+	"goadmin/internal/model/permission"
+	"goadmin/internal/model/role"
+	rolerepo "goadmin/internal/repository/role"
+	userrepo "goadmin/internal/repository/user"
+	"goadmin/pkg/cache"
+	"goadmin/pkg/db"
+	"goadmin/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// 模拟一次数据库查询的耗时
+const dbLatency = 500 * time.Microsecond
+
+// fakeRolePermissionRepo 内存中的角色权限，查询时模拟数据库耗时
+type fakeRolePermissionRepo struct {
+	rolerepo.RolePermissionRepository
+
+	mu      sync.Mutex
+	perms   []permission.Permission
+	granted map[string][]string
+	queries atomic.Int32
+}
+
+func (r *fakeRolePermissionRepo) GetAllPermissions(stdctx.Context, ...bool) ([]permission.Permission, error) {
+	return r.perms, nil
+}
+
+func (r *fakeRolePermissionRepo) GetPermissionCodesByRoleCodes(
+	_ stdctx.Context, roleCodes []string, _ ...bool) ([]string, error) {
+	r.queries.Add(1)
+	time.Sleep(dbLatency)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var codes []string
+	for _, rc := range roleCodes {
+		codes = append(codes, r.granted[rc]...)
+	}
+	return codes, nil
+}
+
+func (r *fakeRolePermissionRepo) GetPermissionsByRoleCodes(
+	_ stdctx.Context, roleCodes []string) (map[string][]permission.Permission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[string][]permission.Permission)
+	for _, rc := range roleCodes {
+		for _, code := range r.granted[rc] {
+			result[rc] = append(result[rc], permission.Permission{Code: code})
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeRolePermissionRepo) HasAnyPermission(_ stdctx.Context, roleCodes []string, codes []string) (bool, error) {
+	r.queries.Add(1)
+	time.Sleep(dbLatency)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rc := range roleCodes {
+		for _, c := range codes {
+			if slices.Contains(r.granted[rc], c) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRolePermissionRepo) DeleteByRoleCode(_ stdctx.Context, roleCode string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.granted, roleCode)
+	return nil
+}
+
+func (r *fakeRolePermissionRepo) BatchCreate(_ stdctx.Context, rps []*role.RolePermission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rp := range rps {
+		r.granted[rp.RoleCode] = append(r.granted[rp.RoleCode], rp.PermissionCode)
+	}
+	return nil
+}
+
+// fakeRoleRepo 内存中的角色，按代码索引
+type fakeRoleRepo struct {
+	rolerepo.RoleRepository
+
+	mu    sync.Mutex
+	roles map[string]*role.Role
+}
+
+func (r *fakeRoleRepo) GetByCode(_ stdctx.Context, code string) (*role.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rs, ok := r.roles[code]; ok {
+		cp := *rs
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetByID(_ stdctx.Context, id uint64) (*role.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rs := range r.roles {
+		if rs.ID == id {
+			cp := *rs
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetByName(stdctx.Context, string) (*role.Role, error) {
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) Update(_ stdctx.Context, rs *role.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *rs
+	r.roles[rs.Code] = &cp
+	return nil
+}
+
+func (r *fakeRoleRepo) ListChildren(_ stdctx.Context, parentCode string) ([]*role.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var children []*role.Role
+	for _, rs := range r.roles {
+		if rs.ParentCode == parentCode {
+			children = append(children, rs)
+		}
+	}
+	return children, nil
+}
+
+// GetChain 与仓储实现一致：遇到停用或不存在的祖先时停止
+func (r *fakeRoleRepo) GetChain(ctx stdctx.Context, code string) ([]string, error) {
+	chain := []string{code}
+	current, _ := r.GetByCode(ctx, code)
+	for current != nil && current.ParentCode != "" && len(chain) < role.MaxDepth {
+		if slices.Contains(chain, current.ParentCode) {
+			break
+		}
+		current, _ = r.GetByCode(ctx, current.ParentCode)
+		if current == nil || !current.IsActive() {
+			break
+		}
+		chain = append(chain, current.Code)
+	}
+	return chain, nil
+}
+
+// fakeUserRoleRepo 内存中的用户角色关联，按角色代码索引用户ID
+type fakeUserRoleRepo struct {
+	userrepo.UserRoleRepository
+
+	users map[string][]uint64
+}
+
+func (r *fakeUserRoleRepo) GetUserIDsByRoleCode(_ stdctx.Context, roleCode string) ([]uint64, error) {
+	return r.users[roleCode], nil
+}
+
+func newTestRoleService(permCache *cache.Tiered[[]string]) (*roleService, *fakeRolePermissionRepo) {
+	repo := &fakeRolePermissionRepo{
+		perms: []permission.Permission{
+			{Code: "user_list", Method: http.MethodGet, Path: "admin/v1/user/list", GlobalFlag: permission.GlobalFlagNo},
+			{Code: "user_create", Method: http.MethodPost, Path: "admin/v1/user/create", GlobalFlag: permission.GlobalFlagNo},
+		},
+		granted: map[string][]string{"operator": {"user_list"}},
+	}
+	roles := &fakeRoleRepo{roles: map[string]*role.Role{
+		"operator": {Code: "operator", Status: role.RoleStatusActive, SystemFlag: role.SystemFlagNo, DataScope: db.ScopeAll},
+	}}
+	roles.roles["operator"].ID = 1
+	return &roleService{
+		roleRepo:           roles,
+		rolePermissionRepo: repo,
+		userRoleRepo:       &fakeUserRoleRepo{users: map[string][]uint64{}},
+		matcher:            &routeMatcher{},
+		permCache:          permCache,
+	}, repo
+}
+
+func newTestContext() *context.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/v1/user/list", nil)
+	return &context.Context{Context: c, Logger: logger.Global()}
+}
+
+func newTestRedis(tb testing.TB) func() *redis.Client {
+	tb.Helper()
+	mr := miniredis.RunT(tb)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tb.Cleanup(func() { _ = client.Close() })
+	return func() *redis.Client { return client }
+}
+
+func TestHasAccessURLCacheInvalidatedOnAssign(t *testing.T) {
+	permCache := cache.NewTiered[[]string](cache.Options{
+		Name: "test:role_perms", Size: 16, LocalTTL: time.Hour, RemoteTTL: time.Hour, Client: newTestRedis(t),
+	})
+	s, repo := newTestRoleService(permCache)
+	ctx := newTestContext()
+
+	for i := 0; i < 3; i++ {
+		if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/list"); err != nil {
+			t.Fatalf("HasAccessURL = %v", err)
+		}
+	}
+	// 有效权限一次查询取得
+	if n := repo.queries.Load(); n != 1 {
+		t.Errorf("queries = %d, want 1", n)
+	}
+	if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodPost, "admin/v1/user/create"); err == nil {
+		t.Fatal("user_create should be denied")
+	}
+
+	if err := s.AssignPermissions(ctx, "operator", []string{"user_create"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodPost, "admin/v1/user/create"); err != nil {
+		t.Errorf("user_create should be allowed after assign: %v", err)
+	}
+	if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/list"); err == nil {
+		t.Error("user_list should be denied after assign")
+	}
+}
+
+// BenchmarkHasAccessURL 对比每次请求鉴权的耗时，数据库查询模拟为 500µs
+//
+// miniredis 运行在进程内，RedisHit 不含真实网络往返，仅体现序列化等开销
+func BenchmarkHasAccessURL(b *testing.B) {
+	cases := []struct {
+		name  string
+		cache func(b *testing.B) *cache.Tiered[[]string]
+	}{
+		{"NoCache", func(*testing.B) *cache.Tiered[[]string] { return nil }},
+		{"LocalHit", func(*testing.B) *cache.Tiered[[]string] {
+			return cache.NewTiered[[]string](cache.Options{Name: "bench:local", Size: 16, LocalTTL: time.Hour})
+		}},
+		{"RedisHit", func(b *testing.B) *cache.Tiered[[]string] {
+			// 进程内条目立即过期，每次均从 Redis 读取
+			return cache.NewTiered[[]string](cache.Options{
+				Name: "bench:redis", Size: 16, LocalTTL: time.Nanosecond, RemoteTTL: time.Hour, Client: newTestRedis(b),
+			})
+		}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			s, _ := newTestRoleService(c.cache(b))
+			ctx := newTestContext()
+			if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/list"); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/list"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteMatcherRebuiltOnInvalidation(t *testing.T) {
+	client := newTestRedis(t)
+	opts := cache.Options{Name: "test:route_perms", Size: 16, LocalTTL: time.Hour, RemoteTTL: time.Hour, Client: client}
+	s, repo := newTestRoleService(cache.NewTiered[[]string](opts))
+	ctx := newTestContext()
+
+	if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/list"); err != nil {
+		t.Fatalf("HasAccessURL = %v", err)
+	}
+	repo.mu.Lock()
+	repo.perms[0].Path = "admin/v1/user/page"
+	repo.mu.Unlock()
+
+	// 本实例的变更立即生效
+	s.invalidatePermCache(ctx, ctx.Logger)
+	if err := s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/page"); err != nil {
+		t.Fatalf("new route should match after invalidation: %v", err)
+	}
+
+	// 其他实例（如 permissions sync 命令）的变更经失效通知生效
+	repo.mu.Lock()
+	repo.perms[0].Path = "admin/v1/user/all"
+	repo.mu.Unlock()
+	if err := cache.NewTiered[[]string](opts).Purge(stdctx.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for s.HasAccessURL(ctx, []string{"operator"}, http.MethodGet, "admin/v1/user/all") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("route matcher not rebuilt after remote invalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,40 @@
+package role
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/role"
+	"goadmin/pkg/db"
+)
+
+// checkDataScope 校验角色的数据范围，仅自定义范围保留条件
+//
+// 未指定时新建角色为全部数据（租户内为本租户数据），更新角色（existing 非空）保留原有范围。
+// 租户角色不能使用全部数据，否则租户管理员可越过数据范围
+func (s *roleService) checkDataScope(
+	ctx *context.Context, req *role.CreateRequest, existing *role.Role,
+) (db.ScopeKind, string, error) {
+	kind, filter := req.DataScope, req.DataFilter
+	switch {
+	case kind != 0:
+	case existing != nil:
+		kind, filter = existing.DataScope, existing.DataFilter
+	case inTenant(ctx):
+		kind = db.ScopeTenant
+	default:
+		kind = db.ScopeAll
+	}
+	if !kind.IsValid() {
+		return 0, "", i18n.E(ctx.Context, "role.DataScopeInvalid", nil)
+	}
+	if kind == db.ScopeAll && inTenant(ctx) {
+		return 0, "", i18n.E(ctx.Context, "role.DataScopeAllDenied", nil)
+	}
+	if kind != db.ScopeCustom {
+		return kind, "", nil
+	}
+	if _, err := db.ParseConditions(filter); err != nil {
+		return 0, "", i18n.E(ctx.Context, "role.DataFilterInvalid", map[string]any{"err": err.Error()})
+	}
+	return kind, filter, nil
+}
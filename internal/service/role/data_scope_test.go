@@ -0,0 +1,75 @@
+package role
+
+import (
+	"testing"
+
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/db"
+)
+
+func TestUpdateRoleKeepsDataScope(t *testing.T) {
+	s, _ := newTestRoleService(nil)
+	const filter = `[{"field":"dept_id","op":"eq","value":"$dept_id"}]`
+	existing := s.roleRepo.(*fakeRoleRepo).roles["operator"]
+	existing.DataScope = db.ScopeCustom
+	existing.DataFilter = filter
+	ctx := newTestContext()
+
+	// 未提交数据范围时保留原有范围，不得放宽为全部数据
+	err := s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", Description: "changed"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.roleRepo.(*fakeRoleRepo).roles["operator"]
+	if got.DataScope != db.ScopeCustom || got.DataFilter != filter {
+		t.Fatalf("data scope = %d %q, want the existing custom scope", got.DataScope, got.DataFilter)
+	}
+
+	err = s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", DataScope: db.ScopeSelf},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = s.roleRepo.(*fakeRoleRepo).roles["operator"]
+	if got.DataScope != db.ScopeSelf || got.DataFilter != "" {
+		t.Fatalf("data scope = %d %q, want self", got.DataScope, got.DataFilter)
+	}
+}
+
+func TestTenantRoleRestrictions(t *testing.T) {
+	s, perms := newTestRoleService(nil)
+	operator := s.roleRepo.(*fakeRoleRepo).roles["operator"]
+	operator.TenantID = 7
+	operator.DataScope = db.ScopeTenant
+	ctx := newTestContext()
+	ctx.Set(db.TenantCtxKey, uint64(7))
+
+	// 租户角色不能放宽为全部数据
+	err := s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", DataScope: db.ScopeAll},
+	})
+	if err == nil || err.Error() != "role.DataScopeAllDenied" {
+		t.Fatalf("err = %v, want role.DataScopeAllDenied", err)
+	}
+
+	// 租户管理及平台级权限不可授予租户角色，拒绝时保留原有权限
+	for _, code := range []string{"tenant_update", "server_decrypted"} {
+		err = s.AssignPermissions(ctx, "operator", []string{"user_list", code})
+		if err == nil || err.Error() != "role.PermissionNotAssignable" {
+			t.Fatalf("assign %s: err = %v, want role.PermissionNotAssignable", code, err)
+		}
+	}
+	if got := perms.granted["operator"]; len(got) != 1 || got[0] != "user_list" {
+		t.Fatalf("granted = %v, want the original permissions", got)
+	}
+	if err = s.AssignPermissions(ctx, "operator", []string{"user_list", "user_create"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,124 @@
+package role
+
+import (
+	stdctx "context"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/permission"
+	"goadmin/internal/model/role"
+	"slices"
+)
+
+// effectivePermissionCodes 获取多个角色及其祖先的权限代码并集
+func (s *roleService) effectivePermissionCodes(
+	ctx stdctx.Context, roleCodes []string, containPublic bool) ([]string, error) {
+	chains, err := s.roleChains(ctx, roleCodes)
+	if err != nil {
+		return nil, err
+	}
+	return s.rolePermissionRepo.GetPermissionCodesByRoleCodes(ctx, chains, containPublic)
+}
+
+// roleChains 获取多个角色及其祖先的代码，去重后合并
+func (s *roleService) roleChains(ctx stdctx.Context, roleCodes []string) ([]string, error) {
+	var codes []string
+	for _, roleCode := range roleCodes {
+		chain, err := s.roleRepo.GetChain(ctx, roleCode)
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range chain {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes, nil
+}
+
+// checkParent 校验父角色：须存在、不能是超级管理员，且不能形成环或超过层级上限
+//
+// code 为空表示新建角色；调整已有角色的父角色时，其下方的子孙角色随之移动，一并计入层级
+func (s *roleService) checkParent(ctx *context.Context, code, parentCode string) error {
+	if parentCode == "" {
+		return nil
+	}
+	if parentCode == code {
+		return i18n.E(ctx.Context, "role.ParentCycle", nil)
+	}
+	if parentCode == role.CodeSuperAdmin {
+		return i18n.E(ctx.Context, "role.ParentInvalid", nil)
+	}
+
+	height := 0
+	if code != "" {
+		var err error
+		if height, err = s.subtreeHeight(ctx, code); err != nil {
+			ctx.Logger.Errorf("%s 获取子角色失败: %s %v", s.logPrefix(), code, err)
+			return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+	}
+
+	// 自父角色向上查找，遇到自身即成环
+	depth := 1 + height
+	for current := parentCode; current != ""; depth++ {
+		if depth >= role.MaxDepth {
+			return i18n.E(ctx.Context, "role.HierarchyTooDeep", map[string]any{"max": role.MaxDepth})
+		}
+		r, err := s.roleRepo.GetByCode(ctx, current)
+		if err != nil {
+			ctx.Logger.Errorf("%s 获取角色失败: %s %v", s.logPrefix(), current, err)
+			return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		if r == nil {
+			if current == parentCode {
+				return i18n.E(ctx.Context, "common.NotFound",
+					map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
+			}
+			break
+		}
+		if code != "" && r.ParentCode == code {
+			return i18n.E(ctx.Context, "role.ParentCycle", nil)
+		}
+		current = r.ParentCode
+	}
+	return nil
+}
+
+// subtreeHeight 角色下方子孙角色的层数，没有子角色时为 0，达到层级上限后不再查找
+func (s *roleService) subtreeHeight(ctx stdctx.Context, code string) (int, error) {
+	height := 0
+	for level := []string{code}; height < role.MaxDepth; height++ {
+		var next []string
+		for _, c := range level {
+			children, err := s.roleRepo.ListChildren(ctx, c)
+			if err != nil {
+				return 0, err
+			}
+			for _, child := range children {
+				next = append(next, child.Code)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		level = next
+	}
+	return height, nil
+}
+
+// effectivePermissions 合并角色及其祖先的权限，按代码去重，近的角色优先
+func effectivePermissions(chain []string, byRole map[string][]permission.Permission) []permission.Permission {
+	seen := make(map[string]bool)
+	list := make([]permission.Permission, 0)
+	for _, code := range chain {
+		for _, p := range byRole[code] {
+			if p.Code == "" || seen[p.Code] {
+				continue
+			}
+			seen[p.Code] = true
+			list = append(list, p)
+		}
+	}
+	return list
+}
@@ -0,0 +1,155 @@
+package role
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/schema"
+	"goadmin/pkg/cache"
+	"goadmin/pkg/db"
+)
+
+// addTestRole 添加启用的非系统角色
+func addTestRole(s *roleService, id uint64, code, parentCode string) {
+	r := &role.Role{Code: code, Name: code, ParentCode: parentCode,
+		Status: role.RoleStatusActive, SystemFlag: role.SystemFlagNo, DataScope: db.ScopeAll}
+	r.ID = id
+	s.roleRepo.(*fakeRoleRepo).roles[code] = r
+}
+
+func TestHasAccessURLInheritsParent(t *testing.T) {
+	caches := map[string]*cache.Tiered[[]string]{
+		"NoCache": nil,
+		"Cache": cache.NewTiered[[]string](cache.Options{
+			Name: "test:role_perms_inherit", Size: 16, LocalTTL: time.Hour, RemoteTTL: time.Hour,
+		}),
+	}
+	for name, permCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestRoleService(permCache)
+			addTestRole(s, 2, "auditor", "operator")
+			ctx := newTestContext()
+
+			if err := s.HasAccessURL(ctx, []string{"auditor"}, http.MethodGet, "admin/v1/user/list"); err != nil {
+				t.Errorf("auditor should inherit user_list: %v", err)
+			}
+			if err := s.HasAccessURL(ctx, []string{"auditor"}, http.MethodPost, "admin/v1/user/create"); err == nil {
+				t.Error("user_create should be denied")
+			}
+
+			// 父角色停用后不再继承
+			op := s.roleRepo.(*fakeRoleRepo).roles["operator"]
+			if err := s.UpdateRole(ctx, &role.UpdateRequest{
+				IDRequest:     schema.IDRequest{ID: op.ID},
+				CreateRequest: role.CreateRequest{Code: op.Code, Name: op.Name, Status: role.RoleStatusInactive},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.HasAccessURL(ctx, []string{"auditor"}, http.MethodGet, "admin/v1/user/list"); err == nil {
+				t.Error("inactive parent should not be inherited")
+			}
+		})
+	}
+}
+
+func TestUpdateRoleRejectsCycle(t *testing.T) {
+	s, _ := newTestRoleService(nil)
+	addTestRole(s, 2, "auditor", "operator")
+	addTestRole(s, 3, "viewer", "auditor")
+	ctx := newTestContext()
+
+	err := s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", ParentCode: "viewer"},
+	})
+	if err == nil || err.Error() != "role.ParentCycle" {
+		t.Errorf("err = %v, want role.ParentCycle", err)
+	}
+	err = s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", ParentCode: role.CodeSuperAdmin},
+	})
+	if err == nil || err.Error() != "role.ParentInvalid" {
+		t.Errorf("err = %v, want role.ParentInvalid", err)
+	}
+}
+
+func TestDeleteRoleWithChildren(t *testing.T) {
+	s, _ := newTestRoleService(nil)
+	addTestRole(s, 2, "auditor", "operator")
+
+	if err := s.DeleteRole(newTestContext(), 1); err == nil || err.Error() != "role.HasChildren" {
+		t.Errorf("err = %v, want role.HasChildren", err)
+	}
+}
+
+func TestDeleteRoleWithUsers(t *testing.T) {
+	s, _ := newTestRoleService(nil)
+	s.userRoleRepo.(*fakeUserRoleRepo).users["operator"] = []uint64{7, 8}
+
+	if err := s.DeleteRole(newTestContext(), 1); err == nil || err.Error() != "role.HasUsers" {
+		t.Errorf("err = %v, want role.HasUsers", err)
+	}
+}
+
+func TestHasAccessURLUnionAcrossRoles(t *testing.T) {
+	caches := map[string]*cache.Tiered[[]string]{
+		"NoCache": nil,
+		"Cache": cache.NewTiered[[]string](cache.Options{
+			Name: "test:role_perms_union", Size: 16, LocalTTL: time.Hour, RemoteTTL: time.Hour,
+		}),
+	}
+	for name, permCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			s, repo := newTestRoleService(permCache)
+			addTestRole(s, 2, "creator", "")
+			repo.granted["creator"] = []string{"user_create"}
+			ctx := newTestContext()
+
+			roles := []string{"operator", "creator"}
+			if err := s.HasAccessURL(ctx, roles, http.MethodGet, "admin/v1/user/list"); err != nil {
+				t.Errorf("user_list should be allowed: %v", err)
+			}
+			if err := s.HasAccessURL(ctx, roles, http.MethodPost, "admin/v1/user/create"); err != nil {
+				t.Errorf("user_create should be allowed: %v", err)
+			}
+			if err := s.HasAccessURL(ctx, nil, http.MethodGet, "admin/v1/user/list"); err == nil {
+				t.Error("user without roles should be denied")
+			}
+		})
+	}
+}
+
+func TestUpdateRoleRejectsDeepSubtree(t *testing.T) {
+	s, _ := newTestRoleService(nil)
+	// operator 下方共 MaxDepth 层：operator <- r2 <- ... <- r8
+	parent := "operator"
+	for i := 2; i <= role.MaxDepth; i++ {
+		code := "r" + strconv.Itoa(i)
+		addTestRole(s, uint64(i), code, parent)
+		parent = code
+	}
+	addTestRole(s, 100, "root", "")
+	ctx := newTestContext()
+
+	// 挂到 root 下后 r8 位于第 MaxDepth+1 层
+	err := s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 1},
+		CreateRequest: role.CreateRequest{Code: "operator", Name: "operator", ParentCode: "root"},
+	})
+	if err == nil || err.Error() != "role.HierarchyTooDeep" {
+		t.Errorf("err = %v, want role.HierarchyTooDeep", err)
+	}
+
+	// 较浅的子树可以移动：r3 <- ... <- r8 挂到 root 下后 r8 位于第 7 层
+	err = s.UpdateRole(ctx, &role.UpdateRequest{
+		IDRequest:     schema.IDRequest{ID: 3},
+		CreateRequest: role.CreateRequest{Code: "r3", Name: "r3", ParentCode: "root"},
+	})
+	if err != nil {
+		t.Errorf("moving r3 under root: %v", err)
+	}
+}
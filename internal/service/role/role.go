@@ -1,11 +1,15 @@
 package role
 
 import (
+	"goadmin/internal/catalog"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
+	"goadmin/internal/model/permission"
 	"goadmin/internal/model/role"
 	"goadmin/internal/model/schema"
 	rolerepo "goadmin/internal/repository/role"
+	userrepo "goadmin/internal/repository/user"
+	"goadmin/pkg/cache"
 	"goadmin/pkg/db"
 	"goadmin/pkg/util"
 
@@ -44,38 +48,57 @@ type RoleService interface {
 	// GetRolePermissions 获取角色的权限列表
 	GetRolePermissions(ctx *context.Context, roleCode string) ([]string, error)
 
+	// GetEffectivePermissions 获取多个角色含继承及公共权限在内的全部权限代码并集
+	GetEffectivePermissions(ctx *context.Context, roleCodes []string) ([]string, error)
+
 	// AssignPermissions 分配权限给角色
 	AssignPermissions(ctx *context.Context, roleCode string, permissionCodes []string) error
 
-	// HasAccessURL 检查角色是否有访问权限
-	HasAccessURL(ctx *context.Context, roleCode string, accessURL string) error
+	// HasAccessURL 检查角色集合是否有访问权限，按请求方法及URL匹配权限路由
+	//
+	// 任一角色（含继承自祖先的权限）拥有匹配的权限即可访问
+	HasAccessURL(ctx *context.Context, roleCodes []string, method string, accessURL string) error
+
+	// MatchPermissions 获取与请求方法及URL最匹配的权限
+	MatchPermissions(ctx *context.Context, method string, accessURL string) ([]permission.Permission, error)
 
 	// ListAllPermissions 获取所有权限列表
 	ListAllPermissions(ctx *context.Context) ([]map[string]interface{}, error)
+
+	// SyncPermissionCatalog 将路由声明的权限目录同步到权限表，dryRun 时只返回差异
+	SyncPermissionCatalog(ctx *context.CliContext, dryRun bool) (*catalog.Plan, error)
 }
 
 // roleService 角色服务实现
 type roleService struct {
 	roleRepo           rolerepo.RoleRepository
 	rolePermissionRepo rolerepo.RolePermissionRepository
+	userRoleRepo       userrepo.UserRoleRepository
 	cfg                *config.Config
+	matcher            *routeMatcher
+	permCache          *cache.Tiered[[]string] // 角色权限缓存，为空表示未开启
 }
 
 // NewRoleService 创建角色服务实例（Wire 注入）
-func NewRoleService(roleRepo rolerepo.RoleRepository, rolePermissionRepo rolerepo.RolePermissionRepository, cfg *config.Config) RoleService {
+func NewRoleService(roleRepo rolerepo.RoleRepository, rolePermissionRepo rolerepo.RolePermissionRepository,
+	userRoleRepo userrepo.UserRoleRepository, cfg *config.Config) RoleService {
 	return &roleService{
 		cfg:                cfg,
 		roleRepo:           roleRepo,
 		rolePermissionRepo: rolePermissionRepo,
+		userRoleRepo:       userRoleRepo,
+		matcher:            &routeMatcher{},
+		permCache:          sharedPermCache(cfg),
 	}
 }
 
-// Deprecated: 使用 NewRoleService(roleRepo, rolePermissionRepo, cfg) 替代
+// Deprecated: 使用 NewRoleService(roleRepo, rolePermissionRepo, userRoleRepo, cfg) 替代
 // NewRoleService_legacy 创建角色服务实例（兼容旧代码，使用全局db）
 func NewRoleService_legacy() RoleService {
 	return NewRoleService(
 		rolerepo.NewRoleRepositoryWithDB(),
 		rolerepo.NewRolePermissionRepositoryWithDB(),
+		userrepo.NewUserRoleRepository_legacy(),
 		config.Get(),
 	)
 }
@@ -167,8 +190,8 @@ func (s *roleService) CreateRole(ctx *context.Context, roleModel *role.CreateReq
 			ctx.Logger.Errorf("%s 生成角色代码失败: times=%d %v", s.logPrefix(), i, err)
 			continue
 		}
-		// 检查角色代码是否已存在
-		existingRole, err = s.roleRepo.GetByCode(ctx, roleModel.Code)
+		// 检查角色代码是否已存在，代码全局唯一
+		existingRole, err = s.roleRepo.GetByCode(db.SkipTenant(ctx), roleModel.Code)
 		if err != nil {
 			ctx.Logger.Errorf("%s 检查角色代码是否存在失败: %s %v", s.logPrefix(), roleModel.Code, err)
 			continue
@@ -199,13 +222,24 @@ func (s *roleService) CreateRole(ctx *context.Context, roleModel *role.CreateReq
 			ctx.Context, "common.HadExist", map[string]any{"item": roleModel.Name})
 	}
 
+	if err = s.checkParent(ctx, "", roleModel.ParentCode); err != nil {
+		return err
+	}
+	dataScope, dataFilter, err := s.checkDataScope(ctx, roleModel, nil)
+	if err != nil {
+		return err
+	}
+
 	// 创建角色
 	err = s.roleRepo.Create(ctx, &role.Role{
 		Code:        code,
+		ParentCode:  roleModel.ParentCode,
 		Name:        roleModel.Name,
 		Description: roleModel.Description,
 		Status:      roleModel.Status,
 		SystemFlag:  role.SystemFlagNo,
+		DataScope:   dataScope,
+		DataFilter:  dataFilter,
 	})
 	if err != nil {
 		ctx.Logger.Errorf("%s 创建角色失败: %v", s.logPrefix(), err)
@@ -228,7 +262,7 @@ func (s *roleService) UpdateRole(ctx *context.Context, roleModel *role.UpdateReq
 			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
 	}
 
-	if existingRole.IsSystem() {
+	if existingRole.IsSystem() || readOnly(ctx, existingRole) {
 		return i18n.E(ctx.Context, "common.PermissionDeny", nil)
 	}
 
@@ -244,7 +278,20 @@ func (s *roleService) UpdateRole(ctx *context.Context, roleModel *role.UpdateReq
 			return i18n.E(ctx.Context, "common.HadExist", map[string]any{"item": roleModel.Name})
 		}
 	}
+	// 调整父角色时检查是否成环
+	if existingRole.ParentCode != roleModel.ParentCode {
+		if err = s.checkParent(ctx, existingRole.Code, roleModel.ParentCode); err != nil {
+			return err
+		}
+	}
+	dataScope, dataFilter, err := s.checkDataScope(ctx, &roleModel.CreateRequest, existingRole)
+	if err != nil {
+		return err
+	}
 	existingRole.Name = roleModel.Name
+	existingRole.ParentCode = roleModel.ParentCode
+	existingRole.DataScope = dataScope
+	existingRole.DataFilter = dataFilter
 	// existingRole.Code = roleModel.Code  // code 禁止修改
 	existingRole.Description = roleModel.Description
 	existingRole.Status = roleModel.Status
@@ -256,6 +303,8 @@ func (s *roleService) UpdateRole(ctx *context.Context, roleModel *role.UpdateReq
 		ctx.Logger.Errorf("%s 更新角色失败: %v", s.logPrefix(), err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	s.invalidatePermCache(ctx, ctx.Logger)
+	s.invalidateRoleSessions(ctx, existingRole.Code)
 	return nil
 }
 
@@ -273,16 +322,37 @@ func (s *roleService) DeleteRole(ctx *context.Context, id uint64) error {
 			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
 	}
 
-	if existingRole.IsSystem() {
+	if existingRole.IsSystem() || readOnly(ctx, existingRole) {
 		return i18n.E(ctx.Context, "common.PermissionDeny", nil)
 	}
 
+	// 存在子角色时拒绝删除，避免子角色静默失去继承的权限
+	children, err := s.roleRepo.ListChildren(ctx, existingRole.Code)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取子角色失败: %s %v", s.logPrefix(), existingRole.Code, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if len(children) > 0 {
+		return i18n.E(ctx.Context, "role.HasChildren", map[string]any{"count": len(children)})
+	}
+
+	// 仍有用户时拒绝删除，避免遗留的用户角色关联在同代码角色重建后静默恢复权限
+	userIDs, err := s.userRoleRepo.GetUserIDsByRoleCode(ctx, existingRole.Code)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取角色用户失败: %s %v", s.logPrefix(), existingRole.Code, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if len(userIDs) > 0 {
+		return i18n.E(ctx.Context, "role.HasUsers", map[string]any{"count": len(userIDs)})
+	}
+
 	// 删除角色权限关联
 	err = s.rolePermissionRepo.DeleteByRoleCode(ctx, existingRole.Code)
 	if err != nil {
 		ctx.Logger.Errorf("%s 删除角色权限关联失败: %s %v", s.logPrefix(), existingRole.Code, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	defer s.invalidatePermCache(ctx, ctx.Logger)
 
 	// 删除角色
 	err = s.roleRepo.Delete(ctx, id)
@@ -294,8 +364,37 @@ func (s *roleService) DeleteRole(ctx *context.Context, id uint64) error {
 }
 
 // GetRoleWithPermissions 获取角色及其权限
+//
+// Permissions 为直接分配的权限，EffectivePermissions 含自祖先继承的权限
 func (s *roleService) GetRoleWithPermissions(ctx *context.Context, id uint64) (*role.Role, error) {
-	return s.roleRepo.GetWithPermissions(ctx, id)
+	rs, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.roleRepo.GetChain(ctx, rs.Code)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取角色层级失败: %s %v", s.logPrefix(), rs.Code, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	byRole, err := s.rolePermissionRepo.GetPermissionsByRoleCodes(ctx, chain)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取角色权限失败: %s %v", s.logPrefix(), rs.Code, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	rs.Permissions = effectivePermissions(chain[:1], byRole)
+	rs.Ancestors = chain[1:]
+	rs.EffectivePermissions = effectivePermissions(chain, byRole)
+	return rs, nil
+}
+
+// GetEffectivePermissions 获取多个角色含继承及公共权限在内的全部权限代码并集
+func (s *roleService) GetEffectivePermissions(ctx *context.Context, roleCodes []string) ([]string, error) {
+	codes, err := s.effectivePermissionCodes(ctx, roleCodes, true)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取角色有效权限失败: %v %v", s.logPrefix(), roleCodes, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return codes, nil
 }
 
 // GetRolePermissions 获取角色的权限列表
@@ -316,6 +415,17 @@ func (s *roleService) AssignPermissions(ctx *context.Context, roleCode string, p
 		return i18n.E(
 			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
 	}
+	if readOnly(ctx, existingRole) {
+		return i18n.E(ctx.Context, "common.PermissionDeny", nil)
+	}
+	if inTenant(ctx) {
+		for _, code := range permissionCodes {
+			if !tenantAssignable(code) {
+				ctx.Logger.Warnf("%s 租户角色不能授予平台权限: %s %s", s.logPrefix(), roleCode, code)
+				return i18n.E(ctx.Context, "role.PermissionNotAssignable", map[string]any{"code": code})
+			}
+		}
+	}
 
 	// 删除当前角色的所有权限
 	err = s.rolePermissionRepo.DeleteByRoleCode(ctx, roleCode)
@@ -323,6 +433,8 @@ func (s *roleService) AssignPermissions(ctx *context.Context, roleCode string, p
 		ctx.Logger.Errorf("%s 删除角色权限关联失败: %s %v", s.logPrefix(), roleCode, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	// 删除后即失效，批量创建失败时也不会继续使用旧权限
+	defer s.invalidatePermCache(ctx, ctx.Logger)
 
 	// 如果没有新权限，直接返回
 	if len(permissionCodes) == 0 {
@@ -347,11 +459,24 @@ func (s *roleService) AssignPermissions(ctx *context.Context, roleCode string, p
 	return nil
 }
 
-// HasPermission 检查角色是否有特定权限
-func (s *roleService) HasAccessURL(ctx *context.Context, roleCode string, accessURL string) error {
-	hasPerm, err := s.rolePermissionRepo.HasAccessURL(ctx, roleCode, accessURL)
+// HasAccessURL 检查角色集合是否有访问权限
+//
+// 请求匹配的权限中任一为公共权限或已授予任一角色即可访问，未登记的接口拒绝访问
+func (s *roleService) HasAccessURL(ctx *context.Context, roleCodes []string, method string, accessURL string) error {
+	perms, err := s.MatchPermissions(ctx, method, accessURL)
 	if err != nil {
-		ctx.Logger.Errorf("hasPermission HasAccessURL %v", err)
+		return err
+	}
+	codes := make([]string, 0, len(perms))
+	for _, p := range perms {
+		if p.IsGlobal() {
+			return nil
+		}
+		codes = append(codes, p.Code)
+	}
+	hasPerm, err := s.hasAnyPermission(ctx, roleCodes, codes)
+	if err != nil {
+		ctx.Logger.Errorf("%s 检查角色权限失败: %v %v", s.logPrefix(), roleCodes, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 	if !hasPerm {
@@ -360,6 +485,17 @@ func (s *roleService) HasAccessURL(ctx *context.Context, roleCode string, access
 	return nil
 }
 
+// MatchPermissions 获取与请求方法及URL最匹配的权限
+func (s *roleService) MatchPermissions(
+	ctx *context.Context, method string, accessURL string) ([]permission.Permission, error) {
+	perms, err := s.matcher.match(ctx, s.rolePermissionRepo, s.routeGeneration(), method, accessURL)
+	if err != nil {
+		ctx.Logger.Errorf("%s 加载权限路由失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return perms, nil
+}
+
 // ListAllPermissions 获取所有权限列表
 func (s *roleService) ListAllPermissions(ctx *context.Context) ([]map[string]any, error) {
 	permissions, err := s.rolePermissionRepo.GetAllPermissions(ctx, false)
@@ -368,9 +504,13 @@ func (s *roleService) ListAllPermissions(ctx *context.Context) ([]map[string]any
 		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 
-	// 将权限按模块分组
+	// 将权限按模块分组，租户内不列出不可授予的权限
+	tenantOnly := inTenant(ctx)
 	moduleMap := make(map[string][]map[string]any)
 	for _, perm := range permissions {
+		if tenantOnly && !tenantAssignable(perm.Code) {
+			continue
+		}
 		permMap := map[string]any{
 			"code":        perm.Code,
 			"name":        perm.Name,
@@ -391,3 +531,30 @@ func (s *roleService) ListAllPermissions(ctx *context.Context) ([]map[string]any
 
 	return result, nil
 }
+
+// SyncPermissionCatalog 将路由声明的权限目录同步到权限表，dryRun 时只返回差异
+//
+// 新增及变更的权限写入数据库；未被路由声明的孤立权限可能仍被角色引用，只记录告警
+func (s *roleService) SyncPermissionCatalog(ctx *context.CliContext, dryRun bool) (*catalog.Plan, error) {
+	existing, err := s.rolePermissionRepo.GetAllPermissions(ctx)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取所有权限列表失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	plan := catalog.Diff(catalog.Entries(), existing)
+	for _, p := range plan.Orphans {
+		ctx.Logger.Warnf("%s 权限未被任何路由声明: %s %s", s.logPrefix(), p.Code, p.Path)
+	}
+	if dryRun || !plan.Changed() {
+		return plan, nil
+	}
+
+	if err = s.rolePermissionRepo.SavePermissions(ctx, append(plan.Create, plan.Update...)); err != nil {
+		ctx.Logger.Errorf("%s 同步权限目录失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+	s.invalidatePermCache(ctx, ctx.Logger)
+	ctx.Logger.Infof("%s 同步权限目录: 新增 %d 更新 %d 孤立 %d",
+		s.logPrefix(), len(plan.Create), len(plan.Update), len(plan.Orphans))
+	return plan, nil
+}
@@ -0,0 +1,63 @@
+package role
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/model/permission"
+	rolerepo "goadmin/internal/repository/role"
+	"goadmin/pkg/routetrie"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 权限路由树的最长重建间隔，兜底未开启鉴权缓存时其他进程（如 permissions sync 命令）对权限表的修改
+const routeMatcherTTL = time.Minute
+
+// routeGen 本进程内权限路由的失效代数，与角色权限缓存同时失效
+var routeGen atomic.Uint64
+
+// routeMatcher 由权限表编译的路由树，按请求方法及路径查找权限
+type routeMatcher struct {
+	mu       sync.Mutex
+	trie     *routetrie.Trie[permission.Permission]
+	gen      uint64
+	loadedAt time.Time
+}
+
+// match 返回与请求最匹配的路由模式对应的权限，gen 变化时重建路由树
+func (m *routeMatcher) match(ctx *context.Context,
+	repo rolerepo.RolePermissionRepository, gen uint64, method, accessURL string) ([]permission.Permission, error) {
+	trie, err := m.load(ctx, repo, gen)
+	if err != nil {
+		return nil, err
+	}
+	perms, _ := trie.Match(method, accessURL)
+	return perms, nil
+}
+
+// load 获取路由树，已失效或过期时从数据库重建，重建失败时沿用旧的路由树
+func (m *routeMatcher) load(ctx *context.Context,
+	repo rolerepo.RolePermissionRepository, gen uint64) (*routetrie.Trie[permission.Permission], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.trie != nil && m.gen == gen && time.Since(m.loadedAt) < routeMatcherTTL {
+		return m.trie, nil
+	}
+
+	perms, err := repo.GetAllPermissions(ctx)
+	if err != nil {
+		if m.trie != nil {
+			ctx.Logger.Errorf("role-service 重建权限路由失败，沿用旧数据: %v", err)
+			return m.trie, nil
+		}
+		return nil, err
+	}
+	trie := routetrie.New[permission.Permission]()
+	for _, p := range perms {
+		if err = trie.Add(p.Method, p.Path, p); err != nil {
+			ctx.Logger.Warnf("role-service 忽略无效的权限路由: %s %v", p.Code, err)
+		}
+	}
+	m.trie, m.gen, m.loadedAt = trie, gen, time.Now()
+	return trie, nil
+}
@@ -0,0 +1,32 @@
+package role
+
+import (
+	stdctx "context"
+	"goadmin/internal/model/role"
+	"goadmin/pkg/db"
+	"slices"
+	"strings"
+)
+
+// platformPermissions 平台级权限，仅超级管理员可用，不可授予租户角色
+var platformPermissions = []string{
+	"server_decrypted",         // 解密查看系统设置中的密钥
+	"operate_log_writer_stats", // 全部实例的日志写入统计
+}
+
+// readOnly 角色对当前租户是否只读，平台内置角色各租户共享但不能修改
+func readOnly(ctx stdctx.Context, r *role.Role) bool {
+	tenantID, ok := db.TenantFrom(ctx)
+	return ok && r.TenantID != tenantID
+}
+
+// inTenant 当前请求是否在某个租户内管理角色
+func inTenant(ctx stdctx.Context) bool {
+	tenantID, ok := db.TenantFrom(ctx)
+	return ok && tenantID > 0
+}
+
+// tenantAssignable 权限能否授予租户角色，租户管理及平台级权限仅限超级管理员
+func tenantAssignable(code string) bool {
+	return !strings.HasPrefix(code, "tenant_") && !slices.Contains(platformPermissions, code)
+}
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"encoding/json"
+
+	"goadmin/internal/context"
+	"goadmin/internal/model/server"
+	"goadmin/internal/model/tenant"
+	"goadmin/pkg/db"
+)
+
+// defaults 内置默认配置，全局及租户均未配置时使用
+var defaults = map[string]any{
+	server.SettingSystemConfig:  server.SystemConfig{SystemName: "管理系统", Language: "zh_CN"},
+	server.SettingCaptchaSwitch: server.CaptchaSwitchConfig{Admin: server.SwitchOff, Web: server.SwitchOff},
+}
+
+// tenantConfig 当前租户的扩展配置
+//
+// 已登录请求取数据隔离所用的租户，未登录请求取按请求头或子域名识别的租户；
+// 租户不存在或配置无效时忽略，只使用全局配置
+func (s *serverSettingServiceImpl) tenantConfig(ctx *context.Context) tenant.Config {
+	id, ok := db.TenantFrom(ctx)
+	if !ok || id == 0 {
+		id, _ = ctx.Value(tenant.HintCtxKey).(uint64)
+	}
+	if id == 0 {
+		return nil
+	}
+	t, err := s.tenantRepo.GetByID(db.SkipScope(ctx), id)
+	if err != nil {
+		ctx.Logger.Errorf("%s tenantConfig GetByID %d failed, err: %v", s.logPrefix(), id, err)
+		return nil
+	}
+	if t == nil {
+		return nil
+	}
+	cfg, err := tenant.ParseConfig(t.Config)
+	if err != nil {
+		ctx.Logger.Warnf("%s tenantConfig invalid config of tenant %d, err: %v", s.logPrefix(), id, err)
+		return nil
+	}
+	return cfg
+}
+
+// resolve 按 租户覆盖 → 全局配置 → 内置默认 合并配置值
+//
+// 各层均为 JSON 对象时按字段合并，上层字段覆盖下层同名字段；否则上层整体替换下层。
+// 全局配置为空字符串表示未配置，各层均未配置时返回 nil
+func resolve(name, global string, overrides tenant.Config) (any, error) {
+	var value any
+	if d, ok := defaults[name]; ok {
+		raw, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+	}
+	layers := []json.RawMessage{json.RawMessage(global), overrides.Override(name)}
+	for _, layer := range layers {
+		if len(layer) == 0 {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(layer, &v); err != nil {
+			return nil, err
+		}
+		value = merge(value, v)
+	}
+	return value, nil
+}
+
+// merge 将 upper 合并到 lower
+func merge(lower, upper any) any {
+	lm, ok1 := lower.(map[string]any)
+	um, ok2 := upper.(map[string]any)
+	if !ok1 || !ok2 {
+		return upper
+	}
+	merged := make(map[string]any, len(lm)+len(um))
+	for k, v := range lm {
+		merged[k] = v
+	}
+	for k, v := range um {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,76 @@
+package setting
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"goadmin/internal/model/server"
+	"goadmin/internal/model/tenant"
+)
+
+func TestResolve(t *testing.T) {
+	overrides, err := tenant.ParseConfig(`{"system_config":{"system_name":"租户A","logo":"/a.png"},"captcha_switch":{"admin":1}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	global := `{"system_name":"平台","logo":"/p.png","language":"en_US"}`
+
+	cases := []struct {
+		name      string
+		setting   string
+		global    string
+		overrides tenant.Config
+		want      any
+	}{
+		{"Default", server.SettingSystemConfig, "", nil,
+			map[string]any{"system_name": "管理系统", "logo": "", "language": "zh_CN"}},
+		{"Global", server.SettingSystemConfig, global, nil,
+			map[string]any{"system_name": "平台", "logo": "/p.png", "language": "en_US"}},
+		{"Tenant", server.SettingSystemConfig, global, overrides,
+			map[string]any{"system_name": "租户A", "logo": "/a.png", "language": "en_US"}},
+		{"TenantOverDefault", server.SettingCaptchaSwitch, "", overrides,
+			map[string]any{"admin": float64(1), "web": float64(0)}},
+		{"Missing", server.SettingTwoFactor, "", overrides, nil},
+		{"NonObject", "custom", `[1,2]`, nil, []any{float64(1), float64(2)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolve(tc.setting, tc.global, tc.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("resolve = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+
+	if _, err = resolve(server.SettingSystemConfig, `{`, nil); err == nil {
+		t.Error("invalid global value should fail")
+	}
+}
+
+func TestParseTenantConfig(t *testing.T) {
+	for _, raw := range []string{``, `{}`, `{"system_config":{"language":"en_US"},"extra":{"domain":"a.example.com"}}`} {
+		if _, err := tenant.ParseConfig(raw); err != nil {
+			t.Errorf("ParseConfig(%q): %v", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		`{"system_config":{"language":"fr"}}`,
+		`{"system_config":{"system_name":""}}`,
+		`{"captcha_switch":{"admin":2}}`,
+		`{"two_factor":{}}`,
+		`not json`,
+	} {
+		if _, err := tenant.ParseConfig(raw); err == nil {
+			t.Errorf("ParseConfig(%q) should fail", raw)
+		}
+	}
+	// 覆盖值原样保留
+	cfg, _ := tenant.ParseConfig(`{"captcha_switch":{"web":1}}`)
+	if string(cfg.Override(server.SettingCaptchaSwitch)) != `{"web":1}` || cfg.Override(server.SettingSystemConfig) != nil {
+		t.Errorf("Override = %s", json.RawMessage(cfg.Override(server.SettingCaptchaSwitch)))
+	}
+}
@@ -1,13 +1,14 @@
 package setting
 
 import (
+	"goadmin/config"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
 	"goadmin/internal/model/server"
 	serverRepo "goadmin/internal/repository/server"
+	tenantrepo "goadmin/internal/repository/tenant"
 	"goadmin/pkg/db"
 	"goadmin/pkg/util"
-	"reflect"
 )
 
 // ServerSettingService 服务端设置服务接口
@@ -15,7 +16,7 @@ type ServerSettingService interface {
 	// SetByName 设置服务端配置
 	SetByName(ctx *context.Context, name string, value any) error
 
-	// GetSrcValue 根据名称获取服务端配置值
+	// GetSrcValue 根据名称获取服务端配置值，按 租户覆盖 → 全局配置 → 内置默认 合并
 	GetSrcValue(ctx *context.Context, name string, resultPtr any) error
 
 	// GetValues 根据名称批量获取服务端配置值
@@ -24,7 +25,7 @@ type ServerSettingService interface {
 	// GetByName 根据名称获取服务端配置
 	GetByName(ctx *context.Context, name string) (*server.ServerSetting, error)
 
-	// GetSystemSettings 获取系统设置
+	// GetSystemSettings 获取系统设置，合并规则同 GetSrcValue
 	GetSystemSettings(ctx *context.Context) (*server.SystemSettingsResponse, error)
 
 	// SetSystemSettings 设置系统设置
@@ -39,25 +40,32 @@ type ServerSettingService interface {
 
 // serverSettingServiceImpl 服务端设置服务实现
 type serverSettingServiceImpl struct {
-	repo serverRepo.ServerSettingRepository
+	cfg        *config.Config
+	repo       serverRepo.ServerSettingRepository
+	tenantRepo tenantrepo.Repository
 }
 
 // NewServerSettingService 创建服务端设置服务（Wire 注入）
-func NewServerSettingService(repo serverRepo.ServerSettingRepository) ServerSettingService {
+func NewServerSettingService(
+	cfg *config.Config, repo serverRepo.ServerSettingRepository, tenantRepo tenantrepo.Repository) ServerSettingService {
 	return &serverSettingServiceImpl{
-		repo: repo,
+		cfg:        cfg,
+		repo:       repo,
+		tenantRepo: tenantRepo,
 	}
 }
 
-// Deprecated: 使用 NewServerSettingService(repo) 替代
+// Deprecated: 使用 NewServerSettingService(cfg, repo, tenantRepo) 替代
 // NewServerSettingService_legacy 创建服务端设置服务（兼容旧代码，使用全局db）
 func NewServerSettingService_legacy() ServerSettingService {
-	return NewServerSettingService(serverRepo.NewServerSettingRepository(db.GetDB()))
+	return NewServerSettingService(config.Get(),
+		serverRepo.NewServerSettingRepository(db.GetDB()), tenantrepo.NewTenantRepository_legacy())
 }
 
-// NewServerSettingServiceWithRepo creates a ServerSettingService with the given repository (for Wire compatibility).
-func NewServerSettingServiceWithRepo(repo serverRepo.ServerSettingRepository) ServerSettingService {
-	return NewServerSettingService(repo)
+// NewServerSettingServiceWithRepo creates a ServerSettingService with the given repositories (for Wire compatibility).
+func NewServerSettingServiceWithRepo(cfg *config.Config,
+	repo serverRepo.ServerSettingRepository, tenantRepo tenantrepo.Repository) ServerSettingService {
+	return NewServerSettingService(cfg, repo, tenantRepo)
 }
 
 func (s *serverSettingServiceImpl) logPrefix() string {
@@ -88,7 +96,7 @@ func (s *serverSettingServiceImpl) SetByName(ctx *context.Context, name string,
 		ctx.Logger.Errorf("%s SetByName GetByName failed, err: %v", s.logPrefix(), err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
-	if setting == nil {
+	if setting == nil || !ownSetting(ctx, setting) {
 		// 创建新配置
 		setting = &server.ServerSetting{
 			Name:  name,
@@ -107,95 +115,121 @@ func (s *serverSettingServiceImpl) SetByName(ctx *context.Context, name string,
 	return nil
 }
 
-// GetValue 根据名称获取服务端配置值
+// GetSrcValue 根据名称获取服务端配置值，按 租户覆盖 → 全局配置 → 内置默认 合并，均未配置时 resultPtr 保持不变
 func (s *serverSettingServiceImpl) GetSrcValue(ctx *context.Context, name string, resultPtr any) error {
-	setting, err := s.GetByName(ctx, name)
+	setting, err := s.repo.GetByName(ctx, name)
 	if err != nil {
+		ctx.Logger.Errorf("%s GetSrcValue GetByName failed, err: %v", s.logPrefix(), err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	var global string
+	if setting != nil {
+		global = setting.Value
+	}
+	value, err := resolve(name, global, s.tenantConfig(ctx))
+	if err != nil {
+		ctx.Logger.Errorf("%s GetSrcValue resolve %s failed, err: %v", s.logPrefix(), name, err)
 		return err
 	}
-	// 如果配置不存在，返回空对象
-	if setting == nil {
-		// 将resultPtr设置为空map
-		if reflect.ValueOf(resultPtr).Kind() == reflect.Ptr {
-			// 创建一个空的map[string]any并赋值给resultPtr
-			emptyMap := make(map[string]any)
-			reflect.ValueOf(resultPtr).Elem().Set(reflect.ValueOf(emptyMap))
-		}
+	if value == nil {
 		return nil
 	}
-	return decoding(setting.Value, resultPtr)
+	str, err := encoding(value)
+	if err != nil {
+		return err
+	}
+	return decoding(str, resultPtr)
 }
 
 // GetValues 根据名称批量获取服务端配置值
 func (s *serverSettingServiceImpl) GetValues(ctx *context.Context, names []string) (map[string]any, error) {
-	result := make(map[string]any)
-
-	// 批量获取配置
-	settings, err := s.repo.BatchGet(ctx, names)
+	globals, err := s.globalValues(ctx, names)
 	if err != nil {
 		ctx.Logger.Errorf("%s GetValues failed, err: %v", s.logPrefix(), err)
 		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 
-	// 将配置转换为map
-	for _, setting := range settings {
-		var value any
-		err = decoding(setting.Value, &value)
+	overrides := s.tenantConfig(ctx)
+	result := make(map[string]any, len(names))
+	for _, name := range names {
+		value, err := resolve(name, globals[name], overrides)
 		if err != nil {
-			ctx.Logger.Errorf("%s GetValues unmarshal failed for %s, err: %v", s.logPrefix(), setting.Name, err)
-			// 如果解析失败，返回空对象
-			result[setting.Name] = make(map[string]any)
-			continue
+			ctx.Logger.Errorf("%s GetValues unmarshal failed for %s, err: %v", s.logPrefix(), name, err)
 		}
-		result[setting.Name] = value
-	}
-
-	// 对于不存在的配置，返回空对象
-	for _, name := range names {
-		if _, exists := result[name]; !exists {
-			result[name] = make(map[string]any)
+		// 解析失败或不存在的配置，返回空对象
+		if err != nil || value == nil {
+			value = make(map[string]any)
 		}
+		result[name] = value
 	}
-
 	return result, nil
 }
 
+// globalValues 批量获取全局配置原始值，租户另存的配置优先
+func (s *serverSettingServiceImpl) globalValues(ctx *context.Context, names []string) (map[string]string, error) {
+	settings, err := s.repo.BatchGet(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Name] = setting.Value
+	}
+	return values, nil
+}
+
 // GetSystemSettings 获取系统设置
 func (s *serverSettingServiceImpl) GetSystemSettings(ctx *context.Context) (*server.SystemSettingsResponse, error) {
-	cfgs, err := s.repo.BatchGet(ctx, []string{server.SettingCaptchaSwitch, server.SettingSystemConfig})
+	var rs server.SystemSettingsResponse
+	targets := []struct {
+		name string
+		ptr  any
+	}{
+		{server.SettingCaptchaSwitch, &rs.CaptchaSwitchConfig},
+		{server.SettingSystemConfig, &rs.SystemConfig},
+		{server.SettingTwoFactor, &rs.TwoFactorConfig},
+		{server.SettingLoginSecurity, &rs.LoginSecurityConfig},
+		{server.SettingPasswordPolicy, &rs.PasswordPolicyConfig},
+		{server.SettingPasswordReset, &rs.PasswordResetConfig},
+	}
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.name)
+	}
+	globals, err := s.globalValues(ctx, names)
 	if err != nil {
 		ctx.Logger.Errorf("%s GetSystemSettings failed, err: %v", s.logPrefix(), err)
 		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
-	var (
-		rs            server.SystemSettingsResponse
-		captchaSwitch server.CaptchaSwitchConfig
-		systemConfig  server.SystemConfig
-	)
-
-	for _, cfg := range cfgs {
-		switch cfg.Name {
-		case server.SettingCaptchaSwitch:
-			err = decoding(cfg.Value, &captchaSwitch)
-			if err != nil {
-				ctx.Logger.Errorf("%s GetSystemSettings unmarshal captcha switch failed, err: %v", s.logPrefix(), err)
-				return nil, err
-			}
-		case server.SettingSystemConfig:
-			err = decoding(cfg.Value, &systemConfig)
-			if err != nil {
-				ctx.Logger.Errorf("%s GetSystemSettings unmarshal system config failed, err: %v", s.logPrefix(), err)
-				return nil, err
-			}
+
+	overrides := s.tenantConfig(ctx)
+	for _, target := range targets {
+		value, err := resolve(target.name, globals[target.name], overrides)
+		if err != nil {
+			ctx.Logger.Errorf("%s GetSystemSettings unmarshal %s failed, err: %v", s.logPrefix(), target.name, err)
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		str, err := encoding(value)
+		if err != nil {
+			return nil, err
+		}
+		if err = decoding(str, target.ptr); err != nil {
+			ctx.Logger.Errorf("%s GetSystemSettings unmarshal %s failed, err: %v", s.logPrefix(), target.name, err)
+			return nil, err
 		}
 	}
-	rs.CaptchaSwitchConfig = captchaSwitch
-	rs.SystemConfig = systemConfig
 	return &rs, nil
 }
 
 // SetSystemSettings 设置系统设置
 func (s *serverSettingServiceImpl) SetSystemSettings(ctx *context.Context, settings *server.SystemSettingsRequest) error {
+	// 找回密码令牌使用独立密钥签名，未配置时不允许开启
+	if settings.PasswordResetConfig.IsOn() && s.cfg.PasswordReset.Secret == "" {
+		return i18n.E(ctx.Context, "user.PasswordResetSecretMissing", nil)
+	}
 	err := s.SetByName(ctx, server.SettingCaptchaSwitch, settings.CaptchaSwitchConfig)
 	if err != nil {
 		ctx.Logger.Errorf("%s SetSystemSettings SetCaptchaSwitch failed, err: %v", s.logPrefix(), err)
@@ -206,6 +240,26 @@ func (s *serverSettingServiceImpl) SetSystemSettings(ctx *context.Context, setti
 		ctx.Logger.Errorf("%s SetSystemSettings SettingSystemConfig failed, err: %v", s.logPrefix(), err)
 		return err
 	}
+	err = s.SetByName(ctx, server.SettingTwoFactor, settings.TwoFactorConfig)
+	if err != nil {
+		ctx.Logger.Errorf("%s SetSystemSettings SettingTwoFactor failed, err: %v", s.logPrefix(), err)
+		return err
+	}
+	err = s.SetByName(ctx, server.SettingLoginSecurity, settings.LoginSecurityConfig)
+	if err != nil {
+		ctx.Logger.Errorf("%s SetSystemSettings SettingLoginSecurity failed, err: %v", s.logPrefix(), err)
+		return err
+	}
+	err = s.SetByName(ctx, server.SettingPasswordPolicy, settings.PasswordPolicyConfig)
+	if err != nil {
+		ctx.Logger.Errorf("%s SetSystemSettings SettingPasswordPolicy failed, err: %v", s.logPrefix(), err)
+		return err
+	}
+	err = s.SetByName(ctx, server.SettingPasswordReset, settings.PasswordResetConfig)
+	if err != nil {
+		ctx.Logger.Errorf("%s SetSystemSettings SettingPasswordReset failed, err: %v", s.logPrefix(), err)
+		return err
+	}
 	return nil
 }
 
@@ -229,7 +283,7 @@ func (s *serverSettingServiceImpl) SetEncryptedValue(ctx *context.Context, name
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 
-	if setting == nil {
+	if setting == nil || !ownSetting(ctx, setting) {
 		// 创建新配置
 		setting = &server.ServerSetting{
 			Name:  name,
@@ -275,3 +329,9 @@ func (s *serverSettingServiceImpl) GetDecryptedValue(ctx *context.Context, name
 
 	return value, err
 }
+
+// ownSetting 配置是否属于当前租户，租户修改全局配置时另存一份自有配置
+func ownSetting(ctx *context.Context, setting *server.ServerSetting) bool {
+	tenantID, ok := db.TenantFrom(ctx)
+	return !ok || setting.TenantID == tenantID
+}
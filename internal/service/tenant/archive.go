@@ -0,0 +1,64 @@
+package tenant
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// archiveLine 归档文件中的一行，对应一条记录
+type archiveLine struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+// archiveWriter 将租户数据写入 gzip 压缩的 JSON Lines 文件，实现 tenantrepo.Exporter
+type archiveWriter struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// newArchiveWriter 在 dir 下创建租户的归档文件
+func newArchiveWriter(dir string, tenantID uint64) (*archiveWriter, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("tenant-%d-%s.jsonl.gz", tenantID, time.Now().Format("20060102150405"))
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(f)
+	return &archiveWriter{path: path, file: f, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// Write 写入一条记录
+func (w *archiveWriter) Write(table string, row map[string]any) error {
+	return w.enc.Encode(archiveLine{Table: table, Row: row})
+}
+
+// Close 刷新并关闭文件，数据写入磁盘后返回
+func (w *archiveWriter) Close() error {
+	if err := w.gz.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Remove 删除归档文件，用于注销失败时清理
+func (w *archiveWriter) Remove() {
+	_ = w.gz.Close()
+	_ = w.file.Close()
+	_ = os.Remove(w.path)
+}
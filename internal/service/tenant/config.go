@@ -0,0 +1,16 @@
+package tenant
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/tenant"
+)
+
+// checkConfig 按 JSON Schema 校验租户扩展配置
+func (s *tenantService) checkConfig(ctx *context.Context, raw string) error {
+	if _, err := tenant.ParseConfig(raw); err != nil {
+		ctx.Logger.Warnf("%s 租户扩展配置无效: %v", s.logPrefix(), err)
+		return i18n.E(ctx.Context, "tenant.ConfigInvalid", map[string]any{"err": err.Error()})
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package tenant
+
+import (
+	"sync"
+
+	tenantrepo "goadmin/internal/repository/tenant"
+)
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []tenantrepo.Hook
+)
+
+// RegisterHook 注册开通、注销租户的扩展步骤，按注册顺序执行
+//
+// 各模块可在 init 中注册，为新租户写入初始数据或在注销时清理自有数据
+func RegisterHook(h tenantrepo.Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// registeredHooks 已注册的扩展步骤
+func registeredHooks() []tenantrepo.Hook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return append([]tenantrepo.Hook(nil), hooks...)
+}
@@ -0,0 +1,281 @@
+package tenant
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/server"
+	"goadmin/internal/model/tenant"
+	modeluser "goadmin/internal/model/user"
+	tenantrepo "goadmin/internal/repository/tenant"
+	"goadmin/internal/service/token"
+	userservice "goadmin/internal/service/user"
+	"goadmin/pkg/db"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/util"
+)
+
+// maxSystemNameLen 租户扩展配置中系统名称的长度上限，与 config.schema.json 一致
+const maxSystemNameLen = 64
+
+var (
+	// errCodeExists 租户编码已存在
+	errCodeExists = errors.New("tenant code already exists")
+	// errUsernameExists 管理员用户名已存在
+	errUsernameExists = errors.New("admin username already exists")
+	// errEmailExists 管理员邮箱已存在
+	errEmailExists = errors.New("admin email already exists")
+)
+
+// configError 租户扩展配置不符合 JSON Schema
+type configError struct{ err error }
+
+func (e *configError) Error() string { return "invalid tenant config: " + e.err.Error() }
+
+func (e *configError) Unwrap() error { return e.err }
+
+// ProvisionTenant 开通租户
+func (s *tenantService) ProvisionTenant(
+	ctx *context.Context, req *tenant.ProvisionRequest) (*tenant.ProvisionResponse, error) {
+	if err := s.requireSuperAdmin(ctx); err != nil {
+		return nil, err
+	}
+	rs, err := s.provision(ctx, ctx.Logger, req)
+	var cfgErr *configError
+	switch {
+	case errors.As(err, &cfgErr):
+		return nil, i18n.E(ctx.Context, "tenant.ConfigInvalid", map[string]any{"err": cfgErr.err.Error()})
+	case errors.Is(err, errCodeExists):
+		return nil, i18n.E(
+			ctx.Context, "common.HadExist", map[string]any{"item": i18n.T(ctx.Context, "common.item.tenant", nil)})
+	case errors.Is(err, errUsernameExists):
+		return nil, i18n.E(ctx.Context, "user.UsernameAlreadyExists", nil)
+	case errors.Is(err, errEmailExists):
+		return nil, i18n.E(ctx.Context, "user.EmailAlreadyExists", nil)
+	case errors.Is(err, tenantrepo.ErrRoleTemplateNotFound):
+		return nil, i18n.E(ctx.Context, "tenant.RoleTemplateNotFound",
+			map[string]any{"code": s.cfg.Tenant.AdminRoleOrDefault()})
+	case err != nil:
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.Tenant.Provision", map[string]any{"code": req.Code}))
+	return rs, nil
+}
+
+// ProvisionTenantCli 开通租户，供命令行调用
+func (s *tenantService) ProvisionTenantCli(
+	ctx *context.CliContext, req *tenant.ProvisionRequest) (*tenant.ProvisionResponse, error) {
+	return s.provision(ctx, ctx.Logger, req)
+}
+
+// provision 开通租户
+//
+// 在同一事务中创建租户、由模板克隆的管理员角色、使用随机密码的管理员用户，并执行已注册的扩展步骤，
+// 任一步失败整体回滚。租户未配置系统名称时默认使用租户名称
+func (s *tenantService) provision(
+	ctx stdctx.Context, log logger.Logger, req *tenant.ProvisionRequest) (*tenant.ProvisionResponse, error) {
+	cfg, err := seedConfig(req.Config, req.Name)
+	if err != nil {
+		log.Warnf("%s 租户扩展配置无效: %v", s.logPrefix(), err)
+		return nil, &configError{err: err}
+	}
+
+	exists, err := s.tenantRepo.ExistsByCode(ctx, req.Code)
+	if err != nil {
+		log.Errorf("%s 检查租户编码是否存在失败: %s %v", s.logPrefix(), req.Code, err)
+		return nil, err
+	}
+	if exists {
+		log.Warnf("%s 租户编码已存在: %s", s.logPrefix(), req.Code)
+		return nil, errCodeExists
+	}
+	if err = s.checkAdmin(ctx, log, req); err != nil {
+		return nil, err
+	}
+
+	admin, password, err := s.newAdmin(ctx, log, req)
+	if err != nil {
+		return nil, err
+	}
+
+	status := tenant.TenantStatusEnabled
+	if req.Status == int(tenant.TenantStatusDisabled) {
+		status = tenant.TenantStatusDisabled
+	}
+	p := &tenantrepo.Provision{
+		Tenant: &tenant.Tenant{
+			Name:         req.Name,
+			Code:         req.Code,
+			ContactEmail: req.ContactEmail,
+			ContactPhone: req.ContactPhone,
+			Status:       status,
+			Config:       cfg,
+		},
+		RoleTemplate: s.cfg.Tenant.AdminRoleOrDefault(),
+		Admin:        admin,
+	}
+	if err = s.provisionRepo.Provision(ctx, p, registeredHooks()); err != nil {
+		log.Errorf("%s 开通租户失败: %s %v", s.logPrefix(), req.Code, err)
+		return nil, err
+	}
+
+	log.Infof("%s 开通租户成功: %s %d", s.logPrefix(), req.Code, p.Tenant.ID)
+	return &tenant.ProvisionResponse{
+		Tenant:        p.Tenant,
+		AdminRole:     p.AdminRole,
+		AdminUsername: admin.Username,
+		AdminPassword: password,
+	}, nil
+}
+
+// checkAdmin 管理员用户名、邮箱全局唯一
+func (s *tenantService) checkAdmin(ctx stdctx.Context, log logger.Logger, req *tenant.ProvisionRequest) error {
+	exists, err := s.userRepo.IsUsernameExists(ctx, req.AdminUsername)
+	if err != nil {
+		log.Errorf("%s 检查用户名是否存在失败: %s %v", s.logPrefix(), req.AdminUsername, err)
+		return err
+	}
+	if exists {
+		log.Warnf("%s 用户名已存在: %s", s.logPrefix(), req.AdminUsername)
+		return errUsernameExists
+	}
+	if req.AdminEmail == "" {
+		return nil
+	}
+	exists, err = s.userRepo.IsEmailExists(ctx, req.AdminEmail)
+	if err != nil {
+		log.Errorf("%s 检查邮箱是否存在失败: %s %v", s.logPrefix(), req.AdminEmail, err)
+		return err
+	}
+	if exists {
+		log.Warnf("%s 邮箱已存在: %s", s.logPrefix(), req.AdminEmail)
+		return errEmailExists
+	}
+	return nil
+}
+
+// newAdmin 生成租户管理员，使用符合全局密码策略的随机密码，首次登录须修改
+func (s *tenantService) newAdmin(
+	ctx stdctx.Context, log logger.Logger, req *tenant.ProvisionRequest) (*modeluser.User, string, error) {
+	var policy server.PasswordPolicyConfig
+	setting, err := s.settingRepo.GetByName(db.SkipTenant(ctx), server.SettingPasswordPolicy)
+	if err != nil {
+		log.Errorf("%s 获取密码策略失败: %v", s.logPrefix(), err)
+		return nil, "", err
+	}
+	if setting != nil {
+		if err = json.Unmarshal([]byte(setting.Value), &policy); err != nil {
+			log.Errorf("%s 解析密码策略失败: %v", s.logPrefix(), err)
+			return nil, "", err
+		}
+	}
+	password, err := util.GenerateRandomPassword(policy.GenerateLength())
+	if err != nil {
+		log.Errorf("%s 生成随机密码失败: %v", s.logPrefix(), err)
+		return nil, "", err
+	}
+	encryptPwd, err := util.Password2Hash(util.PasswordDigest(password))
+	if err != nil {
+		log.Errorf("%s 密码加密失败: %s %v", s.logPrefix(), req.AdminUsername, err)
+		return nil, "", err
+	}
+	now := util.Now()
+	return &modeluser.User{
+		Username:           req.AdminUsername,
+		Password:           encryptPwd,
+		PasswordChangedAt:  &now,
+		MustChangePassword: true,
+		Email:              req.AdminEmail,
+		Status:             modeluser.UserStatusActive,
+	}, password, nil
+}
+
+// seedConfig 校验租户扩展配置，并写入默认的系统名称
+func seedConfig(raw, name string) (string, error) {
+	cfg, err := tenant.ParseConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		cfg = tenant.Config{}
+	}
+	system := map[string]any{}
+	if v := cfg.Override(server.SettingSystemConfig); v != nil {
+		if err = json.Unmarshal(v, &system); err != nil {
+			return "", err
+		}
+	}
+	if _, ok := system["system_name"]; !ok {
+		if r := []rune(name); len(r) > maxSystemNameLen {
+			name = string(r[:maxSystemNameLen])
+		}
+		system["system_name"] = name
+	}
+	if cfg[server.SettingSystemConfig], err = json.Marshal(system); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// DeprovisionTenant 注销租户
+//
+// 在同一事务中将租户的全部数据归档到 gzip 压缩的 JSON Lines 文件后删除，归档失败时不删除任何数据
+func (s *tenantService) DeprovisionTenant(
+	ctx *context.Context, req *schema.IDRequest) (*tenant.DeprovisionResponse, error) {
+	if err := s.requireSuperAdmin(ctx); err != nil {
+		return nil, err
+	}
+	t, err := s.GetTenantByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 注销期间新建的用户由认证时的租户状态校验拦截
+	userIDs, err := s.provisionRepo.UserIDs(ctx, t.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取租户用户失败: %d %v", s.logPrefix(), t.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+
+	w, err := newArchiveWriter(s.cfg.Tenant.ArchiveDirOrDefault(), t.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 创建归档文件失败: %d %v", s.logPrefix(), t.ID, err)
+		return nil, i18n.E(ctx.Context, "tenant.ArchiveFailed", nil)
+	}
+	rows, err := s.provisionRepo.Deprovision(ctx, t, w, registeredHooks())
+	if err != nil {
+		w.Remove()
+		ctx.Logger.Errorf("%s 注销租户失败: %d %v", s.logPrefix(), t.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.invalidateTenant(ctx, t.ID)
+	s.killSessions(ctx, userIDs)
+
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.Tenant.Deprovision", map[string]any{"code": t.Code}))
+
+	ctx.Logger.Infof("%s 注销租户成功: %d 归档 %s", s.logPrefix(), t.ID, w.path)
+	return &tenant.DeprovisionResponse{Archive: w.path, Rows: rows}, nil
+}
+
+// killSessions 注销租户用户的全部会话并使登录用户缓存失效，失败仅记录日志，不影响注销结果
+func (s *tenantService) killSessions(ctx *context.Context, userIDs []uint64) {
+	jwtToken := token.NewJwtTokenService(&s.cfg.JWT)
+	for _, id := range userIDs {
+		if err := jwtToken.RevokeAllSessions(ctx, id); err != nil {
+			ctx.Logger.Errorf("%s 注销用户会话失败: %d %v", s.logPrefix(), id, err)
+		}
+	}
+	if err := userservice.InvalidateSessionUsers(ctx, s.cfg, userIDs...); err != nil {
+		ctx.Logger.Errorf("%s 用户缓存失效通知失败: %v %v", s.logPrefix(), userIDs, err)
+	}
+}
@@ -1,12 +1,18 @@
 package tenant
 
 import (
+	"goadmin/config"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
+	"goadmin/internal/model/role"
 	"goadmin/internal/model/schema"
 	"goadmin/internal/model/tenant"
+	serverrepo "goadmin/internal/repository/server"
 	tenantrepo "goadmin/internal/repository/tenant"
+	userrepo "goadmin/internal/repository/user"
 	"goadmin/internal/service/operate_log"
+	userservice "goadmin/internal/service/user"
+	"goadmin/pkg/db"
 )
 
 // TenantService 租户服务接口
@@ -14,37 +20,90 @@ type TenantService interface {
 	// GetTenantByID 根据ID获取租户
 	GetTenantByID(ctx *context.Context, id uint64) (*tenant.Tenant, error)
 
+	// GetTenantByCode 根据编码获取租户
+	GetTenantByCode(ctx *context.Context, code string) (*tenant.Tenant, error)
+
 	// ListTenants 获取租户列表
 	ListTenants(ctx *context.Context, req *tenant.ListRequest) ([]*tenant.Tenant, int64, error)
 
-	// CreateTenant 创建租户
-	CreateTenant(ctx *context.Context, req *tenant.CreateRequest) error
+	// ProvisionTenant 开通租户，同时创建租户管理员角色及用户
+	ProvisionTenant(ctx *context.Context, req *tenant.ProvisionRequest) (*tenant.ProvisionResponse, error)
+
+	// ProvisionTenantCli 开通租户，供命令行调用
+	ProvisionTenantCli(ctx *context.CliContext, req *tenant.ProvisionRequest) (*tenant.ProvisionResponse, error)
 
 	// UpdateTenant 更新租户
 	UpdateTenant(ctx *context.Context, req *tenant.UpdateRequest) error
 
-	// DeleteTenant 删除租户
-	DeleteTenant(ctx *context.Context, req *schema.IDRequest) error
+	// DeprovisionTenant 注销租户，归档租户的全部数据后删除
+	DeprovisionTenant(ctx *context.Context, req *schema.IDRequest) (*tenant.DeprovisionResponse, error)
 }
 
 // tenantService 租户服务实现
 type tenantService struct {
-	tenantRepo tenantrepo.Repository
-	logService operate_log.OperateLogService
+	cfg           *config.Config
+	tenantRepo    tenantrepo.Repository
+	provisionRepo tenantrepo.ProvisionRepository
+	userRepo      userrepo.UserRepository
+	settingRepo   serverrepo.ServerSettingRepository
+	logService    operate_log.OperateLogService
 }
 
 // NewTenantService 创建租户服务实例（Wire 注入）
-func NewTenantService(tenantRepo tenantrepo.Repository, logService operate_log.OperateLogService) TenantService {
+func NewTenantService(
+	cfg *config.Config,
+	tenantRepo tenantrepo.Repository,
+	provisionRepo tenantrepo.ProvisionRepository,
+	userRepo userrepo.UserRepository,
+	settingRepo serverrepo.ServerSettingRepository,
+	logService operate_log.OperateLogService,
+) TenantService {
 	return &tenantService{
-		tenantRepo: tenantRepo,
-		logService: logService,
+		cfg:           cfg,
+		tenantRepo:    tenantRepo,
+		provisionRepo: provisionRepo,
+		userRepo:      userRepo,
+		settingRepo:   settingRepo,
+		logService:    logService,
 	}
 }
 
+// Deprecated: 使用 NewTenantService 替代
+// NewTenantService_legacy 创建租户服务实例（兼容旧代码，使用全局db）
+func NewTenantService_legacy() TenantService {
+	return NewTenantService(
+		config.Get(),
+		tenantrepo.NewTenantRepository_legacy(),
+		tenantrepo.NewProvisionRepository_legacy(),
+		userrepo.NewUserRepository_legacy(),
+		serverrepo.NewServerSettingRepository(db.GetDB()),
+		operate_log.NewOperateLogService_legacy(),
+	)
+}
+
 func (*tenantService) logPrefix() string {
 	return "tenant-service"
 }
 
+// invalidateTenant 租户状态变更后使认证中间件缓存的租户状态失效，失败时依赖缓存有效期兜底
+func (s *tenantService) invalidateTenant(ctx *context.Context, tenantID uint64) {
+	if err := userservice.InvalidateTenant(ctx, s.cfg, tenantID); err != nil {
+		ctx.Logger.Errorf("%s 租户状态缓存失效通知失败: %d %v", s.logPrefix(), tenantID, err)
+	}
+}
+
+// requireSuperAdmin 租户的列表、开通、更新及注销仅限超级管理员
+//
+// 权限码可被授予租户角色，租户管理员即使拥有权限也不可管理其他租户或停用、注销本租户
+func (s *tenantService) requireSuperAdmin(ctx *context.Context) error {
+	sess := ctx.Session()
+	if sess == nil || !sess.GetRoles().Has(role.CodeSuperAdmin) {
+		ctx.Logger.Warnf("%s 非超级管理员操作租户: %v", s.logPrefix(), ctx.Request.URL.Path)
+		return i18n.E(ctx.Context, "common.PermissionDeny", nil)
+	}
+	return nil
+}
+
 // GetTenantByID 根据ID获取租户
 func (s *tenantService) GetTenantByID(ctx *context.Context, id uint64) (*tenant.Tenant, error) {
 	t, err := s.tenantRepo.GetByID(ctx, id)
@@ -62,8 +121,26 @@ func (s *tenantService) GetTenantByID(ctx *context.Context, id uint64) (*tenant.
 	return t, nil
 }
 
+// GetTenantByCode 根据编码获取租户
+func (s *tenantService) GetTenantByCode(ctx *context.Context, code string) (*tenant.Tenant, error) {
+	t, err := s.tenantRepo.GetByCode(ctx, code)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取租户信息失败 GetByCode %s %v", s.logPrefix(), code, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if t == nil {
+		ctx.Logger.Warnf("%s 租户不存在: %s", s.logPrefix(), code)
+		return nil, i18n.E(
+			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.tenant", nil)})
+	}
+	return t, nil
+}
+
 // ListTenants 获取租户列表
 func (s *tenantService) ListTenants(ctx *context.Context, req *tenant.ListRequest) ([]*tenant.Tenant, int64, error) {
+	if err := s.requireSuperAdmin(ctx); err != nil {
+		return nil, 0, err
+	}
 	list, total, err := s.tenantRepo.PageList(ctx, req)
 	if err != nil {
 		ctx.Logger.Errorf("%s 获取租户列表失败: %v", s.logPrefix(), err)
@@ -75,50 +152,15 @@ func (s *tenantService) ListTenants(ctx *context.Context, req *tenant.ListReques
 	return list, total, nil
 }
 
-// CreateTenant 创建租户
-func (s *tenantService) CreateTenant(ctx *context.Context, req *tenant.CreateRequest) error {
-	// 检查租户编码是否已存在
-	exists, err := s.tenantRepo.ExistsByCode(ctx, req.Code)
-	if err != nil {
-		ctx.Logger.Errorf("%s 检查租户编码是否存在失败: %s %v", s.logPrefix(), req.Code, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
-	}
-	if exists {
-		ctx.Logger.Warnf("%s 租户编码已存在: %s", s.logPrefix(), req.Code)
-		return i18n.E(
-			ctx.Context, "common.HadExist", map[string]any{"item": i18n.T(ctx.Context, "common.item.tenant", nil)})
-	}
-
-	// 设置默认状态
-	status := tenant.TenantStatusEnabled
-	if req.Status == 2 {
-		status = tenant.TenantStatusDisabled
-	}
-
-	// 创建租户
-	t := &tenant.Tenant{
-		Name:         req.Name,
-		Code:         req.Code,
-		ContactEmail: req.ContactEmail,
-		ContactPhone: req.ContactPhone,
-		Status:       status,
-		Config:       req.Config,
+// UpdateTenant 更新租户
+func (s *tenantService) UpdateTenant(ctx *context.Context, req *tenant.UpdateRequest) error {
+	if err := s.requireSuperAdmin(ctx); err != nil {
+		return err
 	}
-
-	err = s.tenantRepo.Create(ctx, t)
-	if err != nil {
-		ctx.Logger.Errorf("%s 创建租户失败: %s %v", s.logPrefix(), req.Code, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	if err := s.checkConfig(ctx, req.Config); err != nil {
+		return err
 	}
 
-	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Tenant.Create", nil))
-
-	ctx.Logger.Infof("%s 创建租户成功: %s", s.logPrefix(), req.Code)
-	return nil
-}
-
-// UpdateTenant 更新租户
-func (s *tenantService) UpdateTenant(ctx *context.Context, req *tenant.UpdateRequest) error {
 	// 获取租户信息
 	t, err := s.tenantRepo.GetByID(ctx, req.ID)
 	if err != nil {
@@ -146,6 +188,7 @@ func (s *tenantService) UpdateTenant(ctx *context.Context, req *tenant.UpdateReq
 	}
 
 	// 更新字段
+	oldStatus := t.Status
 	t.Name = req.Name
 	t.ContactEmail = req.ContactEmail
 	t.ContactPhone = req.ContactPhone
@@ -162,37 +205,12 @@ func (s *tenantService) UpdateTenant(ctx *context.Context, req *tenant.UpdateReq
 		ctx.Logger.Errorf("%s 更新租户失败: %d %v", s.logPrefix(), req.ID, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	if t.Status != oldStatus {
+		s.invalidateTenant(ctx, t.ID)
+	}
 
 	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Tenant.Update", nil))
 
 	ctx.Logger.Infof("%s 更新租户成功: %d", s.logPrefix(), req.ID)
 	return nil
 }
-
-// DeleteTenant 删除租户
-func (s *tenantService) DeleteTenant(ctx *context.Context, req *schema.IDRequest) error {
-	// 获取租户信息
-	t, err := s.tenantRepo.GetByID(ctx, req.ID)
-	if err != nil {
-		ctx.Logger.Errorf("%s 获取租户信息失败: %d %v", s.logPrefix(), req.ID, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
-	}
-	if t == nil {
-		ctx.Logger.Warnf("%s 租户不存在: %d", s.logPrefix(), req.ID)
-		return i18n.E(
-			ctx.Context, "common.NotFound",
-			map[string]any{"item": i18n.T(ctx.Context, "common.item.tenant", nil)})
-	}
-
-	// 删除租户
-	err = s.tenantRepo.Delete(ctx, req.ID)
-	if err != nil {
-		ctx.Logger.Errorf("%s 删除租户失败: %d %v", s.logPrefix(), req.ID, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
-	}
-
-	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Tenant.Delete", nil))
-
-	ctx.Logger.Infof("%s 删除租户成功: %d", s.logPrefix(), req.ID)
-	return nil
-}
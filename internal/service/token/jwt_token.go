@@ -28,18 +28,33 @@ func (s *JwtTokenService) logPrefix() string {
 }
 
 // GenerateJWTTokenPair 生成JWT访问令牌和刷新令牌对
+//
+// claims 未携带会话ID时视为新登录并创建会话，否则沿用原会话（刷新令牌场景）
 func (s *JwtTokenService) GenerateJWTTokenPair(ctx *context.Context, claims Claims) (*TokenPair, error) {
+	var (
+		record *sessionRecord
+		err    error
+	)
+	if claims.SessionID == "" {
+		claims.SessionID = util.GenerateUUIDWithoutHyphen()
+		record = newSessionRecord(ctx, claims.SessionID)
+	} else {
+		record, err = s.getSession(ctx, claims.UserID, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("获取会话失败: %w", err)
+		}
+		if record == nil {
+			return nil, fmt.Errorf("会话已失效: %s", claims.SessionID)
+		}
+	}
+
 	accessToken, expiresAt, err := s.generateJWT(claims)
 	if err != nil {
 		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
 	}
 
 	// 生成刷新令牌
-	refreshExpire := s.config.RefreshExpire
-	if refreshExpire == 0 {
-		refreshExpire = 7 * 24 * time.Hour // 默认7天
-	}
-
+	refreshExpire := s.refreshExpire()
 	refreshToken := util.GenerateUUIDWithoutHyphen()
 
 	// 将刷新令牌存储到Redis中
@@ -47,8 +62,24 @@ func (s *JwtTokenService) GenerateJWTTokenPair(ctx *context.Context, claims Clai
 	// 存储用户ID和访问令牌的相关信息，用于刷新时生成新的访问令牌
 	refreshData := claims.String()
 
-	err = redisx.GetClient().Set(ctx, refreshKey, refreshData, refreshExpire).Err()
-	if err != nil {
+	// 轮换时旧的访问令牌随之作废
+	prevJTI, prevExpire := record.AccessJTI, record.AccessExpire
+
+	// 更新会话登记信息，吊销会话时据此使令牌失效
+	record.LastRefresh = time.Now().Unix()
+	record.RefreshToken = refreshToken
+	record.AccessJTI = claims.ID
+	record.AccessExpire = expiresAt
+
+	sessionsKey := s.getSessionsKey(claims.UserID)
+	pipe := redisx.GetClient().TxPipeline()
+	pipe.Set(ctx, refreshKey, refreshData, refreshExpire)
+	pipe.HSet(ctx, sessionsKey, record.ID, record.String())
+	pipe.Expire(ctx, sessionsKey, refreshExpire)
+	if ttl := time.Until(time.Unix(prevExpire, 0)); prevJTI != "" && ttl > 0 {
+		pipe.Set(ctx, s.getRevokedTokenKey(prevJTI), claims.UserID, ttl)
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
 		ctx.Logger.Errorf("%s 构建新的令牌失败: %s %s %v", s.logPrefix(), refreshKey, refreshData, err)
 		return nil, fmt.Errorf("保存刷新令牌失败: %w", err)
 	}
@@ -60,15 +91,31 @@ func (s *JwtTokenService) GenerateJWTTokenPair(ctx *context.Context, claims Clai
 	}, nil
 }
 
+// RefreshTokenReusedError 已轮换作废的刷新令牌被再次使用，通常意味着令牌已泄露
+type RefreshTokenReusedError struct {
+	UserID    uint64
+	SessionID string
+}
+
+func (e *RefreshTokenReusedError) Error() string {
+	return fmt.Sprintf("刷新令牌被重复使用: user=%d session=%s", e.UserID, e.SessionID)
+}
+
 // RefreshJWTToken 刷新JWT访问令牌
+//
+// 刷新令牌一次性使用：每次刷新都会作废旧令牌并签发新令牌。
+// 若已作废的令牌被再次提交，视为令牌泄露，吊销该用户的全部会话并返回 *RefreshTokenReusedError
 func (s *JwtTokenService) RefreshJWTToken(
 	ctx *context.Context, refreshToken string, f func(Claims) (Claims, error)) (*TokenPair, error) {
-	// 验证刷新令牌是否存在
+	// 原子地取出并删除刷新令牌，保证同一令牌只能成功刷新一次
 	refreshKey := s.getRefreshTokenKey(refreshToken)
-	str, err := redisx.GetClient().Get(ctx, refreshKey).Result()
+	str, err := redisx.GetClient().GetDel(ctx, refreshKey).Result()
+	if err == redisx.Nil {
+		return nil, s.checkRefreshTokenReused(ctx, refreshToken)
+	}
 	if err != nil {
-		ctx.Logger.Errorf("%s 刷新令牌无效或已过期: %s %v", s.logPrefix(), refreshKey, err)
-		return nil, fmt.Errorf("刷新令牌无效或已过期: %w", err)
+		ctx.Logger.Errorf("%s 读取刷新令牌失败: %s %v", s.logPrefix(), refreshKey, err)
+		return nil, fmt.Errorf("读取刷新令牌失败: %w", err)
 	}
 	var old Claims
 	err = json.Unmarshal([]byte(str), &old)
@@ -76,25 +123,71 @@ func (s *JwtTokenService) RefreshJWTToken(
 		return nil, fmt.Errorf("解析刷新令牌失败: %w", err)
 	}
 
+	// 记录已作废的刷新令牌，保留至其原有效期结束，用于识别重放
+	err = redisx.GetClient().Set(ctx, s.getUsedRefreshTokenKey(refreshToken), str, s.refreshExpire()).Err()
+	if err != nil {
+		ctx.Logger.Errorf("%s 记录已作废刷新令牌失败: %s %v", s.logPrefix(), refreshKey, err)
+	}
+
 	newClaims, err := f(old)
 	if err != nil {
 		ctx.Logger.Errorf("%s 构建新的令牌失败: %s %+v %v", s.logPrefix(), refreshKey, old, err)
 		return nil, fmt.Errorf("构建新的令牌失败: %w", err)
 	}
+	// 刷新后的令牌仍归属于原会话
+	newClaims.SessionID = old.SessionID
 
 	// 生成新的令牌对
 	return s.GenerateJWTTokenPair(ctx, newClaims)
 }
 
+// checkRefreshTokenReused 刷新令牌不存在时，区分“已过期/伪造”与“已作废令牌被重放”
+func (s *JwtTokenService) checkRefreshTokenReused(ctx *context.Context, refreshToken string) error {
+	str, err := redisx.GetClient().Get(ctx, s.getUsedRefreshTokenKey(refreshToken)).Result()
+	if err != nil {
+		ctx.Logger.Errorf("%s 刷新令牌无效或已过期: %s %v", s.logPrefix(), refreshToken, err)
+		return fmt.Errorf("刷新令牌无效或已过期: %w", err)
+	}
+	var old Claims
+	if err = json.Unmarshal([]byte(str), &old); err != nil {
+		return fmt.Errorf("解析刷新令牌失败: %w", err)
+	}
+
+	ctx.Logger.Warnf("%s 检测到刷新令牌重放，吊销用户全部会话: %d %s", s.logPrefix(), old.UserID, old.SessionID)
+	if err = s.RevokeAllSessions(ctx, old.UserID); err != nil {
+		return fmt.Errorf("吊销会话失败: %w", err)
+	}
+	return &RefreshTokenReusedError{UserID: old.UserID, SessionID: old.SessionID}
+}
+
 // ValidateJWTToken 验证JWT令牌并返回claims
+//
+// 根据令牌头部的 kid 选取验签密钥，并校验签发者与受众
 func (s *JwtTokenService) ValidateJWTToken(tokenString string) (*Claims, error) {
+	ks, err := loadKeySet(s.config)
+	if err != nil {
+		return nil, err
+	}
+	opts := []jwt.ParserOption{jwt.WithValidMethods(ks.algorithms())}
+	if s.config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.config.Issuer))
+	}
+	if len(s.config.Audience) > 0 {
+		opts = append(opts, jwt.WithAudience(s.config.Audience[0]))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.verifying(kid, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+		// 验证签名算法与密钥一致，防止算法混淆
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("invalid signing method: %v", token.Header["alg"])
 		}
-		return []byte(s.config.Secret), nil
-	})
+		return key.verifyKey, nil
+	}, opts...)
 
 	if err != nil {
 		return nil, err
@@ -107,6 +200,61 @@ func (s *JwtTokenService) ValidateJWTToken(tokenString string) (*Claims, error)
 	return nil, fmt.Errorf("invalid token")
 }
 
+// LoadKeys 预加载签名密钥，用于启动时尽早发现密钥配置错误
+func (s *JwtTokenService) LoadKeys() error {
+	ks, err := loadKeySet(s.config)
+	if err != nil {
+		return err
+	}
+	_, err = ks.signing(time.Now())
+	return err
+}
+
+// JWKS 返回用于验签的公钥集合，HMAC 签名时为空
+func (s *JwtTokenService) JWKS() (*JWKS, error) {
+	ks, err := loadKeySet(s.config)
+	if err != nil {
+		return nil, err
+	}
+	return ks.jwks(time.Now()), nil
+}
+
+// RevokeJWTToken 吊销访问令牌，并注销其所属会话（含配对的刷新令牌）
+//
+// 吊销记录的有效期与令牌剩余有效期一致，令牌过期后自动清理
+func (s *JwtTokenService) RevokeJWTToken(ctx *context.Context, claims *Claims) error {
+	if claims.ID == "" {
+		return fmt.Errorf("令牌缺少jti")
+	}
+	record, err := s.getSession(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取会话失败: %d %s %v", s.logPrefix(), claims.UserID, claims.SessionID, err)
+		return fmt.Errorf("获取会话失败: %w", err)
+	}
+	if record == nil {
+		record = &sessionRecord{SessionInfo: SessionInfo{ID: claims.SessionID}}
+	}
+	// 以当前令牌为准，避免会话记录滞后时漏掉吊销
+	record.AccessJTI = claims.ID
+	record.AccessExpire = claims.ExpiresAt.Unix()
+	return s.revokeSessions(ctx, claims.UserID, record)
+}
+
+// IsRevoked 检查访问令牌是否已被吊销或其所属会话已注销
+func (s *JwtTokenService) IsRevoked(ctx *context.Context, claims *Claims) (bool, error) {
+	if claims.ID == "" || claims.SessionID == "" {
+		// 未携带jti或会话ID的令牌无法吊销，一律视为无效
+		return true, nil
+	}
+	pipe := redisx.GetClient().Pipeline()
+	revoked := pipe.Exists(ctx, s.getRevokedTokenKey(claims.ID))
+	active := pipe.HExists(ctx, s.getSessionsKey(claims.UserID), claims.SessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return revoked.Val() > 0 || !active.Val(), nil
+}
+
 // InvalidateRefreshToken 使刷新令牌失效
 func (s *JwtTokenService) InvalidateRefreshToken(ctx *context.Context, refreshToken string) error {
 	refreshKey := s.getRefreshTokenKey(refreshToken)
@@ -115,13 +263,25 @@ func (s *JwtTokenService) InvalidateRefreshToken(ctx *context.Context, refreshTo
 
 // 生成JWT令牌
 func (s *JwtTokenService) generateJWT(claims Claims) (string, int64, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	ks, err := loadKeySet(s.config)
+	if err != nil {
+		return "", 0, err
+	}
+	key, err := ks.signing(time.Now())
+	if err != nil {
+		return "", 0, err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.kid != "" {
+		token.Header["kid"] = key.kid
+	}
 
 	// 获取过期时间
 	expiresAt := claims.ExpiresAt.Unix()
 
-	// 使用密钥签名
-	tokenString, err := token.SignedString([]byte(s.config.Secret))
+	// 使用当前密钥签名
+	tokenString, err := token.SignedString(key.signKey)
 	if err != nil {
 		return "", 0, err
 	}
@@ -137,3 +297,25 @@ func (s *JwtTokenService) getRefreshTokenKey(refreshToken string) string {
 	}
 	return prefix + refreshToken
 }
+
+// 获取已作废刷新令牌的Redis键
+func (s *JwtTokenService) getUsedRefreshTokenKey(refreshToken string) string {
+	return s.getRefreshTokenKey(refreshToken) + ":used"
+}
+
+// 获取已吊销访问令牌的Redis键
+func (s *JwtTokenService) getRevokedTokenKey(jti string) string {
+	prefix := s.config.RevokedTokenKey
+	if prefix == "" {
+		prefix = "revoked_token:"
+	}
+	return prefix + jti
+}
+
+// 刷新令牌有效期
+func (s *JwtTokenService) refreshExpire() time.Duration {
+	if s.config.RefreshExpire == 0 {
+		return 7 * 24 * time.Hour // 默认7天
+	}
+	return s.config.RefreshExpire
+}
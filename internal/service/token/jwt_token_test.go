@@ -0,0 +1,216 @@
+package token
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/config"
+	"goadmin/internal/context"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/redisx"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	testRedisOnce sync.Once
+	testRedis     *miniredis.Miniredis
+)
+
+// useTestRedis 使用进程内的 Redis，redisx 按DB缓存客户端，同一测试进程共用一个实例，每个测试前清空
+func useTestRedis(t *testing.T) {
+	t.Helper()
+	testRedisOnce.Do(func() {
+		testRedis = miniredis.NewMiniRedis()
+		if err := testRedis.Start(); err != nil {
+			t.Fatal(err)
+		}
+		port, _ := strconv.Atoi(testRedis.Port())
+		if err := redisx.Init(&config.RedisConfig{Enable: true, Host: testRedis.Host(), Port: port}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	testRedis.FlushAll()
+}
+
+func newTestJwtService() (*JwtTokenService, *config.JWTConfig) {
+	cfg := &config.JWTConfig{Secret: "test-secret", AccessExpire: time.Hour, RefreshExpire: 24 * time.Hour}
+	return NewJwtTokenService(cfg), cfg
+}
+
+func newTestContext(userAgent string) *context.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/v1/user/login", nil)
+	c.Request.Header.Set("User-Agent", userAgent)
+	return &context.Context{Context: c, Logger: logger.Global()}
+}
+
+// login 签发新会话的令牌对，返回访问令牌的 claims
+func login(t *testing.T, s *JwtTokenService, cfg *config.JWTConfig, ctx *context.Context, userID uint64) (*TokenPair, *Claims) {
+	t.Helper()
+	pair, err := s.GenerateJWTTokenPair(ctx, NewAdminClaims(userID, 0, cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair, validate(t, s, pair)
+}
+
+func validate(t *testing.T, s *JwtTokenService, pair *TokenPair) *Claims {
+	t.Helper()
+	claims, err := s.ValidateJWTToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func revoked(t *testing.T, s *JwtTokenService, ctx *context.Context, claims *Claims) bool {
+	t.Helper()
+	r, err := s.IsRevoked(ctx, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRevokeJWTToken(t *testing.T) {
+	useTestRedis(t)
+	s, cfg := newTestJwtService()
+	ctx := newTestContext("curl/8.0")
+
+	pair, claims := login(t, s, cfg, ctx, 1)
+	if revoked(t, s, ctx, claims) {
+		t.Fatal("new token should not be revoked")
+	}
+
+	// 注销后访问令牌按 jti 吊销，配对的刷新令牌同时失效
+	if err := s.RevokeJWTToken(ctx, claims); err != nil {
+		t.Fatal(err)
+	}
+	if !revoked(t, s, ctx, claims) {
+		t.Error("token should be revoked after logout")
+	}
+	if !testRedis.Exists(s.getRevokedTokenKey(claims.ID)) {
+		t.Error("revoked jti not recorded")
+	}
+	if ttl := testRedis.TTL(s.getRevokedTokenKey(claims.ID)); ttl <= 0 || ttl > cfg.AccessExpire {
+		t.Errorf("revoked jti ttl = %s, want the remaining token lifetime", ttl)
+	}
+	if _, err := s.RefreshJWTToken(ctx, pair.RefreshToken, func(c Claims) (Claims, error) { return c, nil }); err == nil {
+		t.Error("refresh token should be invalid after logout")
+	}
+
+	// 未携带 jti 或会话ID的令牌无法吊销，一律视为无效
+	if !revoked(t, s, ctx, &Claims{UserID: 1}) {
+		t.Error("token without jti should be treated as revoked")
+	}
+}
+
+func TestSessionRegistry(t *testing.T) {
+	useTestRedis(t)
+	s, cfg := newTestJwtService()
+	ctx := newTestContext("Mozilla/5.0 (Windows NT 10.0) Chrome/120.0")
+
+	_, first := login(t, s, cfg, ctx, 1)
+	_, second := login(t, s, cfg, newTestContext("Mozilla/5.0 (iPhone) Safari/604.1"), 1)
+	_, other := login(t, s, cfg, ctx, 2)
+
+	sessions, err := s.ListSessions(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+	devices := map[string]bool{}
+	for _, info := range sessions {
+		devices[info.Device] = true
+	}
+	if !devices["Windows / Chrome"] || !devices["iPhone / Safari"] {
+		t.Errorf("devices = %v", devices)
+	}
+
+	// 注销单个会话只影响该会话的令牌
+	ok, err := s.RevokeSession(ctx, 1, first.SessionID)
+	if err != nil || !ok {
+		t.Fatalf("RevokeSession = %v, %v", ok, err)
+	}
+	if !revoked(t, s, ctx, first) || revoked(t, s, ctx, second) {
+		t.Error("only the revoked session's token should be rejected")
+	}
+	if ok, _ = s.RevokeSession(ctx, 1, first.SessionID); ok {
+		t.Error("revoking a missing session should report false")
+	}
+
+	// 强制下线注销用户的全部会话，不影响其他用户
+	if err = s.RevokeAllSessions(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !revoked(t, s, ctx, second) {
+		t.Error("all sessions should be revoked")
+	}
+	if revoked(t, s, ctx, other) {
+		t.Error("other users' sessions should be kept")
+	}
+	if sessions, _ = s.ListSessions(ctx, 1); len(sessions) != 0 {
+		t.Errorf("sessions after revoke all = %d", len(sessions))
+	}
+}
+
+func TestRefreshRotationAndReuse(t *testing.T) {
+	useTestRedis(t)
+	s, cfg := newTestJwtService()
+	ctx := newTestContext("curl/8.0")
+	renew := func(old Claims) (Claims, error) { return NewAdminClaims(old.UserID, old.TenantID, cfg), nil }
+
+	first, firstClaims := login(t, s, cfg, ctx, 1)
+	second, err := s.RefreshJWTToken(ctx, first.RefreshToken, renew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondClaims := validate(t, s, second)
+
+	// 刷新令牌轮换：新令牌沿用原会话，旧的访问令牌随之作废
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token should be rotated")
+	}
+	if secondClaims.SessionID != firstClaims.SessionID {
+		t.Errorf("session = %s, want %s", secondClaims.SessionID, firstClaims.SessionID)
+	}
+	if !revoked(t, s, ctx, firstClaims) || revoked(t, s, ctx, secondClaims) {
+		t.Error("rotation should revoke only the previous access token")
+	}
+	if sessions, _ := s.ListSessions(ctx, 1); len(sessions) != 1 {
+		t.Errorf("sessions = %d, refresh must not create a new session", len(sessions))
+	}
+
+	// 已作废的刷新令牌被再次提交，视为泄露，吊销用户的全部会话
+	_, err = s.RefreshJWTToken(ctx, first.RefreshToken, renew)
+	var reused *RefreshTokenReusedError
+	if !errors.As(err, &reused) || reused.UserID != 1 || reused.SessionID != firstClaims.SessionID {
+		t.Fatalf("reuse err = %v, want RefreshTokenReusedError", err)
+	}
+	if !revoked(t, s, ctx, secondClaims) {
+		t.Error("reuse should revoke the current access token")
+	}
+	if _, err = s.RefreshJWTToken(ctx, second.RefreshToken, renew); err == nil {
+		t.Error("current refresh token should be revoked after reuse")
+	}
+
+	// 从未签发的刷新令牌只是无效，不触发吊销
+	_, fresh := login(t, s, cfg, ctx, 1)
+	_, err = s.RefreshJWTToken(ctx, "unknown", renew)
+	if err == nil || errors.As(err, &reused) {
+		t.Fatalf("unknown refresh token err = %v", err)
+	}
+	if revoked(t, s, ctx, fresh) {
+		t.Error("unknown refresh token must not revoke sessions")
+	}
+}
@@ -0,0 +1,277 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"goadmin/config"
+	"goadmin/pkg/logger"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey 单把签名密钥
+type signingKey struct {
+	kid        string
+	method     jwt.SigningMethod
+	signKey    any // 私钥（HMAC 为共享密钥），仅用于验签时为空
+	verifyKey  any // 公钥（HMAC 为共享密钥）
+	activateAt time.Time
+	retireAt   time.Time
+}
+
+// symmetric 是否为对称密钥，对称密钥不对外公开
+func (k *signingKey) symmetric() bool {
+	_, ok := k.method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// canSign 在指定时间是否可用于签发
+func (k *signingKey) canSign(now time.Time) bool {
+	return k.signKey != nil &&
+		!now.Before(k.activateAt) &&
+		(k.retireAt.IsZero() || now.Before(k.retireAt))
+}
+
+// expired 退役且超过宽限期后不再用于验签
+func (k *signingKey) expired(now time.Time, grace time.Duration) bool {
+	return !k.retireAt.IsZero() && now.After(k.retireAt.Add(grace))
+}
+
+// keySet 按 kid 管理的一组签名密钥
+type keySet struct {
+	keys  []*signingKey
+	byKid map[string]*signingKey
+	grace time.Duration // 退役密钥的验签宽限期，等于访问令牌有效期
+}
+
+var (
+	keySetMu    sync.Mutex
+	keySetCache = make(map[*config.JWTConfig]*keySet)
+)
+
+// loadKeySet 加载并缓存配置对应的密钥集，避免每次请求读取密钥文件
+func loadKeySet(cfg *config.JWTConfig) (*keySet, error) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	if ks, ok := keySetCache[cfg]; ok {
+		return ks, nil
+	}
+	ks, err := newKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	keySetCache[cfg] = ks
+	return ks, nil
+}
+
+// newKeySet 根据配置构建密钥集
+//
+// 算法为 HMAC 且未配置 keys 时，使用 secret 作为唯一密钥（不带 kid）
+func newKeySet(cfg *config.JWTConfig) (*keySet, error) {
+	ks := &keySet{
+		byKid: make(map[string]*signingKey),
+		grace: cfg.AccessExpire,
+	}
+	alg := cfg.Algorithm
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+
+	if len(cfg.Keys) == 0 {
+		method := jwt.GetSigningMethod(alg)
+		if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("签名算法 %s 需要配置 keys", alg)
+		}
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("签名算法 %s 需要配置 secret", alg)
+		}
+		if cfg.Placeholder() {
+			logger.Global().Warnf("jwt.secret 仍为示例值，任何人都可伪造令牌，请勿用于生产环境")
+		}
+		key := &signingKey{method: method, signKey: []byte(cfg.Secret), verifyKey: []byte(cfg.Secret)}
+		ks.keys = append(ks.keys, key)
+		ks.byKid[""] = key
+		return ks, nil
+	}
+
+	for _, kc := range cfg.Keys {
+		if kc.Kid == "" {
+			return nil, fmt.Errorf("密钥缺少 kid")
+		}
+		if _, ok := ks.byKid[kc.Kid]; ok {
+			return nil, fmt.Errorf("密钥 kid 重复: %s", kc.Kid)
+		}
+		keyAlg := kc.Algorithm
+		if keyAlg == "" {
+			keyAlg = alg
+		}
+		key, err := loadSigningKey(kc, keyAlg)
+		if err != nil {
+			return nil, fmt.Errorf("加载密钥 %s 失败: %w", kc.Kid, err)
+		}
+		ks.keys = append(ks.keys, key)
+		ks.byKid[key.kid] = key
+	}
+	return ks, nil
+}
+
+// loadSigningKey 从PEM文件加载非对称密钥
+func loadSigningKey(kc config.JWTKey, alg string) (*signingKey, error) {
+	key := &signingKey{
+		kid:        kc.Kid,
+		method:     jwt.GetSigningMethod(alg),
+		activateAt: kc.ActivateAt,
+		retireAt:   kc.RetireAt,
+	}
+	if kc.PrivateKey == "" && kc.PublicKey == "" {
+		return nil, fmt.Errorf("未配置 private_key 或 public_key")
+	}
+
+	var (
+		parsePrivate func([]byte) (any, error)
+		parsePublic  func([]byte) (any, error)
+	)
+	switch key.method.(type) {
+	case *jwt.SigningMethodRSA:
+		parsePrivate = func(b []byte) (any, error) { return jwt.ParseRSAPrivateKeyFromPEM(b) }
+		parsePublic = func(b []byte) (any, error) { return jwt.ParseRSAPublicKeyFromPEM(b) }
+	case *jwt.SigningMethodECDSA:
+		parsePrivate = func(b []byte) (any, error) { return jwt.ParseECPrivateKeyFromPEM(b) }
+		parsePublic = func(b []byte) (any, error) { return jwt.ParseECPublicKeyFromPEM(b) }
+	case *jwt.SigningMethodEd25519:
+		parsePrivate = func(b []byte) (any, error) { return jwt.ParseEdPrivateKeyFromPEM(b) }
+		parsePublic = func(b []byte) (any, error) { return jwt.ParseEdPublicKeyFromPEM(b) }
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+
+	if kc.PrivateKey != "" {
+		data, err := os.ReadFile(kc.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := parsePrivate(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析私钥失败: %w", err)
+		}
+		key.signKey = priv
+		key.verifyKey = priv.(crypto.Signer).Public()
+	}
+	if kc.PublicKey != "" {
+		data, err := os.ReadFile(kc.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := parsePublic(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析公钥失败: %w", err)
+		}
+		key.verifyKey = pub
+	}
+	if ec, ok := key.verifyKey.(*ecdsa.PublicKey); ok {
+		if ec.Curve.Params().BitSize != key.method.(*jwt.SigningMethodECDSA).CurveBits {
+			return nil, fmt.Errorf("椭圆曲线与签名算法 %s 不匹配", alg)
+		}
+	}
+	return key, nil
+}
+
+// signing 选取当前用于签发的密钥：已生效且未退役的密钥中生效时间最晚的一把
+func (ks *keySet) signing(now time.Time) (*signingKey, error) {
+	var current *signingKey
+	for _, key := range ks.keys {
+		if !key.canSign(now) {
+			continue
+		}
+		if current == nil || !key.activateAt.Before(current.activateAt) {
+			current = key
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("没有可用的签名密钥")
+	}
+	return current, nil
+}
+
+// verifying 根据 kid 查找验签密钥
+func (ks *keySet) verifying(kid string, now time.Time) (*signingKey, bool) {
+	key, ok := ks.byKid[kid]
+	if !ok || key.expired(now, ks.grace) {
+		return nil, false
+	}
+	return key, true
+}
+
+// algorithms 密钥集内使用的全部签名算法
+func (ks *keySet) algorithms() []string {
+	algs := make([]string, 0, len(ks.keys))
+	seen := make(map[string]bool)
+	for _, key := range ks.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// JWK JSON Web Key（RFC 7517），仅包含公钥部分
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwks 导出可用于验签的公钥，包括尚未生效的密钥，便于下游提前缓存
+func (ks *keySet) jwks(now time.Time) *JWKS {
+	set := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		if key.symmetric() || key.expired(now, ks.grace) {
+			continue
+		}
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64URL(pub.N.Bytes())
+			jwk.E = base64URL(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = pub.Curve.Params().Name
+			jwk.X = base64URL(pub.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64URL(pub.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64URL(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func base64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
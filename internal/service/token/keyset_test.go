@@ -0,0 +1,164 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"goadmin/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey 生成指定类型的私钥并写入PEM文件
+func writeKey(t *testing.T, dir, name string) string {
+	t.Helper()
+	var priv any
+	var err error
+	switch name {
+	case "rsa":
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ec":
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("编码密钥失败: %v", err)
+	}
+	path := filepath.Join(dir, name+".pem")
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("写入密钥失败: %v", err)
+	}
+	return path
+}
+
+func TestJwtTokenService_SignAndValidate(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		alg string
+		key string
+		kty string
+		crv string
+	}{
+		{"RS256", "rsa", "RSA", ""},
+		{"ES256", "ec", "EC", "P-256"},
+		{"EdDSA", "ed", "OKP", "Ed25519"},
+	}
+	for _, c := range cases {
+		t.Run(c.alg, func(t *testing.T) {
+			cfg := &config.JWTConfig{
+				AccessExpire: time.Hour,
+				Issuer:       "goadmin",
+				Audience:     []string{"goadmin", "other"},
+				Algorithm:    c.alg,
+				Keys:         []config.JWTKey{{Kid: "k1", PrivateKey: writeKey(t, dir, c.key)}},
+			}
+			s := NewJwtTokenService(cfg)
+			if err := s.LoadKeys(); err != nil {
+				t.Fatalf("加载密钥失败: %v", err)
+			}
+
+			tokenString, _, err := s.generateJWT(NewAdminClaims(7, 0, cfg))
+			if err != nil {
+				t.Fatalf("签发令牌失败: %v", err)
+			}
+			claims, err := s.ValidateJWTToken(tokenString)
+			if err != nil {
+				t.Fatalf("验证令牌失败: %v", err)
+			}
+			if claims.UserID != 7 || !claims.IsAdmin() || claims.Issuer != "goadmin" {
+				t.Errorf("claims 不符合预期: %+v", claims)
+			}
+
+			jwks, err := s.JWKS()
+			if err != nil {
+				t.Fatalf("导出JWKS失败: %v", err)
+			}
+			if len(jwks.Keys) != 1 {
+				t.Fatalf("期望JWKS包含1把密钥, 实际为 %d", len(jwks.Keys))
+			}
+			if k := jwks.Keys[0]; k.Kid != "k1" || k.Alg != c.alg || k.Kty != c.kty || k.Crv != c.crv {
+				t.Errorf("JWK 不符合预期: %+v", k)
+			}
+		})
+	}
+}
+
+func TestJwtTokenService_Rotation(t *testing.T) {
+	now := time.Now()
+	oldKey, newKey := writeKey(t, t.TempDir(), "rsa"), writeKey(t, t.TempDir(), "rsa")
+	retireAt := now.Add(time.Minute)
+	cfg := &config.JWTConfig{
+		AccessExpire: time.Hour,
+		Algorithm:    "RS256",
+		Keys: []config.JWTKey{
+			{Kid: "old", PrivateKey: oldKey, RetireAt: retireAt},
+			{Kid: "new", PrivateKey: newKey, ActivateAt: retireAt},
+		},
+	}
+	ks, err := newKeySet(cfg)
+	if err != nil {
+		t.Fatalf("加载密钥失败: %v", err)
+	}
+
+	if key, _ := ks.signing(now); key.kid != "old" {
+		t.Errorf("轮换前期望使用 old, 实际为 %s", key.kid)
+	}
+	if key, _ := ks.signing(retireAt.Add(time.Second)); key.kid != "new" {
+		t.Errorf("轮换后期望使用 new, 实际为 %s", key.kid)
+	}
+	// 退役密钥在访问令牌有效期内仍可验签
+	if _, ok := ks.verifying("old", retireAt.Add(30*time.Minute)); !ok {
+		t.Error("退役密钥在宽限期内应可验签")
+	}
+	if _, ok := ks.verifying("old", retireAt.Add(2*time.Hour)); ok {
+		t.Error("退役密钥超过宽限期后不应可验签")
+	}
+	// 尚未生效的密钥提前公开
+	if n := len(ks.jwks(now).Keys); n != 2 {
+		t.Errorf("期望JWKS包含2把密钥, 实际为 %d", n)
+	}
+}
+
+func TestJwtTokenService_RejectsForeignTokens(t *testing.T) {
+	cfg := &config.JWTConfig{
+		Secret:       "test-secret",
+		AccessExpire: time.Hour,
+		Issuer:       "goadmin",
+		Audience:     []string{"goadmin"},
+	}
+	s := NewJwtTokenService(cfg)
+
+	// 受众不符
+	other := *cfg
+	other.Audience = []string{"other"}
+	tokenString, _, err := s.generateJWT(NewAdminClaims(1, 0, &other))
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	if _, err = s.ValidateJWTToken(tokenString); err == nil {
+		t.Error("受众不符的令牌应校验失败")
+	}
+
+	// 未配置的算法
+	tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS512, NewAdminClaims(1, 0, cfg)).
+		SignedString([]byte(cfg.Secret))
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	if _, err = s.ValidateJWTToken(tokenString); err == nil {
+		t.Error("算法不符的令牌应校验失败")
+	}
+}
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"encoding/json"
+	"goadmin/internal/context"
+	"goadmin/pkg/redisx"
+	"time"
+)
+
+// OIDCState 单点登录发起时保存的一次性参数，回调时按 state 取回
+type OIDCState struct {
+	Verifier string `json:"verifier"` // PKCE code_verifier
+	Nonce    string `json:"nonce"`
+}
+
+// SaveOIDCState 保存单点登录参数
+func (s *TokenService) SaveOIDCState(
+	ctx *context.Context, state string, value *OIDCState, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err = redisx.GetClient().Set(ctx, s.getOIDCStateKey(state), data, expiration).Err(); err != nil {
+		ctx.Logger.Errorf("%s 保存单点登录参数失败: %v", s.logPrefix(), err)
+		return err
+	}
+	return nil
+}
+
+// ConsumeOIDCState 取回并删除单点登录参数，state 不存在或已使用时返回 nil
+func (s *TokenService) ConsumeOIDCState(ctx *context.Context, state string) (*OIDCState, error) {
+	data, err := redisx.GetClient().GetDel(ctx, s.getOIDCStateKey(state)).Bytes()
+	if err == redisx.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var value OIDCState
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func (s *TokenService) getOIDCStateKey(state string) string {
+	return "oidc_state:" + state
+}
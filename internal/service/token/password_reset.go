@@ -0,0 +1,140 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"goadmin/internal/context"
+	"goadmin/pkg/redisx"
+	"goadmin/pkg/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GeneratePasswordResetToken 生成找回密码令牌
+//
+// 令牌包含用户ID、过期时间及随机串，并使用 secret 签名；随机串同时写入 Redis 保证令牌只能使用一次，
+// 同一用户重新申请时此前的令牌随即失效
+func (s *TokenService) GeneratePasswordResetToken(
+	ctx *context.Context, userID uint64, secret []byte, expiration time.Duration) (string, error) {
+	nonce := util.GenerateUUIDWithoutHyphen()
+	userKey := s.getPasswordResetUserKey(userID)
+
+	client := redisx.GetClient()
+	oldNonce, err := client.Get(ctx, userKey).Result()
+	if err != nil && err != redisx.Nil {
+		ctx.Logger.Errorf("%s 获取找回密码令牌失败: %d %v", s.logPrefix(), userID, err)
+		return "", err
+	}
+
+	pipe := client.TxPipeline()
+	if oldNonce != "" {
+		pipe.Del(ctx, s.getPasswordResetKey(oldNonce))
+	}
+	pipe.Set(ctx, s.getPasswordResetKey(nonce), userID, expiration)
+	pipe.Set(ctx, userKey, nonce, expiration)
+	if _, err = pipe.Exec(ctx); err != nil {
+		ctx.Logger.Errorf("%s 生成找回密码令牌失败: %d %v", s.logPrefix(), userID, err)
+		return "", err
+	}
+	return signPasswordResetToken(secret, userID, time.Now().Add(expiration), nonce), nil
+}
+
+// ValidatePasswordResetToken 校验找回密码令牌但不作废，返回对应的用户ID
+//
+// 令牌签名错误、已过期或已使用时返回0
+func (s *TokenService) ValidatePasswordResetToken(ctx *context.Context, token string, secret []byte) (uint64, error) {
+	userID, nonce, ok := parsePasswordResetToken(secret, token, time.Now())
+	if !ok {
+		return 0, nil
+	}
+	str, err := redisx.GetClient().Get(ctx, s.getPasswordResetKey(nonce)).Result()
+	if err == redisx.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if str != strconv.FormatUint(userID, 10) {
+		return 0, nil
+	}
+	return userID, nil
+}
+
+// ConsumePasswordResetToken 校验并作废找回密码令牌，返回对应的用户ID
+//
+// 令牌签名错误、已过期或已使用时返回0
+func (s *TokenService) ConsumePasswordResetToken(ctx *context.Context, token string, secret []byte) (uint64, error) {
+	userID, nonce, ok := parsePasswordResetToken(secret, token, time.Now())
+	if !ok {
+		return 0, nil
+	}
+
+	str, err := redisx.GetClient().GetDel(ctx, s.getPasswordResetKey(nonce)).Result()
+	if err == redisx.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if str != strconv.FormatUint(userID, 10) {
+		return 0, nil
+	}
+	if err = redisx.GetClient().Del(ctx, s.getPasswordResetUserKey(userID)).Err(); err != nil {
+		ctx.Logger.Errorf("%s 删除找回密码令牌失败: %d %v", s.logPrefix(), userID, err)
+	}
+	return userID, nil
+}
+
+func (s *TokenService) getPasswordResetKey(nonce string) string {
+	return "pwd_reset:" + nonce
+}
+
+func (s *TokenService) getPasswordResetUserKey(userID uint64) string {
+	return "pwd_reset_user:" + strconv.FormatUint(userID, 10)
+}
+
+// signPasswordResetToken 生成签名令牌，格式为 base64url(用户ID.过期时间.随机串).base64url(签名)
+func signPasswordResetToken(secret []byte, userID uint64, expiresAt time.Time, nonce string) string {
+	payload := strconv.FormatUint(userID, 10) + "." + strconv.FormatInt(expiresAt.Unix(), 10) + "." + nonce
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parsePasswordResetToken 校验令牌签名及有效期，返回用户ID及随机串
+func parsePasswordResetToken(secret []byte, token string, now time.Time) (uint64, string, bool) {
+	encodedPayload, encodedSig, found := strings.Cut(token, ".")
+	if !found {
+		return 0, "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, "", false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return 0, "", false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return 0, "", false
+	}
+
+	parts := strings.Split(string(payload), ".")
+	if len(parts) != 3 || parts[2] == "" {
+		return 0, "", false
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expiresAt {
+		return 0, "", false
+	}
+	return userID, parts[2], true
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordResetTokenSignature(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Unix(1700000000, 0)
+	token := signPasswordResetToken(secret, 42, now.Add(30*time.Minute), "abc123")
+
+	userID, nonce, ok := parsePasswordResetToken(secret, token, now)
+	if !ok || userID != 42 || nonce != "abc123" {
+		t.Fatalf("有效令牌应校验通过: %d %s %v", userID, nonce, ok)
+	}
+	if _, _, ok = parsePasswordResetToken(secret, token, now.Add(30*time.Minute)); ok {
+		t.Error("过期令牌不应校验通过")
+	}
+	if _, _, ok = parsePasswordResetToken([]byte("other-secret"), token, now); ok {
+		t.Error("签名密钥不一致时不应校验通过")
+	}
+
+	// 篡改用户ID
+	forged := signPasswordResetToken([]byte("other-secret"), 1, now.Add(time.Hour), "abc123")
+	if _, _, ok = parsePasswordResetToken(secret, forged, now); ok {
+		t.Error("伪造令牌不应校验通过")
+	}
+	for _, bad := range []string{"", "abc", "abc.def", token + "x"} {
+		if _, _, ok = parsePasswordResetToken(secret, bad, now); ok {
+			t.Errorf("格式错误的令牌不应校验通过: %q", bad)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goadmin/internal/context"
+	"goadmin/pkg/redisx"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionInfo 用户登录会话
+type SessionInfo struct {
+	ID          string `json:"id"`
+	Device      string `json:"device"`
+	IP          string `json:"ip"`
+	UserAgent   string `json:"user_agent"`
+	IssuedAt    int64  `json:"issued_at"`
+	LastRefresh int64  `json:"last_refresh"`
+	Current     bool   `json:"current"`
+}
+
+// sessionRecord 会话在Redis中的存储结构
+type sessionRecord struct {
+	SessionInfo
+	RefreshToken string `json:"refresh_token"`
+	AccessJTI    string `json:"access_jti"`
+	AccessExpire int64  `json:"access_expire"`
+}
+
+func (r *sessionRecord) String() string {
+	jsonBytes, _ := json.Marshal(r)
+	return string(jsonBytes)
+}
+
+// newSessionRecord 根据当前请求创建会话记录
+func newSessionRecord(ctx *context.Context, sessionID string) *sessionRecord {
+	userAgent := ctx.Request.UserAgent()
+	device := ctx.GetHeader("X-Device")
+	if device == "" {
+		device = deviceFromUserAgent(userAgent)
+	}
+	return &sessionRecord{
+		SessionInfo: SessionInfo{
+			ID:        sessionID,
+			Device:    device,
+			IP:        ctx.ClientIP(),
+			UserAgent: userAgent,
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+}
+
+// ListSessions 获取用户的所有有效会话，按最近活跃时间倒序
+func (s *JwtTokenService) ListSessions(ctx *context.Context, userID uint64) ([]*SessionInfo, error) {
+	sessionsKey := s.getSessionsKey(userID)
+	values, err := redisx.GetClient().HGetAll(ctx, sessionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		list    = make([]*SessionInfo, 0, len(values))
+		expired []string
+		// 超过刷新令牌有效期未活跃的会话已无法续期，顺带清理
+		deadline = time.Now().Add(-s.refreshExpire()).Unix()
+	)
+	for id, value := range values {
+		var record sessionRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil || record.LastRefresh < deadline {
+			expired = append(expired, id)
+			continue
+		}
+		info := record.SessionInfo
+		list = append(list, &info)
+	}
+	if len(expired) > 0 {
+		if err := redisx.GetClient().HDel(ctx, sessionsKey, expired...).Err(); err != nil {
+			ctx.Logger.Warnf("%s 清理过期会话失败: %d %v", s.logPrefix(), userID, err)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastRefresh > list[j].LastRefresh
+	})
+	return list, nil
+}
+
+// RevokeSession 注销用户的指定会话
+//
+// 返回值表示会话是否存在
+func (s *JwtTokenService) RevokeSession(ctx *context.Context, userID uint64, sessionID string) (bool, error) {
+	record, err := s.getSession(ctx, userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if record == nil {
+		return false, nil
+	}
+	return true, s.revokeSessions(ctx, userID, record)
+}
+
+// RevokeAllSessions 注销用户的全部会话
+func (s *JwtTokenService) RevokeAllSessions(ctx *context.Context, userID uint64) error {
+	values, err := redisx.GetClient().HGetAll(ctx, s.getSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	records := make([]*sessionRecord, 0, len(values))
+	for id, value := range values {
+		var record sessionRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			ctx.Logger.Warnf("%s 解析会话失败: %d %s %v", s.logPrefix(), userID, id, err)
+		}
+		record.ID = id
+		records = append(records, &record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return s.revokeSessions(ctx, userID, records...)
+}
+
+// getSession 获取会话记录，不存在时返回 nil
+func (s *JwtTokenService) getSession(ctx *context.Context, userID uint64, sessionID string) (*sessionRecord, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	value, err := redisx.GetClient().HGet(ctx, s.getSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		if errors.Is(err, redisx.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var record sessionRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("解析会话失败: %w", err)
+	}
+	return &record, nil
+}
+
+// revokeSessions 删除会话登记、刷新令牌，并吊销会话当前的访问令牌
+func (s *JwtTokenService) revokeSessions(ctx *context.Context, userID uint64, records ...*sessionRecord) error {
+	pipe := redisx.GetClient().TxPipeline()
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.RefreshToken != "" {
+			pipe.Del(ctx, s.getRefreshTokenKey(record.RefreshToken))
+		}
+		if ttl := time.Until(time.Unix(record.AccessExpire, 0)); record.AccessJTI != "" && ttl > 0 {
+			pipe.Set(ctx, s.getRevokedTokenKey(record.AccessJTI), userID, ttl)
+		}
+		if record.ID != "" {
+			ids = append(ids, record.ID)
+		}
+	}
+	if len(ids) > 0 {
+		pipe.HDel(ctx, s.getSessionsKey(userID), ids...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		ctx.Logger.Errorf("%s 注销会话失败: %d %v %v", s.logPrefix(), userID, ids, err)
+		return fmt.Errorf("注销会话失败: %w", err)
+	}
+	return nil
+}
+
+// 获取用户会话登记的Redis键
+func (s *JwtTokenService) getSessionsKey(userID uint64) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
+// deviceFromUserAgent 根据 User-Agent 粗略识别设备，如 "Windows / Chrome"
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	platform := "Unknown"
+	for _, p := range []struct{ key, name string }{
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"linux", "Linux"},
+	} {
+		if strings.Contains(ua, p.key) {
+			platform = p.name
+			break
+		}
+	}
+	client := "Unknown"
+	for _, c := range []struct{ key, name string }{
+		{"edg/", "Edge"},
+		{"chrome/", "Chrome"},
+		{"firefox/", "Firefox"},
+		{"safari/", "Safari"},
+		{"curl/", "curl"},
+		{"postman", "Postman"},
+	} {
+		if strings.Contains(ua, c.key) {
+			client = c.name
+			break
+		}
+	}
+	return platform + " / " + client
+}
@@ -0,0 +1,55 @@
+package token
+
+import (
+	"goadmin/internal/context"
+	"goadmin/pkg/redisx"
+	"goadmin/pkg/util"
+	"strconv"
+	"time"
+)
+
+// GeneratePreAuthToken 生成预认证令牌，用于密码校验通过后的第二步认证
+func (s *TokenService) GeneratePreAuthToken(ctx *context.Context, userID uint64, expiration time.Duration) (string, error) {
+	token := util.GenerateUUIDWithoutHyphen()
+	key := s.getPreAuthKey(token)
+	pipe := redisx.GetClient().TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "attempts", 0)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		ctx.Logger.Errorf("%s 生成预认证令牌失败: %d %v", s.logPrefix(), userID, err)
+		return "", err
+	}
+	return token, nil
+}
+
+// GetPreAuthUserID 获取预认证令牌对应的用户ID，令牌不存在或已过期时返回0
+func (s *TokenService) GetPreAuthUserID(ctx *context.Context, token string) (uint64, error) {
+	str, err := redisx.GetClient().HGet(ctx, s.getPreAuthKey(token), "user_id").Result()
+	if err == redisx.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(str, 10, 64)
+}
+
+// IncrPreAuthAttempts 累加预认证令牌的验证次数，返回累加后的次数
+func (s *TokenService) IncrPreAuthAttempts(ctx *context.Context, token string) (int64, error) {
+	return redisx.GetClient().HIncrBy(ctx, s.getPreAuthKey(token), "attempts", 1).Result()
+}
+
+// DeletePreAuthToken 删除预认证令牌
+func (s *TokenService) DeletePreAuthToken(ctx *context.Context, token string) error {
+	return redisx.GetClient().Del(ctx, s.getPreAuthKey(token)).Err()
+}
+
+func (s *TokenService) getPreAuthKey(token string) string {
+	return "pre_auth:" + token
+}
+
+// MarkTOTPUsed 记录用户已使用的动态码时间步，返回 false 表示该动态码已被使用过
+func (s *TokenService) MarkTOTPUsed(ctx *context.Context, userID uint64, counter int64, expiration time.Duration) (bool, error) {
+	key := "totp_used:" + strconv.FormatUint(userID, 10) + ":" + strconv.FormatInt(counter, 10)
+	return redisx.GetClient().SetNX(ctx, key, 1, expiration).Result()
+}
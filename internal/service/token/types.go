@@ -2,16 +2,23 @@ package token
 
 import (
 	"encoding/json"
+	"goadmin/config"
+	"goadmin/pkg/util"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ClaimsKey 认证通过后 claims 在 gin.Context 中的键
+const ClaimsKey = "jwt_claims"
+
 // Claims 自定义JWT claims结构体
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID uint64 `json:"user_id"`
-	UType  int    `json:"type"` // 区分用户类型
+	UserID    uint64 `json:"user_id"`
+	UType     int    `json:"type"`          // 区分用户类型
+	SessionID string `json:"sid,omitempty"` // 登录会话ID，同一会话刷新令牌时保持不变
+	TenantID  uint64 `json:"tid,omitempty"` // 用户所属租户，为 0 表示平台用户
 }
 
 func (c Claims) IsAdmin() bool {
@@ -23,26 +30,23 @@ func (c Claims) String() string {
 	return string(jsonBytes)
 }
 
-// NewClaims 创建一个新的Claims实例
-func NewAdminClaims(userID uint64, expiration time.Duration) Claims {
-	now := time.Now()
-	return Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-		},
-		UserID: userID,
-		UType:  1,
-	}
+// NewAdminClaims 创建管理端用户的Claims实例
+func NewAdminClaims(userID, tenantID uint64, cfg *config.JWTConfig) Claims {
+	claims := NewClaims(userID, cfg)
+	claims.UType = 1
+	claims.TenantID = tenantID
+	return claims
 }
 
-// NewClaims 创建一个新的Claims实例
-func NewClaims(userID uint64, expiration time.Duration) Claims {
+// NewClaims 创建一个新的Claims实例，签发者、受众及有效期取自配置
+func NewClaims(userID uint64, cfg *config.JWTConfig) Claims {
 	now := time.Now()
 	return Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+			ID:        util.GenerateUUIDWithoutHyphen(),
+			Issuer:    cfg.Issuer,
+			Audience:  cfg.Audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.AccessExpire)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
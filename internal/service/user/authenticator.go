@@ -0,0 +1,171 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"goadmin/config"
+	"goadmin/internal/context"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/ldap"
+	"goadmin/pkg/util"
+	"path"
+	"strings"
+)
+
+// 认证器名称，用于配置认证器链
+const (
+	AuthenticatorLocal = "local"
+	AuthenticatorLDAP  = "ldap"
+)
+
+var (
+	// ErrUnknownUser 认证源中不存在该用户，认证器链继续尝试下一个认证器
+	ErrUnknownUser = errors.New("unknown user")
+	// ErrInvalidCredentials 用户名或密码错误，认证器链不再继续
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrSourceUnavailable 认证源不可用，认证器链继续尝试下一个认证器，保证本地应急账号可用
+	ErrSourceUnavailable = errors.New("authentication source unavailable")
+
+	// errNoAuthenticator 用户名没有可用的认证器
+	errNoAuthenticator = errors.New("no authenticator available")
+)
+
+// Credential 用户名密码凭证
+type Credential struct {
+	Username string
+	Password string // 明文密码
+	// 本地同名用户，不存在时为空
+	User *modeluser.User
+}
+
+// Authenticator 用户名密码认证器
+//
+// 新的认证源实现该接口并在 newAuthChain 中注册后，即可通过 auth.chain 配置参与认证
+type Authenticator interface {
+	// Name 认证器名称
+	Name() string
+
+	// Authenticate 校验凭证，返回对应的本地用户
+	//
+	// 认证源中不存在该用户时返回 ErrUnknownUser，凭证错误时返回 ErrInvalidCredentials，
+	// 认证源异常时返回包装了 ErrSourceUnavailable 的错误，其余错误直接返回给调用方
+	Authenticate(ctx *context.Context, cred *Credential) (*modeluser.User, error)
+}
+
+// authChain 按用户名选择认证器并依次尝试
+type authChain struct {
+	authenticators map[string]Authenticator
+	rules          []config.AuthChainRule
+}
+
+// newAuthChain 创建认证器链，未启用的认证器不会注册
+func newAuthChain(cfg *config.AuthConfig, authenticators ...Authenticator) *authChain {
+	c := &authChain{authenticators: make(map[string]Authenticator), rules: cfg.Chain}
+	for _, a := range authenticators {
+		c.authenticators[a.Name()] = a
+	}
+	if len(c.rules) == 0 {
+		c.rules = []config.AuthChainRule{{Pattern: "*", Authenticators: []string{AuthenticatorLDAP, AuthenticatorLocal}}}
+	}
+	return c
+}
+
+// resolve 返回用户名匹配的第一条规则中已注册的认证器
+func (c *authChain) resolve(username string) []Authenticator {
+	for _, rule := range c.rules {
+		if ok, _ := path.Match(rule.Pattern, username); !ok {
+			continue
+		}
+		list := make([]Authenticator, 0, len(rule.Authenticators))
+		for _, name := range rule.Authenticators {
+			if a, ok := c.authenticators[strings.ToLower(name)]; ok {
+				list = append(list, a)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// enabled 是否有可用的认证器
+func (c *authChain) enabled() bool {
+	return len(c.authenticators) > 0
+}
+
+// authenticate 依次尝试认证器，返回本地用户及认证成功的认证器名称
+func (c *authChain) authenticate(ctx *context.Context, cred *Credential) (*modeluser.User, string, error) {
+	list := c.resolve(cred.Username)
+	if len(list) == 0 {
+		return nil, "", errNoAuthenticator
+	}
+	lastErr := ErrUnknownUser
+	for _, a := range list {
+		u, err := a.Authenticate(ctx, cred)
+		switch {
+		case err == nil:
+			return u, a.Name(), nil
+		case errors.Is(err, ErrUnknownUser):
+			continue
+		case errors.Is(err, ErrSourceUnavailable):
+			ctx.Logger.Errorf("[auth] 认证源 %s 不可用: %s %v", a.Name(), cred.Username, err)
+			lastErr = err
+			continue
+		default:
+			return nil, a.Name(), err
+		}
+	}
+	return nil, "", lastErr
+}
+
+// localAuthenticator 使用本地账号密码认证
+type localAuthenticator struct{}
+
+func (localAuthenticator) Name() string {
+	return AuthenticatorLocal
+}
+
+func (localAuthenticator) Authenticate(_ *context.Context, cred *Credential) (*modeluser.User, error) {
+	if cred.User == nil {
+		return nil, ErrUnknownUser
+	}
+	if !util.ValidatePasswordAndHash(util.PasswordDigest(cred.Password), cred.User.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return cred.User, nil
+}
+
+// ldapAuthenticator 使用 LDAP / Active Directory 认证，首次登录按配置创建本地用户
+type ldapAuthenticator struct {
+	s   *userService
+	dir *ldap.Directory
+}
+
+func (a *ldapAuthenticator) Name() string {
+	return AuthenticatorLDAP
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx *context.Context, cred *Credential) (*modeluser.User, error) {
+	entry, err := a.dir.Authenticate(cred.Username, cred.Password)
+	switch {
+	case errors.Is(err, ldap.ErrUserNotFound):
+		return nil, ErrUnknownUser
+	case errors.Is(err, ldap.ErrInvalidCredentials):
+		return nil, ErrInvalidCredentials
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	cfg := a.dir.Config()
+	return a.s.externalUser(ctx, ldapIdentity(cfg, entry), cfg.AutoCreate, cfg.SyncRole)
+}
+
+// ldapIdentity 目录用户对应的外部身份，以用户DN作为唯一标识
+func ldapIdentity(cfg *ldap.Config, entry *ldap.Entry) *externalIdentity {
+	return &externalIdentity{
+		Provider: modeluser.IdentityProviderLDAP,
+		Issuer:   strings.ToLower(cfg.BaseDN),
+		Subject:  strings.ToLower(entry.DN),
+		Username: entry.Username,
+		Email:    entry.Email,
+		RoleCode: cfg.MapRole(entry.Groups),
+	}
+}
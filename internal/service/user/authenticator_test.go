@@ -0,0 +1,105 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"goadmin/config"
+	"goadmin/internal/context"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/logger"
+)
+
+// fakeAuthenticator 按用户名返回预设结果的认证器
+type fakeAuthenticator struct {
+	name    string
+	results map[string]error
+	calls   []string
+}
+
+func (f *fakeAuthenticator) Name() string {
+	return f.name
+}
+
+func (f *fakeAuthenticator) Authenticate(_ *context.Context, cred *Credential) (*modeluser.User, error) {
+	f.calls = append(f.calls, cred.Username)
+	err, ok := f.results[cred.Username]
+	if !ok {
+		return nil, ErrUnknownUser
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &modeluser.User{Username: cred.Username}, nil
+}
+
+func TestAuthChain(t *testing.T) {
+	ldap := &fakeAuthenticator{name: AuthenticatorLDAP, results: map[string]error{
+		"alice": nil,
+		"bob":   ErrInvalidCredentials,
+		"carol": fmt.Errorf("%w: connection refused", ErrSourceUnavailable),
+		"dave":  errIdentityUsernameConflict,
+	}}
+	local := &fakeAuthenticator{name: AuthenticatorLocal, results: map[string]error{
+		"admin": nil,
+		"bob":   nil,
+		"carol": nil,
+	}}
+	chain := newAuthChain(&config.AuthConfig{Chain: []config.AuthChainRule{
+		{Pattern: "admin", Authenticators: []string{AuthenticatorLocal}},
+		{Pattern: "svc-*", Authenticators: []string{"kerberos"}},
+		{Pattern: "*", Authenticators: []string{AuthenticatorLDAP, AuthenticatorLocal}},
+	}}, local, ldap)
+	ctx := &context.Context{Logger: logger.Global()}
+
+	cases := []struct {
+		username string
+		source   string
+		err      error
+	}{
+		{"alice", AuthenticatorLDAP, nil},
+		{"admin", AuthenticatorLocal, nil},
+		{"bob", AuthenticatorLDAP, ErrInvalidCredentials},
+		{"carol", AuthenticatorLocal, nil},
+		{"dave", AuthenticatorLDAP, errIdentityUsernameConflict},
+		{"erin", "", ErrUnknownUser},
+		{"svc-backup", "", errNoAuthenticator},
+	}
+	for _, tc := range cases {
+		t.Run(tc.username, func(t *testing.T) {
+			u, source, err := chain.authenticate(ctx, &Credential{Username: tc.username, Password: "x"})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if source != tc.source {
+				t.Fatalf("source = %q, want %q", source, tc.source)
+			}
+			if tc.err == nil && (u == nil || u.Username != tc.username) {
+				t.Fatalf("user = %+v", u)
+			}
+		})
+	}
+
+	for _, name := range ldap.calls {
+		if name == "admin" {
+			t.Fatal("admin must only be checked against the local authenticator")
+		}
+	}
+}
+
+func TestAuthChainDefaultRules(t *testing.T) {
+	local := &fakeAuthenticator{name: AuthenticatorLocal, results: map[string]error{"admin": nil}}
+	chain := newAuthChain(&config.AuthConfig{}, local)
+	if !chain.enabled() {
+		t.Fatal("chain with local authenticator should be enabled")
+	}
+	if _, source, err := chain.authenticate(&context.Context{Logger: logger.Global()},
+		&Credential{Username: "admin"}); err != nil || source != AuthenticatorLocal {
+		t.Fatalf("authenticate = %q, %v", source, err)
+	}
+
+	if newAuthChain(&config.AuthConfig{}).enabled() {
+		t.Fatal("chain without authenticators should be disabled")
+	}
+}
@@ -0,0 +1,211 @@
+package user
+
+import (
+	stdctx "context"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/server"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/mail"
+	"goadmin/pkg/redisx"
+	"goadmin/pkg/util"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 找回密码申请次数的统计窗口
+const passwordResetLimitWindow = time.Hour
+
+// passwordReset 获取自助找回密码配置
+func (s *userService) passwordReset(ctx *context.Context) (*server.PasswordResetConfig, error) {
+	var cfg server.PasswordResetConfig
+	if err := s.setSrv.GetSrcValue(ctx, server.SettingPasswordReset, &cfg); err != nil {
+		ctx.Logger.Errorf("%s 获取找回密码配置失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return &cfg, nil
+}
+
+// passwordResetSecret 签名找回密码令牌的密钥，未配置时视为未开启找回密码
+func (s *userService) passwordResetSecret(ctx *context.Context) ([]byte, error) {
+	if s.cfg.PasswordReset.Secret == "" {
+		ctx.Logger.Errorf("%s 未配置 password_reset.secret，拒绝找回密码", s.logPrefix())
+		return nil, i18n.E(ctx.Context, "user.PasswordResetDisabled", nil)
+	}
+	return []byte(s.cfg.PasswordReset.Secret), nil
+}
+
+// ForgotPassword 申请通过邮件找回密码
+//
+// 无论邮箱是否存在均返回成功，避免通过响应差异枚举账号；邮件异步发送
+func (s *userService) ForgotPassword(ctx *context.Context, req *modeluser.ForgotPasswordRequest) error {
+	if err := s.checkLocalLogin(ctx); err != nil {
+		return err
+	}
+	cfg, err := s.passwordReset(ctx)
+	if err != nil {
+		return err
+	}
+	if !cfg.IsOn() {
+		return i18n.E(ctx.Context, "user.PasswordResetDisabled", nil)
+	}
+	secret, err := s.passwordResetSecret(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.checkCaptcha(ctx, req.Token, req.Email); err != nil {
+		return err
+	}
+	if !s.allowPasswordReset(ctx, s.passwordResetIPKey(ctx.ClientIP()), cfg.IPLimit) {
+		ctx.Logger.Warnf("%s 找回密码申请过于频繁: %s", s.logPrefix(), ctx.ClientIP())
+		return i18n.E(ctx.Context, "user.PasswordResetTooFrequent", nil)
+	}
+
+	u, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %s %v", s.logPrefix(), req.Email, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if u == nil || !u.IsActive() {
+		ctx.Logger.Warnf("%s 找回密码的邮箱不存在或账号不可用: %s", s.logPrefix(), req.Email)
+		return nil
+	}
+	if !s.allowPasswordReset(ctx, s.passwordResetUserKey(u.ID), cfg.EmailLimit) {
+		ctx.Logger.Warnf("%s 找回密码邮件发送过于频繁: %s", s.logPrefix(), u.Username)
+		return nil
+	}
+
+	token, err := s.tokenSvc.GeneratePasswordResetToken(ctx, u.ID, secret, cfg.TTL())
+	if err != nil {
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	msg := s.passwordResetMail(ctx, cfg, u, token)
+
+	logger := ctx.Logger
+	go func() {
+		sendCtx, cancel := stdctx.WithTimeout(stdctx.Background(), time.Minute)
+		defer cancel()
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			logger.Errorf("%s 发送找回密码邮件失败: %s %v", s.logPrefix(), u.Username, err)
+			return
+		}
+		logger.Infof("%s 发送找回密码邮件成功: %s", s.logPrefix(), u.Username)
+	}()
+	return nil
+}
+
+// ConfirmPasswordReset 使用找回密码令牌设置新密码
+func (s *userService) ConfirmPasswordReset(ctx *context.Context, req *modeluser.PasswordResetConfirmRequest) error {
+	secret, err := s.passwordResetSecret(ctx)
+	if err != nil {
+		return err
+	}
+	userID, err := s.tokenSvc.ValidatePasswordResetToken(ctx, req.Token, secret)
+	if err != nil {
+		ctx.Logger.Errorf("%s 校验找回密码令牌失败: %v", s.logPrefix(), err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if userID == 0 {
+		return i18n.E(ctx.Context, "user.PasswordResetTokenInvalid", nil)
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), userID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if u == nil || !u.IsActive() {
+		return i18n.E(ctx.Context, "user.PasswordResetTokenInvalid", nil)
+	}
+
+	policy, err := s.passwordPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.checkPasswordPolicy(ctx, policy, req.NewPassword, u.Username); err != nil {
+		return err
+	}
+	digest := util.PasswordDigest(req.NewPassword)
+	if err = s.checkPasswordReuse(ctx, policy, u, digest); err != nil {
+		return err
+	}
+	encryptPwd, err := util.Password2Hash(digest)
+	if err != nil {
+		ctx.Logger.Errorf("%s 密码加密失败: %d %v", s.logPrefix(), userID, err)
+		return i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+
+	// 校验通过后再作废令牌，并发请求中仅有一个能成功
+	consumed, err := s.tokenSvc.ConsumePasswordResetToken(ctx, req.Token, secret)
+	if err != nil {
+		ctx.Logger.Errorf("%s 作废找回密码令牌失败: %v", s.logPrefix(), err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if consumed != userID {
+		return i18n.E(ctx.Context, "user.PasswordResetTokenInvalid", nil)
+	}
+
+	if err = s.savePassword(ctx, policy, userID, encryptPwd, false); err != nil {
+		return err
+	}
+	s.killSessions(ctx, userID)
+	s.clearLoginFailures(ctx, u.Username)
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.ResetPasswordByEmail", nil), u.Username)
+	ctx.Logger.Infof("%s 通过邮件重置密码成功: %s", s.logPrefix(), u.Username)
+	return nil
+}
+
+// passwordResetMail 使用当前语言渲染找回密码邮件
+func (s *userService) passwordResetMail(
+	ctx *context.Context, cfg *server.PasswordResetConfig, u *modeluser.User, token string) *mail.Message {
+	link := cfg.ResetURL
+	if strings.Contains(link, "?") {
+		link += "&token=" + url.QueryEscape(token)
+	} else {
+		link += "?token=" + url.QueryEscape(token)
+	}
+	data := map[string]any{
+		"username": u.Username,
+		"link":     link,
+		"minutes":  int(cfg.TTL().Minutes()),
+	}
+	// 模板不做转义，HTML 正文使用转义后的数据
+	htmlData := map[string]any{
+		"username": html.EscapeString(u.Username),
+		"link":     html.EscapeString(link),
+		"minutes":  data["minutes"],
+	}
+	return &mail.Message{
+		To:      []string{u.Email},
+		Subject: i18n.T(ctx.Context, "mail.PasswordReset.Subject", data),
+		Text:    i18n.T(ctx.Context, "mail.PasswordReset.Text", data),
+		HTML:    i18n.T(ctx.Context, "mail.PasswordReset.HTML", htmlData),
+	}
+}
+
+// allowPasswordReset 累加申请次数，超过 limit 时返回 false，Redis 异常时放行
+func (s *userService) allowPasswordReset(ctx *context.Context, key string, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+	pipe := redisx.GetClient().TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, passwordResetLimitWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		ctx.Logger.Errorf("%s 记录找回密码申请次数失败: %s %v", s.logPrefix(), key, err)
+		return true
+	}
+	return count.Val() <= int64(limit)
+}
+
+func (s *userService) passwordResetIPKey(ip string) string {
+	return "pwd_reset_limit:ip:" + ip
+}
+
+func (s *userService) passwordResetUserKey(userID uint64) string {
+	return "pwd_reset_limit:user:" + strconv.FormatUint(userID, 10)
+}
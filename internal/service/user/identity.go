@@ -0,0 +1,175 @@
+package user
+
+import (
+	stdctx "context"
+	"errors"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modelrole "goadmin/internal/model/role"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/logger"
+	"strings"
+)
+
+var (
+	// errIdentityNoRole 外部身份没有匹配的角色，或映射的角色不存在
+	errIdentityNoRole = errors.New("no role mapped for external identity")
+	// errIdentityUsernameConflict 外部身份的用户名已被本地用户使用
+	errIdentityUsernameConflict = errors.New("username of external identity already exists")
+)
+
+// externalIdentity 外部认证源（OIDC、LDAP 等）确认的用户身份
+type externalIdentity struct {
+	Provider string
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	RoleCode string // 按映射规则计算出的角色，为空表示没有匹配
+}
+
+// externalUser 获取外部身份绑定的本地用户，未绑定时按 autoCreate 自动创建，syncRole 时同步角色
+func (s *userService) externalUser(
+	ctx *context.Context, id *externalIdentity, autoCreate, syncRole bool) (*modeluser.User, error) {
+	binding, err := s.identityRepo.GetBySubject(ctx, id.Provider, id.Issuer, id.Subject)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取外部身份失败: %s %v", s.logPrefix(), id.Subject, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if binding == nil {
+		if !autoCreate {
+			ctx.Logger.Warnf("%s 外部身份用户未开通: %s %s %s", s.logPrefix(), id.Provider, id.Username, id.Subject)
+			return nil, i18n.E(ctx.Context, "user.IdentityNotProvisioned", nil)
+		}
+		u, err := s.provisionExternalUser(ctx, ctx.Logger, id)
+		switch {
+		case errors.Is(err, errIdentityNoRole):
+			return nil, i18n.E(ctx.Context, "user.IdentityNoRole", nil)
+		case errors.Is(err, errIdentityUsernameConflict):
+			return nil, i18n.E(ctx.Context, "user.IdentityUsernameConflict", nil)
+		case err != nil:
+			return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.Provision", map[string]any{
+			"username": u.Username,
+			"source":   id.Provider,
+			"role":     id.RoleCode,
+		}), u.Username)
+		return u, nil
+	}
+
+	u, err := s.userRepo.GetByID(ctx, binding.UserID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), binding.UserID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if u == nil || u.Status == modeluser.UserStatusDeleted {
+		ctx.Logger.Warnf("%s 外部身份绑定的用户不存在: %d", s.logPrefix(), binding.UserID)
+		return nil, i18n.E(ctx.Context, "user.AccountStatusAbnormal", nil)
+	}
+	if syncRole && id.RoleCode != "" && !rolesSynced(u, id.RoleCode) {
+		from := strings.Join(u.RoleCodes(), ",")
+		if err = s.syncExternalRole(ctx, ctx.Logger, u, id.RoleCode); err != nil {
+			if errors.Is(err, errIdentityNoRole) {
+				return nil, i18n.E(ctx.Context, "user.IdentityNoRole", nil)
+			}
+			return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.SyncRole", map[string]any{
+			"username": u.Username,
+			"from":     from,
+			"to":       id.RoleCode,
+		}), u.Username)
+	}
+	return u, nil
+}
+
+// provisionExternalUser 创建用户并绑定外部身份
+//
+// 用户不设置本地密码，只能通过外部认证源或管理员重置密码后登录；
+// 不按用户名自动关联已有账号，避免外部认证源中的同名用户接管本地账号
+func (s *userService) provisionExternalUser(
+	ctx stdctx.Context, log logger.Logger, id *externalIdentity) (*modeluser.User, error) {
+	if id.RoleCode == "" {
+		log.Warnf("%s 外部身份没有匹配的角色: %s %s", s.logPrefix(), id.Provider, id.Username)
+		return nil, errIdentityNoRole
+	}
+	r, err := s.checkRoleExists(ctx, log, id.RoleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.userRepo.IsUsernameExists(ctx, id.Username)
+	if err != nil {
+		log.Errorf("%s 检查用户名是否存在失败: %s %v", s.logPrefix(), id.Username, err)
+		return nil, err
+	}
+	if exists {
+		log.Warnf("%s 外部身份用户名与本地用户冲突: %s %s", s.logPrefix(), id.Provider, id.Username)
+		return nil, errIdentityUsernameConflict
+	}
+	email := id.Email
+	if email != "" {
+		if exists, err = s.userRepo.IsEmailExists(ctx, email); err != nil {
+			log.Errorf("%s 检查邮箱是否存在失败: %s %v", s.logPrefix(), email, err)
+			return nil, err
+		} else if exists {
+			log.Warnf("%s 外部身份邮箱已被使用，不再保存: %s", s.logPrefix(), email)
+			email = ""
+		}
+	}
+
+	u := &modeluser.User{
+		Username: id.Username,
+		Email:    email,
+		Roles:    modelrole.Set{*r},
+		Status:   modeluser.UserStatusActive,
+	}
+	err = s.userRepo.CreateWithIdentity(ctx, u, &modeluser.Identity{
+		Provider: id.Provider,
+		Issuer:   id.Issuer,
+		Subject:  id.Subject,
+	})
+	if err != nil {
+		log.Errorf("%s 创建外部身份用户失败: %s %v", s.logPrefix(), id.Username, err)
+		return nil, err
+	}
+	log.Infof("%s 外部身份创建用户: %s %s %s", s.logPrefix(), id.Provider, u.Username, id.RoleCode)
+	return u, nil
+}
+
+// syncExternalRole 按映射规则同步用户角色，外部认证源为角色的权威来源，同步后用户只保留映射的角色
+func (s *userService) syncExternalRole(
+	ctx stdctx.Context, log logger.Logger, u *modeluser.User, roleCode string) error {
+	r, err := s.checkRoleExists(ctx, log, roleCode)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepo.UpdateRoles(ctx, u.ID, []string{roleCode}); err != nil {
+		log.Errorf("%s 同步用户角色失败: %s %v", s.logPrefix(), u.Username, err)
+		return err
+	}
+	s.invalidateSessions(ctx, log, u.ID)
+	log.Infof("%s 同步用户角色: %s %v -> %s", s.logPrefix(), u.Username, u.RoleCodes(), roleCode)
+	u.Roles = modelrole.Set{*r}
+	return nil
+}
+
+// rolesSynced 用户是否只拥有映射的角色
+func rolesSynced(u *modeluser.User, roleCode string) bool {
+	return len(u.Roles) == 1 && u.Roles[0].Code == roleCode
+}
+
+// checkRoleExists 校验映射得到的角色存在
+func (s *userService) checkRoleExists(ctx stdctx.Context, log logger.Logger, roleCode string) (*modelrole.Role, error) {
+	r, err := s.roleRepo.GetByCode(ctx, roleCode)
+	if err != nil {
+		log.Errorf("%s 检查角色是否存在失败: %s %v", s.logPrefix(), roleCode, err)
+		return nil, err
+	}
+	if r == nil {
+		log.Errorf("%s 映射的角色不存在: %s", s.logPrefix(), roleCode)
+		return nil, errIdentityNoRole
+	}
+	return r, nil
+}
@@ -0,0 +1,125 @@
+package user
+
+import (
+	"errors"
+	"goadmin/internal/context"
+	modeluser "goadmin/internal/model/user"
+	"strings"
+)
+
+// SyncLDAPUsers 同步目录中的用户，供定时任务调用
+//
+// 未绑定的目录用户按 auto_create 创建，已绑定的用户按 sync_role 同步角色；
+// 开启 disable_missing 时禁用目录中已不存在的用户，目录返回空列表时视为异常不做禁用
+func (s *userService) SyncLDAPUsers(ctx *context.CliContext) (*modeluser.LDAPSyncResult, error) {
+	if !s.ldapDir.Enabled() {
+		return &modeluser.LDAPSyncResult{}, nil
+	}
+	cfg := s.ldapDir.Config()
+	entries, err := s.ldapDir.SearchUsers()
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取目录用户失败: %v", s.logPrefix(), err)
+		return nil, err
+	}
+
+	result := &modeluser.LDAPSyncResult{Total: len(entries)}
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		id := ldapIdentity(cfg, entry)
+		seen[id.Subject] = true
+		if err = s.syncLDAPUser(ctx, id, result); err != nil {
+			return result, err
+		}
+	}
+
+	if cfg.Sync.DisableMissing && len(entries) > 0 {
+		if err = s.disableMissingLDAPUsers(ctx, strings.ToLower(cfg.BaseDN), seen, result); err != nil {
+			return result, err
+		}
+	}
+	ctx.Logger.Infof("%s 同步目录用户完成: %+v", s.logPrefix(), *result)
+	return result, nil
+}
+
+// syncLDAPUser 同步单个目录用户，仅在数据库异常时返回错误
+func (s *userService) syncLDAPUser(
+	ctx *context.CliContext, id *externalIdentity, result *modeluser.LDAPSyncResult) error {
+	cfg := s.ldapDir.Config()
+	binding, err := s.identityRepo.GetBySubject(ctx, id.Provider, id.Issuer, id.Subject)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取外部身份失败: %s %v", s.logPrefix(), id.Subject, err)
+		return err
+	}
+
+	if binding == nil {
+		if !cfg.AutoCreate {
+			result.Skipped++
+			return nil
+		}
+		_, err = s.provisionExternalUser(ctx, ctx.Logger, id)
+		switch {
+		case errors.Is(err, errIdentityNoRole), errors.Is(err, errIdentityUsernameConflict):
+			result.Skipped++
+			return nil
+		case err != nil:
+			return err
+		}
+		result.Created++
+		return nil
+	}
+
+	if !cfg.SyncRole || id.RoleCode == "" {
+		return nil
+	}
+	u, err := s.userRepo.GetByID(ctx, binding.UserID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), binding.UserID, err)
+		return err
+	}
+	if u == nil || u.Status == modeluser.UserStatusDeleted || rolesSynced(u, id.RoleCode) {
+		return nil
+	}
+	err = s.syncExternalRole(ctx, ctx.Logger, u, id.RoleCode)
+	switch {
+	case errors.Is(err, errIdentityNoRole):
+		result.Skipped++
+		return nil
+	case err != nil:
+		return err
+	}
+	result.Updated++
+	return nil
+}
+
+// disableMissingLDAPUsers 禁用目录中已不存在的用户
+//
+// 已签发的访问令牌在过期前仍然有效，刷新令牌时会因账户状态异常被拒绝
+func (s *userService) disableMissingLDAPUsers(
+	ctx *context.CliContext, issuer string, seen map[string]bool, result *modeluser.LDAPSyncResult) error {
+	bindings, err := s.identityRepo.ListByIssuer(ctx, modeluser.IdentityProviderLDAP, issuer)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取外部身份失败: %v", s.logPrefix(), err)
+		return err
+	}
+	for _, b := range bindings {
+		if seen[b.Subject] {
+			continue
+		}
+		u, err := s.userRepo.GetByID(ctx, b.UserID)
+		if err != nil {
+			ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), b.UserID, err)
+			return err
+		}
+		if u == nil || !u.IsActive() {
+			continue
+		}
+		if err = s.userRepo.UpdateStatus(ctx, u.ID, modeluser.UserStatusInactive); err != nil {
+			ctx.Logger.Errorf("%s 禁用用户失败: %s %v", s.logPrefix(), u.Username, err)
+			return err
+		}
+		s.invalidateSessions(ctx, ctx.Logger, u.ID)
+		ctx.Logger.Infof("%s 目录中已不存在，禁用用户: %s", s.logPrefix(), u.Username)
+		result.Disabled++
+	}
+	return nil
+}
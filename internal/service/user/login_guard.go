@@ -0,0 +1,188 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/server"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/redisx"
+	"math"
+	"strconv"
+	"time"
+)
+
+// loginSecurity 获取登录安全配置
+func (s *userService) loginSecurity(ctx *context.Context) (*server.LoginSecurityConfig, error) {
+	var cfg server.LoginSecurityConfig
+	if err := s.setSrv.GetSrcValue(ctx, server.SettingLoginSecurity, &cfg); err != nil {
+		ctx.Logger.Errorf("%s 获取登录安全配置失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return &cfg, nil
+}
+
+// checkLoginAllowed 校验IP失败次数及账号的等待时间，Redis 异常时放行
+func (s *userService) checkLoginAllowed(ctx *context.Context, cfg *server.LoginSecurityConfig, username string) error {
+	pipe := redisx.GetClient().Pipeline()
+	ipFailures := pipe.Get(ctx, s.ipFailureKey(ctx.ClientIP()))
+	delay := pipe.TTL(ctx, s.loginDelayKey(username))
+	if _, err := pipe.Exec(ctx); err != nil && err != redisx.Nil {
+		ctx.Logger.Errorf("%s 读取登录失败计数失败: %s %v", s.logPrefix(), username, err)
+		return nil
+	}
+
+	if n, _ := ipFailures.Int64(); cfg.IPMaxFailures > 0 && n >= int64(cfg.IPMaxFailures) {
+		ctx.Logger.Warnf("%s IP登录失败次数过多: %s %d", s.logPrefix(), ctx.ClientIP(), n)
+		return i18n.E(ctx.Context, "user.TooManyLoginAttempts", nil)
+	}
+	if ttl := delay.Val(); ttl > 0 {
+		return i18n.E(ctx.Context, "user.LoginRetryLater",
+			map[string]any{"seconds": int(math.Ceil(ttl.Seconds()))})
+	}
+	return nil
+}
+
+// checkAccountLock 检查账号锁定状态，自动锁定到期的账号在此解锁
+func (s *userService) checkAccountLock(ctx *context.Context, u *modeluser.User) error {
+	if !u.IsLocked() {
+		return nil
+	}
+	if !u.LockExpired(time.Now()) {
+		ctx.Logger.Warnf("%s 账号已锁定: %s", s.logPrefix(), u.Username)
+		if u.LockedUntil != nil {
+			return i18n.E(ctx.Context, "user.AccountLockedUntil", map[string]any{"time": u.LockedUntil.String()})
+		}
+		return i18n.E(ctx.Context, "user.AccountLocked", nil)
+	}
+
+	if err := s.userRepo.Unlock(ctx, u.ID); err != nil {
+		ctx.Logger.Errorf("%s 自动解锁失败: %d %v", s.logPrefix(), u.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.invalidateSessions(ctx, ctx.Logger, u.ID)
+	u.Status = modeluser.UserStatusActive
+	u.LockedUntil = nil
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.User.AutoUnlock", map[string]any{"username": u.Username}), u.Username)
+	return nil
+}
+
+// recordLoginFailure 累加登录失败次数，达到阈值时锁定账号，返回账号是否因此被锁定
+//
+// 账号不存在时同样计数，避免通过响应差异枚举用户名；
+// 超级管理员不自动锁定以免系统被锁死，仍受等待时间及IP次数限制
+func (s *userService) recordLoginFailure(
+	ctx *context.Context, cfg *server.LoginSecurityConfig, username string, u *modeluser.User) bool {
+	return s.recordFailure(ctx, cfg, s.userFailureKey(username), username, u)
+}
+
+// recordTwoFactorFailure 累加动态码错误次数，与密码错误共用锁定阈值
+//
+// 单独按用户计数，密码校验通过时不清零，避免重新登录获取预认证令牌后无限次尝试动态码
+func (s *userService) recordTwoFactorFailure(
+	ctx *context.Context, cfg *server.LoginSecurityConfig, u *modeluser.User) bool {
+	return s.recordFailure(ctx, cfg, s.twoFactorFailureKey(u.ID), u.Username, u)
+}
+
+// recordFailure 累加 userKey 及IP的失败次数，达到阈值时锁定账号，返回账号是否因此被锁定
+func (s *userService) recordFailure(
+	ctx *context.Context, cfg *server.LoginSecurityConfig, userKey, username string, u *modeluser.User) bool {
+	window := cfg.Window()
+	ipKey := s.ipFailureKey(ctx.ClientIP())
+	pipe := redisx.GetClient().TxPipeline()
+	userFailures := pipe.Incr(ctx, userKey)
+	pipe.ExpireNX(ctx, userKey, window)
+	pipe.Incr(ctx, ipKey)
+	pipe.ExpireNX(ctx, ipKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		ctx.Logger.Errorf("%s 记录登录失败次数失败: %s %v", s.logPrefix(), username, err)
+		return false
+	}
+
+	n := userFailures.Val()
+	if u != nil && u.IsActive() && !u.IsSuperAdmin() && cfg.MaxFailures > 0 && n >= int64(cfg.MaxFailures) {
+		var until *time.Time
+		if cfg.LockDuration > 0 {
+			t := time.Now().Add(time.Duration(cfg.LockDuration) * time.Second)
+			until = &t
+		}
+		if err := s.userRepo.Lock(ctx, u.ID, until); err != nil {
+			ctx.Logger.Errorf("%s 锁定账号失败: %d %v", s.logPrefix(), u.ID, err)
+			return false
+		}
+		s.invalidateSessions(ctx, ctx.Logger, u.ID)
+		s.clearLoginFailures(ctx, username)
+		s.clearTwoFactorFailures(ctx, u.ID)
+		ctx.Logger.Warnf("%s 登录失败次数过多，锁定账号: %s %d", s.logPrefix(), username, n)
+		s.logService.CreateOperateLog(
+			ctx, i18n.T(ctx.Context, "operate.User.AutoLock", map[string]any{"username": username, "count": n}), username)
+		return true
+	}
+
+	if delay := cfg.Delay(n); delay > 0 {
+		if err := redisx.GetClient().Set(ctx, s.loginDelayKey(username), n, delay).Err(); err != nil {
+			ctx.Logger.Errorf("%s 记录登录等待时间失败: %s %v", s.logPrefix(), username, err)
+		}
+	}
+	return false
+}
+
+// clearLoginFailures 清除账号的登录失败计数及等待时间
+func (s *userService) clearLoginFailures(ctx *context.Context, username string) {
+	err := redisx.GetClient().Del(ctx, s.userFailureKey(username), s.loginDelayKey(username)).Err()
+	if err != nil {
+		ctx.Logger.Errorf("%s 清除登录失败计数失败: %s %v", s.logPrefix(), username, err)
+	}
+}
+
+// clearTwoFactorFailures 清除用户的动态码错误计数
+func (s *userService) clearTwoFactorFailures(ctx *context.Context, userID uint64) {
+	if err := redisx.GetClient().Del(ctx, s.twoFactorFailureKey(userID)).Err(); err != nil {
+		ctx.Logger.Errorf("%s 清除动态码错误计数失败: %d %v", s.logPrefix(), userID, err)
+	}
+}
+
+// UnlockUser 管理员解锁用户
+func (s *userService) UnlockUser(ctx *context.Context, req *schema.IDRequest) error {
+	user, err := s.userRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if user == nil {
+		return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
+	}
+	if !user.IsLocked() {
+		return i18n.E(ctx.Context, "user.AccountNotLocked", nil)
+	}
+
+	if err = s.userRepo.Unlock(ctx, req.ID); err != nil {
+		ctx.Logger.Errorf("%s 解锁用户失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.invalidateSessions(ctx, ctx.Logger, req.ID)
+	s.clearLoginFailures(ctx, user.Username)
+	s.clearTwoFactorFailures(ctx, req.ID)
+
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.User.Unlock", map[string]any{"username": user.Username}))
+	ctx.Logger.Infof("%s 解锁用户成功: %d", s.logPrefix(), req.ID)
+	return nil
+}
+
+func (s *userService) userFailureKey(username string) string {
+	return "login_fail:user:" + username
+}
+
+func (s *userService) ipFailureKey(ip string) string {
+	return "login_fail:ip:" + ip
+}
+
+func (s *userService) twoFactorFailureKey(userID uint64) string {
+	return "login_fail:2fa:" + strconv.FormatUint(userID, 10)
+}
+
+func (s *userService) loginDelayKey(username string) string {
+	return "login_delay:" + username
+}
@@ -0,0 +1,143 @@
+package user
+
+import (
+	stdctx "context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/config"
+	"goadmin/internal/context"
+	"goadmin/internal/model/role"
+	"goadmin/internal/model/server"
+	modeluser "goadmin/internal/model/user"
+	userrepo "goadmin/internal/repository/user"
+	"goadmin/internal/service/operate_log"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/redisx"
+	"goadmin/pkg/util"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	testRedisOnce sync.Once
+	testRedis     *miniredis.Miniredis
+)
+
+// useTestRedis 使用进程内的 Redis，redisx 按DB缓存客户端，同一测试进程共用一个实例，每个测试前清空
+func useTestRedis(t *testing.T) {
+	t.Helper()
+	testRedisOnce.Do(func() {
+		testRedis = miniredis.NewMiniRedis()
+		if err := testRedis.Start(); err != nil {
+			t.Fatal(err)
+		}
+		port, _ := strconv.Atoi(testRedis.Port())
+		if err := redisx.Init(&config.RedisConfig{Enable: true, Host: testRedis.Host(), Port: port}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	testRedis.FlushAll()
+}
+
+// fakeLockRepo 记录锁定及解锁的用户
+type fakeLockRepo struct {
+	userrepo.UserRepository
+	locked   map[uint64]*time.Time
+	unlocked []uint64
+}
+
+func (r *fakeLockRepo) Lock(_ stdctx.Context, id uint64, until *time.Time) error {
+	r.locked[id] = until
+	return nil
+}
+
+func (r *fakeLockRepo) Unlock(_ stdctx.Context, id uint64) error {
+	r.unlocked = append(r.unlocked, id)
+	return nil
+}
+
+// fakeLogService 忽略操作日志
+type fakeLogService struct {
+	operate_log.OperateLogService
+}
+
+func (fakeLogService) CreateOperateLog(*context.Context, string, ...string) error {
+	return nil
+}
+
+func newLockTestContext() *context.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/v1/user/login", nil)
+	return &context.Context{Context: c, Logger: logger.Global()}
+}
+
+func TestLoginLockout(t *testing.T) {
+	useTestRedis(t)
+	repo := &fakeLockRepo{locked: map[uint64]*time.Time{}}
+	s := &userService{userRepo: repo, logService: fakeLogService{}, cfg: &config.Config{}}
+	ctx := newLockTestContext()
+	cfg := &server.LoginSecurityConfig{MaxFailures: 3, IPMaxFailures: 5, LockDuration: 600, DelayBase: 1, DelayMax: 4}
+	alice := &modeluser.User{Username: "alice", Status: modeluser.UserStatusActive}
+	alice.ID = 1
+
+	// 未达到阈值时只需等待，等待时间逐次翻倍
+	for i := 1; i < cfg.MaxFailures; i++ {
+		if s.recordLoginFailure(ctx, cfg, alice.Username, alice) {
+			t.Fatalf("locked after %d failures", i)
+		}
+	}
+	if ttl := testRedis.TTL(s.loginDelayKey(alice.Username)); ttl != 2*time.Second {
+		t.Errorf("delay = %s, want 2s", ttl)
+	}
+	if err := s.checkLoginAllowed(ctx, cfg, alice.Username); err == nil || err.Error() != "user.LoginRetryLater" {
+		t.Errorf("checkLoginAllowed = %v, want LoginRetryLater", err)
+	}
+
+	// 达到阈值时锁定账号并清除计数
+	if !s.recordLoginFailure(ctx, cfg, alice.Username, alice) {
+		t.Fatal("account should be locked")
+	}
+	until, ok := repo.locked[alice.ID]
+	if !ok || until == nil || time.Until(*until) <= 0 || time.Until(*until) > 10*time.Minute {
+		t.Fatalf("lock until = %v, want about 10 minutes", until)
+	}
+	if testRedis.Exists(s.userFailureKey(alice.Username)) || testRedis.Exists(s.loginDelayKey(alice.Username)) {
+		t.Error("failure counters should be cleared after lock")
+	}
+
+	// 锁定期内拒绝登录，到期后自动解锁
+	lockedUntil := util.DateTime(*until)
+	alice.Status, alice.LockedUntil = modeluser.UserStatusLocked, &lockedUntil
+	if err := s.checkAccountLock(ctx, alice); err == nil || err.Error() != "user.AccountLockedUntil" {
+		t.Errorf("checkAccountLock = %v, want AccountLockedUntil", err)
+	}
+	expired := util.DateTime(time.Now().Add(-time.Second))
+	alice.LockedUntil = &expired
+	if err := s.checkAccountLock(ctx, alice); err != nil {
+		t.Fatalf("expired lock = %v", err)
+	}
+	if len(repo.unlocked) != 1 || repo.unlocked[0] != alice.ID || !alice.IsActive() {
+		t.Errorf("unlocked = %v, status = %d", repo.unlocked, alice.Status)
+	}
+
+	// 超级管理员不自动锁定
+	root := &modeluser.User{Username: "root", Status: modeluser.UserStatusActive, Roles: role.Set{{Code: role.CodeSuperAdmin}}}
+	root.ID = 2
+	for i := 0; i < cfg.MaxFailures; i++ {
+		if s.recordLoginFailure(ctx, cfg, root.Username, root) {
+			t.Fatal("super admin must not be locked")
+		}
+	}
+
+	// 同一IP失败次数过多时拒绝该IP的全部登录
+	if err := s.checkLoginAllowed(ctx, cfg, "bob"); err == nil || err.Error() != "user.TooManyLoginAttempts" {
+		t.Errorf("checkLoginAllowed = %v, want TooManyLoginAttempts", err)
+	}
+}
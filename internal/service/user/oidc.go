@@ -0,0 +1,141 @@
+package user
+
+import (
+	"crypto/subtle"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/internal/service/token"
+	"goadmin/pkg/oidc"
+	"goadmin/pkg/util"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// 单点登录参数的有效期，需覆盖用户在身份提供方完成登录的时间
+const oidcStateExpiration = 10 * time.Minute
+
+// oidcStateCookie 保存 state 的 Cookie，将登录流程绑定到发起登录的浏览器
+const oidcStateCookie = "oidc_state"
+
+// LoginOptions 获取登录页可用的登录方式
+func (s *userService) LoginOptions(ctx *context.Context) *modeluser.LoginOptionsResponse {
+	resp := &modeluser.LoginOptionsResponse{LocalLogin: s.authChain.enabled()}
+	if s.oidcClient.Enabled() {
+		resp.OIDC = true
+		resp.OIDCName = s.oidcClient.Config().Name
+	}
+	return resp
+}
+
+// checkLocalLogin 校验是否允许本地账号密码认证
+func (s *userService) checkLocalLogin(ctx *context.Context) error {
+	if s.cfg.Auth.DisableLocalLogin {
+		return i18n.E(ctx.Context, "user.LocalLoginDisabled", nil)
+	}
+	return nil
+}
+
+// OIDCLoginURL 生成跳转到身份提供方的授权地址
+//
+// state、nonce 及 PKCE code_verifier 保存在 Redis 中，回调时一次性取回；
+// state 同时写入 HttpOnly Cookie，回调时要求一致，防止以他人发起的回调地址完成登录
+func (s *userService) OIDCLoginURL(ctx *context.Context) (string, error) {
+	if !s.oidcClient.Enabled() {
+		return "", i18n.E(ctx.Context, "user.OIDCDisabled", nil)
+	}
+	state := util.GenerateUUIDWithoutHyphen()
+	value := &token.OIDCState{
+		Verifier: oidc.GenerateVerifier(),
+		Nonce:    util.GenerateUUIDWithoutHyphen(),
+	}
+	if err := s.tokenSvc.SaveOIDCState(ctx, state, value, oidcStateExpiration); err != nil {
+		return "", i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	authURL, err := s.oidcClient.AuthCodeURL(ctx, state, value.Nonce, value.Verifier)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取身份提供方授权地址失败: %v", s.logPrefix(), err)
+		return "", i18n.E(ctx.Context, "user.OIDCUnavailable", nil)
+	}
+	s.setOIDCStateCookie(ctx, state, int(oidcStateExpiration.Seconds()))
+	return authURL, nil
+}
+
+// OIDCCallback 处理身份提供方回调，校验身份后签发令牌
+//
+// 首次登录的用户按配置自动创建，角色由声明映射规则决定
+func (s *userService) OIDCCallback(
+	ctx *context.Context, req *modeluser.OIDCCallbackRequest) (*modeluser.LoginResponse, error) {
+	if !s.oidcClient.Enabled() {
+		return nil, i18n.E(ctx.Context, "user.OIDCDisabled", nil)
+	}
+	cookie, _ := ctx.Cookie(oidcStateCookie)
+	s.setOIDCStateCookie(ctx, "", -1)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(req.State)) != 1 {
+		ctx.Logger.Warnf("%s 单点登录 state 与发起登录的浏览器不一致: %s", s.logPrefix(), req.State)
+		return nil, i18n.E(ctx.Context, "user.OIDCStateInvalid", nil)
+	}
+	value, err := s.tokenSvc.ConsumeOIDCState(ctx, req.State)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取单点登录参数失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if value == nil {
+		ctx.Logger.Warnf("%s 单点登录 state 无效或已过期: %s", s.logPrefix(), req.State)
+		return nil, i18n.E(ctx.Context, "user.OIDCStateInvalid", nil)
+	}
+	if req.Error != "" || req.Code == "" {
+		ctx.Logger.Warnf("%s 身份提供方拒绝授权: %s %s", s.logPrefix(), req.Error, req.ErrorDescription)
+		return nil, i18n.E(ctx.Context, "user.OIDCLoginFailed", nil)
+	}
+
+	identity, err := s.oidcClient.Exchange(ctx, req.Code, value.Verifier, value.Nonce)
+	if err != nil {
+		ctx.Logger.Errorf("%s 单点登录校验失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "user.OIDCLoginFailed", nil)
+	}
+
+	u, err := s.oidcUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkAccountLock(ctx, u); err != nil {
+		return nil, err
+	}
+	if !u.IsActive() {
+		ctx.Logger.Warnf("%s 账户状态异常: %s %s", s.logPrefix(), u.Username, u.Status.String())
+		return nil, i18n.E(ctx.Context, "user.AccountStatusAbnormal", nil)
+	}
+	return s.completeLogin(ctx, u, i18n.T(ctx.Context, "operate.LoginOIDC", nil))
+}
+
+// setOIDCStateCookie 写入或清除（maxAge 为负数时）state Cookie
+//
+// 限定在回调路径，SameSite=Lax 保证从身份提供方跳转回来时携带
+func (s *userService) setOIDCStateCookie(ctx *context.Context, state string, maxAge int) {
+	path, secure := "/", ctx.Request.TLS != nil
+	if u, err := url.Parse(s.oidcClient.Config().RedirectURL); err == nil && u.Path != "" {
+		path = u.Path
+		secure = secure || u.Scheme == "https"
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, state, maxAge, path, "", secure, true)
+}
+
+// oidcUser 获取外部身份绑定的本地用户，未绑定时按配置自动创建
+func (s *userService) oidcUser(ctx *context.Context, identity *oidc.Identity) (*modeluser.User, error) {
+	if identity.Username == "" {
+		ctx.Logger.Warnf("%s 单点登录缺少用户名声明: %s", s.logPrefix(), identity.Subject)
+		return nil, i18n.E(ctx.Context, "user.OIDCLoginFailed", nil)
+	}
+	cfg := s.oidcClient.Config()
+	return s.externalUser(ctx, &externalIdentity{
+		Provider: modeluser.IdentityProviderOIDC,
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+		Username: identity.Username,
+		Email:    identity.Email,
+		RoleCode: cfg.MapRole(identity.Claims),
+	}, cfg.AutoCreate, cfg.SyncRole)
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/server"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/util"
+	"time"
+)
+
+// passwordPolicy 获取密码策略配置
+func (s *userService) passwordPolicy(ctx *context.Context) (*server.PasswordPolicyConfig, error) {
+	var cfg server.PasswordPolicyConfig
+	if err := s.setSrv.GetSrcValue(ctx, server.SettingPasswordPolicy, &cfg); err != nil {
+		ctx.Logger.Errorf("%s 获取密码策略失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return &cfg, nil
+}
+
+// checkPasswordPolicy 校验明文密码是否符合密码策略
+func (s *userService) checkPasswordPolicy(
+	ctx *context.Context, cfg *server.PasswordPolicyConfig, password, username string) error {
+	if e := cfg.Check(password, username); e != nil {
+		ctx.Logger.Warnf("%s 密码不符合策略: %s %s", s.logPrefix(), username, e.Key)
+		return i18n.E(ctx.Context, e.Key, e.Params)
+	}
+	return nil
+}
+
+// checkPasswordReuse 校验新密码与当前密码及最近 N 次历史密码均不相同
+//
+// digest 为明文密码的摘要，与数据库中哈希的原文格式一致
+func (s *userService) checkPasswordReuse(
+	ctx *context.Context, cfg *server.PasswordPolicyConfig, u *modeluser.User, digest string) error {
+	if util.ValidatePasswordAndHash(digest, u.Password) {
+		return i18n.E(ctx.Context, "user.PasswordSameAsOld", nil)
+	}
+	if cfg.HistoryCount <= 0 {
+		return nil
+	}
+
+	histories, err := s.pwdHistoryRepo.ListRecent(ctx, u.ID, cfg.HistoryCount)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取历史密码失败: %d %v", s.logPrefix(), u.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	for _, h := range histories {
+		if util.ValidatePasswordAndHash(digest, h.Password) {
+			ctx.Logger.Warnf("%s 新密码与历史密码相同: %s", s.logPrefix(), u.Username)
+			return i18n.E(ctx.Context, "user.PasswordReused", map[string]any{"count": cfg.HistoryCount})
+		}
+	}
+	return nil
+}
+
+// savePassword 更新用户密码并记录历史密码
+func (s *userService) savePassword(
+	ctx *context.Context, cfg *server.PasswordPolicyConfig, userID uint64, hash string, mustChange bool) error {
+	if err := s.userRepo.UpdatePassword(ctx, userID, hash, mustChange); err != nil {
+		ctx.Logger.Errorf("%s 更新密码失败: %d %v", s.logPrefix(), userID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.invalidateSessions(ctx, ctx.Logger, userID)
+	s.recordPasswordHistory(ctx, cfg, userID, hash)
+	return nil
+}
+
+// recordPasswordHistory 记录历史密码，失败仅记录日志
+func (s *userService) recordPasswordHistory(
+	ctx *context.Context, cfg *server.PasswordPolicyConfig, userID uint64, hash string) {
+	if cfg.HistoryCount <= 0 {
+		return
+	}
+	err := s.pwdHistoryRepo.Create(ctx, &modeluser.PasswordHistory{UserID: userID, Password: hash})
+	if err != nil {
+		ctx.Logger.Errorf("%s 记录历史密码失败: %d %v", s.logPrefix(), userID, err)
+		return
+	}
+	if err = s.pwdHistoryRepo.Prune(ctx, userID, cfg.HistoryCount); err != nil {
+		ctx.Logger.Errorf("%s 清理历史密码失败: %d %v", s.logPrefix(), userID, err)
+	}
+}
+
+// fillPasswordStatus 填充密码过期信息
+func (s *userService) fillPasswordStatus(cfg *server.PasswordPolicyConfig, u *modeluser.User) {
+	if u.PasswordChangedAt == nil {
+		return
+	}
+	changedAt := time.Time(*u.PasswordChangedAt)
+	if expiresAt := cfg.ExpiresAt(changedAt); !expiresAt.IsZero() {
+		t := util.DateTime(expiresAt)
+		u.PasswordExpiresAt = &t
+	}
+	u.PasswordExpired = cfg.Expired(changedAt, time.Now())
+}
+
+// passwordChangeRequired 用户是否须修改密码后才能继续使用，获取策略失败时不做要求
+func (s *userService) passwordChangeRequired(ctx *context.Context, u *modeluser.User) bool {
+	if u.MustChangePassword {
+		return true
+	}
+	cfg, err := s.passwordPolicy(ctx)
+	if err != nil {
+		return false
+	}
+	s.fillPasswordStatus(cfg, u)
+	return u.PasswordExpired
+}
+
+// GetCurrentUser 获取当前登录用户信息，包含权限及密码过期状态
+func (s *userService) GetCurrentUser(ctx *context.Context) (*modeluser.User, error) {
+	u, err := s.GetUserByIDWithPerm(ctx, ctx.Session().GetID())
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.passwordPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.fillPasswordStatus(cfg, u)
+	return u, nil
+}
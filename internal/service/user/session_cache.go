@@ -0,0 +1,78 @@
+package user
+
+import (
+	stdctx "context"
+	"goadmin/config"
+	"goadmin/internal/context"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/cache"
+	"goadmin/pkg/logger"
+	"goadmin/pkg/redisx"
+	"strconv"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	sessionCacheOnce sync.Once
+	sessionCache     *cache.Tiered[*modeluser.User]
+)
+
+// sharedSessionCache 进程内共享的登录用户缓存，键为用户ID，未开启鉴权缓存时返回 nil
+//
+// 仅缓存状态正常的用户，密码等 json 忽略的字段不会写入缓存
+func sharedSessionCache(cfg *config.Config) *cache.Tiered[*modeluser.User] {
+	if cfg == nil || !cfg.Auth.Cache.Enable {
+		return nil
+	}
+	sessionCacheOnce.Do(func() {
+		sessionCache = cache.NewTiered[*modeluser.User](cache.Options{
+			Name:      "rbac:session_user",
+			Size:      cfg.Auth.Cache.SizeOrDefault(),
+			LocalTTL:  cfg.Auth.Cache.LocalTTLOrDefault(),
+			RemoteTTL: cfg.Auth.Cache.RemoteTTLOrDefault(),
+			Client:    func() *redis.Client { return redisx.GetClient() },
+		})
+	})
+	return sessionCache
+}
+
+// GetSessionUser 获取认证用的用户信息，开启鉴权缓存时优先读取缓存
+func (s *userService) GetSessionUser(ctx *context.Context, userID uint64) (*modeluser.User, error) {
+	c := sharedSessionCache(s.cfg)
+	if c == nil {
+		return s.GetUserByID(ctx, userID)
+	}
+	u, err := c.Get(ctx, strconv.FormatUint(userID, 10), func(stdctx.Context) (*modeluser.User, error) {
+		return s.GetUserByID(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 进程内缓存的条目在请求间共享，返回副本避免被修改
+	cp := *u
+	return &cp, nil
+}
+
+// invalidateSessions 用户状态、角色等变更后使各实例的缓存失效，失败时依赖缓存有效期兜底
+func (s *userService) invalidateSessions(ctx stdctx.Context, log logger.Logger, userIDs ...uint64) {
+	if err := InvalidateSessionUsers(ctx, s.cfg, userIDs...); err != nil {
+		log.Errorf("%s 用户缓存失效通知失败: %v %v", s.logPrefix(), userIDs, err)
+	}
+}
+
+// InvalidateSessionUsers 使指定用户的登录用户缓存在各实例失效
+//
+// 角色数据范围、租户状态等影响登录用户信息的变更由其他服务调用，未开启鉴权缓存时不做处理
+func InvalidateSessionUsers(ctx stdctx.Context, cfg *config.Config, userIDs ...uint64) error {
+	c := sharedSessionCache(cfg)
+	if c == nil || len(userIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, strconv.FormatUint(id, 10))
+	}
+	return c.Invalidate(ctx, keys...)
+}
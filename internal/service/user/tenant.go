@@ -0,0 +1,83 @@
+package user
+
+import (
+	stdctx "context"
+	"goadmin/config"
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/cache"
+	"goadmin/pkg/redisx"
+	"strconv"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	tenantCacheOnce sync.Once
+	tenantCache     *cache.Tiered[bool]
+)
+
+// sharedTenantCache 进程内共享的租户启用状态缓存，键为租户ID，未开启鉴权缓存时返回 nil
+//
+// 认证中间件每个请求都会校验租户状态，租户变更后须通过 InvalidateTenant 使各实例失效
+func sharedTenantCache(cfg *config.Config) *cache.Tiered[bool] {
+	if cfg == nil || !cfg.Auth.Cache.Enable {
+		return nil
+	}
+	tenantCacheOnce.Do(func() {
+		tenantCache = cache.NewTiered[bool](cache.Options{
+			Name:      "rbac:tenant_active",
+			Size:      cfg.Auth.Cache.SizeOrDefault(),
+			LocalTTL:  cfg.Auth.Cache.LocalTTLOrDefault(),
+			RemoteTTL: cfg.Auth.Cache.RemoteTTLOrDefault(),
+			Client:    func() *redis.Client { return redisx.GetClient() },
+		})
+	})
+	return tenantCache
+}
+
+// CheckTenant 校验用户所属租户已启用，平台用户不属于任何租户
+//
+// 登录及每次认证时调用，租户停用后已签发的令牌随即失效
+func (s *userService) CheckTenant(ctx *context.Context, u *modeluser.User) error {
+	if u.TenantID == 0 {
+		return nil
+	}
+	active, err := s.tenantActive(ctx, u.TenantID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户所属租户失败: %s %d %v", s.logPrefix(), u.Username, u.TenantID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if !active {
+		ctx.Logger.Warnf("%s 用户所属租户已停用: %s %d", s.logPrefix(), u.Username, u.TenantID)
+		return i18n.E(ctx.Context, "user.TenantDisabled", nil)
+	}
+	return nil
+}
+
+// tenantActive 租户是否存在且已启用，开启鉴权缓存时优先读取缓存
+func (s *userService) tenantActive(ctx *context.Context, tenantID uint64) (bool, error) {
+	load := func(stdctx.Context) (bool, error) {
+		t, err := s.tenantRepo.GetByID(ctx, tenantID)
+		if err != nil {
+			return false, err
+		}
+		return t != nil && t.IsActive(), nil
+	}
+	c := sharedTenantCache(s.cfg)
+	if c == nil {
+		return load(ctx)
+	}
+	return c.Get(ctx, strconv.FormatUint(tenantID, 10), load)
+}
+
+// InvalidateTenant 租户停用、启用或注销后使各实例的租户状态缓存失效
+func InvalidateTenant(ctx stdctx.Context, cfg *config.Config, tenantID uint64) error {
+	c := sharedTenantCache(cfg)
+	if c == nil {
+		return nil
+	}
+	return c.Invalidate(ctx, strconv.FormatUint(tenantID, 10))
+}
@@ -0,0 +1,503 @@
+package user
+
+import (
+	"goadmin/internal/context"
+	"goadmin/internal/i18n"
+	"goadmin/internal/model/schema"
+	"goadmin/internal/model/server"
+	modeluser "goadmin/internal/model/user"
+	"goadmin/pkg/util"
+	"strings"
+	"time"
+)
+
+const (
+	preAuthExpire      = 5 * time.Minute // 预认证令牌有效期
+	preAuthMaxAttempts = 5               // 单个预认证令牌允许的动态码校验次数
+	totpSkew           = 1               // 动态码允许的时间步偏差
+	recoveryCodeCount  = 10              // 恢复码数量
+)
+
+// twoFactorChallenge 开启双因素认证时生成预认证令牌，未开启时返回 nil
+func (s *userService) twoFactorChallenge(ctx *context.Context, u *modeluser.User) (*modeluser.LoginResponse, error) {
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, u.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	enabled := tf != nil && tf.IsEnabled()
+	required := false
+	if !enabled {
+		if required, err = s.isTwoFactorRequired(ctx, u.RoleCodes()); err != nil {
+			return nil, err
+		}
+	}
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	preAuthToken, err := s.tokenSvc.GeneratePreAuthToken(ctx, u.ID, preAuthExpire)
+	if err != nil {
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	return &modeluser.LoginResponse{
+		TwoFactorRequired: enabled,
+		TwoFactorSetup:    !enabled,
+		PreAuthToken:      preAuthToken,
+	}, nil
+}
+
+// LoginTwoFactor 登录第二步：校验动态码或恢复码后签发令牌
+//
+// 角色强制启用但尚未绑定的用户，在此提交首个动态码完成绑定，并返回恢复码
+func (s *userService) LoginTwoFactor(
+	ctx *context.Context, req *modeluser.TwoFactorLoginRequest) (*modeluser.LoginResponse, error) {
+	u, err := s.preAuthUser(ctx, req.PreAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.tokenSvc.IncrPreAuthAttempts(ctx, req.PreAuthToken)
+	if err != nil {
+		ctx.Logger.Errorf("%s 记录预认证次数失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if attempts > preAuthMaxAttempts {
+		ctx.Logger.Warnf("%s 动态码校验次数过多: %s", s.logPrefix(), u.Username)
+		_ = s.tokenSvc.DeletePreAuthToken(ctx, req.PreAuthToken)
+		return nil, i18n.E(ctx.Context, "user.PreAuthTokenInvalid", nil)
+	}
+
+	// 动态码错误同样计入账号的失败次数，受等待时间、IP次数及锁定限制
+	securityCfg, err := s.loginSecurity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkLoginAllowed(ctx, securityCfg, u.Username); err != nil {
+		return nil, err
+	}
+
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, u.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if tf == nil {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorNotEnrolled", nil)
+	}
+
+	var resp modeluser.LoginResponse
+	if tf.IsEnabled() {
+		ok, err := s.verifyTwoFactor(ctx, u.ID, tf, req.Code)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			ctx.Logger.Warnf("%s 动态码错误: %s", s.logPrefix(), u.Username)
+			if s.recordTwoFactorFailure(ctx, securityCfg, u) {
+				_ = s.tokenSvc.DeletePreAuthToken(ctx, req.PreAuthToken)
+				return nil, i18n.E(ctx.Context, "user.AccountLocked", nil)
+			}
+			return nil, i18n.E(ctx.Context, "user.TwoFactorCodeInvalid", nil)
+		}
+		s.clearTwoFactorFailures(ctx, u.ID)
+	} else {
+		if resp.RecoveryCodes, err = s.activateTwoFactor(ctx, u.ID, tf, req.Code); err != nil {
+			return nil, err
+		}
+		s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.EnableTwoFactor", nil), u.Username)
+	}
+	_ = s.tokenSvc.DeletePreAuthToken(ctx, req.PreAuthToken)
+
+	tokenPairs, err := s.GenerateUserCredential(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Login", nil), u.Username)
+
+	resp.Token = tokenPairs.AccessToken
+	resp.RefreshToken = tokenPairs.RefreshToken
+	resp.ExpiresAt = tokenPairs.ExpiresAt
+	resp.PasswordChangeRequired = s.passwordChangeRequired(ctx, u)
+	return &resp, nil
+}
+
+// LoginTwoFactorSetup 登录过程中为强制启用双因素认证的用户生成绑定密钥
+func (s *userService) LoginTwoFactorSetup(
+	ctx *context.Context, req *modeluser.TwoFactorSetupRequest) (*modeluser.TwoFactorEnrollResponse, error) {
+	u, err := s.preAuthUser(ctx, req.PreAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.enrollTwoFactor(ctx, u)
+}
+
+// TwoFactorStatus 获取当前用户的双因素认证状态
+func (s *userService) TwoFactorStatus(ctx *context.Context) (*modeluser.TwoFactorStatusResponse, error) {
+	u := ctx.Session().(*modeluser.User)
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, u.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	required, err := s.isTwoFactorRequired(ctx, u.RoleCodes())
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &modeluser.TwoFactorStatusResponse{Required: required}
+	if tf != nil && tf.IsEnabled() {
+		rs.Enabled = true
+		rs.RecoveryCodes = len(tf.RecoveryHashes())
+	}
+	return rs, nil
+}
+
+// EnrollTwoFactor 为当前用户生成 TOTP 密钥，校验动态码后生效
+func (s *userService) EnrollTwoFactor(ctx *context.Context) (*modeluser.TwoFactorEnrollResponse, error) {
+	return s.enrollTwoFactor(ctx, ctx.Session().(*modeluser.User))
+}
+
+// VerifyTwoFactor 校验动态码并启用双因素认证，返回恢复码
+func (s *userService) VerifyTwoFactor(
+	ctx *context.Context, req *modeluser.TwoFactorCodeRequest) (*modeluser.TwoFactorRecoveryResponse, error) {
+	userID := ctx.Session().GetID()
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if tf == nil {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorNotEnrolled", nil)
+	}
+	if tf.IsEnabled() {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorAlreadyEnabled", nil)
+	}
+
+	codes, err := s.activateTwoFactor(ctx, userID, tf, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.EnableTwoFactor", nil))
+	return &modeluser.TwoFactorRecoveryResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor 校验动态码或恢复码后关闭双因素认证
+func (s *userService) DisableTwoFactor(ctx *context.Context, req *modeluser.TwoFactorCodeRequest) error {
+	u := ctx.Session().(*modeluser.User)
+	tf, err := s.enabledTwoFactor(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	required, err := s.isTwoFactorRequired(ctx, u.RoleCodes())
+	if err != nil {
+		return err
+	}
+	if required {
+		return i18n.E(ctx.Context, "user.TwoFactorRequired", nil)
+	}
+
+	ok, err := s.verifyTwoFactor(ctx, u.ID, tf, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return i18n.E(ctx.Context, "user.TwoFactorCodeInvalid", nil)
+	}
+
+	if err = s.twoFactorRepo.DeleteByUserID(ctx, u.ID); err != nil {
+		ctx.Logger.Errorf("%s 关闭双因素认证失败: %d %v", s.logPrefix(), u.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.DisableTwoFactor", nil))
+	return nil
+}
+
+// RegenerateRecoveryCodes 校验动态码后重新生成恢复码，旧恢复码全部作废
+func (s *userService) RegenerateRecoveryCodes(
+	ctx *context.Context, req *modeluser.TwoFactorCodeRequest) (*modeluser.TwoFactorRecoveryResponse, error) {
+	userID := ctx.Session().GetID()
+	tf, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.verifyTOTP(ctx, userID, tf, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorCodeInvalid", nil)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.Logger.Errorf("%s 生成恢复码失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+	tf.SetRecoveryHashes(hashes)
+	if err = s.twoFactorRepo.Update(ctx, tf); err != nil {
+		ctx.Logger.Errorf("%s 保存恢复码失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.RegenerateRecoveryCodes", nil))
+	return &modeluser.TwoFactorRecoveryResponse{RecoveryCodes: codes}, nil
+}
+
+// ResetTwoFactor 管理员重置指定用户的双因素认证（如认证器丢失）
+func (s *userService) ResetTwoFactor(ctx *context.Context, req *schema.IDRequest) error {
+	user, err := s.userRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if user == nil {
+		return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
+	}
+
+	if err = s.twoFactorRepo.DeleteByUserID(ctx, req.ID); err != nil {
+		ctx.Logger.Errorf("%s 重置双因素认证失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.User.ResetTwoFactor", map[string]any{"username": user.Username}))
+	return nil
+}
+
+// isTwoFactorRequired 用户的角色是否被系统设置要求强制启用双因素认证
+func (s *userService) isTwoFactorRequired(ctx *context.Context, roleCodes []string) (bool, error) {
+	var cfg server.TwoFactorConfig
+	if err := s.setSrv.GetSrcValue(ctx, server.SettingTwoFactor, &cfg); err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证设置失败: %v", s.logPrefix(), err)
+		return false, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return cfg.IsRequired(roleCodes), nil
+}
+
+// preAuthUser 根据预认证令牌获取用户
+func (s *userService) preAuthUser(ctx *context.Context, preAuthToken string) (*modeluser.User, error) {
+	userID, err := s.tokenSvc.GetPreAuthUserID(ctx, preAuthToken)
+	if err != nil {
+		ctx.Logger.Errorf("%s 读取预认证令牌失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if userID == 0 {
+		return nil, i18n.E(ctx.Context, "user.PreAuthTokenInvalid", nil)
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if u == nil || !u.IsActive() {
+		return nil, i18n.E(ctx.Context, "user.AccountStatusAbnormal", nil)
+	}
+	return u, nil
+}
+
+// enabledTwoFactor 获取已启用的双因素认证配置
+func (s *userService) enabledTwoFactor(ctx *context.Context, userID uint64) (*modeluser.TwoFactor, error) {
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if tf == nil || !tf.IsEnabled() {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorNotEnrolled", nil)
+	}
+	return tf, nil
+}
+
+// enrollTwoFactor 生成新的待验证密钥，覆盖未完成的绑定
+func (s *userService) enrollTwoFactor(
+	ctx *context.Context, u *modeluser.User) (*modeluser.TwoFactorEnrollResponse, error) {
+	tf, err := s.twoFactorRepo.GetByUserID(ctx, u.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取双因素认证配置失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	if tf != nil && tf.IsEnabled() {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorAlreadyEnabled", nil)
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		ctx.Logger.Errorf("%s 生成TOTP密钥失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+	key, err := s.twoFactorKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := util.EncryptAESGCM([]byte(secret), key)
+	if err != nil {
+		ctx.Logger.Errorf("%s 加密TOTP密钥失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+
+	if tf == nil {
+		tf = &modeluser.TwoFactor{UserID: u.ID, Secret: encrypted, Status: modeluser.TwoFactorPending}
+		err = s.twoFactorRepo.Create(ctx, tf)
+	} else {
+		tf.Secret = encrypted
+		tf.SetRecoveryHashes(nil)
+		err = s.twoFactorRepo.Update(ctx, tf)
+	}
+	if err != nil {
+		ctx.Logger.Errorf("%s 保存TOTP密钥失败: %d %v", s.logPrefix(), u.ID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+
+	return &modeluser.TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    util.TOTPURI(s.cfg.App.Name, u.Username, secret),
+	}, nil
+}
+
+// activateTwoFactor 校验待验证密钥的动态码，启用双因素认证并生成恢复码
+func (s *userService) activateTwoFactor(
+	ctx *context.Context, userID uint64, tf *modeluser.TwoFactor, code string) ([]string, error) {
+	ok, err := s.verifyTOTP(ctx, userID, tf, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, i18n.E(ctx.Context, "user.TwoFactorCodeInvalid", nil)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.Logger.Errorf("%s 生成恢复码失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+	tf.Status = modeluser.TwoFactorEnabled
+	tf.SetRecoveryHashes(hashes)
+	if err = s.twoFactorRepo.Update(ctx, tf); err != nil {
+		ctx.Logger.Errorf("%s 启用双因素认证失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	return codes, nil
+}
+
+// verifyTwoFactor 校验动态码，非6位数字时按恢复码校验，恢复码使用后即作废
+func (s *userService) verifyTwoFactor(
+	ctx *context.Context, userID uint64, tf *modeluser.TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return s.verifyTOTP(ctx, userID, tf, code)
+	}
+
+	code = normalizeRecoveryCode(code)
+	hashes := tf.RecoveryHashes()
+	for i, hash := range hashes {
+		if !util.ValidatePasswordAndHash(code, hash) {
+			continue
+		}
+		old := tf.RecoveryCodes
+		tf.SetRecoveryHashes(append(hashes[:i:i], hashes[i+1:]...))
+		updated, err := s.twoFactorRepo.UpdateRecoveryCodes(ctx, tf.ID, old, tf.RecoveryCodes)
+		if err != nil {
+			ctx.Logger.Errorf("%s 作废恢复码失败: %d %v", s.logPrefix(), userID, err)
+			return false, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		if updated {
+			ctx.Logger.Infof("%s 使用恢复码登录: %d 剩余 %d", s.logPrefix(), userID, len(hashes)-1)
+		}
+		return updated, nil
+	}
+	return false, nil
+}
+
+// verifyTOTP 校验动态码，同一动态码只能使用一次
+func (s *userService) verifyTOTP(ctx *context.Context, userID uint64, tf *modeluser.TwoFactor, code string) (bool, error) {
+	secret, err := s.totpSecret(ctx, userID, tf)
+	if err != nil {
+		return false, err
+	}
+	counter, ok := util.ValidateTOTP(secret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	fresh, err := s.tokenSvc.MarkTOTPUsed(
+		ctx, userID, counter, time.Duration(2*totpSkew+1)*util.TOTPPeriod*time.Second)
+	if err != nil {
+		ctx.Logger.Errorf("%s 记录动态码使用失败: %d %v", s.logPrefix(), userID, err)
+		return false, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	return fresh, nil
+}
+
+// twoFactorKey 加密TOTP密钥的密钥，未配置时不能启用或校验双因素认证
+func (s *userService) twoFactorKey(ctx *context.Context) ([]byte, error) {
+	key, err := s.cfg.Auth.TwoFactorKeyBytes()
+	if err != nil {
+		ctx.Logger.Errorf("%s 双因素认证加密密钥不可用: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "user.TwoFactorKeyMissing", nil)
+	}
+	return key, nil
+}
+
+// totpSecret 解密TOTP密钥
+//
+// 早期版本使用代码内置的密钥加密，解密成功后改用配置的密钥重新加密
+func (s *userService) totpSecret(ctx *context.Context, userID uint64, tf *modeluser.TwoFactor) (string, error) {
+	key, err := s.twoFactorKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	if secret, err := util.DecryptAESGCM(tf.Secret, key); err == nil {
+		return string(secret), nil
+	}
+	secret, err := util.DecryptAESGCM(tf.Secret)
+	if err != nil {
+		ctx.Logger.Errorf("%s 解密TOTP密钥失败: %d %v", s.logPrefix(), userID, err)
+		return "", i18n.E(ctx.Context, "common.EncryptErr", nil)
+	}
+
+	encrypted, err := util.EncryptAESGCM(secret, key)
+	if err == nil {
+		_, err = s.twoFactorRepo.UpdateSecret(ctx, tf.ID, tf.Secret, encrypted)
+	}
+	if err != nil {
+		ctx.Logger.Errorf("%s 重新加密TOTP密钥失败: %d %v", s.logPrefix(), userID, err)
+	} else {
+		tf.Secret = encrypted
+		ctx.Logger.Infof("%s 已使用配置的密钥重新加密TOTP密钥: %d", s.logPrefix(), userID)
+	}
+	return string(secret), nil
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文（仅展示一次）及其哈希
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		str, err := util.GenerateRandomString(10)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(str[:5] + "-" + str[5:])
+		hash, err := util.Password2Hash(normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 恢复码不区分大小写，忽略分隔符
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != util.TOTPDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
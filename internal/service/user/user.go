@@ -1,18 +1,29 @@
 package user
 
 import (
+	"errors"
+	"fmt"
 	"goadmin/internal/context"
 	"goadmin/internal/i18n"
+	modelrole "goadmin/internal/model/role"
 	"goadmin/internal/model/schema"
 	"goadmin/internal/model/server"
 	modeluser "goadmin/internal/model/user"
 	"goadmin/internal/repository/role"
+	tenantrepo "goadmin/internal/repository/tenant"
 	userrepo "goadmin/internal/repository/user"
 	"goadmin/internal/service/captcha"
 	"goadmin/internal/service/operate_log"
 	"goadmin/internal/service/setting"
 	"goadmin/internal/service/token"
+	"goadmin/pkg/db"
+	"goadmin/pkg/ldap"
+	"goadmin/pkg/mail"
+	"goadmin/pkg/oidc"
 	"goadmin/pkg/util"
+	"os"
+	"slices"
+	"strconv"
 
 	"goadmin/config"
 )
@@ -22,15 +33,46 @@ type UserService interface {
 	// Login 用户登录
 	Login(ctx *context.Context, req modeluser.LoginRequest) (*modeluser.LoginResponse, error)
 
-	// GenerateUserCredential 根据用户ID生成用户身份凭证
-	GenerateUserCredential(ctx *context.Context, userID uint64) (*token.TokenPair, error)
+	// LoginTwoFactor 登录第二步，校验动态码或恢复码
+	LoginTwoFactor(ctx *context.Context, req *modeluser.TwoFactorLoginRequest) (*modeluser.LoginResponse, error)
+
+	// LoginTwoFactorSetup 登录时为强制启用双因素认证的用户绑定认证器
+	LoginTwoFactorSetup(
+		ctx *context.Context, req *modeluser.TwoFactorSetupRequest) (*modeluser.TwoFactorEnrollResponse, error)
+
+	// LoginOptions 获取登录页可用的登录方式
+	LoginOptions(ctx *context.Context) *modeluser.LoginOptionsResponse
+
+	// OIDCLoginURL 生成跳转到身份提供方的授权地址
+	OIDCLoginURL(ctx *context.Context) (string, error)
+
+	// OIDCCallback 处理身份提供方回调，校验身份后签发令牌
+	OIDCCallback(ctx *context.Context, req *modeluser.OIDCCallbackRequest) (*modeluser.LoginResponse, error)
+
+	// RefreshToken 使用刷新令牌换取新的令牌对（刷新令牌轮换）
+	RefreshToken(ctx *context.Context, req *modeluser.RefreshTokenRequest) (*modeluser.LoginResponse, error)
+
+	// Logout 退出登录，吊销当前访问令牌
+	Logout(ctx *context.Context) error
+
+	// GenerateUserCredential 生成用户身份凭证，所属租户停用时拒绝
+	GenerateUserCredential(ctx *context.Context, u *modeluser.User) (*token.TokenPair, error)
+
+	// CheckTenant 校验用户所属租户已启用
+	CheckTenant(ctx *context.Context, u *modeluser.User) error
 
 	// GetUserByID
 	GetUserByID(ctx *context.Context, userID uint64) (*modeluser.User, error)
 
+	// GetSessionUser 获取认证用的用户信息，开启鉴权缓存时优先读取缓存
+	GetSessionUser(ctx *context.Context, userID uint64) (*modeluser.User, error)
+
 	// GetUserByIDWithPerm
 	GetUserByIDWithPerm(ctx *context.Context, userID uint64) (*modeluser.User, error)
 
+	// GetCurrentUser 获取当前登录用户信息，包含权限及密码过期状态
+	GetCurrentUser(ctx *context.Context) (*modeluser.User, error)
+
 	// ListUsers 获取用户列表
 	ListUsers(ctx *context.Context, req *modeluser.ListRequest) ([]*modeluser.User, int64, error)
 
@@ -43,22 +85,75 @@ type UserService interface {
 	// DeleteUser 删除用户
 	DeleteUser(ctx *context.Context, req *schema.IDRequest) error
 
+	// ChangePassword 修改当前用户密码
 	ChangePassword(ctx *context.Context, req *modeluser.ChangePasswordRequest) error
 
-	// ResetPassword 重置密码
-	ResetPassword(ctx *context.Context, req *schema.IDRequest) error
+	// ResetPassword 重置密码为随机一次性密码，用户下次登录须修改
+	ResetPassword(ctx *context.Context, req *schema.IDRequest) (*modeluser.ResetPasswordResponse, error)
+
+	// ForgotPassword 申请通过邮件找回密码
+	ForgotPassword(ctx *context.Context, req *modeluser.ForgotPasswordRequest) error
+
+	// ConfirmPasswordReset 使用找回密码令牌设置新密码
+	ConfirmPasswordReset(ctx *context.Context, req *modeluser.PasswordResetConfirmRequest) error
+
+	// ListSessions 获取当前用户的登录会话
+	ListSessions(ctx *context.Context) ([]*token.SessionInfo, error)
+
+	// RevokeSession 注销当前用户的指定会话
+	RevokeSession(ctx *context.Context, req *modeluser.RevokeSessionRequest) error
+
+	// RevokeAllSessions 注销当前用户的全部会话（退出所有设备）
+	RevokeAllSessions(ctx *context.Context) error
+
+	// ForceLogout 管理员强制指定用户下线
+	ForceLogout(ctx *context.Context, req *schema.IDRequest) error
+
+	// UnlockUser 管理员解锁因登录失败过多被锁定的用户
+	UnlockUser(ctx *context.Context, req *schema.IDRequest) error
+
+	// TwoFactorStatus 获取当前用户的双因素认证状态
+	TwoFactorStatus(ctx *context.Context) (*modeluser.TwoFactorStatusResponse, error)
+
+	// EnrollTwoFactor 生成TOTP密钥，用于绑定认证器
+	EnrollTwoFactor(ctx *context.Context) (*modeluser.TwoFactorEnrollResponse, error)
+
+	// VerifyTwoFactor 校验动态码并启用双因素认证
+	VerifyTwoFactor(
+		ctx *context.Context, req *modeluser.TwoFactorCodeRequest) (*modeluser.TwoFactorRecoveryResponse, error)
+
+	// DisableTwoFactor 关闭双因素认证
+	DisableTwoFactor(ctx *context.Context, req *modeluser.TwoFactorCodeRequest) error
+
+	// RegenerateRecoveryCodes 重新生成恢复码
+	RegenerateRecoveryCodes(
+		ctx *context.Context, req *modeluser.TwoFactorCodeRequest) (*modeluser.TwoFactorRecoveryResponse, error)
+
+	// ResetTwoFactor 管理员重置指定用户的双因素认证
+	ResetTwoFactor(ctx *context.Context, req *schema.IDRequest) error
+
+	// SyncLDAPUsers 同步目录中的用户，供定时任务调用
+	SyncLDAPUsers(ctx *context.CliContext) (*modeluser.LDAPSyncResult, error)
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo   userrepo.UserRepository
-	roleRepo   role.RoleRepository
-	logService operate_log.OperateLogService
-	tokenSvc   *token.TokenService
-	jwtToken   *token.JwtTokenService
-	captchaSvc captcha.CaptchaService
-	setSrv     setting.ServerSettingService
-	cfg        *config.Config
+	userRepo       userrepo.UserRepository
+	roleRepo       role.RoleRepository
+	twoFactorRepo  userrepo.TwoFactorRepository
+	pwdHistoryRepo userrepo.PasswordHistoryRepository
+	identityRepo   userrepo.IdentityRepository
+	tenantRepo     tenantrepo.Repository
+	logService     operate_log.OperateLogService
+	tokenSvc       *token.TokenService
+	jwtToken       *token.JwtTokenService
+	captchaSvc     captcha.CaptchaService
+	setSrv         setting.ServerSettingService
+	mailer         mail.Sender
+	oidcClient     *oidc.Client
+	ldapDir        *ldap.Directory
+	authChain      *authChain
+	cfg            *config.Config
 }
 
 // NewUserService 创建用户服务实例（Wire 注入）
@@ -66,36 +161,71 @@ func NewUserService(
 	cfg *config.Config,
 	userRepo userrepo.UserRepository,
 	roleRepo role.RoleRepository,
+	twoFactorRepo userrepo.TwoFactorRepository,
+	pwdHistoryRepo userrepo.PasswordHistoryRepository,
+	identityRepo userrepo.IdentityRepository,
+	tenantRepo tenantrepo.Repository,
 	logService operate_log.OperateLogService,
 	tokenSvc *token.TokenService,
 	jwtToken *token.JwtTokenService,
 	captchaSvc captcha.CaptchaService,
 	setSrv setting.ServerSettingService,
+	mailer mail.Sender,
+	oidcClient *oidc.Client,
+	ldapDir *ldap.Directory,
 ) UserService {
-	return &userService{
-		cfg:        cfg,
-		userRepo:   userRepo,
-		roleRepo:   roleRepo,
-		logService: logService,
-		tokenSvc:   tokenSvc,
-		jwtToken:   jwtToken,
-		captchaSvc: captchaSvc,
-		setSrv:     setSrv,
-	}
+	s := &userService{
+		cfg:            cfg,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		twoFactorRepo:  twoFactorRepo,
+		pwdHistoryRepo: pwdHistoryRepo,
+		identityRepo:   identityRepo,
+		tenantRepo:     tenantRepo,
+		logService:     logService,
+		tokenSvc:       tokenSvc,
+		jwtToken:       jwtToken,
+		captchaSvc:     captchaSvc,
+		setSrv:         setSrv,
+		mailer:         mailer,
+		oidcClient:     oidcClient,
+		ldapDir:        ldapDir,
+	}
+
+	var authenticators []Authenticator
+	if !cfg.Auth.DisableLocalLogin {
+		authenticators = append(authenticators, localAuthenticator{})
+	}
+	if ldapDir.Enabled() {
+		authenticators = append(authenticators, &ldapAuthenticator{s: s, dir: ldapDir})
+	}
+	s.authChain = newAuthChain(&cfg.Auth, authenticators...)
+	return s
 }
 
 // Deprecated: 使用 NewUserService 替代
 // NewUserService_legacy 创建用户服务实例（兼容旧代码，使用全局db）
 func NewUserService_legacy() UserService {
+	mailer, err := mail.New(config.Get().Mail)
+	if err != nil {
+		mailer = mail.NewConsoleSender(config.Get().Mail, os.Stdout)
+	}
 	return NewUserService(
 		config.Get(),
 		userrepo.NewUserRepository_legacy(),
 		role.NewRoleRepositoryWithDB(),
+		userrepo.NewTwoFactorRepository_legacy(),
+		userrepo.NewPasswordHistoryRepository_legacy(),
+		userrepo.NewIdentityRepository_legacy(),
+		tenantrepo.NewTenantRepository_legacy(),
 		operate_log.NewOperateLogService_legacy(),
 		token.NewTokenService(),
 		token.NewJwtTokenService(&config.Get().JWT),
 		captcha.NewCaptchaService(),
 		setting.NewServerSettingService_legacy(),
+		mailer,
+		oidc.NewClient(config.Get().Auth.OIDC),
+		ldap.New(config.Get().Auth.LDAP),
 	)
 }
 
@@ -103,64 +233,172 @@ func (*userService) logPrefix() string {
 	return "user-service"
 }
 
-// GenerateUserCredential 根据用户ID生成用户身份凭证
-func (s *userService) GenerateUserCredential(ctx *context.Context, userID uint64) (*token.TokenPair, error) {
+// GenerateUserCredential 生成用户身份凭证，所属租户停用时拒绝
+func (s *userService) GenerateUserCredential(ctx *context.Context, u *modeluser.User) (*token.TokenPair, error) {
+	if err := s.CheckTenant(ctx, u); err != nil {
+		return nil, err
+	}
+
 	// 生成JWT令牌
 	tokenPairs, err := s.jwtToken.GenerateJWTTokenPair(
-		ctx, token.NewAdminClaims(userID, s.cfg.JWT.AccessExpire))
+		ctx, token.NewAdminClaims(u.ID, u.TenantID, &s.cfg.JWT))
 	if err != nil {
-		ctx.Logger.Errorf("%s 生成用户凭证失败: %d %v", s.logPrefix(), userID, err)
+		ctx.Logger.Errorf("%s 生成用户凭证失败: %d %v", s.logPrefix(), u.ID, err)
 		return nil, i18n.E(ctx.Context, "user.token.generate.failed", nil)
 	}
 
 	return tokenPairs, nil
 }
 
-func (s *userService) Login(ctx *context.Context, req modeluser.LoginRequest) (*modeluser.LoginResponse, error) {
+// checkCaptcha 管理后台开启验证码时校验验证码令牌
+func (s *userService) checkCaptcha(ctx *context.Context, token, username string) error {
 	var captchaCfg server.CaptchaSwitchConfig
 	err := s.setSrv.GetSrcValue(ctx, server.SettingCaptchaSwitch, &captchaCfg)
 	if err != nil {
 		ctx.Logger.Errorf("%s Generate GetValue %+v", s.logPrefix(), err)
-		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 	if captchaCfg.IsAdminOn() {
-		if req.Token == "" {
-			ctx.Logger.Warnf("%s Login captcha require token", s.logPrefix())
-			return nil, i18n.E(ctx.Context, "common.BadParameter", nil)
+		if token == "" {
+			ctx.Logger.Warnf("%s captcha require token", s.logPrefix())
+			return i18n.E(ctx.Context, "common.BadParameter", nil)
 		}
-		if !s.tokenSvc.ValidateToken(ctx, req.Token) {
-			ctx.Logger.Errorf("%s ValidateToken faild %s %s", s.logPrefix(), req.Username, req.Token)
-			return nil, i18n.E(
+		if !s.tokenSvc.ValidateToken(ctx, token) {
+			ctx.Logger.Errorf("%s ValidateToken faild %s %s", s.logPrefix(), username, token)
+			return i18n.E(
 				ctx.Context,
 				"common.InvalidParameter",
 				map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
 		}
 	}
-	// 获取用户信息
-	u, err := s.userRepo.GetByUsername(ctx, req.Username)
+	return nil
+}
+
+func (s *userService) Login(ctx *context.Context, req modeluser.LoginRequest) (*modeluser.LoginResponse, error) {
+	if !s.authChain.enabled() {
+		return nil, i18n.E(ctx.Context, "user.LocalLoginDisabled", nil)
+	}
+	if err := s.checkCaptcha(ctx, req.Token, req.Username); err != nil {
+		return nil, err
+	}
+	// 防暴力破解：IP失败次数及账号等待时间
+	securityCfg, err := s.loginSecurity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkLoginAllowed(ctx, securityCfg, req.Username); err != nil {
+		return nil, err
+	}
+
+	// 获取本地用户信息，外部认证源的用户首次登录时尚不存在
+	existing, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		ctx.Logger.Errorf("%s 获取用户信息失败: %s %v", s.logPrefix(), req.Username, err)
 		return nil, err
 	}
+	if existing != nil {
+		if err = s.checkAccountLock(ctx, existing); err != nil {
+			return nil, err
+		}
+	}
 
-	if u == nil || !util.ValidatePasswordAndHash(req.Password, u.Password) {
-		ctx.Logger.Warnf("%s 用户名或密码错误: %s %v", s.logPrefix(), req.Username, err)
+	u, source, err := s.authChain.authenticate(ctx, &Credential{
+		Username: req.Username,
+		Password: req.Password,
+		User:     existing,
+	})
+	if err != nil {
+		if errors.Is(err, errNoAuthenticator) {
+			ctx.Logger.Warnf("%s 用户名没有可用的认证器: %s", s.logPrefix(), req.Username)
+			return nil, i18n.E(ctx.Context, "user.LocalLoginDisabled", nil)
+		}
+		if !errors.Is(err, ErrInvalidCredentials) && !errors.Is(err, ErrUnknownUser) &&
+			!errors.Is(err, ErrSourceUnavailable) {
+			return nil, err
+		}
+		ctx.Logger.Warnf("%s 用户名或密码错误: %s %s %v", s.logPrefix(), req.Username, source, err)
+		if s.recordLoginFailure(ctx, securityCfg, req.Username, existing) {
+			return nil, i18n.E(ctx.Context, "user.AccountLocked", nil)
+		}
 		return nil, i18n.E(ctx.Context, "user.InvalidUsernameOrPassword", nil)
 	}
+	s.clearLoginFailures(ctx, req.Username)
 
+	// 外部身份绑定的用户与登录名不一致时，同样校验锁定状态
+	if existing == nil || existing.ID != u.ID {
+		if err = s.checkAccountLock(ctx, u); err != nil {
+			return nil, err
+		}
+	}
 	if !u.IsActive() {
 		ctx.Logger.Warnf("%s 账户状态异常: %s %s", s.logPrefix(), req.Username, u.Status.String())
 		return nil, i18n.E(ctx.Context, "user.AccountStatusAbnormal", nil)
 	}
 
+	content := i18n.T(ctx.Context, "operate.Login", nil)
+	if source == AuthenticatorLDAP {
+		content = i18n.T(ctx.Context, "operate.LoginLDAP", nil)
+	}
+	return s.completeLogin(ctx, u, content)
+}
+
+// completeLogin 身份校验通过后签发令牌并记录登录日志
+//
+// 开启双因素认证时仅返回预认证令牌，校验动态码后再签发令牌
+func (s *userService) completeLogin(
+	ctx *context.Context, u *modeluser.User, content string) (*modeluser.LoginResponse, error) {
+	challenge, err := s.twoFactorChallenge(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
 	// 生成JWT令牌
-	tokenPairs, err := s.GenerateUserCredential(ctx, u.ID)
+	tokenPairs, err := s.GenerateUserCredential(ctx, u)
 	if err != nil {
-		ctx.Logger.Errorf("%s jwt token: %s %v", s.logPrefix(), req.Username, err)
+		ctx.Logger.Errorf("%s jwt token: %s %v", s.logPrefix(), u.Username, err)
 		return nil, err
 	}
 
-	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Login", nil), req.Username)
+	s.logService.CreateOperateLog(ctx, content, u.Username)
+
+	return &modeluser.LoginResponse{
+		Token:                  tokenPairs.AccessToken,
+		RefreshToken:           tokenPairs.RefreshToken,
+		ExpiresAt:              tokenPairs.ExpiresAt,
+		PasswordChangeRequired: s.passwordChangeRequired(ctx, u),
+	}, nil
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对，旧的刷新令牌随即作废
+//
+// 已作废的刷新令牌被再次使用时，用户的全部会话会被吊销，并记录安全事件
+func (s *userService) RefreshToken(
+	ctx *context.Context, req *modeluser.RefreshTokenRequest) (*modeluser.LoginResponse, error) {
+	tokenPairs, err := s.jwtToken.RefreshJWTToken(ctx, req.RefreshToken, func(old token.Claims) (token.Claims, error) {
+		u, err := s.userRepo.GetByID(ctx, old.UserID)
+		if err != nil {
+			return old, err
+		}
+		if u == nil || !u.IsActive() {
+			return old, fmt.Errorf("账户状态异常: %d", old.UserID)
+		}
+		if err = s.CheckTenant(ctx, u); err != nil {
+			return old, err
+		}
+		return token.NewAdminClaims(u.ID, u.TenantID, &s.cfg.JWT), nil
+	})
+	if err != nil {
+		var reused *token.RefreshTokenReusedError
+		if errors.As(err, &reused) {
+			s.logRefreshTokenReused(ctx, reused.UserID)
+			return nil, i18n.E(ctx.Context, "user.RefreshTokenReused", nil)
+		}
+		ctx.Logger.Warnf("%s 刷新令牌失败: %v", s.logPrefix(), err)
+		return nil, i18n.E(ctx.Context, "user.RefreshTokenInvalid", nil)
+	}
 
 	return &modeluser.LoginResponse{
 		Token:        tokenPairs.AccessToken,
@@ -169,6 +407,37 @@ func (s *userService) Login(ctx *context.Context, req modeluser.LoginRequest) (*
 	}, nil
 }
 
+// logRefreshTokenReused 记录刷新令牌重放的安全事件
+func (s *userService) logRefreshTokenReused(ctx *context.Context, userID uint64) {
+	username := strconv.FormatUint(userID, 10)
+	if u, err := s.userRepo.GetByID(ctx, userID); err == nil && u != nil {
+		username = u.Username
+	}
+	s.logService.CreateOperateLog(
+		ctx,
+		i18n.T(ctx.Context, "operate.Security.RefreshTokenReused", map[string]any{"username": username}),
+		username)
+}
+
+// Logout 退出登录，吊销当前访问令牌及与之配对的刷新令牌
+func (s *userService) Logout(ctx *context.Context) error {
+	val, _ := ctx.Get(token.ClaimsKey)
+	claims, ok := val.(*token.Claims)
+	if !ok || claims == nil {
+		ctx.Logger.Warnf("%s Logout 上下文中缺少令牌信息", s.logPrefix())
+		return i18n.E(
+			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.token", nil)})
+	}
+
+	if err := s.jwtToken.RevokeJWTToken(ctx, claims); err != nil {
+		ctx.Logger.Errorf("%s 吊销令牌失败: %d %s %v", s.logPrefix(), claims.UserID, claims.ID, err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.Logout", nil))
+	return nil
+}
+
 // GetUserByID 当前Session
 func (s *userService) GetUserByID(ctx *context.Context, userID uint64) (*modeluser.User, error) {
 	u, err := s.userRepo.GetByID(ctx, userID)
@@ -195,8 +464,18 @@ func (s *userService) GetUserByIDWithPerm(ctx *context.Context, userID uint64) (
 	if err != nil {
 		return nil, err
 	}
+	// 取各角色及其祖先权限的并集
+	var chains []string
+	for _, code := range u.RoleCodes() {
+		chain, err := s.roleRepo.GetChain(ctx, code)
+		if err != nil {
+			ctx.Logger.Errorf("%s GetUserByIDWithPerm GetChain %d %v", s.logPrefix(), userID, err)
+			return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+		chains = append(chains, chain...)
+	}
 	// TODO: Need to inject RolePermissionRepository separately
-	perms, err := role.NewRolePermissionRepositoryWithDB().GetPermissionsByRoleCode(ctx, u.RoleCode, true)
+	perms, err := role.NewRolePermissionRepositoryWithDB().GetPermissionCodesByRoleCodes(ctx, util.Unique(chains), true)
 	if err != nil {
 		ctx.Logger.Errorf("%s GetUserByIDWithPerm GetPermissionURLsByRoleCode %d %v", s.logPrefix(), userID, err)
 		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
@@ -245,30 +524,37 @@ func (s *userService) CreateUser(ctx *context.Context, req *modeluser.CreateUser
 	}
 
 	// 检查角色是否存在
-	role, err := s.roleRepo.GetByCode(ctx, req.RoleCode)
+	roles, err := s.checkRoles(ctx, req.RoleCodes)
 	if err != nil {
-		ctx.Logger.Errorf("%s 检查角色是否存在失败: %s %v", s.logPrefix(), req.RoleCode, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		return err
+	}
+
+	// 校验密码策略
+	policy, err := s.passwordPolicy(ctx)
+	if err != nil {
+		return err
 	}
-	if role == nil {
-		ctx.Logger.Warnf("%s 角色不存在: %s", s.logPrefix(), req.RoleCode)
-		return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
+	if err = s.checkPasswordPolicy(ctx, policy, req.Password, req.Username); err != nil {
+		return err
 	}
 
 	// 加密密码
-	encryptPwd, err := util.Password2Hash(req.Password)
+	encryptPwd, err := util.Password2Hash(util.PasswordDigest(req.Password))
 	if err != nil {
 		ctx.Logger.Errorf("%s 密码加密失败: %s %v", s.logPrefix(), req.Username, err)
 		return i18n.E(ctx.Context, "common.EncryptErr", nil)
 	}
 
 	// 创建用户
+	now := util.Now()
 	user := &modeluser.User{
-		Username: req.Username,
-		Password: encryptPwd,
-		Email:    req.Email,
-		RoleCode: req.RoleCode,
-		Status:   modeluser.UserStatus(req.Status),
+		Username:          req.Username,
+		Password:          encryptPwd,
+		PasswordChangedAt: &now,
+		Email:             req.Email,
+		Roles:             roles,
+		Status:            modeluser.UserStatus(req.Status),
+		DeptID:            req.DeptID,
 	}
 
 	err = s.userRepo.Create(ctx, user)
@@ -276,6 +562,7 @@ func (s *userService) CreateUser(ctx *context.Context, req *modeluser.CreateUser
 		ctx.Logger.Errorf("%s 创建用户失败: %s %v", s.logPrefix(), req.Username, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	s.recordPasswordHistory(ctx, policy, user.ID, encryptPwd)
 
 	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.Create", nil))
 
@@ -330,24 +617,22 @@ func (s *userService) UpdateUser(ctx *context.Context, req *modeluser.UpdateUser
 		user.Email = req.Email
 	}
 
-	// 更新角色代码
-	if req.RoleCode != "" && req.RoleCode != user.RoleCode {
-		// 检查角色是否存在
-		role, err := s.roleRepo.GetByCode(ctx, req.RoleCode)
-		if err != nil {
-			ctx.Logger.Errorf("%s 检查角色是否存在失败: %s %v", s.logPrefix(), req.RoleCode, err)
-			return i18n.E(ctx.Context, "common.RepositoryErr", nil)
-		}
-		if role == nil {
-			ctx.Logger.Warnf("%s 角色不存在: %s", s.logPrefix(), req.RoleCode)
-			return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
+	// 更新角色
+	var roles modelrole.Set
+	if len(req.RoleCodes) > 0 {
+		if roles, err = s.checkRoles(ctx, req.RoleCodes); err != nil {
+			return err
 		}
-		user.RoleCode = req.RoleCode
 	}
 
-	// 更新状态
+	if req.DeptID != nil {
+		user.DeptID = *req.DeptID
+	}
+
+	// 更新状态，手动设置的状态不会自动解锁
 	if req.Status >= 0 {
 		user.Status = modeluser.UserStatus(req.Status)
+		user.LockedUntil = nil
 	}
 
 	// 更新用户
@@ -356,6 +641,18 @@ func (s *userService) UpdateUser(ctx *context.Context, req *modeluser.UpdateUser
 		ctx.Logger.Errorf("%s 更新用户失败: %d %v", s.logPrefix(), req.ID, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	if roles != nil && !slices.Equal(util.Unique(user.RoleCodes()), roles.Codes()) {
+		if err = s.userRepo.UpdateRoles(ctx, user.ID, roles.Codes()); err != nil {
+			ctx.Logger.Errorf("%s 更新用户角色失败: %d %v", s.logPrefix(), req.ID, err)
+			return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		}
+	}
+	s.invalidateSessions(ctx, ctx.Logger, user.ID)
+
+	// 账户被锁定或禁用后立即下线
+	if !user.IsActive() {
+		s.killSessions(ctx, user.ID)
+	}
 
 	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.Update", nil))
 
@@ -363,6 +660,35 @@ func (s *userService) UpdateUser(ctx *context.Context, req *modeluser.UpdateUser
 	return nil
 }
 
+// checkRoles 校验角色均存在，返回按代码排序、去重后的角色集合
+func (s *userService) checkRoles(ctx *context.Context, roleCodes []string) (modelrole.Set, error) {
+	codes := util.Unique(slices.Clone(roleCodes))
+	// 限定在租户内时不能授予超级管理员，否则可借此跨租户访问
+	if _, ok := db.TenantFrom(ctx); ok && slices.Contains(codes, modelrole.CodeSuperAdmin) {
+		ctx.Logger.Warnf("%s 租户内不能授予超级管理员", s.logPrefix())
+		return nil, i18n.E(ctx.Context, "common.PermissionDeny", nil)
+	}
+	list, err := s.roleRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		ctx.Logger.Errorf("%s 检查角色是否存在失败: %v %v", s.logPrefix(), codes, err)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	}
+	byCode := make(map[string]*modelrole.Role, len(list))
+	for _, r := range list {
+		byCode[r.Code] = r
+	}
+	roles := make(modelrole.Set, 0, len(codes))
+	for _, code := range codes {
+		r, ok := byCode[code]
+		if !ok {
+			ctx.Logger.Warnf("%s 角色不存在: %s", s.logPrefix(), code)
+			return nil, i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.role", nil)})
+		}
+		roles = append(roles, *r)
+	}
+	return roles, nil
+}
+
 // DeleteUser 删除用户
 func (s *userService) DeleteUser(ctx *context.Context, req *schema.IDRequest) error {
 	// 获取用户信息
@@ -396,6 +722,9 @@ func (s *userService) DeleteUser(ctx *context.Context, req *schema.IDRequest) er
 		ctx.Logger.Errorf("%s 删除用户失败: %d %v", s.logPrefix(), req.ID, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	s.invalidateSessions(ctx, ctx.Logger, req.ID)
+
+	s.killSessions(ctx, req.ID)
 
 	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.Delete", nil))
 
@@ -403,62 +732,174 @@ func (s *userService) DeleteUser(ctx *context.Context, req *schema.IDRequest) er
 	return nil
 }
 
+// ChangePassword 修改当前用户密码
+//
+// 新旧密码均为前端提交的明文，新密码须先校验密码策略，二者均经 util.PasswordDigest 摘要后再与 bcrypt 哈希比对或生成哈希
 func (s *userService) ChangePassword(ctx *context.Context, req *modeluser.ChangePasswordRequest) error {
-	if !util.ValidatePasswordAndHash(req.OldPassword, ctx.Session().(*modeluser.User).Password) {
-		ctx.Logger.Warnf("%s 密码错误: %s", s.logPrefix(), ctx.Session().GetUsername())
+	// 登录用户可能来自鉴权缓存，不含密码，须重新读取
+	u, err := s.GetUserByID(ctx, ctx.Session().GetID())
+	if err != nil {
+		return err
+	}
+	if !util.ValidatePasswordAndHash(util.PasswordDigest(req.OldPassword), u.Password) {
+		ctx.Logger.Warnf("%s 密码错误: %s", s.logPrefix(), u.Username)
 		return i18n.E(ctx.Context, "user.InvalidPassword", nil)
 	}
 
-	encryptPwd, err := util.Password2Hash(req.NewPassword)
+	policy, err := s.passwordPolicy(ctx)
 	if err != nil {
-		ctx.Logger.Warnf("%s Password2Hash: %s %+v", s.logPrefix(), ctx.Session().GetUsername(), err)
+		return err
+	}
+	if err = s.checkPasswordPolicy(ctx, policy, req.NewPassword, u.Username); err != nil {
+		return err
+	}
+	digest := util.PasswordDigest(req.NewPassword)
+	if err = s.checkPasswordReuse(ctx, policy, u, digest); err != nil {
+		return err
+	}
+
+	encryptPwd, err := util.Password2Hash(digest)
+	if err != nil {
+		ctx.Logger.Warnf("%s Password2Hash: %s %+v", s.logPrefix(), u.Username, err)
 		return i18n.E(ctx.Context, "common.EncryptErr", nil)
 	}
 
 	// 更新密码
-	err = s.userRepo.UpdatePassword(ctx, ctx.Session().GetID(), encryptPwd)
-	if err != nil {
-		ctx.Logger.Warnf("%s UpdatePassword: %s %+v", s.logPrefix(), ctx.Session().GetUsername(), err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+	if err = s.savePassword(ctx, policy, u.ID, encryptPwd, false); err != nil {
+		return err
 	}
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.ChangePassword", nil))
 	return nil
 }
 
-// ResetPassword 重置密码
-func (s *userService) ResetPassword(ctx *context.Context, req *schema.IDRequest) error {
+// ResetPassword 重置密码为随机一次性密码，用户下次登录须修改
+func (s *userService) ResetPassword(
+	ctx *context.Context, req *schema.IDRequest) (*modeluser.ResetPasswordResponse, error) {
 	// 获取用户信息
 	user, err := s.userRepo.GetByID(ctx, req.ID)
 	if err != nil {
 		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), req.ID, err)
-		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
+		return nil, i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
 	if user == nil {
 		ctx.Logger.Warnf("%s 用户不存在: %d", s.logPrefix(), req.ID)
-		return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
+		return nil, i18n.E(
+			ctx.Context, "common.NotFound",
+			map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
 	}
 
 	// 检查是否为超级管理员
 	if user.IsSuperAdmin() {
 		ctx.Logger.Warnf("%s 超级管理员不能重置密码: %d", s.logPrefix(), req.ID)
-		return i18n.E(ctx.Context, "user.SuperAdminCannotModify", nil)
+		return nil, i18n.E(ctx.Context, "user.SuperAdminCannotModify", nil)
+	}
+
+	policy, err := s.passwordPolicy(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// 加密默认密码
-	encryptPwd, err := util.Password2Hash(modeluser.DefaultPassword)
+	// 生成一次性密码
+	password, err := util.GenerateRandomPassword(policy.GenerateLength())
+	if err != nil {
+		ctx.Logger.Errorf("%s 生成随机密码失败: %d %v", s.logPrefix(), req.ID, err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	encryptPwd, err := util.Password2Hash(util.PasswordDigest(password))
 	if err != nil {
 		ctx.Logger.Errorf("%s 密码加密失败: %d %v", s.logPrefix(), req.ID, err)
-		return i18n.E(ctx.Context, "common.EncryptErr", nil)
+		return nil, i18n.E(ctx.Context, "common.EncryptErr", nil)
 	}
 
-	// 更新密码
-	err = s.userRepo.UpdatePassword(ctx, req.ID, encryptPwd)
+	// 更新密码，并要求用户下次登录修改
+	if err = s.savePassword(ctx, policy, req.ID, encryptPwd, true); err != nil {
+		return nil, err
+	}
+
+	s.killSessions(ctx, req.ID)
+
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.User.ResetPassword", map[string]any{"username": user.Username}))
+
+	ctx.Logger.Infof("%s 重置密码成功: %d", s.logPrefix(), req.ID)
+	return &modeluser.ResetPasswordResponse{Password: password}, nil
+}
+
+// ListSessions 获取当前用户的登录会话
+func (s *userService) ListSessions(ctx *context.Context) ([]*token.SessionInfo, error) {
+	userID := ctx.Session().GetID()
+	list, err := s.jwtToken.ListSessions(ctx, userID)
 	if err != nil {
-		ctx.Logger.Errorf("%s 重置密码失败: %d %v", s.logPrefix(), req.ID, err)
+		ctx.Logger.Errorf("%s 获取会话列表失败: %d %v", s.logPrefix(), userID, err)
+		return nil, i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+
+	val, _ := ctx.Get(token.ClaimsKey)
+	if claims, ok := val.(*token.Claims); ok {
+		for _, item := range list {
+			item.Current = item.ID == claims.SessionID
+		}
+	}
+	return list, nil
+}
+
+// RevokeSession 注销当前用户的指定会话
+func (s *userService) RevokeSession(ctx *context.Context, req *modeluser.RevokeSessionRequest) error {
+	userID := ctx.Session().GetID()
+	found, err := s.jwtToken.RevokeSession(ctx, userID, req.SessionID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 注销会话失败: %d %s %v", s.logPrefix(), userID, req.SessionID, err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+	if !found {
+		return i18n.E(
+			ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.session", nil)})
+	}
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.RevokeSession", nil))
+	return nil
+}
+
+// RevokeAllSessions 注销当前用户的全部会话（退出所有设备）
+func (s *userService) RevokeAllSessions(ctx *context.Context) error {
+	userID := ctx.Session().GetID()
+	if err := s.jwtToken.RevokeAllSessions(ctx, userID); err != nil {
+		ctx.Logger.Errorf("%s 注销全部会话失败: %d %v", s.logPrefix(), userID, err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
+
+	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.RevokeAllSessions", nil))
+	return nil
+}
+
+// ForceLogout 管理员强制指定用户下线
+func (s *userService) ForceLogout(ctx *context.Context, req *schema.IDRequest) error {
+	user, err := s.userRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		ctx.Logger.Errorf("%s 获取用户信息失败: %d %v", s.logPrefix(), req.ID, err)
 		return i18n.E(ctx.Context, "common.RepositoryErr", nil)
 	}
+	if user == nil {
+		ctx.Logger.Warnf("%s 用户不存在: %d", s.logPrefix(), req.ID)
+		return i18n.E(ctx.Context, "common.NotFound", map[string]any{"item": i18n.T(ctx.Context, "common.item.user", nil)})
+	}
 
-	s.logService.CreateOperateLog(ctx, i18n.T(ctx.Context, "operate.User.ResetPassword", nil))
+	if err = s.jwtToken.RevokeAllSessions(ctx, req.ID); err != nil {
+		ctx.Logger.Errorf("%s 强制下线失败: %d %v", s.logPrefix(), req.ID, err)
+		return i18n.E(ctx.Context, "common.InternalError", nil)
+	}
 
-	ctx.Logger.Infof("%s 重置密码成功: %d", s.logPrefix(), req.ID)
+	s.logService.CreateOperateLog(
+		ctx, i18n.T(ctx.Context, "operate.User.ForceLogout", map[string]any{"username": user.Username}))
+
+	ctx.Logger.Infof("%s 强制下线成功: %d", s.logPrefix(), req.ID)
 	return nil
 }
+
+// killSessions 注销用户全部会话，失败仅记录日志，不影响主流程
+func (s *userService) killSessions(ctx *context.Context, userID uint64) {
+	if err := s.jwtToken.RevokeAllSessions(ctx, userID); err != nil {
+		ctx.Logger.Errorf("%s 注销用户会话失败: %d %v", s.logPrefix(), userID, err)
+	}
+}
@@ -2,18 +2,26 @@
 package wire
 
 import (
+	"fmt"
+
 	"goadmin/config"
 
 	// Infrastructure
 	"goadmin/pkg/db"
+	"goadmin/pkg/ldap"
+	"goadmin/pkg/mail"
+	"goadmin/pkg/oidc"
 	"goadmin/pkg/redisx"
 	"goadmin/pkg/task"
 
 	// Internal
 	"goadmin/internal/api"
+	bizcron "goadmin/internal/cron"
 	"goadmin/internal/i18n"
 
 	// Repository
+	apikeyrepo "goadmin/internal/repository/api_key"
+	exportrepo "goadmin/internal/repository/export"
 	operatelogrepo "goadmin/internal/repository/operate_log"
 	positionrepo "goadmin/internal/repository/position"
 	rolerepo "goadmin/internal/repository/role"
@@ -22,7 +30,9 @@ import (
 	userrepo "goadmin/internal/repository/user"
 
 	// Service
+	apikeyservice "goadmin/internal/service/api_key"
 	"goadmin/internal/service/captcha"
+	exportservice "goadmin/internal/service/export"
 	"goadmin/internal/service/operate_log"
 	"goadmin/internal/service/position"
 	"goadmin/internal/service/role"
@@ -99,6 +109,26 @@ func ProvideUserRepository(database *gorm.DB) userrepo.UserRepository {
 	return userrepo.NewUserRepositoryImpl(database)
 }
 
+// ProvideTwoFactorRepository provides the two-factor authentication repository.
+func ProvideTwoFactorRepository(database *gorm.DB) userrepo.TwoFactorRepository {
+	return userrepo.NewTwoFactorRepository(database)
+}
+
+// ProvidePasswordHistoryRepository provides the password history repository.
+func ProvidePasswordHistoryRepository(database *gorm.DB) userrepo.PasswordHistoryRepository {
+	return userrepo.NewPasswordHistoryRepository(database)
+}
+
+// ProvideIdentityRepository provides the external identity repository.
+func ProvideIdentityRepository(database *gorm.DB) userrepo.IdentityRepository {
+	return userrepo.NewIdentityRepository(database)
+}
+
+// ProvideUserRoleRepository provides the user role repository.
+func ProvideUserRoleRepository(database *gorm.DB) userrepo.UserRoleRepository {
+	return userrepo.NewUserRoleRepository(database)
+}
+
 // ProvideRoleRepository provides the role repository.
 func ProvideRoleRepository(database *gorm.DB) rolerepo.RoleRepository {
 	return rolerepo.NewRoleRepository(database)
@@ -129,6 +159,21 @@ func ProvideTenantRepository(database *gorm.DB) tenantrepo.Repository {
 	return tenantrepo.NewTenantRepository(database)
 }
 
+// ProvideProvisionRepository provides the tenant provisioning repository.
+func ProvideProvisionRepository(database *gorm.DB) tenantrepo.ProvisionRepository {
+	return tenantrepo.NewProvisionRepository(database)
+}
+
+// ProvideAPIKeyRepository provides the API key repository.
+func ProvideAPIKeyRepository(database *gorm.DB) apikeyrepo.Repository {
+	return apikeyrepo.NewAPIKeyRepository(database)
+}
+
+// ProvideExportRepository provides the export job repository.
+func ProvideExportRepository(database *gorm.DB) exportrepo.Repository {
+	return exportrepo.NewExportRepository(database)
+}
+
 // ============================================================================
 // Service Providers
 // ============================================================================
@@ -139,8 +184,13 @@ func ProvideTokenService() *token.TokenService {
 }
 
 // ProvideJwtTokenService provides the JWT token service.
-func ProvideJwtTokenService(cfg *config.Config) *token.JwtTokenService {
-	return token.NewJwtTokenService(&cfg.JWT)
+// 启动时预加载签名密钥，密钥配置错误时直接失败
+func ProvideJwtTokenService(cfg *config.Config) (*token.JwtTokenService, error) {
+	svc := token.NewJwtTokenService(&cfg.JWT)
+	if err := svc.LoadKeys(); err != nil {
+		return nil, fmt.Errorf("加载JWT签名密钥失败: %w", err)
+	}
+	return svc, nil
 }
 
 // ProvideCaptchaService provides the captcha service.
@@ -149,14 +199,45 @@ func ProvideCaptchaService(coreInfra CoreInfraInit) captcha.CaptchaService {
 	return captcha.NewCaptchaService()
 }
 
+// ProvideMailSender provides the mail sender configured by config.Mail.
+func ProvideMailSender(cfg *config.Config) (mail.Sender, error) {
+	sender, err := mail.New(cfg.Mail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail sender: %w", err)
+	}
+	return sender, nil
+}
+
+// ProvideOIDCClient provides the OIDC client configured by config.Auth.OIDC.
+// Provider discovery is deferred to the first login so an unreachable IdP does not block startup.
+func ProvideOIDCClient(cfg *config.Config) *oidc.Client {
+	return oidc.NewClient(cfg.Auth.OIDC)
+}
+
+// ProvideLDAPDirectory provides the LDAP directory configured by config.Auth.LDAP.
+func ProvideLDAPDirectory(cfg *config.Config) *ldap.Directory {
+	return ldap.New(cfg.Auth.LDAP)
+}
+
 // ProvideServerSettingService provides the server setting service.
-func ProvideServerSettingService(repo serverrepo.ServerSettingRepository) setting.ServerSettingService {
-	return setting.NewServerSettingService(repo)
+func ProvideServerSettingService(cfg *config.Config,
+	repo serverrepo.ServerSettingRepository, tenantRepo tenantrepo.Repository) setting.ServerSettingService {
+	return setting.NewServerSettingService(cfg, repo, tenantRepo)
+}
+
+// ProvideOperateLogWriter provides the asynchronous operate log writer.
+func ProvideOperateLogWriter(
+	cfg *config.Config, logRepo operatelogrepo.OperateLogRepository) *operate_log.Writer {
+	return operate_log.NewWriter(cfg, logRepo)
 }
 
 // ProvideOperateLogService provides the operate log service.
-func ProvideOperateLogService(logRepo operatelogrepo.OperateLogRepository) operate_log.OperateLogService {
-	return operate_log.NewOperateLogService(logRepo)
+// 同时作为审计事件接收者，模型变更自动写入操作日志
+func ProvideOperateLogService(cfg *config.Config, logRepo operatelogrepo.OperateLogRepository,
+	writer *operate_log.Writer) operate_log.OperateLogService {
+	logService := operate_log.NewOperateLogService(cfg, logRepo, writer)
+	db.SetAuditSink(logService.Audit)
+	return logService
 }
 
 // ProvidePositionService provides the position service.
@@ -165,13 +246,51 @@ func ProvidePositionService(positionRepo positionrepo.PositionRepository, logSer
 }
 
 // ProvideTenantService provides the tenant service.
-func ProvideTenantService(tenantRepo tenantrepo.Repository, logService operate_log.OperateLogService) tenantservice.TenantService {
-	return tenantservice.NewTenantService(tenantRepo, logService)
+func ProvideTenantService(
+	cfg *config.Config,
+	tenantRepo tenantrepo.Repository,
+	provisionRepo tenantrepo.ProvisionRepository,
+	userRepo userrepo.UserRepository,
+	settingRepo serverrepo.ServerSettingRepository,
+	logService operate_log.OperateLogService,
+) tenantservice.TenantService {
+	return tenantservice.NewTenantService(cfg, tenantRepo, provisionRepo, userRepo, settingRepo, logService)
 }
 
 // ProvideRoleService provides the role service.
-func ProvideRoleService(roleRepo rolerepo.RoleRepository, rolePermissionRepo rolerepo.RolePermissionRepository, cfg *config.Config) role.RoleService {
-	return role.NewRoleService(roleRepo, rolePermissionRepo, cfg)
+func ProvideRoleService(roleRepo rolerepo.RoleRepository, rolePermissionRepo rolerepo.RolePermissionRepository,
+	userRoleRepo userrepo.UserRoleRepository, cfg *config.Config) role.RoleService {
+	return role.NewRoleService(roleRepo, rolePermissionRepo, userRoleRepo, cfg)
+}
+
+// ProvideAPIKeyService provides the API key service.
+func ProvideAPIKeyService(
+	apiKeyRepo apikeyrepo.Repository,
+	rolePermissionRepo rolerepo.RolePermissionRepository,
+	roleService role.RoleService,
+	logService operate_log.OperateLogService,
+) apikeyservice.APIKeyService {
+	return apikeyservice.NewAPIKeyService(apiKeyRepo, rolePermissionRepo, roleService, logService)
+}
+
+// ProvideExportRunner provides the background export job runner.
+func ProvideExportRunner(cfg *config.Config, exportRepo exportrepo.Repository) *exportservice.Runner {
+	return exportservice.NewRunner(cfg, exportRepo)
+}
+
+// ProvideExportService provides the list export service.
+func ProvideExportService(
+	cfg *config.Config,
+	exportRepo exportrepo.Repository,
+	runner *exportservice.Runner,
+	userService userservice.UserService,
+	roleService role.RoleService,
+	tenantService tenantservice.TenantService,
+	positionService position.PositionService,
+	logService operate_log.OperateLogService,
+) exportservice.ExportService {
+	return exportservice.NewExportService(
+		cfg, exportRepo, runner, userService, roleService, tenantService, positionService, logService)
 }
 
 // ProvideUserService provides the user service.
@@ -179,21 +298,35 @@ func ProvideUserService(
 	cfg *config.Config,
 	userRepo userrepo.UserRepository,
 	roleRepo rolerepo.RoleRepository,
+	twoFactorRepo userrepo.TwoFactorRepository,
+	pwdHistoryRepo userrepo.PasswordHistoryRepository,
+	identityRepo userrepo.IdentityRepository,
+	tenantRepo tenantrepo.Repository,
 	logService operate_log.OperateLogService,
 	tokenService *token.TokenService,
 	jwtTokenService *token.JwtTokenService,
 	captchaService captcha.CaptchaService,
 	serverSettingService setting.ServerSettingService,
+	mailer mail.Sender,
+	oidcClient *oidc.Client,
+	ldapDir *ldap.Directory,
 ) userservice.UserService {
 	return userservice.NewUserService(
 		cfg,
 		userRepo,
 		roleRepo,
+		twoFactorRepo,
+		pwdHistoryRepo,
+		identityRepo,
+		tenantRepo,
 		logService,
 		tokenService,
 		jwtTokenService,
 		captchaService,
 		serverSettingService,
+		mailer,
+		oidcClient,
+		ldapDir,
 	)
 }
 
@@ -214,38 +347,53 @@ func ProvideWebServer(
 	cfg *config.Config,
 	engine *gin.Engine,
 	tokenService *token.TokenService,
+	jwtTokenService *token.JwtTokenService,
 	userService userservice.UserService,
 	roleService role.RoleService,
 	positionService position.PositionService,
 	logService operate_log.OperateLogService,
 	settingService setting.ServerSettingService,
 	tenantService tenantservice.TenantService,
+	apiKeyService apikeyservice.APIKeyService,
+	exportService exportservice.ExportService,
 	userRepository userrepo.UserRepository,
 	coreInfra CoreInfraInit,
 ) *serverpkg.WebServer {
 	// Create services struct for route registration
 	services := api.Services{
 		TokenService:      tokenService,
+		JwtTokenService:   jwtTokenService,
 		UserService:       userService,
 		RoleService:       roleService,
 		PositionService:   positionService,
 		OperateLogService: logService,
 		SettingService:    settingService,
 		TenantService:     tenantService,
+		APIKeyService:     apiKeyService,
+		ExportService:     exportService,
 		UserRepository:    userRepository,
 	}
 	// Pass the gin.Engine to NewWebServer to avoid creating it twice
 	return serverpkg.NewWebServer(cfg, engine, services)
 }
 
-// ProvideCronManager provides the cron manager.
-func ProvideCronManager() *serverpkg.CronManager {
-	return serverpkg.NewCronManager()
+// ProvideCronManager provides the cron manager with the registered business jobs.
+func ProvideCronManager(
+	cfg *config.Config, userService userservice.UserService, logService operate_log.OperateLogService,
+	exportService exportservice.ExportService,
+) *serverpkg.CronManager {
+	return serverpkg.NewCronManager(bizcron.Register(&bizcron.Deps{
+		Config:        cfg,
+		UserService:   userService,
+		LogService:    logService,
+		ExportService: exportService,
+	}))
 }
 
 // ProvideHookServer provides the hook server.
-func ProvideHookServer() *serverpkg.HookServer {
-	return serverpkg.NewHookServer()
+// 依赖 WebServer 以确保路由（及其声明的权限目录）先于启动钩子注册
+func ProvideHookServer(roleService role.RoleService, webServer *serverpkg.WebServer) *serverpkg.HookServer {
+	return serverpkg.NewHookServer(roleService)
 }
 
 // ProvideServiceManager provides the service manager with all services.
@@ -254,10 +402,12 @@ func ProvideServiceManager(
 	cronManager *serverpkg.CronManager,
 	webServer *serverpkg.WebServer,
 	hookServer *serverpkg.HookServer,
+	logWriter *operate_log.Writer,
+	exportRunner *exportservice.Runner,
 	infraInit CoreInfraInit,
 ) *task.ServiceManager {
 	services := task.NewServiceManager()
-	services.AddService(cronManager, webServer, hookServer)
+	services.AddService(cronManager, webServer, hookServer, logWriter, exportRunner)
 	return services
 }
 
@@ -280,12 +430,19 @@ var CoreInfraSet = wire.NewSet(
 // 依赖：CoreInfraSet → RepositorySet
 var RepositorySet = wire.NewSet(
 	ProvideUserRepository,
+	ProvideTwoFactorRepository,
+	ProvidePasswordHistoryRepository,
+	ProvideIdentityRepository,
+	ProvideUserRoleRepository,
 	ProvideRoleRepository,
 	ProvideRolePermissionRepository,
 	ProvideOperateLogRepository,
 	ProvidePositionRepository,
 	ProvideServerSettingRepository,
 	ProvideTenantRepository,
+	ProvideProvisionRepository,
+	ProvideAPIKeyRepository,
+	ProvideExportRepository,
 )
 
 // ServiceSet provides all service dependencies.
@@ -294,12 +451,19 @@ var ServiceSet = wire.NewSet(
 	ProvideTokenService,
 	ProvideJwtTokenService,
 	ProvideCaptchaService,
+	ProvideMailSender,
+	ProvideOIDCClient,
+	ProvideLDAPDirectory,
 	ProvideServerSettingService,
+	ProvideOperateLogWriter,
 	ProvideOperateLogService,
 	ProvidePositionService,
 	ProvideTenantService,
 	ProvideRoleService,
 	ProvideUserService,
+	ProvideAPIKeyService,
+	ProvideExportRunner,
+	ProvideExportService,
 )
 
 // ServerSet provides all HTTP server dependencies.
@@ -9,6 +9,7 @@ import (
 	positionservice "goadmin/internal/service/position"
 	operatelogsService "goadmin/internal/service/operate_log"
 	settingsservice "goadmin/internal/service/setting"
+	tenantservice "goadmin/internal/service/tenant"
 	"goadmin/internal/service/token"
 	userrepo "goadmin/internal/repository/user"
 	"github.com/gin-gonic/gin"
@@ -28,6 +29,7 @@ type App struct {
 	PositionService positionservice.PositionService
 	LogService      operatelogsService.OperateLogService
 	SettingService  settingsservice.ServerSettingService
+	TenantService   tenantservice.TenantService
 	// Repositories
 	UserRepository  userrepo.UserRepository
 }
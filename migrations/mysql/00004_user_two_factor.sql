@@ -0,0 +1,35 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE `user_two_factor` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_id` int unsigned NOT NULL DEFAULT 0,
+  `secret` varchar(255) NOT NULL DEFAULT '' COMMENT 'AES-GCM 加密后的 TOTP 密钥',
+  `status` int DEFAULT '0' COMMENT '0:pending,1:enabled',
+  `recovery_codes` text COMMENT '未使用恢复码的哈希，JSON 数组',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户双因素认证';
+
+INSERT INTO `permissions` (`code`, `name`, `description`, `path`, `module`, `global_flag`) VALUES
+('user_2fa_status',   '双因素认证状态', '', 'admin/v1/user/2fa/status',         'user',   1),
+('user_2fa_enroll',   '绑定双因素认证', '', 'admin/v1/user/2fa/enroll',         'user',   1),
+('user_2fa_verify',   '启用双因素认证', '', 'admin/v1/user/2fa/verify',         'user',   1),
+('user_2fa_disable',  '关闭双因素认证', '', 'admin/v1/user/2fa/disable',        'user',   1),
+('user_2fa_recovery', '重新生成恢复码', '', 'admin/v1/user/2fa/recovery_codes', 'user',   1),
+('user_2fa_reset',    '重置双因素认证', '', 'admin/v1/user/2fa/reset',          'user',   0);
+
+INSERT INTO `role_permissions` (`role_code`, `permission_code`) VALUES
+('sup_admin', 'user_2fa_reset');
+
+INSERT INTO server_setting (id, name, value) VALUES
+('3', 'two_factor', '{"required_roles":[]}');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from server_setting where name in ('two_factor');
+delete from role_permissions where permission_code in ('user_2fa_reset');
+delete from permissions where code in ('user_2fa_status', 'user_2fa_enroll', 'user_2fa_verify', 'user_2fa_disable', 'user_2fa_recovery', 'user_2fa_reset');
+DROP TABLE IF EXISTS user_two_factor;
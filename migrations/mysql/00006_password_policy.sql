@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `users`
+  ADD COLUMN `password_changed_at` timestamp NULL DEFAULT NULL COMMENT '密码最近修改时间' AFTER `password`,
+  ADD COLUMN `must_change_password` tinyint(1) NOT NULL DEFAULT 0 COMMENT '下次登录须修改密码' AFTER `password_changed_at`;
+UPDATE `users` SET `password_changed_at` = CURRENT_TIMESTAMP;
+
+CREATE TABLE `user_password_history` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_id` int unsigned NOT NULL DEFAULT 0,
+  `password` varchar(100) NOT NULL DEFAULT '' COMMENT '密码哈希',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户历史密码';
+
+INSERT INTO server_setting (id, name, value) VALUES
+('5', 'password_policy', '{"password_min_length":8, "password_max_length":64, "password_require_upper":true, "password_require_lower":true, "password_require_digit":true, "password_require_symbol":false, "password_banned_list":["password","password1","12345678","123456789","qwerty123","admin123","Passw0rd","P@ssw0rd"], "password_history_count":5, "password_max_age":90}');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from server_setting where name in ('password_policy');
+DROP TABLE IF EXISTS user_password_history;
+ALTER TABLE `users` DROP COLUMN `must_change_password`, DROP COLUMN `password_changed_at`;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE `user_identities` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_id` int unsigned NOT NULL DEFAULT 0,
+  `provider` varchar(32) NOT NULL DEFAULT '' COMMENT '身份来源',
+  `issuer` varchar(255) NOT NULL DEFAULT '' COMMENT '身份提供方',
+  `subject` varchar(255) NOT NULL DEFAULT '' COMMENT '身份提供方中的用户标识',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_identity` (`provider`, `issuer`, `subject`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户外部身份绑定';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE `api_keys` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_id` int unsigned NOT NULL DEFAULT 0,
+  `name` varchar(64) NOT NULL DEFAULT '' COMMENT '名称',
+  `prefix` varchar(16) NOT NULL DEFAULT '' COMMENT '密钥前缀',
+  `key_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '完整密钥的SHA-256',
+  `scopes` text COMMENT '可访问的权限编码，JSON数组',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间，为空表示永不过期',
+  `last_used_at` timestamp NULL DEFAULT NULL COMMENT '最近使用时间',
+  `last_used_ip` varchar(45) NOT NULL DEFAULT '' COMMENT '最近使用IP',
+  `revoked_at` timestamp NULL DEFAULT NULL COMMENT '吊销时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_prefix` (`prefix`),
+  KEY `idx_api_key_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='API密钥';
+
+ALTER TABLE `operate_log`
+  ADD COLUMN `api_key_id` int unsigned NOT NULL DEFAULT 0 COMMENT 'API密钥ID' AFTER `ip`,
+  ADD COLUMN `api_key` varchar(16) NOT NULL DEFAULT '' COMMENT 'API密钥前缀' AFTER `api_key_id`;
+
+INSERT INTO `permissions` (`code`, `name`, `description`, `path`, `module`, `global_flag`) VALUES
+('api_key_list',   'API密钥列表', '', 'admin/v1/api_key/list',   'api_key', 1),
+('api_key_create', 'API密钥创建', '', 'admin/v1/api_key/create', 'api_key', 0),
+('api_key_revoke', 'API密钥吊销', '', 'admin/v1/api_key/revoke', 'api_key', 1);
+
+INSERT INTO `role_permissions` (`role_code`, `permission_code`) VALUES
+('sup_admin', 'api_key_create');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from role_permissions where permission_code in ('api_key_create');
+delete from permissions where code in ('api_key_list', 'api_key_create', 'api_key_revoke');
+ALTER TABLE `operate_log` DROP COLUMN `api_key`, DROP COLUMN `api_key_id`;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `permissions` ADD COLUMN `method` varchar(10) NOT NULL DEFAULT '' COMMENT 'HTTP方法，为空表示任意方法' AFTER `description`;
+ALTER TABLE `permissions` MODIFY COLUMN `path` varchar(200) NOT NULL DEFAULT '' COMMENT 'API路径，支持 :name 参数及 *name 通配';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `permissions` DROP COLUMN `method`;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `roles` ADD COLUMN `parent_code` varchar(32) NOT NULL DEFAULT '' COMMENT '父角色代码，子角色继承其权限，为空表示顶级角色' AFTER `code`;
+ALTER TABLE `roles` ADD INDEX `idx_role_parent` (`parent_code`);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `roles` DROP INDEX `idx_role_parent`;
+ALTER TABLE `roles` DROP COLUMN `parent_code`;
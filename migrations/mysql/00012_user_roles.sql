@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE `user_roles` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_id` int unsigned NOT NULL DEFAULT 0,
+  `role_code` varchar(32) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_role` (`user_id`, `role_code`),
+  KEY `idx_user_role_code` (`role_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户角色关联';
+
+-- 迁移原有的单一角色
+INSERT INTO `user_roles` (`user_id`, `role_code`)
+SELECT `id`, `role_code` FROM `users` WHERE `role_code` != '';
+
+ALTER TABLE `users` DROP COLUMN `role_code`;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `users` ADD COLUMN `role_code` varchar(32) NOT NULL DEFAULT '' AFTER `email`;
+
+-- 多个角色时保留代码最小的一个
+UPDATE `users` u
+JOIN (SELECT `user_id`, MIN(`role_code`) AS `role_code` FROM `user_roles` GROUP BY `user_id`) ur ON ur.`user_id` = u.`id`
+SET u.`role_code` = ur.`role_code`;
+
+DROP TABLE IF EXISTS user_roles;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `roles` ADD COLUMN `data_scope` tinyint NOT NULL DEFAULT 1 COMMENT '数据范围 1:全部,2:本租户,3:本部门,4:本人,5:自定义' AFTER `system_flag`;
+ALTER TABLE `roles` ADD COLUMN `data_filter` text COMMENT '自定义数据范围条件，JSON 数组' AFTER `data_scope`;
+
+ALTER TABLE `users` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID' AFTER `status`;
+ALTER TABLE `users` ADD COLUMN `dept_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属部门ID' AFTER `tenant_id`;
+ALTER TABLE `users` ADD INDEX `idx_user_tenant` (`tenant_id`);
+ALTER TABLE `users` ADD INDEX `idx_user_dept` (`dept_id`);
+
+ALTER TABLE `operate_log` ADD COLUMN `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '操作用户ID' AFTER `content`;
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_user` (`user_id`);
+UPDATE `operate_log` l JOIN `users` u ON u.`username` = l.`username` SET l.`user_id` = u.`id`;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_user`;
+ALTER TABLE `operate_log` DROP COLUMN `user_id`;
+ALTER TABLE `users` DROP INDEX `idx_user_dept`;
+ALTER TABLE `users` DROP INDEX `idx_user_tenant`;
+ALTER TABLE `users` DROP COLUMN `dept_id`;
+ALTER TABLE `users` DROP COLUMN `tenant_id`;
+ALTER TABLE `roles` DROP COLUMN `data_filter`;
+ALTER TABLE `roles` DROP COLUMN `data_scope`;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `roles` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID，0为平台内置，各租户只读共享' AFTER `id`;
+ALTER TABLE `roles` ADD INDEX `idx_role_tenant` (`tenant_id`);
+
+ALTER TABLE `position` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID' AFTER `id`;
+ALTER TABLE `position` ADD INDEX `idx_position_tenant` (`tenant_id`);
+
+ALTER TABLE `operate_log` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID' AFTER `id`;
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_tenant` (`tenant_id`);
+UPDATE `operate_log` l JOIN `users` u ON u.`id` = l.`user_id` SET l.`tenant_id` = u.`tenant_id`;
+
+-- 租户可保存自有配置，覆盖同名的全局配置
+ALTER TABLE `server_setting` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID，0为全局配置' AFTER `id`;
+ALTER TABLE `server_setting` DROP INDEX `name`;
+ALTER TABLE `server_setting` ADD UNIQUE KEY `uk_setting_tenant_name` (`tenant_id`, `name`);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DELETE FROM `server_setting` WHERE `tenant_id` != 0;
+ALTER TABLE `server_setting` DROP INDEX `uk_setting_tenant_name`;
+ALTER TABLE `server_setting` ADD UNIQUE KEY `name` (`name`);
+ALTER TABLE `server_setting` DROP COLUMN `tenant_id`;
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_tenant`;
+ALTER TABLE `operate_log` DROP COLUMN `tenant_id`;
+ALTER TABLE `position` DROP INDEX `idx_position_tenant`;
+ALTER TABLE `position` DROP COLUMN `tenant_id`;
+ALTER TABLE `roles` DROP INDEX `idx_role_tenant`;
+ALTER TABLE `roles` DROP COLUMN `tenant_id`;
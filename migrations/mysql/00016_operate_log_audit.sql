@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `operate_log` ADD COLUMN `action` varchar(64) NOT NULL DEFAULT '' COMMENT '操作编码' AFTER `content`;
+ALTER TABLE `operate_log` ADD COLUMN `resource` varchar(64) NOT NULL DEFAULT '' COMMENT '资源类型' AFTER `action`;
+ALTER TABLE `operate_log` ADD COLUMN `resource_id` varchar(64) NOT NULL DEFAULT '' COMMENT '资源ID' AFTER `resource`;
+ALTER TABLE `operate_log` ADD COLUMN `diff` text COMMENT '变更前后的字段，JSON 对象' AFTER `resource_id`;
+ALTER TABLE `operate_log` ADD COLUMN `result` tinyint NOT NULL DEFAULT 1 COMMENT '结果 1:成功,2:失败' AFTER `diff`;
+ALTER TABLE `operate_log` ADD COLUMN `trace_id` varchar(64) NOT NULL DEFAULT '' COMMENT '请求跟踪ID' AFTER `ip`;
+ALTER TABLE `operate_log` ADD COLUMN `user_agent` varchar(255) NOT NULL DEFAULT '' COMMENT '客户端UA' AFTER `trace_id`;
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_resource` (`resource`, `resource_id`);
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_action` (`action`);
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_trace` (`trace_id`);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_trace`;
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_action`;
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_resource`;
+ALTER TABLE `operate_log` DROP COLUMN `user_agent`;
+ALTER TABLE `operate_log` DROP COLUMN `trace_id`;
+ALTER TABLE `operate_log` DROP COLUMN `result`;
+ALTER TABLE `operate_log` DROP COLUMN `diff`;
+ALTER TABLE `operate_log` DROP COLUMN `resource_id`;
+ALTER TABLE `operate_log` DROP COLUMN `resource`;
+ALTER TABLE `operate_log` DROP COLUMN `action`;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `operate_log` ADD COLUMN `prev_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '前一条日志的哈希' AFTER `api_key`;
+ALTER TABLE `operate_log` ADD COLUMN `hash` varchar(64) NOT NULL DEFAULT '' COMMENT '本条日志的哈希' AFTER `prev_hash`;
+
+CREATE TABLE `operate_log_chain` (
+  `id` tinyint unsigned NOT NULL,
+  `last_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '链尾日志ID',
+  `hash` varchar(64) NOT NULL DEFAULT '' COMMENT '链尾日志的哈希',
+  `mtime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='操作日志哈希链链头';
+INSERT INTO `operate_log_chain` (`id`, `last_id`, `hash`) VALUES (1, 0, '');
+
+CREATE TABLE `operate_log_checkpoint` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `last_id` bigint unsigned NOT NULL COMMENT '检查点处的日志ID',
+  `hash` varchar(64) NOT NULL DEFAULT '' COMMENT '检查点处日志的哈希',
+  `signature` varchar(64) NOT NULL DEFAULT '' COMMENT 'HMAC-SHA256 签名',
+  `ctime` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='操作日志哈希链检查点';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS `operate_log_checkpoint`;
+DROP TABLE IF EXISTS `operate_log_chain`;
+ALTER TABLE `operate_log` DROP COLUMN `hash`;
+ALTER TABLE `operate_log` DROP COLUMN `prev_hash`;
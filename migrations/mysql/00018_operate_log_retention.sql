@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- 保留策略按创建时间清理，列表按用户名查询
+
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_ctime` (`ctime`);
+ALTER TABLE `operate_log` ADD INDEX `idx_operate_log_username` (`username`);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_username`;
+ALTER TABLE `operate_log` DROP INDEX `idx_operate_log_ctime`;
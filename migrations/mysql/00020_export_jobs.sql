@@ -0,0 +1,47 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE `export_jobs` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `ctime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `mtime` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `tenant_id` int unsigned NOT NULL DEFAULT 0 COMMENT '所属租户ID',
+  `user_id` int unsigned NOT NULL DEFAULT 0 COMMENT '发起用户ID',
+  `resource` varchar(32) NOT NULL DEFAULT '' COMMENT '导出的资源',
+  `format` varchar(8) NOT NULL DEFAULT '' COMMENT '文件格式 csv/xlsx',
+  `params` text COMMENT '筛选条件，JSON',
+  `lang` varchar(64) NOT NULL DEFAULT '' COMMENT '表头语言',
+  `status` varchar(16) NOT NULL DEFAULT '' COMMENT '状态',
+  `total` bigint NOT NULL DEFAULT 0 COMMENT '开始导出时符合条件的行数',
+  `exported_rows` bigint NOT NULL DEFAULT 0 COMMENT '已导出行数',
+  `file` varchar(255) NOT NULL DEFAULT '' COMMENT '导出文件名',
+  `size` bigint NOT NULL DEFAULT 0 COMMENT '文件大小',
+  `error` varchar(512) NOT NULL DEFAULT '' COMMENT '失败原因',
+  `finished_at` timestamp NULL DEFAULT NULL COMMENT '结束时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_export_job_tenant` (`tenant_id`),
+  KEY `idx_export_job_user` (`user_id`),
+  KEY `idx_export_job_finished` (`finished_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='导出任务';
+
+INSERT INTO `permissions` (`code`, `name`, `description`, `method`, `path`, `module`, `global_flag`) VALUES
+('user_export',        '用户导出',     '', 'POST', 'admin/v1/export/user',        'user',        0),
+('role_export',        '角色导出',     '', 'POST', 'admin/v1/export/role',        'role',        0),
+('tenant_export',      '租户导出',     '', 'POST', 'admin/v1/export/tenant',      'tenant',      0),
+('position_export',    '位置导出',     '', 'POST', 'admin/v1/export/position',    'position',    0),
+('operate_log_export', '操作日志导出', '', 'POST', 'admin/v1/export/operate_log', 'operate_log', 0),
+('export_jobs',        '我的导出',     '', 'GET',  'admin/v1/export/jobs',        'export',      1),
+('export_download',    '下载导出文件', '', 'GET',  'admin/v1/export/download',    'export',      1);
+
+-- 租户管理员模板可导出租户内的数据
+INSERT INTO `role_permissions` (`role_code`, `permission_code`) VALUES
+('tenant_admin', 'user_export'),
+('tenant_admin', 'role_export'),
+('tenant_admin', 'position_export'),
+('tenant_admin', 'operate_log_export');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from role_permissions where permission_code in ('user_export', 'role_export', 'tenant_export', 'position_export', 'operate_log_export');
+delete from permissions where code in ('user_export', 'role_export', 'tenant_export', 'position_export', 'operate_log_export', 'export_jobs', 'export_download');
+DROP TABLE IF EXISTS export_jobs;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `operate_log_chain` ADD COLUMN `anchor_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '链首之前一条日志的ID，之后的日志均须有哈希' AFTER `hash`;
+ALTER TABLE `operate_log_chain` ADD COLUMN `anchor_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '链首之前一条日志的哈希' AFTER `anchor_id`;
+
+-- 已有的链以首条有哈希的日志为链首；尚未写入有哈希的日志时，现有日志均在锚点之前
+UPDATE `operate_log_chain` SET
+  `anchor_id` = COALESCE((SELECT MIN(`id`) - 1 FROM `operate_log` WHERE `hash` <> ''), (SELECT MAX(`id`) FROM `operate_log`), 0),
+  `anchor_hash` = COALESCE((SELECT `prev_hash` FROM `operate_log` WHERE `hash` <> '' ORDER BY `id` LIMIT 1), '')
+WHERE `id` = 1;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `operate_log_chain` DROP COLUMN `anchor_hash`;
+ALTER TABLE `operate_log_chain` DROP COLUMN `anchor_id`;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE `export_jobs` ADD COLUMN `instance` varchar(64) NOT NULL DEFAULT '' COMMENT '执行任务的实例' AFTER `finished_at`;
+ALTER TABLE `export_jobs` ADD KEY `idx_export_job_instance` (`instance`);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE `export_jobs` DROP KEY `idx_export_job_instance`;
+ALTER TABLE `export_jobs` DROP COLUMN `instance`;
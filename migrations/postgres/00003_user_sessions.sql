@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+INSERT INTO permissions (code, name, description, path, module, global_flag) VALUES
+('user_sessions',        '我的会话',     '', 'admin/v1/user/sessions',            'user',   1),
+('user_session_revoke',  '注销会话',     '', 'admin/v1/user/sessions/revoke',     'user',   1),
+('user_session_all',     '退出所有设备', '', 'admin/v1/user/sessions/revoke_all', 'user',   1),
+('user_force_logout',    '强制下线',     '', 'admin/v1/user/force_logout',        'user',   0);
+
+INSERT INTO role_permissions (role_code, permission_code) VALUES
+('sup_admin', 'user_force_logout');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from role_permissions where permission_code in ('user_force_logout');
+delete from permissions where code in ('user_sessions', 'user_session_revoke', 'user_session_all', 'user_force_logout');
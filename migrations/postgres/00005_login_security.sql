@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP NULL DEFAULT NULL;
+COMMENT ON COLUMN users.locked_until IS '自动锁定的解锁时间，为空表示需管理员解锁';
+
+INSERT INTO permissions (code, name, description, path, module, global_flag) VALUES
+('user_unlock', '解锁用户', '', 'admin/v1/user/unlock', 'user', 0);
+
+INSERT INTO role_permissions (role_code, permission_code) VALUES
+('sup_admin', 'user_unlock');
+
+INSERT INTO server_setting (id, name, value) VALUES
+(4, 'login_security', '{"max_failures":5, "ip_max_failures":50, "failure_window":900, "lock_duration":1800, "delay_base":1, "delay_max":30}');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from server_setting where name in ('login_security');
+delete from role_permissions where permission_code in ('user_unlock');
+delete from permissions where code in ('user_unlock');
+ALTER TABLE users DROP COLUMN locked_until;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE users
+  ADD COLUMN password_changed_at TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN users.password_changed_at IS '密码最近修改时间';
+COMMENT ON COLUMN users.must_change_password IS '下次登录须修改密码';
+UPDATE users SET password_changed_at = CURRENT_TIMESTAMP;
+
+CREATE TABLE user_password_history (
+  id SERIAL PRIMARY KEY,
+  ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id INTEGER NOT NULL DEFAULT 0,
+  password VARCHAR(100) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_user_password_history_user_id ON user_password_history (user_id);
+COMMENT ON TABLE user_password_history IS '用户历史密码';
+COMMENT ON COLUMN user_password_history.password IS '密码哈希';
+
+INSERT INTO server_setting (id, name, value) VALUES
+(5, 'password_policy', '{"password_min_length":8, "password_max_length":64, "password_require_upper":true, "password_require_lower":true, "password_require_digit":true, "password_require_symbol":false, "password_banned_list":["password","password1","12345678","123456789","qwerty123","admin123","Passw0rd","P@ssw0rd"], "password_history_count":5, "password_max_age":90}');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from server_setting where name in ('password_policy');
+DROP TABLE IF EXISTS user_password_history;
+ALTER TABLE users DROP COLUMN must_change_password, DROP COLUMN password_changed_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+INSERT INTO server_setting (id, name, value) VALUES
+(6, 'password_reset', '{"password_reset_enable":0, "password_reset_url":"http://localhost:8080/zh/reset-password", "password_reset_token_ttl":1800, "password_reset_email_limit":3, "password_reset_ip_limit":20}');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from server_setting where name in ('password_reset');
@@ -0,0 +1,23 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE user_identities (
+  id SERIAL PRIMARY KEY,
+  ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id INTEGER NOT NULL DEFAULT 0,
+  provider VARCHAR(32) NOT NULL DEFAULT '',
+  issuer VARCHAR(255) NOT NULL DEFAULT '',
+  subject VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX uk_user_identities ON user_identities (provider, issuer, subject);
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+COMMENT ON TABLE user_identities IS '用户外部身份绑定';
+COMMENT ON COLUMN user_identities.provider IS '身份来源';
+COMMENT ON COLUMN user_identities.issuer IS '身份提供方';
+COMMENT ON COLUMN user_identities.subject IS '身份提供方中的用户标识';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE api_keys (
+  id SERIAL PRIMARY KEY,
+  ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id INTEGER NOT NULL DEFAULT 0,
+  name VARCHAR(64) NOT NULL DEFAULT '',
+  prefix VARCHAR(16) NOT NULL DEFAULT '',
+  key_hash VARCHAR(64) NOT NULL DEFAULT '',
+  scopes TEXT,
+  expires_at TIMESTAMP NULL DEFAULT NULL,
+  last_used_at TIMESTAMP NULL DEFAULT NULL,
+  last_used_ip VARCHAR(45) NOT NULL DEFAULT '',
+  revoked_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX uk_api_keys_prefix ON api_keys (prefix);
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
+COMMENT ON TABLE api_keys IS 'API密钥';
+COMMENT ON COLUMN api_keys.name IS '名称';
+COMMENT ON COLUMN api_keys.prefix IS '密钥前缀';
+COMMENT ON COLUMN api_keys.key_hash IS '完整密钥的SHA-256';
+COMMENT ON COLUMN api_keys.scopes IS '可访问的权限编码，JSON数组';
+COMMENT ON COLUMN api_keys.expires_at IS '过期时间，为空表示永不过期';
+COMMENT ON COLUMN api_keys.last_used_at IS '最近使用时间';
+COMMENT ON COLUMN api_keys.last_used_ip IS '最近使用IP';
+COMMENT ON COLUMN api_keys.revoked_at IS '吊销时间';
+
+ALTER TABLE operate_log ADD COLUMN api_key_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE operate_log ADD COLUMN api_key VARCHAR(16) NOT NULL DEFAULT '';
+COMMENT ON COLUMN operate_log.api_key_id IS 'API密钥ID';
+COMMENT ON COLUMN operate_log.api_key IS 'API密钥前缀';
+
+INSERT INTO permissions (code, name, description, path, module, global_flag) VALUES
+('api_key_list',   'API密钥列表', '', 'admin/v1/api_key/list',   'api_key', 1),
+('api_key_create', 'API密钥创建', '', 'admin/v1/api_key/create', 'api_key', 0),
+('api_key_revoke', 'API密钥吊销', '', 'admin/v1/api_key/revoke', 'api_key', 1);
+
+INSERT INTO role_permissions (role_code, permission_code) VALUES
+('sup_admin', 'api_key_create');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from role_permissions where permission_code in ('api_key_create');
+delete from permissions where code in ('api_key_list', 'api_key_create', 'api_key_revoke');
+ALTER TABLE operate_log DROP COLUMN api_key;
+ALTER TABLE operate_log DROP COLUMN api_key_id;
+DROP TABLE IF EXISTS api_keys;
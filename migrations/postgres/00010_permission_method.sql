@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE permissions ADD COLUMN method VARCHAR(10) NOT NULL DEFAULT '';
+COMMENT ON COLUMN permissions.method IS 'HTTP方法，为空表示任意方法';
+COMMENT ON COLUMN permissions.path IS 'API路径，支持 :name 参数及 *name 通配';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE permissions DROP COLUMN method;
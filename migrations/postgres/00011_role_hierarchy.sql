@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE roles ADD COLUMN parent_code VARCHAR(32) NOT NULL DEFAULT '';
+COMMENT ON COLUMN roles.parent_code IS '父角色代码，子角色继承其权限，为空表示顶级角色';
+CREATE INDEX idx_role_parent ON roles (parent_code);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_role_parent;
+ALTER TABLE roles DROP COLUMN parent_code;
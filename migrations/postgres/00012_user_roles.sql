@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE user_roles (
+  id SERIAL PRIMARY KEY,
+  ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  user_id INTEGER NOT NULL DEFAULT 0,
+  role_code VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX uk_user_roles ON user_roles (user_id, role_code);
+CREATE INDEX idx_user_roles_role_code ON user_roles (role_code);
+COMMENT ON TABLE user_roles IS '用户角色关联';
+
+-- 迁移原有的单一角色
+INSERT INTO user_roles (user_id, role_code)
+SELECT id, role_code FROM users WHERE role_code != '';
+
+ALTER TABLE users DROP COLUMN role_code;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE users ADD COLUMN role_code VARCHAR(32) NOT NULL DEFAULT '';
+
+-- 多个角色时保留代码最小的一个
+UPDATE users u SET role_code = ur.role_code
+FROM (SELECT user_id, MIN(role_code) AS role_code FROM user_roles GROUP BY user_id) ur
+WHERE ur.user_id = u.id;
+
+DROP TABLE IF EXISTS user_roles;
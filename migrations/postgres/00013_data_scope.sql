@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE roles ADD COLUMN data_scope SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE roles ADD COLUMN data_filter TEXT;
+COMMENT ON COLUMN roles.data_scope IS '数据范围 1:全部,2:本租户,3:本部门,4:本人,5:自定义';
+COMMENT ON COLUMN roles.data_filter IS '自定义数据范围条件，JSON 数组';
+
+ALTER TABLE users ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN dept_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN users.tenant_id IS '所属租户ID';
+COMMENT ON COLUMN users.dept_id IS '所属部门ID';
+CREATE INDEX idx_user_tenant ON users (tenant_id);
+CREATE INDEX idx_user_dept ON users (dept_id);
+
+ALTER TABLE operate_log ADD COLUMN user_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN operate_log.user_id IS '操作用户ID';
+CREATE INDEX idx_operate_log_user ON operate_log (user_id);
+UPDATE operate_log l SET user_id = u.id FROM users u WHERE u.username = l.username;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_operate_log_user;
+ALTER TABLE operate_log DROP COLUMN user_id;
+DROP INDEX IF EXISTS idx_user_dept;
+DROP INDEX IF EXISTS idx_user_tenant;
+ALTER TABLE users DROP COLUMN dept_id;
+ALTER TABLE users DROP COLUMN tenant_id;
+ALTER TABLE roles DROP COLUMN data_filter;
+ALTER TABLE roles DROP COLUMN data_scope;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE roles ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN roles.tenant_id IS '所属租户ID，0为平台内置，各租户只读共享';
+CREATE INDEX idx_role_tenant ON roles (tenant_id);
+
+ALTER TABLE position ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN position.tenant_id IS '所属租户ID';
+CREATE INDEX idx_position_tenant ON position (tenant_id);
+
+ALTER TABLE operate_log ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN operate_log.tenant_id IS '所属租户ID';
+CREATE INDEX idx_operate_log_tenant ON operate_log (tenant_id);
+UPDATE operate_log l SET tenant_id = u.tenant_id FROM users u WHERE u.id = l.user_id;
+
+-- 租户可保存自有配置，覆盖同名的全局配置
+ALTER TABLE server_setting ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN server_setting.tenant_id IS '所属租户ID，0为全局配置';
+ALTER TABLE server_setting DROP CONSTRAINT server_setting_name_key;
+ALTER TABLE server_setting ADD CONSTRAINT uk_setting_tenant_name UNIQUE (tenant_id, name);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DELETE FROM server_setting WHERE tenant_id != 0;
+ALTER TABLE server_setting DROP CONSTRAINT uk_setting_tenant_name;
+ALTER TABLE server_setting ADD CONSTRAINT server_setting_name_key UNIQUE (name);
+ALTER TABLE server_setting DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_operate_log_tenant;
+ALTER TABLE operate_log DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_position_tenant;
+ALTER TABLE position DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_role_tenant;
+ALTER TABLE roles DROP COLUMN tenant_id;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+-- 租户管理员角色模板，开通租户时克隆为租户自有角色；模板本身停用，不直接分配给用户
+INSERT INTO roles (code, name, description, status, system_flag, data_scope) VALUES
+('tenant_admin', '租户管理员模板', '开通租户时克隆为租户管理员', 2, 1, 2);
+
+INSERT INTO role_permissions (role_code, permission_code) VALUES
+('tenant_admin', 'user_list'),
+('tenant_admin', 'user_info'),
+('tenant_admin', 'user_create'),
+('tenant_admin', 'user_update'),
+('tenant_admin', 'user_delete'),
+('tenant_admin', 'user_unlock'),
+('tenant_admin', 'user_force_logout'),
+('tenant_admin', 'role_list'),
+('tenant_admin', 'role_all'),
+('tenant_admin', 'role_active'),
+('tenant_admin', 'role_info'),
+('tenant_admin', 'role_create'),
+('tenant_admin', 'role_update'),
+('tenant_admin', 'role_delete'),
+('tenant_admin', 'role_perm_info'),
+('tenant_admin', 'role_perm_set'),
+('tenant_admin', 'role_perm_all'),
+('tenant_admin', 'position_list'),
+('tenant_admin', 'position_info'),
+('tenant_admin', 'position_create'),
+('tenant_admin', 'position_update'),
+('tenant_admin', 'position_delete'),
+('tenant_admin', 'operate_log'),
+('tenant_admin', 'server_get'),
+('tenant_admin', 'server_set'),
+('tenant_admin', 'server_settings_set'),
+('tenant_admin', 'upload_file');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DELETE FROM role_permissions WHERE role_code = 'tenant_admin';
+DELETE FROM roles WHERE code = 'tenant_admin';
@@ -0,0 +1,33 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE operate_log ADD COLUMN action VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE operate_log ADD COLUMN resource VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE operate_log ADD COLUMN resource_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE operate_log ADD COLUMN diff TEXT;
+ALTER TABLE operate_log ADD COLUMN result SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE operate_log ADD COLUMN trace_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE operate_log ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '';
+COMMENT ON COLUMN operate_log.action IS '操作编码';
+COMMENT ON COLUMN operate_log.resource IS '资源类型';
+COMMENT ON COLUMN operate_log.resource_id IS '资源ID';
+COMMENT ON COLUMN operate_log.diff IS '变更前后的字段，JSON 对象';
+COMMENT ON COLUMN operate_log.result IS '结果 1:成功,2:失败';
+COMMENT ON COLUMN operate_log.trace_id IS '请求跟踪ID';
+COMMENT ON COLUMN operate_log.user_agent IS '客户端UA';
+CREATE INDEX idx_operate_log_resource ON operate_log (resource, resource_id);
+CREATE INDEX idx_operate_log_action ON operate_log (action);
+CREATE INDEX idx_operate_log_trace ON operate_log (trace_id);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_operate_log_trace;
+DROP INDEX IF EXISTS idx_operate_log_action;
+DROP INDEX IF EXISTS idx_operate_log_resource;
+ALTER TABLE operate_log DROP COLUMN user_agent;
+ALTER TABLE operate_log DROP COLUMN trace_id;
+ALTER TABLE operate_log DROP COLUMN result;
+ALTER TABLE operate_log DROP COLUMN diff;
+ALTER TABLE operate_log DROP COLUMN resource_id;
+ALTER TABLE operate_log DROP COLUMN resource;
+ALTER TABLE operate_log DROP COLUMN action;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE operate_log ADD COLUMN prev_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE operate_log ADD COLUMN hash VARCHAR(64) NOT NULL DEFAULT '';
+COMMENT ON COLUMN operate_log.prev_hash IS '前一条日志的哈希';
+COMMENT ON COLUMN operate_log.hash IS '本条日志的哈希';
+
+CREATE TABLE operate_log_chain (
+    id SMALLINT PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE operate_log_chain IS '操作日志哈希链链头';
+COMMENT ON COLUMN operate_log_chain.last_id IS '链尾日志ID';
+COMMENT ON COLUMN operate_log_chain.hash IS '链尾日志的哈希';
+INSERT INTO operate_log_chain (id, last_id, hash) VALUES (1, 0, '');
+
+CREATE TABLE operate_log_checkpoint (
+    id BIGSERIAL PRIMARY KEY,
+    last_id BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    signature VARCHAR(64) NOT NULL DEFAULT '',
+    ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE operate_log_checkpoint IS '操作日志哈希链检查点';
+COMMENT ON COLUMN operate_log_checkpoint.last_id IS '检查点处的日志ID';
+COMMENT ON COLUMN operate_log_checkpoint.hash IS '检查点处日志的哈希';
+COMMENT ON COLUMN operate_log_checkpoint.signature IS 'HMAC-SHA256 签名';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS operate_log_checkpoint;
+DROP TABLE IF EXISTS operate_log_chain;
+ALTER TABLE operate_log DROP COLUMN hash;
+ALTER TABLE operate_log DROP COLUMN prev_hash;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- 保留策略按创建时间清理，列表按用户名查询
+
+CREATE INDEX idx_operate_log_ctime ON operate_log (ctime);
+CREATE INDEX idx_operate_log_username ON operate_log (username);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_operate_log_username;
+DROP INDEX IF EXISTS idx_operate_log_ctime;
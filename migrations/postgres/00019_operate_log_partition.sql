@@ -0,0 +1,90 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- 可选：将 operate_log 转换为按创建时间按月分区的表。默认不做任何修改，启用时先执行
+--   ALTER DATABASE <数据库名> SET goadmin.operate_log_partition = 'on';
+-- 重新连接后再执行迁移。未启用时已执行过本迁移的库，设置后回滚本迁移再重新执行即可转换。
+-- 转换时按已有数据创建各月分区及之后两个月的分区，之后的分区由定时任务提前创建；
+-- 缺少分区时日志写入默认分区 operate_log_default，须先移出其中对应月份的数据才能创建该月分区。
+-- 分区表的主键须包含分区键，主键改为 (id, ctime)，ID 仍由原序列生成
+
+-- +goose StatementBegin
+DO $$
+DECLARE
+    m DATE;
+    stop DATE;
+BEGIN
+    IF coalesce(current_setting('goadmin.operate_log_partition', true), '') <> 'on' THEN
+        RAISE NOTICE 'operate_log 分区未启用，跳过';
+        RETURN;
+    END IF;
+
+    ALTER TABLE operate_log RENAME TO operate_log_unpartitioned;
+    ALTER TABLE operate_log_unpartitioned RENAME CONSTRAINT operate_log_pkey TO operate_log_unpartitioned_pkey;
+    ALTER SEQUENCE operate_log_id_seq OWNED BY NONE;
+
+    CREATE TABLE operate_log (
+        LIKE operate_log_unpartitioned INCLUDING DEFAULTS INCLUDING COMMENTS,
+        PRIMARY KEY (id, ctime)
+    ) PARTITION BY RANGE (ctime);
+    COMMENT ON TABLE operate_log IS '操作日志';
+    CREATE TABLE operate_log_default PARTITION OF operate_log DEFAULT;
+
+    m := date_trunc('month', coalesce((SELECT min(ctime) FROM operate_log_unpartitioned), now()))::date;
+    stop := (date_trunc('month', now()) + interval '3 month')::date;
+    WHILE m < stop LOOP
+        EXECUTE format('CREATE TABLE operate_log_p%s PARTITION OF operate_log FOR VALUES FROM (%L) TO (%L)',
+            to_char(m, 'YYYYMM'), m, (m + interval '1 month')::date);
+        m := (m + interval '1 month')::date;
+    END LOOP;
+
+    INSERT INTO operate_log SELECT * FROM operate_log_unpartitioned;
+    DROP TABLE operate_log_unpartitioned;
+    ALTER SEQUENCE operate_log_id_seq OWNED BY operate_log.id;
+
+    CREATE INDEX idx_operate_log_tenant ON operate_log (tenant_id);
+    CREATE INDEX idx_operate_log_user ON operate_log (user_id);
+    CREATE INDEX idx_operate_log_resource ON operate_log (resource, resource_id);
+    CREATE INDEX idx_operate_log_action ON operate_log (action);
+    CREATE INDEX idx_operate_log_trace ON operate_log (trace_id);
+    CREATE INDEX idx_operate_log_ctime ON operate_log (ctime);
+    CREATE INDEX idx_operate_log_username ON operate_log (username);
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+-- 已分区时转换回普通表
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_class
+                   WHERE relname = 'operate_log' AND relkind = 'p' AND pg_table_is_visible(oid)) THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE operate_log RENAME TO operate_log_partitioned;
+    ALTER TABLE operate_log_partitioned RENAME CONSTRAINT operate_log_pkey TO operate_log_partitioned_pkey;
+    ALTER SEQUENCE operate_log_id_seq OWNED BY NONE;
+
+    CREATE TABLE operate_log (
+        LIKE operate_log_partitioned INCLUDING DEFAULTS INCLUDING COMMENTS,
+        PRIMARY KEY (id)
+    );
+    COMMENT ON TABLE operate_log IS '操作日志';
+
+    INSERT INTO operate_log SELECT * FROM operate_log_partitioned;
+    DROP TABLE operate_log_partitioned;
+    ALTER SEQUENCE operate_log_id_seq OWNED BY operate_log.id;
+
+    CREATE INDEX idx_operate_log_tenant ON operate_log (tenant_id);
+    CREATE INDEX idx_operate_log_user ON operate_log (user_id);
+    CREATE INDEX idx_operate_log_resource ON operate_log (resource, resource_id);
+    CREATE INDEX idx_operate_log_action ON operate_log (action);
+    CREATE INDEX idx_operate_log_trace ON operate_log (trace_id);
+    CREATE INDEX idx_operate_log_ctime ON operate_log (ctime);
+    CREATE INDEX idx_operate_log_username ON operate_log (username);
+END
+$$;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+CREATE TABLE export_jobs (
+  id SERIAL PRIMARY KEY,
+  ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  mtime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  tenant_id INTEGER NOT NULL DEFAULT 0,
+  user_id INTEGER NOT NULL DEFAULT 0,
+  resource VARCHAR(32) NOT NULL DEFAULT '',
+  format VARCHAR(8) NOT NULL DEFAULT '',
+  params TEXT,
+  lang VARCHAR(64) NOT NULL DEFAULT '',
+  status VARCHAR(16) NOT NULL DEFAULT '',
+  total BIGINT NOT NULL DEFAULT 0,
+  exported_rows BIGINT NOT NULL DEFAULT 0,
+  file VARCHAR(255) NOT NULL DEFAULT '',
+  size BIGINT NOT NULL DEFAULT 0,
+  error VARCHAR(512) NOT NULL DEFAULT '',
+  finished_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_export_job_tenant ON export_jobs (tenant_id);
+CREATE INDEX idx_export_job_user ON export_jobs (user_id);
+CREATE INDEX idx_export_job_finished ON export_jobs (finished_at);
+COMMENT ON TABLE export_jobs IS '导出任务';
+COMMENT ON COLUMN export_jobs.tenant_id IS '所属租户ID';
+COMMENT ON COLUMN export_jobs.user_id IS '发起用户ID';
+COMMENT ON COLUMN export_jobs.resource IS '导出的资源';
+COMMENT ON COLUMN export_jobs.format IS '文件格式 csv/xlsx';
+COMMENT ON COLUMN export_jobs.params IS '筛选条件，JSON';
+COMMENT ON COLUMN export_jobs.lang IS '表头语言';
+COMMENT ON COLUMN export_jobs.status IS '状态';
+COMMENT ON COLUMN export_jobs.total IS '开始导出时符合条件的行数';
+COMMENT ON COLUMN export_jobs.exported_rows IS '已导出行数';
+COMMENT ON COLUMN export_jobs.file IS '导出文件名';
+COMMENT ON COLUMN export_jobs.size IS '文件大小';
+COMMENT ON COLUMN export_jobs.error IS '失败原因';
+COMMENT ON COLUMN export_jobs.finished_at IS '结束时间';
+
+INSERT INTO permissions (code, name, description, method, path, module, global_flag) VALUES
+('user_export',        '用户导出',     '', 'POST', 'admin/v1/export/user',        'user',        0),
+('role_export',        '角色导出',     '', 'POST', 'admin/v1/export/role',        'role',        0),
+('tenant_export',      '租户导出',     '', 'POST', 'admin/v1/export/tenant',      'tenant',      0),
+('position_export',    '位置导出',     '', 'POST', 'admin/v1/export/position',    'position',    0),
+('operate_log_export', '操作日志导出', '', 'POST', 'admin/v1/export/operate_log', 'operate_log', 0),
+('export_jobs',        '我的导出',     '', 'GET',  'admin/v1/export/jobs',        'export',      1),
+('export_download',    '下载导出文件', '', 'GET',  'admin/v1/export/download',    'export',      1);
+
+-- 租户管理员模板可导出租户内的数据
+INSERT INTO role_permissions (role_code, permission_code) VALUES
+('tenant_admin', 'user_export'),
+('tenant_admin', 'role_export'),
+('tenant_admin', 'position_export'),
+('tenant_admin', 'operate_log_export');
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+delete from role_permissions where permission_code in ('user_export', 'role_export', 'tenant_export', 'position_export', 'operate_log_export');
+delete from permissions where code in ('user_export', 'role_export', 'tenant_export', 'position_export', 'operate_log_export', 'export_jobs', 'export_download');
+DROP TABLE IF EXISTS export_jobs;
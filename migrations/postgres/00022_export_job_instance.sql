@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+ALTER TABLE export_jobs ADD COLUMN instance VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX idx_export_job_instance ON export_jobs (instance);
+COMMENT ON COLUMN export_jobs.instance IS '执行任务的实例';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_export_job_instance;
+ALTER TABLE export_jobs DROP COLUMN instance;
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // a 最近使用，淘汰 b
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("a = %v, %v", v, ok)
+	}
+	c.Set("a", 10)
+	if v, _ := c.Get("a"); v != 10 {
+		t.Errorf("a = %v after update", v)
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok || c.Len() != 1 {
+		t.Errorf("delete failed, len %d", c.Len())
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("purge failed, len %d", c.Len())
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewLRU[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Set("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a should not expire yet")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a should expire")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expired entry should be removed, len %d", c.Len())
+	}
+}
+
+type counter struct {
+	calls atomic.Int32
+	value atomic.Int32
+}
+
+func (c *counter) load(context.Context) (int, error) {
+	c.calls.Add(1)
+	return int(c.value.Load()), nil
+}
+
+func newRedis(t *testing.T) func() *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return func() *redis.Client { return client }
+}
+
+func TestTieredLocalOnly(t *testing.T) {
+	ctx := context.Background()
+	c := NewTiered[int](Options{Name: "test", Size: 10})
+	src := &counter{}
+	src.value.Store(1)
+	for i := 0; i < 3; i++ {
+		if v, err := c.Get(ctx, "k", src.load); err != nil || v != 1 {
+			t.Fatalf("Get = %v, %v", v, err)
+		}
+	}
+	if src.calls.Load() != 1 {
+		t.Errorf("load called %d times", src.calls.Load())
+	}
+
+	src.value.Store(2)
+	if err := c.Invalidate(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.Get(ctx, "k", src.load); v != 2 {
+		t.Errorf("Get after invalidate = %v", v)
+	}
+
+	wantErr := errors.New("boom")
+	if _, err := c.Get(ctx, "e", func(context.Context) (int, error) { return 0, wantErr }); !errors.Is(err, wantErr) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestTieredSharesRedisAndInvalidatesReplicas(t *testing.T) {
+	ctx := context.Background()
+	client := newRedis(t)
+	opts := Options{Name: "test:perm", Size: 10, LocalTTL: time.Hour, RemoteTTL: time.Hour, Client: client}
+	a, b := NewTiered[[]string](opts), NewTiered[[]string](opts)
+
+	var calls atomic.Int32
+	value := []string{"user_list"}
+	load := func(context.Context) ([]string, error) {
+		calls.Add(1)
+		return value, nil
+	}
+
+	if v, _ := a.Get(ctx, "admin", load); len(v) != 1 {
+		t.Fatalf("a.Get = %v", v)
+	}
+	// b 从 Redis 读取，不回源
+	if v, _ := b.Get(ctx, "admin", load); len(v) != 1 || calls.Load() != 1 {
+		t.Fatalf("b.Get = %v, calls %d", v, calls.Load())
+	}
+
+	value = []string{"user_list", "user_create"}
+	if err := a.Invalidate(ctx, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	// b 收到失效通知后清除进程内条目
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := b.local.Get("admin"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replica b was not invalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if v, _ := b.Get(ctx, "admin", load); len(v) != 2 {
+		t.Errorf("b.Get after invalidate = %v", v)
+	}
+
+	if err := b.Purge(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := client().Exists(ctx, "test:perm:admin").Result(); n != 0 {
+		t.Error("purge should delete redis keys")
+	}
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// 失效通知中表示清空全部条目的消息
+const purgeAll = "*"
+
+// Options 分层缓存配置
+type Options struct {
+	Name      string               // 缓存名称，用作 Redis 键前缀及失效通知频道
+	Size      int                  // 进程内最多缓存的条目数
+	LocalTTL  time.Duration        // 进程内条目有效期，兜底丢失的失效通知
+	RemoteTTL time.Duration        // Redis 中条目的有效期
+	Client    func() *redis.Client // Redis 客户端，为空或返回 nil 时仅使用进程内缓存
+}
+
+// Tiered 分层缓存：进程内 LRU -> Redis -> 回源加载
+//
+// 失效时删除 Redis 中的条目并通过 Redis 发布订阅通知所有实例清除进程内条目，
+// 订阅在首次读取时建立。值以 JSON 保存在 Redis 中。
+type Tiered[V any] struct {
+	opts   Options
+	local  *LRU[string, V]
+	group  singleflight.Group
+	gen    atomic.Uint64 // 每次失效递增，避免回源期间发生的失效被旧值覆盖
+	listen sync.Once
+}
+
+// NewTiered 创建分层缓存
+func NewTiered[V any](opts Options) *Tiered[V] {
+	return &Tiered[V]{
+		opts:  opts,
+		local: NewLRU[string, V](opts.Size, opts.LocalTTL),
+	}
+}
+
+// Get 读取条目，未命中时调用 load 回源并写入缓存
+func (c *Tiered[V]) Get(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	if v, ok := c.local.Get(key); ok {
+		return v, nil
+	}
+	client := c.client()
+	if client != nil {
+		c.listen.Do(func() { c.subscribe(client) })
+	}
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		gen := c.gen.Load()
+		if client != nil {
+			if data, err := client.Get(ctx, c.redisKey(key)).Bytes(); err == nil {
+				var v V
+				if json.Unmarshal(data, &v) == nil {
+					c.setLocal(gen, key, v)
+					return v, nil
+				}
+			}
+		}
+
+		v, err := load(ctx)
+		if err != nil {
+			return v, err
+		}
+		if client != nil {
+			// 写入失败不影响本次结果，下次读取时再回源
+			if data, err := json.Marshal(v); err == nil {
+				client.Set(ctx, c.redisKey(key), data, c.opts.RemoteTTL)
+			}
+		}
+		c.setLocal(gen, key, v)
+		return v, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return v.(V), nil
+}
+
+// Invalidate 使条目在所有实例上失效
+func (c *Tiered[V]) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		c.local.Delete(key)
+	}
+	c.gen.Add(1)
+
+	client := c.client()
+	if client == nil {
+		return nil
+	}
+	redisKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, c.redisKey(key))
+	}
+	pipe := client.Pipeline()
+	pipe.Del(ctx, redisKeys...)
+	for _, key := range keys {
+		pipe.Publish(ctx, c.channel(), key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Purge 使全部条目在所有实例上失效
+func (c *Tiered[V]) Purge(ctx context.Context) error {
+	c.local.Purge()
+	c.gen.Add(1)
+
+	client := c.client()
+	if client == nil {
+		return nil
+	}
+	iter := client.Scan(ctx, 0, c.redisKey("*"), 100).Iterator()
+	var redisKeys []string
+	for iter.Next(ctx) {
+		redisKeys = append(redisKeys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	pipe := client.Pipeline()
+	if len(redisKeys) > 0 {
+		pipe.Del(ctx, redisKeys...)
+	}
+	pipe.Publish(ctx, c.channel(), purgeAll)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Generation 失效代数，本实例或其他实例每次使条目失效后递增
+//
+// 由缓存数据派生的结构可据此判断是否需要重建
+func (c *Tiered[V]) Generation() uint64 {
+	return c.gen.Load()
+}
+
+// setLocal 仅在回源期间未发生失效时写入进程内缓存
+func (c *Tiered[V]) setLocal(gen uint64, key string, v V) {
+	if c.gen.Load() == gen {
+		c.local.Set(key, v)
+	}
+}
+
+// subscribe 订阅失效通知，确认订阅成功后在后台处理消息
+//
+// 订阅失败时仅依赖进程内条目的有效期；连接断开后由客户端自动重连
+func (c *Tiered[V]) subscribe(client *redis.Client) {
+	ctx := context.Background()
+	pubsub := client.Subscribe(ctx, c.channel())
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return
+	}
+	go func() {
+		defer pubsub.Close()
+		for msg := range pubsub.Channel() {
+			if msg.Payload == purgeAll {
+				c.local.Purge()
+			} else {
+				c.local.Delete(msg.Payload)
+			}
+			c.gen.Add(1)
+		}
+	}()
+}
+
+func (c *Tiered[V]) client() *redis.Client {
+	if c.opts.Client == nil {
+		return nil
+	}
+	return c.opts.Client()
+}
+
+func (c *Tiered[V]) redisKey(key string) string {
+	return c.opts.Name + ":" + key
+}
+
+func (c *Tiered[V]) channel() string {
+	return c.opts.Name + ":invalidate"
+}
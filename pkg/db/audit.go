@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 审计事件的操作类型
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditSkipCtxKey 上下文中标记不产生审计事件的键，值为 bool
+const AuditSkipCtxKey = "goadmin/audit_skip"
+
+// auditBeforeKey 语句实例设置项，保存更新、删除前的记录
+const auditBeforeKey = "goadmin:audit_before"
+
+// auditMask 敏感字段在变更中的显示值
+const auditMask = "******"
+
+// auditSkipColumns 不记录变更的字段，每次更新都会变化
+var auditSkipColumns = map[string]bool{"ctime": true, "mtime": true}
+
+// Audited 实现此接口的模型在创建、更新及删除时自动产生审计事件
+//
+// json 标签为 "-" 的字段视为敏感字段，只记录是否变更，不记录值
+type Audited interface {
+	// AuditResource 资源类型
+	AuditResource() string
+}
+
+// AuditChange 单个字段变更前后的值，创建时无 Before，删除时无 After
+type AuditChange struct {
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
+}
+
+// AuditEvent 审计事件，每条受影响的记录一个事件
+type AuditEvent struct {
+	Action     string                 // 操作类型 create update delete
+	Resource   string                 // 资源类型
+	ResourceID string                 // 资源ID，联合主键以逗号连接
+	TenantID   uint64                 // 记录所属租户，模型无租户字段时为上下文中的租户
+	Diff       map[string]AuditChange // 变更的字段，键为字段名
+	Err        error                  // 操作失败时的错误
+}
+
+// AuditSink 接收审计事件，在语句执行或事务提交后同步调用，不应长时间阻塞
+type AuditSink func(ctx context.Context, e *AuditEvent)
+
+// auditSink 当前的审计事件接收者
+var auditSink atomic.Pointer[AuditSink]
+
+// SetAuditSink 设置审计事件接收者，为 nil 时不产生事件
+func SetAuditSink(sink AuditSink) {
+	if sink == nil {
+		auditSink.Store(nil)
+		return
+	}
+	auditSink.Store(&sink)
+}
+
+// SkipAudit 不产生审计事件，用于最近使用时间等记账类更新
+func SkipAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, AuditSkipCtxKey, true)
+}
+
+// AuditPlugin 审计插件
+//
+// 为实现 Audited 的模型在创建、更新及删除后产生审计事件：更新、删除前按语句条件读取原记录，
+// 更新后按主键重新读取，逐条比较字段差异。事务内的事件在提交后发送，回滚时丢弃。
+// 原生 SQL 不产生事件，须由调用方通过 EmitAudit 发送
+type AuditPlugin struct{}
+
+// Name 实现 gorm.Plugin 接口
+func (AuditPlugin) Name() string {
+	return "goadmin:audit"
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (AuditPlugin) Initialize(db *gorm.DB) error {
+	// 包装连接池以感知事务的提交与回滚
+	if _, ok := db.ConnPool.(*auditPool); !ok {
+		db.ConnPool = &auditPool{ConnPool: db.ConnPool}
+		db.Statement.ConnPool = db.ConnPool
+	}
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("goadmin:audit_create", auditCreate); err != nil {
+		return err
+	}
+	// 读取原记录须在租户条件添加之后，与实际更新、删除的范围一致
+	err := cb.Update().Before("gorm:update").After("goadmin:tenant_update").
+		Register("goadmin:audit_before_update", auditLoadBefore)
+	if err != nil {
+		return err
+	}
+	if err = cb.Update().After("gorm:update").Register("goadmin:audit_update", auditUpdate); err != nil {
+		return err
+	}
+	err = cb.Delete().Before("gorm:delete").After("goadmin:tenant_delete").
+		Register("goadmin:audit_before_delete", auditLoadBefore)
+	if err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("goadmin:audit_delete", auditDelete)
+}
+
+// auditOf 语句涉及的模型需要审计时返回资源类型及事件接收者
+func auditOf(db *gorm.DB) (string, AuditSink, bool) {
+	sink := auditSink.Load()
+	stmt := db.Statement
+	if sink == nil || stmt.Schema == nil {
+		return "", nil, false
+	}
+	if skip, _ := stmt.Context.Value(AuditSkipCtxKey).(bool); skip {
+		return "", nil, false
+	}
+	audited, ok := reflect.New(stmt.Schema.ModelType).Interface().(Audited)
+	if !ok {
+		return "", nil, false
+	}
+	return audited.AuditResource(), *sink, true
+}
+
+// auditCreate 创建后逐条产生事件
+func auditCreate(db *gorm.DB) {
+	resource, sink, ok := auditOf(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	eachRecord(stmt.ReflectValue, func(rv reflect.Value) {
+		e := newAuditEvent(stmt, AuditCreate, resource, rv)
+		e.Diff = diffRecords(stmt, reflect.Value{}, rv)
+		e.Err = db.Error
+		emitAudit(db, sink, e)
+	})
+}
+
+// auditLoadBefore 更新、删除前按语句条件读取原记录
+func auditLoadBefore(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if _, _, ok := auditOf(db); !ok {
+		return
+	}
+	stmt := db.Statement
+	q := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	conditions := false
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			q.Statement.AddClause(where)
+			conditions = true
+		}
+	}
+	if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+		if id, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			q = q.Where(clause.Eq{Column: column(pk.DBName), Value: id})
+			conditions = true
+		}
+	}
+	// 没有条件的语句会被 gorm 拒绝，不读取全表
+	if !conditions {
+		return
+	}
+	before := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+	if err := q.Find(before.Interface()).Error; err != nil {
+		return
+	}
+	db.InstanceSet(auditBeforeKey, before.Elem())
+}
+
+// beforeRecords 更新、删除前读取的原记录
+func beforeRecords(db *gorm.DB) (reflect.Value, bool) {
+	v, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	rv := v.(reflect.Value)
+	return rv, rv.Len() > 0
+}
+
+// auditUpdate 更新后按主键重新读取记录，逐条产生事件
+func auditUpdate(db *gorm.DB) {
+	resource, sink, ok := auditOf(db)
+	if !ok {
+		return
+	}
+	before, ok := beforeRecords(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	after := map[string]reflect.Value{}
+	if db.Error == nil {
+		after = reloadRecords(db, before)
+	}
+	for i := 0; i < before.Len(); i++ {
+		old := before.Index(i).Elem()
+		e := newAuditEvent(stmt, AuditUpdate, resource, old)
+		e.Err = db.Error
+		if cur, ok := after[e.ResourceID]; ok {
+			e.Diff = diffRecords(stmt, old, cur)
+			// 记录未变化时不产生事件
+			if len(e.Diff) == 0 {
+				continue
+			}
+		}
+		emitAudit(db, sink, e)
+	}
+}
+
+// reloadRecords 按主键重新读取记录，以资源ID为键
+func reloadRecords(db *gorm.DB, before reflect.Value) map[string]reflect.Value {
+	stmt := db.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil
+	}
+	ids := make([]any, 0, before.Len())
+	for i := 0; i < before.Len(); i++ {
+		id, _ := pk.ValueOf(stmt.Context, before.Index(i).Elem())
+		ids = append(ids, id)
+	}
+	after := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Where(clause.IN{Column: column(pk.DBName), Values: ids}).Find(after.Interface()).Error
+	if err != nil {
+		return nil
+	}
+	records := make(map[string]reflect.Value, after.Elem().Len())
+	for i := 0; i < after.Elem().Len(); i++ {
+		rv := after.Elem().Index(i).Elem()
+		records[resourceID(stmt, rv)] = rv
+	}
+	return records
+}
+
+// auditDelete 删除后逐条产生事件
+func auditDelete(db *gorm.DB) {
+	resource, sink, ok := auditOf(db)
+	if !ok {
+		return
+	}
+	before, ok := beforeRecords(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	for i := 0; i < before.Len(); i++ {
+		old := before.Index(i).Elem()
+		e := newAuditEvent(stmt, AuditDelete, resource, old)
+		e.Diff = diffRecords(stmt, old, reflect.Value{})
+		e.Err = db.Error
+		emitAudit(db, sink, e)
+	}
+}
+
+// newAuditEvent 创建记录的审计事件
+func newAuditEvent(stmt *gorm.Statement, action, resource string, rv reflect.Value) *AuditEvent {
+	e := &AuditEvent{Action: action, Resource: resource, ResourceID: resourceID(stmt, rv)}
+	if field := stmt.Schema.LookUpField(tenantColumn); field != nil {
+		v, _ := field.ValueOf(stmt.Context, rv)
+		e.TenantID, _ = v.(uint64)
+	}
+	if e.TenantID == 0 {
+		e.TenantID, _ = TenantFrom(stmt.Context)
+	}
+	return e
+}
+
+// resourceID 记录的主键，联合主键以逗号连接
+func resourceID(stmt *gorm.Statement, rv reflect.Value) string {
+	ids := make([]string, 0, len(stmt.Schema.PrimaryFields))
+	for _, pk := range stmt.Schema.PrimaryFields {
+		v, _ := pk.ValueOf(stmt.Context, rv)
+		ids = append(ids, fmt.Sprint(v))
+	}
+	return strings.Join(ids, ",")
+}
+
+// diffRecords 比较记录变更前后的字段，old 无效时为创建，cur 无效时为删除
+func diffRecords(stmt *gorm.Statement, old, cur reflect.Value) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || auditSkipColumns[field.DBName] {
+			continue
+		}
+		var change AuditChange
+		switch {
+		case !old.IsValid():
+			v, zero := field.ValueOf(stmt.Context, cur)
+			if zero {
+				continue
+			}
+			change.After = v
+		case !cur.IsValid():
+			change.Before, _ = field.ValueOf(stmt.Context, old)
+		default:
+			change.Before, _ = field.ValueOf(stmt.Context, old)
+			change.After, _ = field.ValueOf(stmt.Context, cur)
+			if reflect.DeepEqual(change.Before, change.After) {
+				continue
+			}
+		}
+		if sensitive(field) {
+			change = maskChange(change)
+		}
+		diff[field.DBName] = change
+	}
+	return diff
+}
+
+// sensitive 是否为敏感字段
+func sensitive(field *schema.Field) bool {
+	return field.Tag.Get("json") == "-"
+}
+
+// maskChange 隐藏敏感字段的值
+func maskChange(c AuditChange) AuditChange {
+	if c.Before != nil {
+		c.Before = auditMask
+	}
+	if c.After != nil {
+		c.After = auditMask
+	}
+	return c
+}
+
+// eachRecord 遍历单条或批量记录
+func eachRecord(rv reflect.Value, fn func(reflect.Value)) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
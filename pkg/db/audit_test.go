@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"goadmin/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+// auditDoc 需要审计的记录
+type auditDoc struct {
+	ID       uint64 `gorm:"primaryKey"`
+	TenantID uint64
+	Title    string
+	Secret   string `json:"-"`
+}
+
+func (auditDoc) TableName() string { return "audit_docs" }
+
+func (auditDoc) TenantShared() bool { return false }
+
+func (auditDoc) AuditResource() string { return "doc" }
+
+// newAuditDB 注册租户隔离及审计插件，返回收集到的事件
+func newAuditDB(t *testing.T) (*gorm.DB, *[]*AuditEvent) {
+	t.Helper()
+	gdb := dbtest.Open(t, &auditDoc{}, &tenantDoc{})
+	if err := gdb.Use(TenantPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Use(AuditPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	events := &[]*AuditEvent{}
+	SetAuditSink(func(_ context.Context, e *AuditEvent) { *events = append(*events, e) })
+	t.Cleanup(func() { SetAuditSink(nil) })
+	return gdb, events
+}
+
+func TestAuditEvents(t *testing.T) {
+	gdb, events := newAuditDB(t)
+	repo := NewBaseRepository[auditDoc](gdb)
+	ctx := WithTenant(context.Background(), 1)
+
+	doc := &auditDoc{Title: "a", Secret: "s1"}
+	if err := repo.Create(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Title, doc.Secret = "b", "s2"
+	if err := repo.Update(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	// 未变化的更新不产生事件
+	if err := repo.Update(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	// 未实现 Audited 的模型不产生事件
+	if err := NewBaseRepository[tenantDoc](gdb).Create(ctx, &tenantDoc{Title: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*events) != 3 {
+		t.Fatalf("events = %d, want 3", len(*events))
+	}
+	created, updated, deleted := (*events)[0], (*events)[1], (*events)[2]
+	for i, want := range []string{AuditCreate, AuditUpdate, AuditDelete} {
+		e := (*events)[i]
+		if e.Action != want || e.Resource != "doc" || e.ResourceID != "1" || e.TenantID != 1 || e.Err != nil {
+			t.Errorf("event %d = %+v", i, e)
+		}
+	}
+	if c := created.Diff["title"]; c.Before != nil || c.After != "a" {
+		t.Errorf("create title = %+v", c)
+	}
+	if c := updated.Diff["title"]; c.Before != "a" || c.After != "b" {
+		t.Errorf("update title = %+v", c)
+	}
+	if c := updated.Diff["secret"]; c.Before != auditMask || c.After != auditMask {
+		t.Errorf("secret should be masked: %+v", c)
+	}
+	if _, ok := updated.Diff["tenant_id"]; ok || len(updated.Diff) != 2 {
+		t.Errorf("update diff = %+v, want title and secret", updated.Diff)
+	}
+	if c := deleted.Diff["title"]; c.Before != "b" || c.After != nil {
+		t.Errorf("delete title = %+v", c)
+	}
+}
+
+func TestAuditBulkUpdate(t *testing.T) {
+	gdb, events := newAuditDB(t)
+	docs := []auditDoc{{ID: 1, TenantID: 1, Title: "a"}, {ID: 2, TenantID: 1, Title: "b"}, {ID: 3, TenantID: 2, Title: "c"}}
+	if err := gdb.Create(&docs).Error; err != nil {
+		t.Fatal(err)
+	}
+	*events = nil
+
+	// 只记录当前租户内实际更新的记录
+	ctx := WithTenant(context.Background(), 1)
+	err := gdb.WithContext(ctx).Model(&auditDoc{}).Where("id > ?", 0).Update("title", "z").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 2 {
+		t.Fatalf("events = %d, want 2", len(*events))
+	}
+	for _, e := range *events {
+		if e.TenantID != 1 || e.Diff["title"].After != "z" {
+			t.Errorf("event = %+v", e)
+		}
+	}
+
+	// 原生 SQL 不产生事件
+	*events = nil
+	if err = gdb.Exec("UPDATE audit_docs SET title = ?", "y").Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 0 {
+		t.Errorf("raw sql events = %d, want 0", len(*events))
+	}
+}
+
+func TestAuditTransaction(t *testing.T) {
+	gdb, events := newAuditDB(t)
+	ctx := WithTenant(context.Background(), 1)
+	errRollback := errors.New("rollback")
+
+	// 回滚的事务不产生事件
+	err := gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&auditDoc{Title: "a"}).Error; err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+	if len(*events) != 0 {
+		t.Fatalf("events after rollback = %d, want 0", len(*events))
+	}
+
+	// 提交前不发送，提交后按顺序发送；回滚到保存点的嵌套事务不产生事件
+	err = gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&auditDoc{Title: "b"}).Error; err != nil {
+			return err
+		}
+		_ = tx.Transaction(func(nested *gorm.DB) error {
+			if err := nested.Create(&auditDoc{Title: "c"}).Error; err != nil {
+				return err
+			}
+			return errRollback
+		})
+		EmitAudit(tx, &AuditEvent{Action: AuditDelete, Resource: "raw", ResourceID: "9"})
+		if len(*events) != 0 {
+			t.Errorf("events before commit = %d, want 0", len(*events))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 2 {
+		t.Fatalf("events after commit = %d, want 2", len(*events))
+	}
+	if e := (*events)[0]; e.Action != AuditCreate || e.Diff["title"].After != "b" {
+		t.Errorf("event 0 = %+v", e)
+	}
+	if e := (*events)[1]; e.Resource != "raw" || e.ResourceID != "9" {
+		t.Errorf("event 1 = %+v", e)
+	}
+
+	// 事务外的语句立即发送，底层连接仍可取得
+	*events = nil
+	if err = gdb.WithContext(ctx).Create(&auditDoc{Title: "d"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 1 {
+		t.Errorf("events = %d, want 1", len(*events))
+	}
+	if _, err = gdb.DB(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 嵌套事务的保存点语句，gorm 各方言均以此形式执行
+const (
+	savepointPrefix  = "SAVEPOINT "
+	rollbackToPrefix = "ROLLBACK TO SAVEPOINT "
+	releaseSavepoint = "RELEASE SAVEPOINT "
+)
+
+// auditPool 包装连接池，使事务内的审计事件在提交后才发送
+type auditPool struct {
+	gorm.ConnPool
+}
+
+// BeginTx 实现 gorm.ConnPoolBeginner 接口
+func (p *auditPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auditTx{ConnPool: tx, pool: p}, nil
+}
+
+// GetDBConn 实现 gorm.GetDBConnector 接口，gorm.DB.DB() 据此取得底层连接
+func (p *auditPool) GetDBConn() (*sql.DB, error) {
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	if sqlDB, ok := p.ConnPool.(*sql.DB); ok {
+		return sqlDB, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// pendingAudit 等待事务提交的审计事件
+type pendingAudit struct {
+	ctx  context.Context
+	sink AuditSink
+	e    *AuditEvent
+}
+
+// auditTx 缓存事务内的审计事件，提交后按顺序发送，回滚时丢弃
+//
+// 嵌套事务回滚到保存点时同时丢弃保存点之后的事件
+type auditTx struct {
+	gorm.ConnPool
+	pool *auditPool
+
+	mu         sync.Mutex
+	events     []pendingAudit
+	savepoints map[string]int
+}
+
+// add 缓存审计事件
+func (t *auditTx) add(ctx context.Context, sink AuditSink, e *AuditEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, pendingAudit{ctx: ctx, sink: sink, e: e})
+}
+
+// take 取出并清空缓存的事件
+func (t *auditTx) take() []pendingAudit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.events
+	t.events, t.savepoints = nil, nil
+	return events
+}
+
+// ExecContext 记录保存点位置，回滚到保存点时丢弃其后的事件
+func (t *auditTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := t.ConnPool.ExecContext(ctx, query, args...)
+	if err != nil {
+		return res, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, rollbackToPrefix):
+		if n, ok := t.savepoints[strings.TrimPrefix(query, rollbackToPrefix)]; ok && n <= len(t.events) {
+			t.events = t.events[:n]
+		}
+	case strings.HasPrefix(query, releaseSavepoint):
+		delete(t.savepoints, strings.TrimPrefix(query, releaseSavepoint))
+	case strings.HasPrefix(query, savepointPrefix):
+		if t.savepoints == nil {
+			t.savepoints = make(map[string]int)
+		}
+		t.savepoints[strings.TrimPrefix(query, savepointPrefix)] = len(t.events)
+	}
+	return res, nil
+}
+
+// Commit 实现 gorm.TxCommitter 接口，提交成功后发送缓存的事件
+func (t *auditTx) Commit() error {
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	err := committer.Commit()
+	events := t.take()
+	if err != nil {
+		return err
+	}
+	for _, p := range events {
+		p.sink(p.ctx, p.e)
+	}
+	return nil
+}
+
+// Rollback 实现 gorm.TxCommitter 接口，丢弃缓存的事件
+func (t *auditTx) Rollback() error {
+	t.take()
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	return committer.Rollback()
+}
+
+// GetDBConn 实现 gorm.GetDBConnector 接口
+func (t *auditTx) GetDBConn() (*sql.DB, error) {
+	return t.pool.GetDBConn()
+}
+
+// emitAudit 发送审计事件
+//
+// 事务内成功的变更缓存到提交后发送，回滚时丢弃；失败的操作未改变数据，立即发送
+func emitAudit(db *gorm.DB, sink AuditSink, e *AuditEvent) {
+	if tx, ok := db.Statement.ConnPool.(*auditTx); ok && e.Err == nil {
+		tx.add(db.Statement.Context, sink, e)
+		return
+	}
+	sink(db.Statement.Context, e)
+}
+
+// EmitAudit 为原生 SQL 等插件无法感知的变更发送审计事件，未设置接收者或上下文标记跳过审计时忽略
+//
+// tx 为执行变更的会话，处于事务中时事件在提交后发送
+func EmitAudit(tx *gorm.DB, e *AuditEvent) {
+	sink := auditSink.Load()
+	if sink == nil {
+		return
+	}
+	if skip, _ := tx.Statement.Context.Value(AuditSkipCtxKey).(bool); skip {
+		return
+	}
+	emitAudit(tx, *sink, e)
+}
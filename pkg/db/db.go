@@ -33,6 +33,16 @@ func Init(dbCfg *config.DatabaseConfig) error {
 		return fmt.Errorf("初始化主库失败: %w", err)
 	}
 
+	// 注册租户隔离插件
+	if err = DB.Use(TenantPlugin{}); err != nil {
+		return fmt.Errorf("注册租户插件失败: %w", err)
+	}
+
+	// 注册审计插件
+	if err = DB.Use(AuditPlugin{}); err != nil {
+		return fmt.Errorf("注册审计插件失败: %w", err)
+	}
+
 	// 如果配置了从库，添加数据库解析器
 	if len(dbCfg.Slaves) > 0 {
 		resolverCfg := dbresolver.Config{
@@ -0,0 +1,31 @@
+// Package dbtest 单元测试使用的 sqlite 内存数据库
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Open 打开内存数据库并按 models 建表
+//
+// 内存数据库每个连接独立，限制为单连接，否则不同连接看到的是不同的库
+func Open(tb testing.TB, models ...any) *gorm.DB {
+	tb.Helper()
+	gdb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if len(models) > 0 {
+		if err = gdb.AutoMigrate(models...); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return gdb
+}
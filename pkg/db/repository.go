@@ -2,12 +2,15 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	"gorm.io/gorm"
 )
 
 var (
 	ErrRecordNotFound = gorm.ErrRecordNotFound
+	// ErrOutOfScope 记录不在当前数据范围内
+	ErrOutOfScope = errors.New("record out of data scope")
 )
 
 // Model 定义了基础模型接口
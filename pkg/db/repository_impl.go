@@ -3,11 +3,16 @@ package db
 import (
 	"context"
 	"errors"
+	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BaseRepository 通用仓储实现
+//
+// 模型实现 Scoped 时，查询、更新及删除均按上下文中的数据范围过滤；
+// 实现 TenantOwned 时由 TenantPlugin 按当前租户隔离
 type BaseRepository[T Model] struct {
 	db *gorm.DB
 }
@@ -27,7 +32,7 @@ func (r *BaseRepository[T]) Create(ctx context.Context, model *T) error {
 // GetByID 根据ID获取记录
 func (r *BaseRepository[T]) GetByID(ctx context.Context, id uint64) (*T, error) {
 	var model T
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	err := r.Scoped(ctx).Where("id = ?", id).First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -53,7 +58,7 @@ func (r *BaseRepository[T]) List(ctx context.Context, page, pageSize int, opts .
 	)
 
 	// 构建查询
-	query := r.db.WithContext(ctx).Model(new(T))
+	query := r.Scoped(ctx).Model(new(T))
 	query = r.applyOptions(query, opts...)
 
 	// 获取总数
@@ -86,7 +91,7 @@ func (r *BaseRepository[T]) Find(ctx context.Context, opts ...QueryOption[T]) ([
 	var models []*T
 
 	// 构建查询
-	query := r.db.WithContext(ctx)
+	query := r.Scoped(ctx)
 	query = r.applyOptions(query, opts...)
 
 	// 查询数据
@@ -97,20 +102,62 @@ func (r *BaseRepository[T]) Find(ctx context.Context, opts ...QueryOption[T]) ([
 // Exists 根据条件判断数据是否存在
 func (r *BaseRepository[T]) Exists(ctx context.Context, opts ...QueryOption[T]) (bool, error) {
 	var exists bool
-	sub := r.db.Model(new(T)).Select("1")
+	sub := r.Scoped(ctx).Model(new(T)).Select("1")
 	sub = r.applyOptions(sub, opts...)
-	err := r.db.WithContext(ctx).Select("EXISTS(?)", sub).Scan(&exists).Error
+	err := r.db.WithContext(ctx).Raw("SELECT EXISTS(?)", sub).Scan(&exists).Error
 	return exists, err
 }
 
-// Update 更新记录
+// Update 更新记录，记录不在数据范围或当前租户内时返回 ErrOutOfScope
 func (r *BaseRepository[T]) Update(ctx context.Context, model *T) error {
+	// Save 更新不到记录时会转为插入，须先确认记录可写
+	if r.restricted(ctx, model) {
+		visible, err := r.visible(ctx, model)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			return ErrOutOfScope
+		}
+	}
 	return r.db.WithContext(ctx).Save(model).Error
 }
 
+// restricted 记录是否受数据范围或租户隔离限制
+func (r *BaseRepository[T]) restricted(ctx context.Context, model *T) bool {
+	if _, ok := any(model).(Scoped); ok && !ScopeFrom(ctx).Unrestricted() {
+		return true
+	}
+	if _, ok := any(model).(TenantOwned); ok {
+		_, ok = TenantFrom(ctx)
+		return ok
+	}
+	return false
+}
+
+// visible 记录是否在数据范围内且属于当前租户，共享记录视为不可写
+func (r *BaseRepository[T]) visible(ctx context.Context, model *T) (bool, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(model); err != nil {
+		return false, err
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return false, nil
+	}
+	id, zero := pk.ValueOf(ctx, reflect.ValueOf(model).Elem())
+	if zero {
+		return false, nil
+	}
+	var count int64
+	err := r.Scoped(ctx).Set(tenantStrictKey, true).Model(new(T)).
+		Where(clause.Eq{Column: column(pk.DBName), Value: id}).Count(&count).Error
+	return count > 0, err
+}
+
 // Delete 删除记录
 func (r *BaseRepository[T]) Delete(ctx context.Context, id uint64) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Delete(new(T)).Error
+	return r.Scoped(ctx).Where("id = ?", id).Delete(new(T)).Error
 }
 
 // BatchCreate 批量创建记录
@@ -126,7 +173,7 @@ func (r *BaseRepository[T]) BatchDelete(ctx context.Context, ids []uint64) error
 	if len(ids) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(new(T)).Error
+	return r.Scoped(ctx).Where("id IN ?", ids).Delete(new(T)).Error
 }
 
 // GetByIDs 根据ID列表获取多条记录
@@ -135,7 +182,7 @@ func (r *BaseRepository[T]) GetByIDs(ctx context.Context, ids []uint64) ([]*T, e
 		return nil, nil
 	}
 	var models []*T
-	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&models).Error
+	err := r.Scoped(ctx).Where("id IN ?", ids).Find(&models).Error
 	return models, err
 }
 
@@ -144,7 +191,7 @@ func (r *BaseRepository[T]) Count(ctx context.Context, opts ...QueryOption[T]) (
 	var count int64
 
 	// 构建查询
-	query := r.db.WithContext(ctx).Model(new(T))
+	query := r.Scoped(ctx).Model(new(T))
 	query = r.applyOptions(query, opts...)
 
 	// 获取总数
@@ -159,7 +206,12 @@ func (r *BaseRepository[T]) WithTx(tx *gorm.DB) *BaseRepository[T] {
 	}
 }
 
-// DB 获取数据库实例
+// DB 获取数据库实例，不受数据范围限制
 func (r *BaseRepository[T]) DB() *gorm.DB {
 	return r.db
 }
+
+// Scoped 按上下文中的数据范围过滤的数据库实例，仓储自定义的查询、更新及删除应以此为起点
+func (r *BaseRepository[T]) Scoped(ctx context.Context) *gorm.DB {
+	return DataScope[T](ctx)(r.db.WithContext(ctx))
+}
@@ -0,0 +1,283 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScopeCtxKey 上下文中保存数据范围的键
+//
+// 使用字符串键，gin.Context 可直接通过 Set 注入，仓储收到 gin 上下文时即可取到
+const ScopeCtxKey = "goadmin/data_scope"
+
+// ScopeKind 数据范围类型
+type ScopeKind int8
+
+const (
+	ScopeAll    ScopeKind = iota + 1 // 全部数据
+	ScopeTenant                      // 本租户数据
+	ScopeDept                        // 本部门数据
+	ScopeSelf                        // 本人数据
+	ScopeCustom                      // 自定义条件
+)
+
+// IsValid 是否为已知的范围类型
+func (k ScopeKind) IsValid() bool {
+	return k >= ScopeAll && k <= ScopeCustom
+}
+
+// 模型没有租户或部门字段时，按所有者在用户表中的租户、部门过滤
+const (
+	scopeUserTable    = "users"
+	scopeUserTenantID = "tenant_id"
+	scopeUserDeptID   = "dept_id"
+)
+
+// 自定义条件的值可引用当前用户的属性
+const (
+	VarUserID   = "$user_id"
+	VarTenantID = "$tenant_id"
+	VarDeptID   = "$dept_id"
+)
+
+// Columns 模型参与数据范围过滤的字段
+type Columns struct {
+	Owner  string // 所有者字段，本人数据按此字段匹配当前用户ID
+	Tenant string // 租户字段，为空时按所有者所属租户过滤
+	Dept   string // 部门字段，为空时按所有者所属部门过滤
+}
+
+// Scoped 实现此接口的模型受数据范围限制
+type Scoped interface {
+	ScopeColumns() Columns
+}
+
+// Condition 自定义数据范围条件
+type Condition struct {
+	Field string `json:"field"` // 模型字段
+	Op    string `json:"op"`    // eq ne gt gte lt lte in like
+	Value any    `json:"value"` // 值，可使用 $user_id $tenant_id $dept_id 引用当前用户属性
+}
+
+// Rule 单个角色的数据范围
+type Rule struct {
+	Kind       ScopeKind
+	Conditions []Condition // 自定义条件，须全部满足
+}
+
+// Scope 当前请求的数据范围，满足任一规则的记录可见
+type Scope struct {
+	UserID   uint64
+	TenantID uint64
+	DeptID   uint64
+	Rules    []Rule
+}
+
+// Unrestricted 是否可访问全部数据
+func (s *Scope) Unrestricted() bool {
+	if s == nil {
+		return true
+	}
+	for _, r := range s.Rules {
+		if r.Kind == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// WithScope 将数据范围写入上下文
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, ScopeCtxKey, s)
+}
+
+// SkipScope 跳过数据范围，用于唯一性校验等须查询全表的场景
+func SkipScope(ctx context.Context) context.Context {
+	return WithScope(ctx, nil)
+}
+
+// ScopeFrom 获取上下文中的数据范围，为空表示不限制（如命令行、定时任务）
+func ScopeFrom(ctx context.Context) *Scope {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(ScopeCtxKey).(*Scope)
+	return s
+}
+
+// DataScope 按上下文中的数据范围过滤的查询选项，模型未实现 Scoped 时不过滤
+func DataScope[T Model](ctx context.Context) QueryOption[T] {
+	s := ScopeFrom(ctx)
+	scoped, ok := any(new(T)).(Scoped)
+	if !ok || s.Unrestricted() {
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+	cols := scoped.ScopeColumns()
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(s.expression(db, new(T), cols))
+	}
+}
+
+// denyAll 不匹配任何记录
+var denyAll = clause.Expr{SQL: "1 = 0"}
+
+// expression 各规则条件以 OR 连接，本人所有的记录始终可见
+func (s *Scope) expression(db *gorm.DB, model any, cols Columns) clause.Expression {
+	exprs := make([]clause.Expression, 0, len(s.Rules)+1)
+	if cols.Owner != "" && s.UserID > 0 {
+		exprs = append(exprs, s.ruleExpression(db, model, cols, Rule{Kind: ScopeSelf}))
+	}
+	for _, r := range s.Rules {
+		exprs = append(exprs, s.ruleExpression(db, model, cols, r))
+	}
+	if len(exprs) == 0 {
+		return denyAll
+	}
+	return clause.Or(exprs...)
+}
+
+// ruleExpression 单条规则的条件，模型不支持该范围时不匹配任何记录
+func (s *Scope) ruleExpression(db *gorm.DB, model any, cols Columns, r Rule) clause.Expression {
+	switch r.Kind {
+	case ScopeSelf:
+		if cols.Owner != "" {
+			return clause.Eq{Column: column(cols.Owner), Value: s.UserID}
+		}
+	case ScopeTenant:
+		return memberExpression(cols.Tenant, cols.Owner, scopeUserTenantID, s.TenantID)
+	case ScopeDept:
+		return memberExpression(cols.Dept, cols.Owner, scopeUserDeptID, s.DeptID)
+	case ScopeCustom:
+		return s.customExpression(db, model, r.Conditions)
+	}
+	return denyAll
+}
+
+// memberExpression 优先使用模型自身的租户/部门字段，否则按所有者所属的租户/部门过滤
+func memberExpression(own, owner, userColumn string, value uint64) clause.Expression {
+	switch {
+	case own != "":
+		return clause.Eq{Column: column(own), Value: value}
+	case owner != "":
+		return clause.Expr{
+			SQL: "? IN (SELECT id FROM ? WHERE ? = ?)",
+			Vars: []any{
+				column(owner), clause.Table{Name: scopeUserTable}, clause.Column{Name: userColumn}, value,
+			},
+		}
+	}
+	return denyAll
+}
+
+// customExpression 自定义条件以 AND 连接，字段须为模型中存在的字段
+func (s *Scope) customExpression(db *gorm.DB, model any, conds []Condition) clause.Expression {
+	if len(conds) == 0 {
+		return denyAll
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return denyAll
+	}
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, c := range conds {
+		field := stmt.Schema.LookUpField(c.Field)
+		if field == nil || field.DBName == "" {
+			return denyAll
+		}
+		col := column(field.DBName)
+		value := s.resolve(c.Value)
+		switch c.Op {
+		case "eq":
+			exprs = append(exprs, clause.Eq{Column: col, Value: value})
+		case "ne":
+			exprs = append(exprs, clause.Neq{Column: col, Value: value})
+		case "gt":
+			exprs = append(exprs, clause.Gt{Column: col, Value: value})
+		case "gte":
+			exprs = append(exprs, clause.Gte{Column: col, Value: value})
+		case "lt":
+			exprs = append(exprs, clause.Lt{Column: col, Value: value})
+		case "lte":
+			exprs = append(exprs, clause.Lte{Column: col, Value: value})
+		case "like":
+			exprs = append(exprs, clause.Like{Column: col, Value: value})
+		case "in":
+			values, ok := value.([]any)
+			if !ok || len(values) == 0 {
+				return denyAll
+			}
+			exprs = append(exprs, clause.IN{Column: col, Values: values})
+		default:
+			return denyAll
+		}
+	}
+	return clause.And(exprs...)
+}
+
+// resolve 替换引用当前用户属性的值
+func (s *Scope) resolve(value any) any {
+	switch v := value.(type) {
+	case string:
+		switch v {
+		case VarUserID:
+			return s.UserID
+		case VarTenantID:
+			return s.TenantID
+		case VarDeptID:
+			return s.DeptID
+		}
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			values = append(values, s.resolve(item))
+		}
+		return values
+	}
+	return value
+}
+
+// column 当前表的字段，避免关联查询时字段名歧义
+func column(name string) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
+
+var (
+	fieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	conditionOps = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "like"}
+)
+
+// ParseConditions 解析并校验自定义数据范围条件（JSON 数组）
+//
+// 字段是否存在与具体模型有关，在查询时校验，模型中不存在的字段使该规则不匹配任何记录
+func ParseConditions(s string) ([]Condition, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("empty conditions")
+	}
+	var conds []Condition
+	if err := json.Unmarshal([]byte(s), &conds); err != nil {
+		return nil, err
+	}
+	if len(conds) == 0 {
+		return nil, errors.New("empty conditions")
+	}
+	for _, c := range conds {
+		if !fieldPattern.MatchString(c.Field) {
+			return nil, fmt.Errorf("invalid field %q", c.Field)
+		}
+		if !slices.Contains(conditionOps, c.Op) {
+			return nil, fmt.Errorf("invalid op %q", c.Op)
+		}
+		if _, ok := c.Value.([]any); ok != (c.Op == "in") {
+			return nil, fmt.Errorf("invalid value for op %q", c.Op)
+		}
+	}
+	return conds, nil
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TenantCtxKey 上下文中保存当前租户ID的键，值为 uint64
+//
+// 与 ScopeCtxKey 相同，使用字符串键以便通过 gin.Context 的 Set 注入
+const TenantCtxKey = "goadmin/tenant"
+
+// tenantColumn 租户隔离字段
+const tenantColumn = "tenant_id"
+
+// tenantStrictKey 语句设置项，为 true 时共享记录同样不可见，用于写入前的可见性检查
+const tenantStrictKey = "goadmin:tenant_strict"
+
+// TenantOwned 实现此接口的模型按租户隔离，表中须有 tenant_id 字段
+type TenantOwned interface {
+	// TenantShared 租户ID为 0 的记录是否对各租户只读可见，如内置角色、全局设置
+	TenantShared() bool
+}
+
+// WithTenant 将当前租户写入上下文
+func WithTenant(ctx context.Context, tenantID uint64) context.Context {
+	return context.WithValue(ctx, TenantCtxKey, tenantID)
+}
+
+// SkipTenant 跳过租户隔离，用于全局唯一性校验等须查询全部租户的场景
+func SkipTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, TenantCtxKey, nil)
+}
+
+// TenantFrom 获取上下文中的租户，未设置时表示不隔离（如超级管理员、命令行、定时任务）
+func TenantFrom(ctx context.Context) (uint64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(TenantCtxKey).(uint64)
+	return id, ok
+}
+
+// TenantPlugin 租户隔离插件
+//
+// 按上下文中的租户过滤实现 TenantOwned 的模型的查询、更新及删除，创建时写入当前租户
+type TenantPlugin struct{}
+
+// Name 实现 gorm.Plugin 接口
+func (TenantPlugin) Name() string {
+	return "goadmin:tenant"
+}
+
+// Initialize 实现 gorm.Plugin 接口
+func (TenantPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("goadmin:tenant_create", tenantCreate); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("goadmin:tenant_query", tenantQuery); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("goadmin:tenant_row", tenantQuery); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("goadmin:tenant_update", tenantWrite); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("goadmin:tenant_delete", tenantWrite)
+}
+
+// tenantOf 语句涉及的模型受租户隔离时返回当前租户及模型
+func tenantOf(db *gorm.DB) (uint64, TenantOwned, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.Schema.LookUpField(tenantColumn) == nil {
+		return 0, nil, false
+	}
+	owned, ok := reflect.New(stmt.Schema.ModelType).Interface().(TenantOwned)
+	if !ok {
+		return 0, nil, false
+	}
+	id, ok := TenantFrom(stmt.Context)
+	return id, owned, ok
+}
+
+// tenantQuery 查询时只返回当前租户及共享的记录
+func tenantQuery(db *gorm.DB) {
+	// 原生 SQL 不做处理
+	if db.Error != nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+	id, owned, ok := tenantOf(db)
+	if !ok {
+		return
+	}
+	strict, _ := db.Get(tenantStrictKey)
+	addTenantClause(db, id, owned.TenantShared() && strict != true)
+}
+
+// tenantWrite 更新、删除时只作用于当前租户的记录，共享记录只读
+func tenantWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+	id, _, ok := tenantOf(db)
+	if !ok || !hasConditions(db.Statement) {
+		return
+	}
+	addTenantClause(db, id, false)
+}
+
+// tenantCreate 创建时写入当前租户
+func tenantCreate(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	id, _, ok := tenantOf(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantColumn)
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(db.Statement.Context, reflect.Indirect(rv.Index(i)), id); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(db.Statement.Context, rv, id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
+
+// addTenantClause 添加租户条件，shared 为 true 时包含租户ID为 0 的共享记录
+func addTenantClause(db *gorm.DB, id uint64, shared bool) {
+	var expr clause.Expression = clause.Eq{Column: column(tenantColumn), Value: id}
+	if shared && id != 0 {
+		expr = clause.IN{Column: column(tenantColumn), Values: []any{uint64(0), id}}
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+}
+
+// hasConditions 更新、删除语句是否已有条件或主键
+//
+// 没有条件的语句不添加租户条件，以免绕过 gorm 对全表更新、删除的检查
+func hasConditions(stmt *gorm.Statement) bool {
+	if _, ok := stmt.Clauses["WHERE"]; ok {
+		return true
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil || stmt.ReflectValue.Kind() != reflect.Struct {
+		return false
+	}
+	_, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue)
+	return !zero
+}
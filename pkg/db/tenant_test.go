@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"goadmin/pkg/db/dbtest"
+
+	"gorm.io/gorm"
+)
+
+// tenantDoc 按租户隔离的记录
+type tenantDoc struct {
+	ID       uint64 `gorm:"primaryKey"`
+	TenantID uint64
+	Title    string
+}
+
+func (tenantDoc) TableName() string { return "tenant_docs" }
+
+func (tenantDoc) TenantShared() bool { return false }
+
+// tenantRole 租户 0 的记录各租户共享
+type tenantRole struct {
+	ID       uint64 `gorm:"primaryKey"`
+	TenantID uint64
+	Code     string
+}
+
+func (tenantRole) TableName() string { return "tenant_roles" }
+
+func (tenantRole) TenantShared() bool { return true }
+
+// newTenantDB 租户 1、2 各有一篇文档和一个角色，另有一个平台内置角色
+func newTenantDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb := dbtest.Open(t, &tenantDoc{}, &tenantRole{})
+	if err := gdb.Use(TenantPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	docs := []tenantDoc{{ID: 1, TenantID: 1, Title: "a"}, {ID: 2, TenantID: 2, Title: "b"}}
+	roles := []tenantRole{{ID: 1, Code: "operator"}, {ID: 2, TenantID: 1, Code: "t1"}, {ID: 3, TenantID: 2, Code: "t2"}}
+	if err := gdb.Create(&docs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Create(&roles).Error; err != nil {
+		t.Fatal(err)
+	}
+	return gdb
+}
+
+func TestTenantQuery(t *testing.T) {
+	gdb := newTenantDB(t)
+	docs := NewBaseRepository[tenantDoc](gdb)
+	roles := NewBaseRepository[tenantRole](gdb)
+	ctx := WithTenant(context.Background(), 1)
+
+	if n, _ := docs.Count(ctx); n != 1 {
+		t.Errorf("tenant docs = %d, want 1", n)
+	}
+	if d, err := docs.GetByID(ctx, 2); err != nil || d != nil {
+		t.Errorf("doc of other tenant should be hidden: %v %v", d, err)
+	}
+	if ok, err := docs.Exists(ctx, Where[tenantDoc]("id = ?", 2)); err != nil || ok {
+		t.Errorf("Exists(2) = %v %v, want false", ok, err)
+	}
+	// 共享记录可读
+	list, err := roles.Find(ctx, Order[tenantRole]("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Code != "operator" || list[1].Code != "t1" {
+		t.Errorf("roles = %+v, want operator and t1", list)
+	}
+	// 未限定租户或跳过隔离时可访问全部
+	for _, c := range []context.Context{context.Background(), SkipTenant(ctx)} {
+		if n, _ := docs.Count(c); n != 2 {
+			t.Errorf("all docs = %d, want 2", n)
+		}
+	}
+}
+
+func TestTenantWrite(t *testing.T) {
+	gdb := newTenantDB(t)
+	docs := NewBaseRepository[tenantDoc](gdb)
+	roles := NewBaseRepository[tenantRole](gdb)
+	ctx := WithTenant(context.Background(), 1)
+
+	// 创建时写入当前租户
+	d := &tenantDoc{ID: 3, TenantID: 2, Title: "c"}
+	if err := docs.Create(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	if d.TenantID != 1 {
+		t.Errorf("created tenant = %d, want 1", d.TenantID)
+	}
+
+	if err := docs.Update(ctx, &tenantDoc{ID: 2, TenantID: 1, Title: "x"}); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("update other tenant err = %v, want ErrOutOfScope", err)
+	}
+	if err := roles.Update(ctx, &tenantRole{ID: 1, Code: "x"}); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("update shared err = %v, want ErrOutOfScope", err)
+	}
+	if err := roles.Update(ctx, &tenantRole{ID: 2, TenantID: 1, Code: "t1x"}); err != nil {
+		t.Errorf("update own role: %v", err)
+	}
+	if err := roles.BatchDelete(ctx, []uint64{1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := roles.Count(context.Background()); n != 3 {
+		t.Errorf("roles = %d, want 3 (shared and other tenant kept)", n)
+	}
+
+	// 没有条件的更新仍由 gorm 拦截
+	err := gdb.WithContext(ctx).Model(&tenantDoc{}).Update("title", "y").Error
+	if !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("global update err = %v, want ErrMissingWhereClause", err)
+	}
+}
@@ -0,0 +1,53 @@
+// Package export 表格导出：逐行写入 CSV 或 XLSX 文件，调用方分批读取数据写入，不必在内存中保留全部行
+package export
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ErrFormat 不支持的导出格式
+var ErrFormat = errors.New("export: unsupported format")
+
+// Writer 表格写入器，写入的第一行为表头
+type Writer interface {
+	// Write 写入一行
+	Write(row []string) error
+
+	// Close 写完剩余数据，不关闭底层的 io.Writer
+	Close() error
+}
+
+// NewWriter 创建指定格式的表格写入器
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, ErrFormat
+	}
+}
+
+// Valid 是否为支持的导出格式
+func Valid(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// ContentType 导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]string{
+		{"用户名", "备注"},
+		{"alice", "=HYPERLINK(\"x\")"},
+		{"bob", "-12.5"},
+		{"carol", "a,\"b\""},
+	}
+	for _, row := range rows {
+		if err = w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := strings.CutPrefix(buf.String(), utf8BOM)
+	if !ok {
+		t.Fatal("missing UTF-8 BOM")
+	}
+	got, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"用户名", "备注"},
+		{"alice", "'=HYPERLINK(\"x\")"},
+		{"bob", "-12.5"},
+		{"carol", "a,\"b\""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestXLSXWriterSplitsSheets(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newXLSXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.sheetRows = 3
+	for _, row := range [][]string{{"id", "name"}, {"1", "a"}, {"2", "b"}, {"3", "=c"}} {
+		if err = w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Sheet1", "Sheet2"}) {
+		t.Fatalf("sheets = %v", sheets)
+	}
+	first, _ := f.GetRows("Sheet1")
+	second, _ := f.GetRows("Sheet2")
+	if !reflect.DeepEqual(first, [][]string{{"id", "name"}, {"1", "a"}, {"2", "b"}}) {
+		t.Errorf("Sheet1 = %q", first)
+	}
+	// 新工作表重复表头，文本原样写入
+	if !reflect.DeepEqual(second, [][]string{{"id", "name"}, {"3", "=c"}}) {
+		t.Errorf("Sheet2 = %q", second)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", &bytes.Buffer{}); err != ErrFormat {
+		t.Errorf("err = %v, want ErrFormat", err)
+	}
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxWriter XLSX 写入器
+//
+// 使用 excelize 的流式写入，超出一定大小的行数据暂存到临时文件；
+// 行数超过单个工作表的上限时续写到新的工作表，并重复表头
+type xlsxWriter struct {
+	w         io.Writer
+	f         *excelize.File
+	sw        *excelize.StreamWriter
+	header    []any
+	headStyle int
+	sheetRows int // 单个工作表的最大行数，含表头
+	sheets    int
+	row       int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{w: w, f: f, headStyle: style, sheetRows: excelize.TotalRows}, nil
+}
+
+// Write 实现 Writer 接口
+func (x *xlsxWriter) Write(row []string) error {
+	values := make([]any, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	if x.header == nil {
+		x.header = make([]any, len(row))
+		for i, v := range row {
+			x.header[i] = excelize.Cell{StyleID: x.headStyle, Value: v}
+		}
+		return x.nextSheet()
+	}
+	if x.row >= x.sheetRows {
+		if err := x.nextSheet(); err != nil {
+			return err
+		}
+	}
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.sw.SetRow(cell, values)
+}
+
+// nextSheet 写完当前工作表，在新的工作表中写入表头并冻结首行
+func (x *xlsxWriter) nextSheet() error {
+	if x.sw != nil {
+		if err := x.sw.Flush(); err != nil {
+			return err
+		}
+	}
+	x.sheets++
+	name := fmt.Sprintf("Sheet%d", x.sheets)
+	if x.sheets > 1 {
+		if _, err := x.f.NewSheet(name); err != nil {
+			return err
+		}
+	}
+	sw, err := x.f.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	err = sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	if err != nil {
+		return err
+	}
+	if err = sw.SetRow("A1", x.header); err != nil {
+		return err
+	}
+	x.sw, x.row = sw, 1
+	return nil
+}
+
+// Close 实现 Writer 接口，未写入任何行时生成空的工作簿
+func (x *xlsxWriter) Close() error {
+	defer x.f.Close()
+	if x.sw != nil {
+		if err := x.sw.Flush(); err != nil {
+			return err
+		}
+	}
+	return x.f.Write(x.w)
+}
@@ -0,0 +1,239 @@
+// Package jsonschema 实现 JSON Schema 的常用子集，用于校验配置类 JSON
+//
+// 支持的关键字：type、properties、required、additionalProperties（布尔值）、
+// items、enum、minLength、maxLength、pattern、minimum、maximum
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// Schema 已编译的 JSON Schema
+type Schema struct {
+	Type                 string             `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// ValidationError 校验失败的位置及原因
+type ValidationError struct {
+	Path    string // 出错字段路径，如 /system_config/language
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Compile 解析并编译 Schema
+func Compile(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MustCompile 同 Compile，出错时 panic，用于内置 Schema
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *Schema) compile() error {
+	switch s.Type {
+	case "", "object", "array", "string", "integer", "number", "boolean", "null":
+	default:
+		return fmt.Errorf("unsupported type %q", s.Type)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for name, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// ValidateJSON 校验 JSON 文本
+func (s *Schema) ValidateJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return &ValidationError{Message: "invalid json: " + err.Error()}
+	}
+	if dec.More() {
+		return &ValidationError{Message: "invalid json: trailing data"}
+	}
+	return s.Validate(v)
+}
+
+// Validate 校验已解析的 JSON 值，数字可以是 float64 或 json.Number
+func (s *Schema) Validate(v any) error {
+	return s.validate("", v)
+}
+
+func (s *Schema) validate(path string, v any) error {
+	if s.Type != "" && !isType(v, s.Type) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be %s", s.Type)}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be one of %v", s.Enum)}
+	}
+	switch val := v.(type) {
+	case string:
+		return s.validateString(path, val)
+	case json.Number, float64:
+		return s.validateNumber(path, toFloat(val))
+	case map[string]any:
+		return s.validateObject(path, val)
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range val {
+			if err := s.Items.validate(fmt.Sprintf("%s/%d", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(path, v string) error {
+	n := utf8.RuneCountInString(v)
+	if s.MinLength != nil && n < *s.MinLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("length must be >= %d", *s.MinLength)}
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("length must be <= %d", *s.MaxLength)}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must match %q", s.Pattern)}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(path string, v float64) error {
+	if s.Minimum != nil && v < *s.Minimum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", *s.Minimum)}
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", *s.Maximum)}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(path string, v map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			return &ValidationError{Path: path + "/" + name, Message: "is required"}
+		}
+	}
+	// 按键名排序，保证多处出错时返回的错误稳定
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return &ValidationError{Path: path + "/" + name, Message: "is not allowed"}
+			}
+			continue
+		}
+		if err := p.validate(path+"/"+name, v[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isType 判断值是否符合 JSON Schema 类型
+func isType(v any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		switch v.(type) {
+		case json.Number, float64:
+			return true
+		}
+	case "integer":
+		switch v.(type) {
+		case json.Number, float64:
+			f := toFloat(v)
+			return f == math.Trunc(f)
+		}
+	}
+	return false
+}
+
+// equal 比较 enum 取值，数字按数值比较
+func equal(a, b any) bool {
+	if isType(a, "number") && isType(b, "number") {
+		return toFloat(a) == toFloat(b)
+	}
+	return a == b
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	}
+	return math.NaN()
+}
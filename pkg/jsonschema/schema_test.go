@@ -0,0 +1,71 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+)
+
+const testSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1, "maxLength": 4},
+    "code": {"type": "string", "pattern": "^[a-z]+$"},
+    "level": {"type": "integer", "minimum": 0, "maximum": 9},
+    "switch": {"type": "integer", "enum": [0, 1]},
+    "lang": {"type": "string", "enum": ["zh_CN", "en_US"]},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "extra": {"type": "object"}
+  }
+}`
+
+func TestValidateJSON(t *testing.T) {
+	s := MustCompile([]byte(testSchema))
+
+	valid := `{"name":"中文名称","code":"ab","level":3,"switch":1,"lang":"en_US","tags":["a"],"extra":{"any":[1]}}`
+	if err := s.ValidateJSON([]byte(valid)); err != nil {
+		t.Errorf("valid: %v", err)
+	}
+
+	cases := []struct {
+		doc  string
+		path string
+	}{
+		{`[]`, ""},
+		{`{"name":"a"} {}`, ""},
+		{`{}`, "/name"},
+		{`{"name":""}`, "/name"},
+		{`{"name":"abcde"}`, "/name"},
+		{`{"name":"a","code":"A1"}`, "/code"},
+		{`{"name":"a","level":1.5}`, "/level"},
+		{`{"name":"a","level":10}`, "/level"},
+		{`{"name":"a","switch":2}`, "/switch"},
+		{`{"name":"a","lang":"fr"}`, "/lang"},
+		{`{"name":"a","tags":["a",1]}`, "/tags/1"},
+		{`{"name":"a","unknown":1}`, "/unknown"},
+	}
+	for _, tc := range cases {
+		err := s.ValidateJSON([]byte(tc.doc))
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("ValidateJSON(%s) = %v, want ValidationError", tc.doc, err)
+			continue
+		}
+		if ve.Path != tc.path {
+			t.Errorf("ValidateJSON(%s) path = %q, want %q", tc.doc, ve.Path, tc.path)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, s := range []string{
+		`{"type":"date"}`,
+		`{"type":"string","pattern":"("}`,
+		`{"properties":{"a":{"type":"map"}}}`,
+	} {
+		if _, err := Compile([]byte(s)); err == nil {
+			t.Errorf("Compile(%s) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ldap
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// Config LDAP / Active Directory 认证配置
+type Config struct {
+	// 是否启用
+	Enable bool `json:"enable" yaml:"enable"`
+	// 服务器地址，如 ldap://ldap.example.com:389 或 ldaps://ad.example.com:636
+	URL string `json:"url" yaml:"url"`
+	// 使用 StartTLS 升级 ldap:// 连接
+	StartTLS bool `json:"start_tls" yaml:"start_tls"`
+	// 跳过服务器证书校验，仅用于测试环境
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	// 用于查找用户的服务账号，为空时匿名查找
+	BindDN       string `json:"bind_dn" yaml:"bind_dn"`
+	BindPassword string `json:"bind_password" yaml:"bind_password"`
+	// 查找用户的根节点
+	BaseDN string `json:"base_dn" yaml:"base_dn"`
+	// 用户过滤条件，默认 (objectClass=person)；AD 可使用 (&(objectClass=user)(!(userAccountControl:1.2.840.113556.1.4.803:=2)))
+	UserFilter string `json:"user_filter" yaml:"user_filter"`
+	// 用户名属性，默认 uid；AD 使用 sAMAccountName
+	UsernameAttribute string `json:"username_attribute" yaml:"username_attribute"`
+	// 邮箱属性，默认 mail
+	EmailAttribute string `json:"email_attribute" yaml:"email_attribute"`
+	// 用户所属组属性，默认 memberOf
+	GroupAttribute string `json:"group_attribute" yaml:"group_attribute"`
+	// 组DN到角色编码的映射规则，按顺序匹配第一条
+	RoleMappings []RoleMapping `json:"role_mappings" yaml:"role_mappings"`
+	// 没有规则匹配时分配的角色，为空时拒绝登录
+	DefaultRole string `json:"default_role" yaml:"default_role"`
+	// 首次登录时自动创建用户
+	AutoCreate bool `json:"auto_create" yaml:"auto_create"`
+	// 每次登录及同步时按映射规则同步用户角色
+	SyncRole bool `json:"sync_role" yaml:"sync_role"`
+	// 连接及操作超时，默认 10s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// 定时同步用户
+	Sync SyncConfig `json:"sync" yaml:"sync"`
+}
+
+// RoleMapping 组DN到角色编码的映射规则，Group 不区分大小写并支持 path.Match 通配符
+type RoleMapping struct {
+	Group string `json:"group" yaml:"group"`
+	Role  string `json:"role" yaml:"role"`
+}
+
+// SyncConfig 定时同步配置
+type SyncConfig struct {
+	// 是否启用
+	Enable bool `json:"enable" yaml:"enable"`
+	// cron 表达式（含秒），默认每小时同步一次
+	Spec string `json:"spec" yaml:"spec"`
+	// 禁用目录中已不存在的用户
+	DisableMissing bool `json:"disable_missing" yaml:"disable_missing"`
+}
+
+// SyncSpec 返回同步任务的 cron 表达式
+func (c *SyncConfig) SyncSpec() string {
+	if c.Spec == "" {
+		return "0 0 * * * *"
+	}
+	return c.Spec
+}
+
+func (c *Config) userFilter() string {
+	if c.UserFilter == "" {
+		return "(objectClass=person)"
+	}
+	return c.UserFilter
+}
+
+func (c *Config) usernameAttribute() string {
+	if c.UsernameAttribute == "" {
+		return "uid"
+	}
+	return c.UsernameAttribute
+}
+
+func (c *Config) emailAttribute() string {
+	if c.EmailAttribute == "" {
+		return "mail"
+	}
+	return c.EmailAttribute
+}
+
+func (c *Config) groupAttribute() string {
+	if c.GroupAttribute == "" {
+		return "memberOf"
+	}
+	return c.GroupAttribute
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return c.Timeout
+}
+
+// MapRole 按映射规则计算角色编码，没有规则匹配时返回 DefaultRole
+func (c *Config) MapRole(groups []string) string {
+	for _, m := range c.RoleMappings {
+		pattern := strings.ToLower(m.Group)
+		for _, g := range groups {
+			if ok, _ := path.Match(pattern, strings.ToLower(g)); ok {
+				return m.Role
+			}
+		}
+	}
+	return c.DefaultRole
+}
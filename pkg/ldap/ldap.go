@@ -0,0 +1,153 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// ErrUserNotFound 目录中不存在该用户
+	ErrUserNotFound = errors.New("ldap: user not found")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+)
+
+// 同步用户时的分页大小
+const pageSize = 500
+
+// Entry 目录中的用户
+type Entry struct {
+	DN       string
+	Username string
+	Email    string
+	Groups   []string
+}
+
+// Directory LDAP 目录客户端，每次操作使用独立连接
+type Directory struct {
+	cfg Config
+}
+
+// New 创建目录客户端
+func New(cfg Config) *Directory {
+	return &Directory{cfg: cfg}
+}
+
+// Config 返回目录配置
+func (d *Directory) Config() *Config {
+	return &d.cfg
+}
+
+// Enabled 是否启用了 LDAP 认证
+func (d *Directory) Enabled() bool {
+	return d != nil && d.cfg.Enable
+}
+
+// Authenticate 使用服务账号查找用户后，以用户DN及密码绑定校验凭证
+func (d *Directory) Authenticate(username, password string) (*Entry, error) {
+	// 空密码会被服务器视为匿名绑定而成功，必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))",
+		d.cfg.userFilter(), d.cfg.usernameAttribute(), ldap.EscapeFilter(username))
+	result, err := conn.Search(d.searchRequest(filter, 2))
+	if err != nil {
+		return nil, fmt.Errorf("ldap: search user: %w", err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, fmt.Errorf("ldap: username %q matches %d entries", username, len(result.Entries))
+	}
+
+	entry := d.entry(result.Entries[0])
+	if err = conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap: bind user: %w", err)
+	}
+	return entry, nil
+}
+
+// SearchUsers 获取目录中符合过滤条件的全部用户
+func (d *Directory) SearchUsers() ([]*Entry, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.SearchWithPaging(d.searchRequest(d.cfg.userFilter(), 0), pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: search users: %w", err)
+	}
+	entries := make([]*Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		if entry := d.entry(e); entry.Username != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// connect 建立连接并以服务账号绑定
+func (d *Directory) connect() (*ldap.Conn, error) {
+	tlsCfg := &tls.Config{InsecureSkipVerify: d.cfg.InsecureSkipVerify} //nolint:gosec // 由配置显式开启
+	conn, err := ldap.DialURL(d.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.cfg.timeout()}),
+		ldap.DialWithTLSConfig(tlsCfg))
+	if err != nil {
+		return nil, fmt.Errorf("ldap: dial: %w", err)
+	}
+	conn.SetTimeout(d.cfg.timeout())
+
+	if d.cfg.StartTLS {
+		if err = conn.StartTLS(tlsCfg); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap: start tls: %w", err)
+		}
+	}
+	if d.cfg.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(d.cfg.BindDN, d.cfg.BindPassword)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ldap: bind service account: %w", err)
+	}
+	return conn, nil
+}
+
+func (d *Directory) searchRequest(filter string, sizeLimit int) *ldap.SearchRequest {
+	return ldap.NewSearchRequest(
+		d.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		sizeLimit, int(d.cfg.timeout().Seconds()), false,
+		filter,
+		[]string{d.cfg.usernameAttribute(), d.cfg.emailAttribute(), d.cfg.groupAttribute()},
+		nil,
+	)
+}
+
+func (d *Directory) entry(e *ldap.Entry) *Entry {
+	return &Entry{
+		DN:       e.DN,
+		Username: e.GetEqualFoldAttributeValue(d.cfg.usernameAttribute()),
+		Email:    e.GetEqualFoldAttributeValue(d.cfg.emailAttribute()),
+		Groups:   e.GetEqualFoldAttributeValues(d.cfg.groupAttribute()),
+	}
+}
@@ -0,0 +1,160 @@
+package ldap_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"goadmin/pkg/ldap"
+	"goadmin/pkg/ldap/ldaptest"
+)
+
+const (
+	serviceDN = "cn=svc,ou=system,dc=example,dc=com"
+	adminsDN  = "cn=admins,ou=groups,dc=example,dc=com"
+	staffDN   = "cn=staff,ou=groups,dc=example,dc=com"
+)
+
+func newServer(t *testing.T) *ldaptest.Server {
+	t.Helper()
+	srv, err := ldaptest.NewServer(
+		&ldaptest.Entry{DN: serviceDN, Password: "svc-secret"},
+		&ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "alice-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+				"mail":        {"alice@example.com"},
+				"memberOf":    {staffDN, adminsDN},
+			},
+		},
+		&ldaptest.Entry{
+			DN:       "uid=bob,ou=people,dc=example,dc=com",
+			Password: "bob-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"bob"},
+				"memberOf":    {staffDN},
+			},
+		},
+		&ldaptest.Entry{
+			DN:         "cn=printer,ou=devices,dc=example,dc=com",
+			Attributes: map[string][]string{"objectClass": {"device"}, "uid": {"printer"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("start ldap server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newDirectory(srv *ldaptest.Server) *ldap.Directory {
+	return ldap.New(ldap.Config{
+		Enable:       true,
+		URL:          srv.URL(),
+		BindDN:       serviceDN,
+		BindPassword: "svc-secret",
+		BaseDN:       "dc=example,dc=com",
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	srv := newServer(t)
+	dir := newDirectory(srv)
+
+	entry, err := dir.Authenticate("alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if entry.DN != "uid=alice,ou=people,dc=example,dc=com" || entry.Username != "alice" ||
+		entry.Email != "alice@example.com" || !slices.Contains(entry.Groups, adminsDN) {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if binds := srv.Binds(); len(binds) != 2 || binds[0] != serviceDN || binds[1] != entry.DN {
+		t.Fatalf("binds = %v, want service account then user", binds)
+	}
+}
+
+func TestAuthenticateFailures(t *testing.T) {
+	srv := newServer(t)
+	dir := newDirectory(srv)
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+		want     error
+	}{
+		{"wrong password", "alice", "nope", ldap.ErrInvalidCredentials},
+		{"empty password", "alice", "", ldap.ErrInvalidCredentials},
+		{"unknown user", "carol", "secret", ldap.ErrUserNotFound},
+		{"filtered out by objectClass", "printer", "secret", ldap.ErrUserNotFound},
+		{"filter injection", "*)(uid=*", "secret", ldap.ErrUserNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := dir.Authenticate(tc.username, tc.password); !errors.Is(err, tc.want) {
+				t.Fatalf("Authenticate err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateServiceAccountRejected(t *testing.T) {
+	srv := newServer(t)
+	dir := ldap.New(ldap.Config{
+		URL:          srv.URL(),
+		BindDN:       serviceDN,
+		BindPassword: "wrong",
+		BaseDN:       "dc=example,dc=com",
+	})
+	_, err := dir.Authenticate("alice", "alice-secret")
+	if err == nil || errors.Is(err, ldap.ErrInvalidCredentials) || errors.Is(err, ldap.ErrUserNotFound) {
+		t.Fatalf("Authenticate err = %v, want a service bind error", err)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	srv := newServer(t)
+	entries, err := newDirectory(srv).SearchUsers()
+	if err != nil {
+		t.Fatalf("SearchUsers: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Username)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"alice", "bob"}) {
+		t.Fatalf("users = %v", names)
+	}
+}
+
+func TestMapRole(t *testing.T) {
+	cfg := ldap.Config{
+		RoleMappings: []ldap.RoleMapping{
+			{Group: "CN=Admins,OU=Groups,DC=example,DC=com", Role: "sup_admin"},
+			{Group: "cn=*-ops,ou=groups,dc=example,dc=com", Role: "ops"},
+		},
+		DefaultRole: "viewer",
+	}
+	cases := []struct {
+		name   string
+		groups []string
+		want   string
+	}{
+		{"case insensitive", []string{staffDN, adminsDN}, "sup_admin"},
+		{"glob", []string{"cn=db-ops,ou=groups,dc=example,dc=com"}, "ops"},
+		{"default", []string{staffDN}, "viewer"},
+		{"no groups", nil, "viewer"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.MapRole(tc.groups); got != tc.want {
+				t.Fatalf("MapRole = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
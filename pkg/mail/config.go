@@ -0,0 +1,53 @@
+package mail
+
+import "time"
+
+// 发送驱动
+const (
+	DriverSMTP    = "smtp"    // 通过 SMTP 服务器发送
+	DriverFile    = "file"    // 写入 .eml 文件，用于开发及测试
+	DriverConsole = "console" // 输出到控制台，用于开发
+)
+
+// SMTP 加密方式
+const (
+	EncryptionNone     = "none"
+	EncryptionSTARTTLS = "starttls"
+	EncryptionSSL      = "ssl"
+)
+
+// Config 邮件配置
+type Config struct {
+	// 发送驱动 smtp/file/console，为空时输出到控制台
+	Driver string `json:"driver" yaml:"driver"`
+	// SMTP 服务器地址
+	Host string `json:"host" yaml:"host"`
+	// SMTP 服务器端口
+	Port int `json:"port" yaml:"port"`
+	// SMTP 认证用户名，为空时不认证
+	Username string `json:"username" yaml:"username"`
+	// SMTP 认证密码
+	Password string `json:"password" yaml:"password"`
+	// 加密方式 none/starttls/ssl
+	Encryption string `json:"encryption" yaml:"encryption"`
+	// 发件人地址
+	From string `json:"from" yaml:"from"`
+	// 发件人名称
+	FromName string `json:"from_name" yaml:"from_name"`
+	// 连接及发送超时
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// file 驱动的输出目录
+	Dir string `json:"dir" yaml:"dir"`
+}
+
+// DefaultConfig 返回默认的邮件配置
+func DefaultConfig() *Config {
+	return &Config{
+		Driver:     DriverConsole,
+		Port:       587,
+		Encryption: EncryptionSTARTTLS,
+		From:       "noreply@localhost",
+		Timeout:    10 * time.Second,
+		Dir:        "./logs/mail",
+	}
+}
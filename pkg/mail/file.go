@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSender 将邮件写入目录下的 .eml 文件，用于开发及测试环境
+type FileSender struct {
+	cfg Config
+}
+
+// NewFileSender 创建文件邮件发送器
+func NewFileSender(cfg Config) (*FileSender, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultConfig().Dir
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mail: create dir %s: %w", cfg.Dir, err)
+	}
+	return &FileSender{cfg: cfg}, nil
+}
+
+// Send 将邮件原文写入文件，文件名为发送时间
+func (s *FileSender) Send(_ context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	data, err := msg.build(s.cfg.from(), now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%09d.eml", now.Format("20060102150405"), now.Nanosecond())
+	return os.WriteFile(filepath.Join(s.cfg.Dir, name), data, 0o600)
+}
+
+// ConsoleSender 将邮件原文输出到指定 Writer，用于开发环境
+type ConsoleSender struct {
+	cfg Config
+	mu  sync.Mutex
+	w   io.Writer
+}
+
+// NewConsoleSender 创建控制台邮件发送器
+func NewConsoleSender(cfg Config, w io.Writer) *ConsoleSender {
+	return &ConsoleSender{cfg: cfg, w: w}
+}
+
+// Send 输出邮件原文
+func (s *ConsoleSender) Send(_ context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	data, err := msg.build(s.cfg.from(), time.Now())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintf(s.w, "----- mail -----\n%s\n----- end -----\n", data)
+	return err
+}
@@ -0,0 +1,196 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer 本地 SMTP 替身，记录收到的命令及邮件原文
+type fakeSMTPServer struct {
+	ln       net.Listener
+	commands []string
+	data     []byte
+	done     chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := &fakeSMTPServer{ln: ln, done: make(chan struct{})}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		s.commands = append(s.commands, cmd)
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL", "RCPT":
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var buf bytes.Buffer
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				buf.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data = buf.Bytes()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender, err := New(Config{
+		Driver:     DriverSMTP,
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		Username:   "user",
+		Password:   "secret",
+		Encryption: EncryptionNone,
+		From:       "noreply@example.com",
+		FromName:   "GoAdmin",
+		Timeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("创建发送器失败: %v", err)
+	}
+
+	err = sender.Send(context.Background(), &Message{
+		To:      []string{"alice@example.com"},
+		Subject: "重置密码",
+		Text:    "点击链接重置密码",
+		HTML:    "<p>点击链接重置密码</p>",
+	})
+	if err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	<-server.done
+
+	joined := strings.Join(server.commands, "\n")
+	for _, want := range []string{"AUTH PLAIN", "MAIL FROM:<noreply@example.com>", "RCPT TO:<alice@example.com>"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("缺少命令 %q，实际为:\n%s", want, joined)
+		}
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "重置密码" {
+		t.Errorf("主题错误: %s", subject)
+	}
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type 错误: %s", mediaType)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("读取分段失败: %v", err)
+		}
+		b, _ := io.ReadAll(quotedprintable.NewReader(p))
+		parts = append(parts, string(b))
+	}
+	if len(parts) != 2 || parts[0] != "点击链接重置密码" || parts[1] != "<p>点击链接重置密码</p>" {
+		t.Errorf("正文错误: %q", parts)
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := New(Config{Driver: DriverFile, Dir: dir, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatalf("创建发送器失败: %v", err)
+	}
+	if err = sender.Send(context.Background(), &Message{To: []string{"Bob <bob@example.com>"}, Subject: "hi", Text: "hello"}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("应生成1个邮件文件，实际为%d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if msg.Header.Get("To") != "Bob <bob@example.com>" || string(body) != "hello" {
+		t.Errorf("邮件内容错误: %s %q", msg.Header.Get("To"), body)
+	}
+}
+
+func TestSendInvalidRecipient(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewConsoleSender(Config{From: "noreply@example.com"}, &buf)
+	if err := sender.Send(context.Background(), &Message{Subject: "hi"}); err == nil {
+		t.Error("无收件人时应发送失败")
+	}
+	if err := sender.Send(context.Background(), &Message{To: []string{"not-an-email"}}); err == nil {
+		t.Error("收件人地址非法时应发送失败")
+	}
+	if buf.Len() != 0 {
+		t.Error("发送失败时不应输出邮件")
+	}
+}
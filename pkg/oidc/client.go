@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrNonceMismatch ID令牌中的 nonce 与登录请求不一致
+var ErrNonceMismatch = errors.New("oidc: nonce mismatch")
+
+// Identity 身份提供方认证通过的用户身份
+type Identity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Claims   Claims
+}
+
+// Client OIDC 依赖方客户端，实现带 PKCE 的授权码流程
+//
+// 首次使用时才访问身份提供方进行自动发现，失败时下次调用会重试，避免身份提供方不可用时影响服务启动
+type Client struct {
+	cfg Config
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewClient 创建 OIDC 客户端
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg}
+}
+
+// Config 返回客户端配置
+func (c *Client) Config() *Config {
+	return &c.cfg
+}
+
+// Enabled 是否启用了 OIDC 登录
+func (c *Client) Enabled() bool {
+	return c != nil && c.cfg.Enable
+}
+
+// GenerateVerifier 生成 PKCE code_verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// discover 自动发现身份提供方的端点及签名公钥
+func (c *Client) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.oauth != nil {
+		return c.oauth, c.verifier, nil
+	}
+
+	// 公钥由 provider 在后台按需刷新，不能使用请求级别的 context
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(context.Background(), httpClient(ctx)), c.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	c.oauth = &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       c.cfg.scopes(),
+	}
+	c.verifier = provider.Verifier(&gooidc.Config{ClientID: c.cfg.ClientID})
+	return c.oauth, c.verifier, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, _, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange 使用授权码换取令牌，校验 ID 令牌签名、签发方、受众、有效期及 nonce
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	oauth, idVerifier, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims Claims
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("oidc: decode claims: %w", err)
+	}
+	return &Identity{
+		Issuer:   idToken.Issuer,
+		Subject:  idToken.Subject,
+		Username: claims.String(c.cfg.usernameClaim()),
+		Email:    claims.String(c.cfg.emailClaim()),
+		Claims:   claims,
+	}, nil
+}
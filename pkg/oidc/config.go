@@ -0,0 +1,112 @@
+package oidc
+
+import (
+	"path"
+	"slices"
+)
+
+// Config OIDC 单点登录配置
+type Config struct {
+	// 是否启用
+	Enable bool `json:"enable" yaml:"enable"`
+	// 登录按钮显示的名称
+	Name string `json:"name" yaml:"name"`
+	// 身份提供方地址，用于自动发现 /.well-known/openid-configuration
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// 客户端ID
+	ClientID string `json:"client_id" yaml:"client_id"`
+	// 客户端密钥，公共客户端可为空（仅使用 PKCE）
+	ClientSecret string `json:"client_secret" yaml:"client_secret"`
+	// 本服务的回调地址，需在身份提供方登记
+	RedirectURL string `json:"redirect_url" yaml:"redirect_url"`
+	// 申请的 scope，默认 openid profile email
+	Scopes []string `json:"scopes" yaml:"scopes"`
+	// 用作用户名的声明，默认 preferred_username
+	UsernameClaim string `json:"username_claim" yaml:"username_claim"`
+	// 用作邮箱的声明，默认 email
+	EmailClaim string `json:"email_claim" yaml:"email_claim"`
+	// 声明到角色编码的映射规则，按顺序匹配第一条
+	RoleMappings []RoleMapping `json:"role_mappings" yaml:"role_mappings"`
+	// 没有规则匹配时分配的角色，为空时拒绝登录
+	DefaultRole string `json:"default_role" yaml:"default_role"`
+	// 首次登录时自动创建用户
+	AutoCreate bool `json:"auto_create" yaml:"auto_create"`
+	// 每次登录时按映射规则同步用户角色
+	SyncRole bool `json:"sync_role" yaml:"sync_role"`
+	// 登录完成后跳转的前端页面，令牌以 URL fragment 传递；为空时回调直接返回 JSON
+	SuccessURL string `json:"success_url" yaml:"success_url"`
+}
+
+// RoleMapping 声明到角色编码的映射规则
+//
+// 声明值为字符串或字符串数组（如 groups），任一值匹配 Value 即命中，Value 支持 path.Match 通配符
+type RoleMapping struct {
+	Claim string `json:"claim" yaml:"claim"`
+	Value string `json:"value" yaml:"value"`
+	Role  string `json:"role" yaml:"role"`
+}
+
+func (c *Config) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return []string{"openid", "profile", "email"}
+	}
+	if !slices.Contains(c.Scopes, "openid") {
+		return append([]string{"openid"}, c.Scopes...)
+	}
+	return c.Scopes
+}
+
+func (c *Config) usernameClaim() string {
+	if c.UsernameClaim == "" {
+		return "preferred_username"
+	}
+	return c.UsernameClaim
+}
+
+func (c *Config) emailClaim() string {
+	if c.EmailClaim == "" {
+		return "email"
+	}
+	return c.EmailClaim
+}
+
+// MapRole 按映射规则计算角色编码，没有规则匹配时返回 DefaultRole
+func (c *Config) MapRole(claims Claims) string {
+	for _, m := range c.RoleMappings {
+		for _, v := range claims.Strings(m.Claim) {
+			if ok, _ := path.Match(m.Value, v); ok {
+				return m.Role
+			}
+		}
+	}
+	return c.DefaultRole
+}
+
+// Claims ID令牌中的声明
+type Claims map[string]any
+
+// String 获取字符串类型的声明
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings 获取字符串或字符串数组类型的声明
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,16 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// httpClient 返回 context 中通过 oauth2.HTTPClient 指定的客户端，便于测试替换
+func httpClient(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
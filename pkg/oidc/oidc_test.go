@@ -0,0 +1,175 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"goadmin/pkg/oidc"
+	"goadmin/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://admin.example.com/admin/v1/user/oidc/callback"
+
+func newClient(idp *oidctest.IdP) *oidc.Client {
+	return oidc.NewClient(oidc.Config{
+		Enable:       true,
+		Issuer:       idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  redirectURL,
+	})
+}
+
+// authorize 访问授权地址，返回身份提供方回调携带的授权码
+func authorize(t *testing.T, authURL, state string) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", resp.StatusCode)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse location: %v", err)
+	}
+	if loc.Query().Get("state") != state {
+		t.Fatalf("state = %q, want %q", loc.Query().Get("state"), state)
+	}
+	return loc.Query().Get("code")
+}
+
+func TestClientLoginFlow(t *testing.T) {
+	idp := oidctest.NewIdP("goadmin", "secret")
+	defer idp.Close()
+	idp.Claims = map[string]any{
+		"sub":                "u-1001",
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"groups":             []string{"staff", "ops-admin"},
+	}
+
+	c := newClient(idp)
+	ctx := context.Background()
+	verifier := oidc.GenerateVerifier()
+	authURL, err := c.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	q, _ := url.Parse(authURL)
+	if q.Query().Get("code_challenge_method") != "S256" || q.Query().Get("nonce") != "nonce-1" {
+		t.Fatalf("auth url missing pkce or nonce: %s", authURL)
+	}
+
+	code := authorize(t, authURL, "state-1")
+	id, err := c.Exchange(ctx, code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if id.Issuer != idp.Issuer() || id.Subject != "u-1001" || id.Username != "alice" || id.Email != "alice@example.com" {
+		t.Fatalf("identity = %+v", id)
+	}
+	if groups := id.Claims.Strings("groups"); len(groups) != 2 || groups[1] != "ops-admin" {
+		t.Fatalf("groups = %v", groups)
+	}
+}
+
+func TestClientRejectsWrongVerifier(t *testing.T) {
+	idp := oidctest.NewIdP("goadmin", "secret")
+	defer idp.Close()
+	idp.Claims = map[string]any{"sub": "u-1"}
+
+	c := newClient(idp)
+	ctx := context.Background()
+	authURL, err := c.AuthCodeURL(ctx, "s", "n", oidc.GenerateVerifier())
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := authorize(t, authURL, "s")
+	if _, err = c.Exchange(ctx, code, oidc.GenerateVerifier(), "n"); err == nil {
+		t.Fatal("Exchange with wrong verifier succeeded")
+	}
+}
+
+func TestClientRejectsNonceMismatch(t *testing.T) {
+	idp := oidctest.NewIdP("goadmin", "secret")
+	defer idp.Close()
+	idp.Claims = map[string]any{"sub": "u-1"}
+	idp.Nonce = "replayed"
+
+	c := newClient(idp)
+	ctx := context.Background()
+	verifier := oidc.GenerateVerifier()
+	authURL, err := c.AuthCodeURL(ctx, "s", "n", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := authorize(t, authURL, "s")
+	if _, err = c.Exchange(ctx, code, verifier, "n"); !errors.Is(err, oidc.ErrNonceMismatch) {
+		t.Fatalf("Exchange err = %v, want ErrNonceMismatch", err)
+	}
+}
+
+func TestClientRejectsForeignIssuer(t *testing.T) {
+	idp := oidctest.NewIdP("goadmin", "secret")
+	defer idp.Close()
+	other := oidctest.NewIdP("goadmin", "secret")
+	defer other.Close()
+	other.Claims = map[string]any{"sub": "u-1"}
+
+	// 发现信息来自 idp，令牌由 other 签发，签名与签发方均不匹配
+	c := newClient(idp)
+	ctx := context.Background()
+	verifier := oidc.GenerateVerifier()
+	authURL, err := oidc.NewClient(oidc.Config{
+		Issuer: other.Issuer(), ClientID: "goadmin", ClientSecret: "secret", RedirectURL: redirectURL,
+	}).AuthCodeURL(ctx, "s", "n", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := authorize(t, authURL, "s")
+	if _, err = c.Exchange(ctx, code, verifier, "n"); err == nil {
+		t.Fatal("Exchange accepted a code from another issuer")
+	}
+}
+
+func TestMapRole(t *testing.T) {
+	cfg := oidc.Config{
+		RoleMappings: []oidc.RoleMapping{
+			{Claim: "groups", Value: "*-admin", Role: "admin"},
+			{Claim: "department", Value: "finance", Role: "finance"},
+		},
+		DefaultRole: "viewer",
+	}
+	cases := []struct {
+		name   string
+		claims oidc.Claims
+		want   string
+	}{
+		{"glob on array claim", oidc.Claims{"groups": []any{"staff", "ops-admin"}}, "admin"},
+		{"string claim", oidc.Claims{"department": "finance"}, "finance"},
+		{"first rule wins", oidc.Claims{"groups": []any{"hr-admin"}, "department": "finance"}, "admin"},
+		{"default role", oidc.Claims{"groups": []any{"staff"}}, "viewer"},
+		{"missing claim", oidc.Claims{}, "viewer"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.MapRole(tc.claims); got != tc.want {
+				t.Fatalf("MapRole = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	cfg.DefaultRole = ""
+	if got := cfg.MapRole(oidc.Claims{}); got != "" {
+		t.Fatalf("MapRole without default = %q, want empty", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package routetrie 提供按 HTTP 方法及路径模式匹配的路由前缀树
+//
+// 路径模式与 gin 一致：以 / 分隔的各段可以是静态文本、:name 参数（匹配一段）
+// 或 *name 通配（匹配剩余的一段或多段，只能位于末尾）。
+// 多个模式同时匹配时，逐段按 静态 > 参数 > 通配 的优先级取最具体的模式；
+// 同一模式下指定方法优先于任意方法。
+package routetrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnyMethod 表示匹配任意 HTTP 方法
+const AnyMethod = ""
+
+// Trie 路由前缀树，构建完成后可并发读取
+type Trie[T any] struct {
+	root *node[T]
+	size int
+}
+
+type node[T any] struct {
+	static   map[string]*node[T]
+	param    *node[T]
+	catchAll *node[T]
+	values   map[string][]T // 方法 -> 值
+}
+
+// New 创建空的路由前缀树
+func New[T any]() *Trie[T] {
+	return &Trie[T]{root: &node[T]{}}
+}
+
+// Len 返回已添加的模式数量
+func (t *Trie[T]) Len() int {
+	return t.size
+}
+
+// Add 添加模式及其对应的值，method 为空表示任意方法
+//
+// 相同方法与模式可添加多个值，匹配时一并返回
+func (t *Trie[T]) Add(method, pattern string, value T) error {
+	segs := split(pattern)
+	n := t.root
+	for i, seg := range segs {
+		switch seg[0] {
+		case ':':
+			if len(seg) == 1 {
+				return fmt.Errorf("routetrie: 参数缺少名称: %q", pattern)
+			}
+			if n.param == nil {
+				n.param = &node[T]{}
+			}
+			n = n.param
+		case '*':
+			if i != len(segs)-1 {
+				return fmt.Errorf("routetrie: 通配只能位于末尾: %q", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll = &node[T]{}
+			}
+			n = n.catchAll
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*node[T])
+			}
+			child, ok := n.static[seg]
+			if !ok {
+				child = &node[T]{}
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+	if n.values == nil {
+		n.values = make(map[string][]T)
+	}
+	method = strings.ToUpper(method)
+	n.values[method] = append(n.values[method], value)
+	t.size++
+	return nil
+}
+
+// Match 返回与请求方法及路径匹配的最具体模式的值
+func (t *Trie[T]) Match(method, path string) ([]T, bool) {
+	values := t.root.match(strings.ToUpper(method), split(path))
+	return values, len(values) > 0
+}
+
+func (n *node[T]) match(method string, segs []string) []T {
+	if len(segs) == 0 {
+		return n.lookup(method)
+	}
+	if child, ok := n.static[segs[0]]; ok {
+		if values := child.match(method, segs[1:]); len(values) > 0 {
+			return values
+		}
+	}
+	if n.param != nil {
+		if values := n.param.match(method, segs[1:]); len(values) > 0 {
+			return values
+		}
+	}
+	if n.catchAll != nil {
+		return n.catchAll.lookup(method)
+	}
+	return nil
+}
+
+func (n *node[T]) lookup(method string) []T {
+	if values := n.values[method]; len(values) > 0 {
+		return values
+	}
+	return n.values[AnyMethod]
+}
+
+// split 去除首尾的 / 后按 / 分段，忽略空段
+func split(path string) []string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	segs := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			segs = append(segs, p)
+		}
+	}
+	return segs
+}
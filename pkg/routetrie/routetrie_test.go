@@ -0,0 +1,111 @@
+package routetrie
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func build(t *testing.T, routes [][3]string) *Trie[string] {
+	t.Helper()
+	tr := New[string]()
+	for _, r := range routes {
+		if err := tr.Add(r[0], r[1], r[2]); err != nil {
+			t.Fatalf("Add(%q, %q): %v", r[0], r[1], err)
+		}
+	}
+	return tr
+}
+
+func TestMatchOverlappingPatterns(t *testing.T) {
+	tr := build(t, [][3]string{
+		{"", "admin/v1/tenant/list", "tenant_list"},
+		{http.MethodGet, "admin/v1/tenant/:id", "tenant_info"},
+		{http.MethodPut, "admin/v1/tenant/:id", "tenant_update"},
+		{http.MethodDelete, "admin/v1/tenant/:id", "tenant_delete"},
+		{"", "admin/v1/tenant/:id/users", "tenant_users"},
+		{"", "admin/v1/tenant/:id/users/:uid", "tenant_user_info"},
+		{"", "admin/v1/tenant/default/users", "tenant_default_users"},
+		{"", "admin/v1/files/*path", "file_read"},
+		{http.MethodPost, "admin/v1/files/upload", "file_upload"},
+		{"", "admin/v1/*rest", "admin_fallback"},
+	})
+
+	cases := []struct {
+		method, path string
+		want         []string
+	}{
+		// 静态优先于参数
+		{http.MethodGet, "admin/v1/tenant/list", []string{"tenant_list"}},
+		{http.MethodGet, "/admin/v1/tenant/42", []string{"tenant_info"}},
+		{http.MethodPut, "admin/v1/tenant/42", []string{"tenant_update"}},
+		{http.MethodDelete, "admin/v1/tenant/42/", []string{"tenant_delete"}},
+		{http.MethodGet, "admin/v1/tenant/default/users", []string{"tenant_default_users"}},
+		{http.MethodGet, "admin/v1/tenant/7/users", []string{"tenant_users"}},
+		{http.MethodGet, "admin/v1/tenant/7/users/9", []string{"tenant_user_info"}},
+		// 静态分支走不通时回溯到参数分支
+		{http.MethodGet, "admin/v1/tenant/default/users/9", []string{"tenant_user_info"}},
+		// 指定方法的静态路径优先，其他方法回落到通配
+		{http.MethodPost, "admin/v1/files/upload", []string{"file_upload"}},
+		{http.MethodGet, "admin/v1/files/upload", []string{"file_read"}},
+		{http.MethodGet, "admin/v1/files/a/b/c.txt", []string{"file_read"}},
+		// 方法不匹配时回落到上层通配
+		{http.MethodPatch, "admin/v1/tenant/42", []string{"admin_fallback"}},
+		{http.MethodGet, "admin/v1/unknown/route", []string{"admin_fallback"}},
+		{"get", "admin/v1/tenant/42", []string{"tenant_info"}},
+	}
+	for _, c := range cases {
+		got, ok := tr.Match(c.method, c.path)
+		if !ok || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Match(%s %s) = %v, %v; want %v", c.method, c.path, got, ok, c.want)
+		}
+	}
+}
+
+func TestMatchNoRoute(t *testing.T) {
+	tr := build(t, [][3]string{
+		{"", "admin/v1/tenant/:id", "tenant_info"},
+		{"", "admin/v1/files/*path", "file_read"},
+		{http.MethodGet, "admin/v1/user/list", "user_list"},
+	})
+	cases := [][2]string{
+		{http.MethodGet, "admin/v1/tenant"},
+		{http.MethodGet, "admin/v1/tenant/1/2"},
+		// 通配至少匹配一段
+		{http.MethodGet, "admin/v1/files"},
+		{http.MethodPost, "admin/v1/user/list"},
+		{http.MethodGet, "admin/v1/user/list/extra"},
+		{http.MethodGet, ""},
+	}
+	for _, c := range cases {
+		if got, ok := tr.Match(c[0], c[1]); ok {
+			t.Errorf("Match(%s %s) = %v, want no match", c[0], c[1], got)
+		}
+	}
+}
+
+func TestMatchSamePatternReturnsAllValues(t *testing.T) {
+	tr := build(t, [][3]string{
+		{"", "admin/v1/role/:id", "role_info"},
+		{"", "admin/v1/role/:code", "role_view"},
+		{http.MethodGet, "admin/v1/role/:id", "role_get"},
+	})
+	if got, _ := tr.Match(http.MethodGet, "admin/v1/role/1"); !reflect.DeepEqual(got, []string{"role_get"}) {
+		t.Errorf("GET = %v", got)
+	}
+	if got, _ := tr.Match(http.MethodPost, "admin/v1/role/1"); !reflect.DeepEqual(got, []string{"role_info", "role_view"}) {
+		t.Errorf("POST = %v", got)
+	}
+	if tr.Len() != 3 {
+		t.Errorf("Len() = %d", tr.Len())
+	}
+}
+
+func TestAddInvalidPattern(t *testing.T) {
+	tr := New[string]()
+	for _, p := range []string{"admin/v1/:", "admin/v1/*path/more"} {
+		if err := tr.Add("", p, "x"); err == nil {
+			t.Errorf("Add(%q) should fail", p)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 )
 
 const (
@@ -45,4 +47,45 @@ func GenerateRandomString(length int) (string, error) {
 	}
 
 	return string(result), nil
-}
\ No newline at end of file
+}
+const (
+	passwordUpperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLowerChars  = "abcdefghijkmnpqrstuvwxyz"
+	passwordDigitChars  = "23456789"
+	passwordSymbolChars = "!@#$%^&*-_=+?"
+)
+
+// GenerateRandomPassword 生成随机密码，大小写字母、数字及特殊字符各至少包含一个
+//
+// 去除了 0/O、1/l/I 等易混淆字符，便于管理员转告用户
+func GenerateRandomPassword(length int) (string, error) {
+	sets := []string{passwordUpperChars, passwordLowerChars, passwordDigitChars, passwordSymbolChars}
+	if length < len(sets) {
+		return "", errors.New("length must be at least 4")
+	}
+	all := strings.Join(sets, "")
+
+	result := make([]byte, length)
+	for i := range result {
+		charset := all
+		if i < len(sets) {
+			charset = sets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random number: %w", err)
+		}
+		result[i] = charset[n.Int64()]
+	}
+
+	// 打乱顺序，避免固定位置的字符类型
+	for i := len(result) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random number: %w", err)
+		}
+		j := n.Int64()
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result), nil
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B测试向量（SHA1，8位）
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, c := range cases {
+		if got := hotp(key, uint64(c.unix/TOTPPeriod), 8); got != c.code {
+			t.Errorf("T=%d 期望 %s, 实际为 %s", c.unix, c.code, got)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, err := TOTPCode(secret, now)
+	if err != nil {
+		t.Fatalf("计算动态码失败: %v", err)
+	}
+	if len(code) != TOTPDigits {
+		t.Errorf("动态码长度应为%d，实际为%d", TOTPDigits, len(code))
+	}
+
+	counter, ok := ValidateTOTP(secret, code, now.Add(TOTPPeriod*time.Second), 1)
+	if !ok || counter != now.Unix()/TOTPPeriod {
+		t.Errorf("允许偏差内的动态码应校验通过")
+	}
+	if _, ok = ValidateTOTP(secret, code, now.Add(3*TOTPPeriod*time.Second), 1); ok {
+		t.Error("超出偏差的动态码不应校验通过")
+	}
+	if _, ok = ValidateTOTP(strings.ToLower(secret), code, now, 0); !ok {
+		t.Error("小写密钥应可正常校验")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("goadmin", "admin", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/goadmin:admin?") {
+		t.Errorf("配置链接格式错误: %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") {
+		t.Errorf("配置链接缺少密钥: %s", uri)
+	}
+}
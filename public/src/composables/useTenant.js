@@ -20,6 +20,8 @@ export function useTenant(t, locale) {
     code: '',
     contact_email: '',
     contact_phone: '',
+    admin_username: '',
+    admin_email: '',
     status: 1,
     config: ''
   })
@@ -130,8 +132,14 @@ export function useTenant(t, locale) {
           })
 
           if (response.data.code === 200) {
-            ElMessage.success(t('tenant.addSuccess'))
             addDialogVisible.value = false
+            // 初始密码仅返回一次
+            const data = response.data.data
+            ElMessageBox.alert(
+              t('tenant.provisioned', { username: data.admin_username, password: data.admin_password }),
+              t('tenant.addSuccess'),
+              { confirmButtonText: t('common.confirm'), type: 'success' }
+            )
             // 刷新列表
             fetchTenants()
           } else {
@@ -232,7 +240,7 @@ export function useTenant(t, locale) {
         })
 
         if (response.data.code === 200) {
-          ElMessage.success(t('tenant.deleteSuccess'))
+          ElMessage.success(t('tenant.deprovisioned', { archive: response.data.data.archive }))
           // 刷新列表
           fetchTenants()
         } else {
@@ -27,6 +27,11 @@ const routes = [
         name: 'Login',
         component: LoginPage
       },
+      {
+        path: 'reset-password',
+        name: 'ResetPassword',
+        component: () => import('../components/ResetPasswordPage.vue')
+      },
       {
         path: '',
         component: AppLayout,
@@ -100,8 +105,8 @@ router.beforeEach((to, from, next) => {
     return next(`/${lang}${pathWithoutLang || '/dashboard'}`)
   }
 
-  // 处理登录页面
-  if (to.path.includes('/login')) {
+  // 处理登录及找回密码页面
+  if (to.path.includes('/login') || to.path.includes('/reset-password')) {
     next()
   } else if (!token) {
     // 未登录，跳转到对应语言的登录页
@@ -7,7 +7,7 @@ export const useUserStore = defineStore('user', () => {
   const userInfo = ref({
     username: '',
     email: '',
-    role_code: '',
+    roles: [],
     permission_codes: []
   })
 
@@ -93,7 +93,7 @@ export const useUserStore = defineStore('user', () => {
     userInfo.value = {
       username: '',
       email: '',
-      role_code: '',
+      roles: [],
       permission_codes: []
     }
     permissionCodes.value = []